@@ -35,11 +35,13 @@ var (
 
 // 题目相关错误
 var (
-	ErrProblemNotFound       = ErrorCode{Code: 40200, Message: "problem not found"}
-	ErrProblemExists         = ErrorCode{Code: 40201, Message: "problem already exists"}
-	ErrProblemTagExists      = ErrorCode{Code: 40202, Message: "tag already exists"}
-	ErrProblemNoTags         = ErrorCode{Code: 40203, Message: "no tag be found"}
-	ErrProblemInternalServer = ErrorCode{Code: 50204, Message: "internal server error"}
+	ErrProblemNotFound         = ErrorCode{Code: 40200, Message: "problem not found"}
+	ErrProblemExists           = ErrorCode{Code: 40201, Message: "problem already exists"}
+	ErrProblemTagExists        = ErrorCode{Code: 40202, Message: "tag already exists"}
+	ErrProblemNoTags           = ErrorCode{Code: 40203, Message: "no tag be found"}
+	ErrProblemInternalServer   = ErrorCode{Code: 50204, Message: "internal server error"}
+	ErrProblemValidation       = ErrorCode{Code: 40205, Message: "reference solution validation failed"}
+	ErrProblemJudgeUnavailable = ErrorCode{Code: 50206, Message: "judge unavailable"}
 )
 
 // 文章相关错误
@@ -55,6 +57,84 @@ var (
 	ErrInteractiveInternalServer = ErrorCode{Code: 50400, Message: "internal server error"}
 )
 
+// 评论相关错误
+var (
+	ErrCommentInvalidParams  = ErrorCode{Code: 40700, Message: "invalid parameters"}
+	ErrCommentNotFound       = ErrorCode{Code: 40701, Message: "comment not found"}
+	ErrCommentForbidden      = ErrorCode{Code: 40702, Message: "forbidden"}
+	ErrCommentInternalServer = ErrorCode{Code: 50703, Message: "internal server error"}
+	ErrCommentTargetNotFound = ErrorCode{Code: 40704, Message: "comment target not found"}
+)
+
+// 题解相关错误
+var (
+	ErrSolutionInvalidParams  = ErrorCode{Code: 40800, Message: "invalid parameters"}
+	ErrSolutionNotFound       = ErrorCode{Code: 40801, Message: "solution not found"}
+	ErrSolutionForbidden      = ErrorCode{Code: 40802, Message: "forbidden"}
+	ErrSolutionLocked         = ErrorCode{Code: 40803, Message: "solutions are locked until the problem is accepted or revealed"}
+	ErrSolutionInternalServer = ErrorCode{Code: 50804, Message: "internal server error"}
+)
+
+// 收藏相关错误
+var (
+	ErrCollectInvalidParams  = ErrorCode{Code: 40900, Message: "invalid parameters"}
+	ErrCollectFolderNotFound = ErrorCode{Code: 40901, Message: "collect folder not found"}
+	ErrCollectFolderExists   = ErrorCode{Code: 40902, Message: "collect folder already exists"}
+	ErrCollectTargetNotFound = ErrorCode{Code: 40903, Message: "collect target not found"}
+	ErrCollectNotCollected   = ErrorCode{Code: 40904, Message: "not collected"}
+	ErrCollectInternalServer = ErrorCode{Code: 50905, Message: "internal server error"}
+)
+
+// 文章迁移相关错误
+var (
+	ErrMigrateInvalidParams  = ErrorCode{Code: 41000, Message: "invalid parameters"}
+	ErrMigrateDisabled       = ErrorCode{Code: 41001, Message: "article migration is not configured"}
+	ErrMigrateRunning        = ErrorCode{Code: 41002, Message: "another migration task is running"}
+	ErrMigrateCopyNotDone    = ErrorCode{Code: 41003, Message: "full copy has not finished"}
+	ErrMigrateInternalServer = ErrorCode{Code: 51004, Message: "internal server error"}
+	ErrMigrateNotValidated   = ErrorCode{Code: 41005, Message: "no clean validation after the full copy"}
+)
+
+// 文章版本相关错误
+var (
+	ErrRevisionInvalidParams  = ErrorCode{Code: 41100, Message: "invalid parameters"}
+	ErrRevisionNotFound       = ErrorCode{Code: 41101, Message: "revision not found"}
+	ErrRevisionForbidden      = ErrorCode{Code: 41102, Message: "forbidden"}
+	ErrAutosaveNotFound       = ErrorCode{Code: 41103, Message: "no autosaved draft"}
+	ErrRevisionInternalServer = ErrorCode{Code: 51104, Message: "internal server error"}
+)
+
+// 文章标签相关错误
+var (
+	ErrTagInvalidParams  = ErrorCode{Code: 41200, Message: "invalid parameters"}
+	ErrTagNotFound       = ErrorCode{Code: 41201, Message: "tag not found"}
+	ErrTagTooMany        = ErrorCode{Code: 41202, Message: "too many tags"}
+	ErrTagInternalServer = ErrorCode{Code: 51203, Message: "internal server error"}
+)
+
+// 关注和动态相关错误
+var (
+	ErrFollowInvalidParams  = ErrorCode{Code: 41300, Message: "invalid parameters"}
+	ErrFollowSelf           = ErrorCode{Code: 41301, Message: "cannot follow yourself"}
+	ErrNotFollowed          = ErrorCode{Code: 41302, Message: "not followed"}
+	ErrFollowInternalServer = ErrorCode{Code: 51303, Message: "internal server error"}
+)
+
+// 文章审核相关错误
+var (
+	ErrReviewInvalidParams  = ErrorCode{Code: 41400, Message: "invalid parameters"}
+	ErrArticleRejected      = ErrorCode{Code: 41401, Message: "article rejected by moderation"}
+	ErrReviewNotFound       = ErrorCode{Code: 41402, Message: "review not found or already handled"}
+	ErrReviewInternalServer = ErrorCode{Code: 51403, Message: "internal server error"}
+)
+
+// 评测相关错误
+var (
+	ErrJudgeQuotaExceeded = ErrorCode{Code: 40600, Message: "submission quota exceeded"}
+	ErrShareNotFound      = ErrorCode{Code: 40601, Message: "share link not found or expired"}
+	ErrShareInContest     = ErrorCode{Code: 40602, Message: "sharing is disabled during contest"}
+)
+
 // 验证码系统相关错误
 var (
 	ErrCodeInternalServer = ErrorCode{Code: 50500, Message: "internal server error"}
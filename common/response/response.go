@@ -90,6 +90,30 @@ func ErrorWithLog(ctx *gin.Context, name string, msg string, err error) {
 	})
 }
 
+// ErrorWithData 业务错误附带数据返回，例如参考解校验失败时的评测报告
+func ErrorWithData(ctx *gin.Context, name string, msg string, err error, data any) {
+	logger.Error(ctx.Request.Context(), name, msg, zap.Error(err))
+
+	if businessErr, ok := gerrors.FromBizStatusError(err); ok {
+		ctx.JSON(http.StatusOK, Response{
+			Code:    businessErr.BizStatusCode(),
+			Message: businessErr.Error(),
+			Data:    data,
+		})
+
+		// 监控
+		vector.WithLabelValues(strconv.Itoa(int(businessErr.BizStatusCode()))).Inc()
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: err.Error(),
+		Data:    data,
+	})
+}
+
 func SuccessWithLog(ctx *gin.Context, data any, name string, msg string, fields ...zap.Field) {
 	logger.Info(ctx.Request.Context(), name, msg, fields...)
 
@@ -24,6 +24,19 @@ type Config struct {
 	WeChat WeChat `yaml:"wechat"`
 	Kafka  Kafka  `yaml:"kafka"`
 	Judge  Judge  `yaml:"judge"`
+	OSS    OSS    `yaml:"oss"`
+
+	Languages   []Language  `yaml:"languages"`
+	Quota       Quota       `yaml:"quota"`
+	Archive     Archive     `yaml:"archive"`
+	Contests    []Contest   `yaml:"contests"`
+	Ranking     Ranking     `yaml:"ranking"`
+	Article     Article     `yaml:"article"`
+	Mongo       Mongo       `yaml:"mongo"`
+	Feed        Feed        `yaml:"feed"`
+	Outbox      Outbox      `yaml:"outbox"`
+	Interactive Interactive `yaml:"interactive"`
+	Moderation  Moderation  `yaml:"moderation"`
 }
 
 type Server struct {
@@ -49,12 +62,166 @@ type WeChat struct {
 	AppKey string `yaml:"appKey"`
 }
 
+// OSS S3 兼容的对象存储
+type OSS struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+}
+
+// Archive 提交代码的归档策略，超过保留期的代码压缩后移到对象存储
+type Archive struct {
+	Bucket string `yaml:"bucket"`
+	// RetentionDays 代码在 MySQL 中保留的天数，0 表示不归档
+	RetentionDays int `yaml:"retentionDays"`
+	// Interval 归档任务的执行间隔，单位 s
+	Interval int `yaml:"interval"`
+	// BatchSize 每次从数据库取出多少条提交
+	BatchSize int `yaml:"batchSize"`
+}
+
+// Article 文章的存储后端
+type Article struct {
+	// Storage 可选 mysql、mongo、oss，默认 mysql
+	Storage string `yaml:"storage"`
+	// Bucket storage 为 oss 时存放正文的桶
+	Bucket    string           `yaml:"bucket"`
+	Migration ArticleMigration `yaml:"migration"`
+}
+
+// ArticleMigration 文章在存储之间迁移，配置了 Target 才会启用双写
+type ArticleMigration struct {
+	// Target 迁移的目标存储，取值同 Article.Storage
+	Target string `yaml:"target"`
+	// Pattern 启动时的读写模式，之后以管理员切换后保存在 Redis 中的为准
+	Pattern string `yaml:"pattern"`
+	// BatchSize 全量复制和校验每批的行数
+	BatchSize int `yaml:"batchSize"`
+}
+
+type Mongo struct {
+	URI      string `yaml:"uri"`
+	Database string `yaml:"database"`
+}
+
+// Moderation 文章发布前的自动审核
+type Moderation struct {
+	// Words 敏感词，和 WordsFile 中的词合并使用
+	Words []string `yaml:"words"`
+	// WordsFile 敏感词词典，每行一个词，# 开头的行是注释
+	WordsFile string `yaml:"wordsFile"`
+	// WordVerdict 命中敏感词时转人工审核（review）还是直接拒绝（reject），默认 review
+	WordVerdict string `yaml:"wordVerdict"`
+	// MaxLinks 链接数超过这个值转人工审核
+	MaxLinks int `yaml:"maxLinks"`
+	// MaxRepeat 同一行重复超过这个次数转人工审核
+	MaxRepeat int `yaml:"maxRepeat"`
+	// BlockedDomains 链接指向这些域名时直接拒绝
+	BlockedDomains []string `yaml:"blockedDomains"`
+	// HookURL 外部审核服务的地址，为空时不调用
+	HookURL string `yaml:"hookURL"`
+	// HookTimeout 调用外部审核服务的超时时间，单位 ms
+	HookTimeout int `yaml:"hookTimeout"`
+}
+
+// Ranking 文章热榜
+type Ranking struct {
+	// Interval 热榜的计算间隔，单位 s
+	Interval int `yaml:"interval"`
+	// N 热榜保留的文章数
+	N int `yaml:"n"`
+	// BatchSize 计算时每次从数据库取出多少篇文章
+	BatchSize int `yaml:"batchSize"`
+}
+
+// Interactive 阅读数和点赞数先写 Redis，定时落库
+type Interactive struct {
+	// FlushInterval 落库间隔，单位 s
+	FlushInterval int `yaml:"flushInterval"`
+	// BatchSize 每批落库和对账的资源数
+	BatchSize int `yaml:"batchSize"`
+	// ReconcileInterval 两轮对账之间的间隔，单位 s
+	ReconcileInterval int `yaml:"reconcileInterval"`
+}
+
+// Feed 关注动态，推拉结合
+type Feed struct {
+	// BigAuthor 粉丝数超过这个值的作者发文不推送，读的时候从他的发件箱拉
+	BigAuthor int64 `yaml:"bigAuthor"`
+	// InboxSize 收件箱和发件箱保留的文章数
+	InboxSize int `yaml:"inboxSize"`
+	// ActiveDays 多少天内看过动态的用户算活跃，只给活跃用户推送
+	ActiveDays int `yaml:"activeDays"`
+}
+
+// Contest 比赛期间题目的提交不允许分享
+type Contest struct {
+	Id         uint64   `yaml:"id"`
+	ProblemIds []uint64 `yaml:"problemIds"`
+	// Start/End 使用 RFC3339 格式
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	// Participants 报名参赛的用户，为空表示不需要报名
+	Participants []uint64 `yaml:"participants"`
+}
+
 type Kafka struct {
 	Addr string `yaml:"addr"`
 }
 
 type Judge struct {
-	Addr string `yaml:"addr"`
+	// Addr 单节点部署时的地址，配置了 Addrs 时忽略
+	Addr  string   `yaml:"addr"`
+	Addrs []string `yaml:"addrs"`
+	// Strategy 负载均衡策略，round_robin 或 least_loaded
+	Strategy string `yaml:"strategy"`
+	// HealthInterval 健康检查间隔，单位 s
+	HealthInterval int `yaml:"healthInterval"`
+	// FailureThreshold 连续失败多少次熔断
+	FailureThreshold int `yaml:"failureThreshold"`
+	// OpenTimeout 熔断持续时间，单位 s
+	OpenTimeout int `yaml:"openTimeout"`
+	// DedupWindow 相同代码重复提交的去重窗口，单位 s
+	DedupWindow int `yaml:"dedupWindow"`
+	// IdempotencyTTL 幂等键的有效期，单位 s
+	IdempotencyTTL int `yaml:"idempotencyTTL"`
+	// Concurrency 同时送往评测机的任务数，超出的按优先级排队
+	Concurrency int `yaml:"concurrency"`
+	// Weights 每一轮各优先级最多调度的任务数，key 为 contest、submit、run、rejudge
+	Weights map[string]int `yaml:"weights"`
+}
+
+type Language struct {
+	Id         string  `yaml:"id"`
+	Name       string  `yaml:"name"`
+	Version    string  `yaml:"version"`
+	Extension  string  `yaml:"extension"`
+	Judge0Id   int     `yaml:"judge0Id"`
+	GoJudge    string  `yaml:"goJudge"`
+	TimeFactor float64 `yaml:"timeFactor"`
+	MemFactor  float64 `yaml:"memFactor"`
+	Enabled    bool    `yaml:"enabled"`
+}
+
+// Quota 提交配额，不同身份与场景使用不同的档位
+type Quota struct {
+	Normal  QuotaTier `yaml:"normal"`
+	Admin   QuotaTier `yaml:"admin"`
+	Contest QuotaTier `yaml:"contest"`
+}
+
+type QuotaTier struct {
+	// User 针对用户的全部提交
+	User []QuotaRule `yaml:"user"`
+	// Problem 针对用户在单道题目上的提交
+	Problem []QuotaRule `yaml:"problem"`
+}
+
+type QuotaRule struct {
+	// Interval 窗口大小，单位 s
+	Interval int `yaml:"interval"`
+	Rate     int `yaml:"rate"`
 }
 
 // GetConf gets configuration instance
@@ -94,3 +261,15 @@ func GetEnv() string {
 	}
 	return e
 }
+
+// Outbox 事务发件箱的投递任务
+type Outbox struct {
+	// Interval 发件箱为空时多久再查一次，单位 ms
+	Interval int `yaml:"interval"`
+	// BatchSize 每次投递多少条事件
+	BatchSize int `yaml:"batchSize"`
+	// RetentionDays 已投递的事件保留的天数，0 表示不清理
+	RetentionDays int `yaml:"retentionDays"`
+	// MaxAttempts 一条事件最多投递几次，0 表示使用默认的 10 次
+	MaxAttempts int `yaml:"maxAttempts"`
+}
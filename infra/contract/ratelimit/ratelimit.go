@@ -1,7 +1,15 @@
 package ratelimit
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Limiter interface {
 	Limit(ctx context.Context, key string) (bool, error)
 }
+
+// Waiter 被限流之后还要等多久才能再次请求，不是所有限流器都能给出
+type Waiter interface {
+	RetryAfter(ctx context.Context, key string) (time.Duration, error)
+}
@@ -35,3 +35,18 @@ func (r *RedisSlideWindowLimiter) Limit(ctx context.Context, key string) (bool,
 	return r.cmd.Eval(ctx, luaSlideWindow, []string{key},
 		r.interval.Milliseconds(), r.rate, time.Now().UnixMilli()).Bool()
 }
+
+// RetryAfter 窗口内最早的一次请求滑出窗口之后就可以再次请求
+func (r *RedisSlideWindowLimiter) RetryAfter(ctx context.Context, key string) (time.Duration, error) {
+	res, err := r.cmd.ZRangeWithScores(ctx, key, 0, 0).Result()
+	if err != nil || len(res) == 0 {
+		return 0, err
+	}
+
+	wait := time.UnixMilli(int64(res[0].Score)).Add(r.interval).Sub(time.Now())
+	if wait < 0 {
+		return 0, nil
+	}
+
+	return wait, nil
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/pkg/markdown"
+)
 
 type Article struct {
 	ID      uint64
@@ -10,24 +14,22 @@ type Article struct {
 	Utime   time.Time
 	Author  Author
 	Status  ArticleStatus
+	// Tags 为 nil 时不修改标签，空切片表示清空
+	Tags []Tag
 }
 
 type Interactive struct {
-	LikeCnt int64 `json:"like_cnt"`
-	ReadCnt int64 `json:"read_cnt"`
-	Liked   bool  `json:"liked"`
+	LikeCnt    int64 `json:"like_cnt"`
+	ReadCnt    int64 `json:"read_cnt"`
+	CollectCnt int64 `json:"collect_cnt"`
+	Liked      bool  `json:"liked"`
+	Collected  bool  `json:"collected"`
 }
 
 func (a Article) Abstract() string {
-	// 摘要我们取前几句
-	// 不能直接[:1024]
-	// 可能会把中文截断
-	cs := []rune(a.Content)
-	if len(cs) < 100 {
-		return a.Content
-	}
-
-	return string(cs[:100])
+	// 摘要取渲染后纯文本的前 100 个字符，不带 Markdown 标记
+	// 按 rune 截断，不会把中文截断
+	return markdown.Abstract(a.Content, 100)
 }
 
 type ArticleStatus uint8
@@ -37,6 +39,10 @@ const (
 	ArticleStatusUnPublished
 	ArticleStatusPublished
 	ArticleStatusPrivate
+	// ArticleStatusPending 制作库里没通过自动审核、等待人工审核的版本，线上库不会是这个状态
+	ArticleStatusPending
+	// ArticleStatusRejected 人工审核没通过
+	ArticleStatusRejected
 )
 
 func (s ArticleStatus) ToUint8() uint8 {
@@ -55,6 +61,10 @@ func (s ArticleStatus) String() string {
 		return "unpublished"
 	case ArticleStatusPublished:
 		return "published"
+	case ArticleStatusPending:
+		return "pending"
+	case ArticleStatusRejected:
+		return "rejected"
 	default:
 		return "unknown"
 	}
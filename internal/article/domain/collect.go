@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// CollectFolder 收藏夹，ID 为 0 的是每个用户都有的默认收藏夹
+type CollectFolder struct {
+	ID          uint64
+	Uid         uint64
+	Name        string
+	Description string
+	Cnt         int64
+	Ctime       time.Time
+}
+
+// CollectItem 收藏夹里的一项，Title 按 Biz 从对应的资源里取
+type CollectItem struct {
+	Biz      string
+	BizID    uint64
+	FolderID uint64
+	Title    string
+	Ctime    time.Time
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// Comment 评论挂在 biz/bizId 上，和点赞、阅读一样可以用于文章、题目等任意资源
+// 回复只有两层：RootID 为所属的顶级评论，PID 为直接回复的那条评论
+type Comment struct {
+	ID          uint64
+	Biz         string
+	BizID       uint64
+	Commentator Author
+	Content     string
+	RootID      uint64
+	PID         uint64
+	Status      CommentStatus
+	ReplyCnt    int64
+	Inter       Interactive
+	Ctime       time.Time
+	Utime       time.Time
+}
+
+type CommentStatus uint8
+
+const (
+	CommentStatusNormal CommentStatus = iota
+	// CommentStatusDeleted 评论者自己删除
+	CommentStatusDeleted
+	// CommentStatusHidden 被资源作者隐藏
+	CommentStatusHidden
+)
+
+func (s CommentStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s CommentStatus) Visible() bool {
+	return s == CommentStatusNormal
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+type Follow struct {
+	Follower uint64
+	Followee uint64
+	Ctime    time.Time
+}
+
+type FollowStats struct {
+	Uid       uint64
+	Followers int64
+	Followees int64
+	// Following 当前用户是否关注了 Uid
+	Following bool
+}
+
+// FeedItem 收件箱和发件箱里的一项，Ctime 是文章第一次发布的时间（毫秒），用作排序的分数
+type FeedItem struct {
+	ArticleID uint64
+	Ctime     int64
+}
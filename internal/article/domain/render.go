@@ -0,0 +1,24 @@
+package domain
+
+import "github.com/crazyfrankie/onlinejudge/pkg/markdown"
+
+// Rendered 线上文章渲染后的结果
+// Version 是线上库的 utime（毫秒），重新发布后版本变化，旧的缓存不会再被读到
+type Rendered struct {
+	ArticleID uint64             `json:"article_id"`
+	Version   int64              `json:"version"`
+	HTML      string             `json:"html"`
+	TOC       []markdown.Heading `json:"toc"`
+	Abstract  string             `json:"abstract"`
+}
+
+func (a Article) Render() Rendered {
+	res := markdown.Render(a.Content)
+	return Rendered{
+		ArticleID: a.ID,
+		Version:   a.Utime.UnixMilli(),
+		HTML:      res.HTML,
+		TOC:       res.TOC,
+		Abstract:  a.Abstract(),
+	}
+}
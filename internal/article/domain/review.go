@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+type ReviewStatus uint8
+
+const (
+	ReviewStatusUnknown ReviewStatus = iota
+	ReviewStatusPending
+	ReviewStatusApproved
+	ReviewStatusRejected
+)
+
+func (s ReviewStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+// Review 一篇等待或者已经人工审核的文章
+// Title、Content 和 Tags 是提交审核的版本，通过时用它发布，线上库在这之前保持原样
+type Review struct {
+	ArticleID uint64
+	AuthorID  uint64
+	Title     string
+	Content   string
+	// Tags 为 nil 时不修改标签
+	Tags   []Tag
+	Status ReviewStatus
+	// Reasons 自动审核转人工的原因
+	Reasons  []string
+	Reviewer uint64
+	Note     string
+	Ctime    time.Time
+	Utime    time.Time
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Revision 文章每次保存、发布、恢复时留下的快照
+type Revision struct {
+	ArticleID uint64
+	Version   int64
+	Title     string
+	Content   string
+	Author    Author
+	Kind      RevisionKind
+	// Diff 与上一个版本相比的差异
+	Diff  ContentDiff
+	Ctime time.Time
+}
+
+type RevisionKind uint8
+
+const (
+	RevisionKindUnknown RevisionKind = iota
+	RevisionKindSave
+	RevisionKindPublish
+	RevisionKindRestore
+)
+
+func (k RevisionKind) ToUint8() uint8 {
+	return uint8(k)
+}
+
+func (k RevisionKind) String() string {
+	switch k {
+	case RevisionKindSave:
+		return "save"
+	case RevisionKindPublish:
+		return "publish"
+	case RevisionKindRestore:
+		return "restore"
+	default:
+		return "unknown"
+	}
+}
+
+// ContentDiff 按行比较的结果，Unified 是 unified diff 格式的文本
+type ContentDiff struct {
+	Unified   string `json:"unified"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// DiffContent 计算 from 到 to 的按行差异
+func DiffContent(from, to string) (ContentDiff, error) {
+	a, b := splitLines(from), splitLines(to)
+
+	var res ContentDiff
+	for _, op := range difflib.NewMatcher(a, b).GetOpCodes() {
+		switch op.Tag {
+		case 'r':
+			res.Deletions += op.I2 - op.I1
+			res.Additions += op.J2 - op.J1
+		case 'd':
+			res.Deletions += op.I2 - op.I1
+		case 'i':
+			res.Additions += op.J2 - op.J1
+		}
+	}
+	if res.Additions == 0 && res.Deletions == 0 {
+		return res, nil
+	}
+
+	unified, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:       a,
+		B:       b,
+		Context: 3,
+	})
+	res.Unified = unified
+
+	return res, err
+}
+
+// splitLines 保留换行符，最后一行没有换行时补上，避免和下一行粘在一起
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+
+	return lines
+}
+
+// Autosave 自动保存的草稿，不写制作库，也不产生版本
+type Autosave struct {
+	ArticleID uint64 `json:"article_id"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Utime     int64  `json:"utime"`
+}
+
+// RevisionCompare 两个版本之间的比较，From 和 To 不带正文和差异文本
+type RevisionCompare struct {
+	From Revision
+	To   Revision
+	Diff ContentDiff
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffContent(t *testing.T) {
+	testCases := []struct {
+		name      string
+		from      string
+		to        string
+		additions int
+		deletions int
+		unified   string
+	}{
+		{
+			name: "same",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+		},
+		{
+			name:      "first revision",
+			to:        "a\nb",
+			additions: 2,
+			unified:   "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:      "replace and append",
+			from:      "a\nb\nc",
+			to:        "a\nB\nc\nd\n",
+			additions: 2,
+			deletions: 1,
+			unified:   "@@ -1,3 +1,4 @@\n a\n-b\n+B\n c\n+d\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := DiffContent(tc.from, tc.to)
+			require.NoError(t, err)
+			assert.Equal(t, tc.additions, diff.Additions)
+			assert.Equal(t, tc.deletions, diff.Deletions)
+			assert.Equal(t, tc.unified, diff.Unified)
+		})
+	}
+}
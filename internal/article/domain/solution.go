@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Solution 挂在题目下的题解，本身就是一篇文章
+type Solution struct {
+	ArticleID uint64
+	ProblemID uint64
+	Title     string
+	Abstract  string
+	Author    Author
+	// Languages 题解涉及的语言，用于筛选
+	Languages []string
+	Inter     Interactive
+	Ctime     time.Time
+}
+
+type SolutionSort string
+
+const (
+	// SolutionSortHot 按点赞数排序
+	SolutionSortHot SolutionSort = "hot"
+	// SolutionSortNew 按挂载时间排序
+	SolutionSortNew SolutionSort = "new"
+)
+
+type SolutionQuery struct {
+	ProblemID uint64
+	Language  string
+	Sort      SolutionSort
+	Offset    int
+	Limit     int
+}
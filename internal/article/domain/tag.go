@@ -0,0 +1,27 @@
+package domain
+
+// Tag 和题目共用的标签
+type Tag struct {
+	ID   uint64
+	Name string
+}
+
+type TagCount struct {
+	Tag
+	ProblemCnt int64
+	ArticleCnt int64
+}
+
+// TagProblem 标签页上的题目，只有列表需要的字段
+type TagProblem struct {
+	ID       uint64
+	Title    string
+	PassRate string
+}
+
+// TagPage 标签页，同时展示标签下的题目和已发布的文章
+type TagPage struct {
+	TagCount
+	Problems []TagProblem
+	Articles []Article
+}
@@ -12,17 +12,20 @@ import (
 	"github.com/crazyfrankie/onlinejudge/pkg/zapx"
 )
 
+// ArticleConsumer 批量消费阅读事件，同一批里同一篇文章的阅读数合并后一次写入
 type ArticleConsumer struct {
-	client sarama.Client
-	repo   *repository.InteractiveArtRepository
-	l      *zapx.Logger
+	client   sarama.Client
+	repo     *repository.InteractiveArtRepository
+	producer sarama.SyncProducer
+	l        *zapx.Logger
 }
 
-func NewArticleConsumer(client sarama.Client, repo *repository.InteractiveArtRepository, l *zapx.Logger) Consumer {
+func NewArticleConsumer(client sarama.Client, repo *repository.InteractiveArtRepository, producer sarama.SyncProducer, l *zapx.Logger) Consumer {
 	return &ArticleConsumer{
-		client: client,
-		repo:   repo,
-		l:      l,
+		client:   client,
+		repo:     repo,
+		producer: producer,
+		l:        l,
 	}
 }
 
@@ -32,8 +35,14 @@ func (a *ArticleConsumer) Start() error {
 		return err
 	}
 
+	handler := saramax.NewBatchHandler[ReadEvent](a.l.Logger, a.Consume, saramax.BatchConfig{
+		Size:       100,
+		Interval:   time.Second,
+		DeadLetter: "article_read_dlq",
+		Producer:   a.producer,
+	})
 	go func() {
-		err := cg.Consume(context.Background(), []string{"article_read"}, saramax.NewHandler[ReadEvent](a.l.Logger, a.Consume))
+		err := cg.Consume(context.Background(), []string{TopicRead}, handler)
 		if err != nil {
 			a.l.Logger.Error("退出消费循环异常", zap.Error(err))
 		}
@@ -42,8 +51,14 @@ func (a *ArticleConsumer) Start() error {
 	return err
 }
 
-func (a *ArticleConsumer) Consume(msg *sarama.ConsumerMessage, t ReadEvent) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+func (a *ArticleConsumer) Consume(msgs []*sarama.ConsumerMessage, ts []ReadEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	return a.repo.IncrReadCnt(ctx, "article", t.Aid)
+
+	cnts := make(map[uint64]int64, len(ts))
+	for _, t := range ts {
+		cnts[t.Aid]++
+	}
+
+	return a.repo.BatchIncrReadCnt(ctx, "article", cnts)
 }
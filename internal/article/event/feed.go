@@ -0,0 +1,116 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+	"github.com/crazyfrankie/onlinejudge/pkg/saramax"
+	"github.com/crazyfrankie/onlinejudge/pkg/zapx"
+)
+
+// feedPushBatch 推送时每批查询的粉丝数
+const feedPushBatch = 500
+
+// FeedConsumer 文章发布后写入作者的发件箱，普通作者再推到活跃粉丝的收件箱
+// 推送失败时整批重试，重试之后仍然失败的进入死信 topic
+type FeedConsumer struct {
+	client     sarama.Client
+	followRepo *repository.FollowRepository
+	feedRepo   *repository.FeedRepository
+	producer   sarama.SyncProducer
+	l          *zapx.Logger
+	// bigAuthor 粉丝数超过这个值的作者只写发件箱，由读的一方去拉
+	bigAuthor int64
+}
+
+func NewFeedConsumer(client sarama.Client, followRepo *repository.FollowRepository, feedRepo *repository.FeedRepository,
+	producer sarama.SyncProducer, l *zapx.Logger, bigAuthor int64) *FeedConsumer {
+	return &FeedConsumer{
+		client:     client,
+		followRepo: followRepo,
+		feedRepo:   feedRepo,
+		producer:   producer,
+		l:          l,
+		bigAuthor:  bigAuthor,
+	}
+}
+
+func (f *FeedConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("feed_article", f.client)
+	if err != nil {
+		return err
+	}
+
+	// 一次推送可能要写很多粉丝的收件箱，批次不宜太大
+	handler := saramax.NewBatchHandler[PublishEvent](f.l.Logger, f.Consume, saramax.BatchConfig{
+		Size:       10,
+		Interval:   time.Second,
+		DeadLetter: TopicPublish + "_dlq",
+		Producer:   f.producer,
+	})
+	go func() {
+		err := cg.Consume(context.Background(), []string{TopicPublish}, handler)
+		if err != nil {
+			f.l.Logger.Error("退出消费循环异常", zap.Error(err))
+		}
+	}()
+
+	return err
+}
+
+// Consume 收件箱按文章 id 去重，整批重试时已经推送过的只会重复写入同样的内容
+func (f *FeedConsumer) Consume(msgs []*sarama.ConsumerMessage, ts []PublishEvent) error {
+	for _, t := range ts {
+		if err := f.push(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *FeedConsumer) push(t PublishEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	item := domain.FeedItem{ArticleID: t.Aid, Ctime: t.Ctime}
+	if err := f.feedRepo.AddOutbox(ctx, t.Uid, item); err != nil {
+		return err
+	}
+
+	stats, err := f.followRepo.Stats(ctx, t.Uid)
+	if err != nil {
+		return err
+	}
+	if stats.Followers > f.bigAuthor {
+		return nil
+	}
+
+	var cursor uint64
+	for {
+		uids, next, err := f.followRepo.FollowersAfter(ctx, t.Uid, cursor, feedPushBatch)
+		if err != nil {
+			return err
+		}
+		if len(uids) == 0 {
+			return nil
+		}
+
+		active, err := f.feedRepo.FilterActive(ctx, uids)
+		if err != nil {
+			return err
+		}
+		if err = f.feedRepo.Push(ctx, active, item); err != nil {
+			return err
+		}
+		if len(uids) < feedPushBatch {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -1,16 +1,47 @@
 package event
 
-import "context"
+import (
+	"strconv"
 
-type Producer interface {
-	ProduceReadEvent(ctx context.Context, evt ReadEvent) error
-}
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
+)
+
+const (
+	TopicPublish = "article_publish"
+	TopicRead    = "article_read"
+)
 
+// ReadEvent 阅读事件，通过发件箱投递，由 ArticleConsumer 批量累加阅读数
 type ReadEvent struct {
 	Uid uint64
 	Aid uint64
 }
 
+// NewReadOutbox 阅读事件没有要一起提交的业务数据，按文章 id 分区
+func NewReadOutbox(evt ReadEvent) outbox.Event {
+	return outbox.Event{
+		Topic:   TopicRead,
+		Key:     strconv.FormatUint(evt.Aid, 10),
+		Payload: evt,
+	}
+}
+
+// PublishEvent 文章发布，Ctime 是第一次发布的时间（毫秒）
+type PublishEvent struct {
+	Aid   uint64
+	Uid   uint64
+	Ctime int64
+}
+
+// NewPublishOutbox 发布事件通过发件箱投递，和文章一起提交，按文章 id 分区
+func NewPublishOutbox(evt PublishEvent) outbox.Event {
+	return outbox.Event{
+		Topic:   TopicPublish,
+		Key:     strconv.FormatUint(evt.Aid, 10),
+		Payload: evt,
+	}
+}
+
 type Consumer interface {
 	Start() error
 }
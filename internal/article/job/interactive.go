@@ -0,0 +1,175 @@
+/*
+计数落库任务
+阅读数和点赞数先累加在 Redis 里，这个任务定时把增量批量写回 MySQL，
+同时分批对账，用点赞、收藏明细修正计数表，并清理和数据库不一致的缓存。
+和热榜任务一样通过 Redis 分布式锁选出一个实例执行
+*/
+
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+)
+
+const interactiveLockKey = "job:interactive:flush"
+
+type InteractiveConfig struct {
+	// Interval 落库间隔，默认 5s
+	Interval time.Duration
+	// BatchSize 每批落库和对账的资源数，默认 500
+	BatchSize int
+	// ReconcileInterval 两轮对账之间的间隔，默认 10min
+	ReconcileInterval time.Duration
+}
+
+type InteractiveJob struct {
+	repo   *repository.InteractiveArtRepository
+	leader *leader
+	cfg    InteractiveConfig
+
+	// cursor 本轮对账进行到的计数表 id，为 0 表示没有在对账
+	cursor        uint64
+	lastReconcile time.Time
+
+	lag      prometheus.Gauge
+	duration *prometheus.SummaryVec
+	flushed  prometheus.Counter
+	fixed    *prometheus.CounterVec
+}
+
+func NewInteractiveJob(repo *repository.InteractiveArtRepository, cmd redis.Cmdable, cfg InteractiveConfig) *InteractiveJob {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second * 5
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.ReconcileInterval <= 0 {
+		cfg.ReconcileInterval = time.Minute * 10
+	}
+
+	lag := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "cfc_studio_frank",
+		Subsystem: "onlinejudge",
+		Name:      "interactive_flush_lag_seconds",
+		Help:      "最早一个还没落库的计数增量已经等待的时间，单位 s",
+	})
+	duration := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "cfc_studio_frank",
+		Subsystem: "onlinejudge",
+		Name:      "interactive_flush_run_time",
+		Help:      "计数落库任务每轮的耗时，单位 ms",
+		Objectives: map[float64]float64{
+			0.5:  0.01,
+			0.9:  0.01,
+			0.99: 0.001,
+		},
+	}, []string{"result"})
+	flushed := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "cfc_studio_frank",
+		Subsystem: "onlinejudge",
+		Name:      "interactive_flushed_total",
+		Help:      "落库的资源数",
+	})
+	fixed := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "cfc_studio_frank",
+		Subsystem: "onlinejudge",
+		Name:      "interactive_reconcile_fixed_total",
+		Help:      "对账时修正的计数，target 为 db 或 cache",
+	}, []string{"target"})
+	prometheus.MustRegister(lag, duration, flushed, fixed)
+
+	return &InteractiveJob{
+		repo:     repo,
+		leader:   newLeader(cmd, interactiveLockKey, cfg.Interval),
+		cfg:      cfg,
+		lag:      lag,
+		duration: duration,
+		flushed:  flushed,
+		fixed:    fixed,
+	}
+}
+
+// Run 阻塞执行，直到 ctx 结束，退出时释放锁
+func (j *InteractiveJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+	defer j.leader.release()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			log.Printf("flush interactive failed: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce 每个实例都上报落库延迟，只有拿到锁的实例落库和对账
+// 对账每轮只做一批，避免长时间占用而没有续约
+func (j *InteractiveJob) RunOnce(ctx context.Context) error {
+	if lag, err := j.repo.FlushLag(ctx); err == nil {
+		j.lag.Set(lag.Seconds())
+	}
+	if !j.leader.acquire(ctx) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, j.cfg.Interval)
+	defer cancel()
+
+	start := time.Now()
+	err := j.flush(ctx)
+	result := "success"
+	if err != nil {
+		result = "failed"
+	}
+	j.duration.WithLabelValues(result).Observe(float64(time.Since(start).Milliseconds()))
+	if err != nil {
+		return err
+	}
+
+	return j.reconcile(ctx)
+}
+
+// flush 一直落库到没有待落库的增量，或者这一轮的时间用完
+func (j *InteractiveJob) flush(ctx context.Context) error {
+	for ctx.Err() == nil {
+		n, err := j.repo.Flush(ctx, j.cfg.BatchSize)
+		j.flushed.Add(float64(n))
+		if err != nil || n < j.cfg.BatchSize {
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
+
+func (j *InteractiveJob) reconcile(ctx context.Context) error {
+	if j.cursor == 0 && time.Since(j.lastReconcile) < j.cfg.ReconcileInterval {
+		return nil
+	}
+	if j.cursor == 0 {
+		j.lastReconcile = time.Now()
+	}
+
+	next, dbFixed, cacheFixed, err := j.repo.Reconcile(ctx, j.cursor, j.cfg.BatchSize)
+	j.fixed.WithLabelValues("db").Add(float64(dbFixed))
+	j.fixed.WithLabelValues("cache").Add(float64(cacheFixed))
+	if err != nil {
+		return err
+	}
+	j.cursor = next
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/pkg/redisx"
+)
+
+// leader 用 Redis 锁在多个实例中选出一个执行任务
+// 锁的过期时间是两个间隔，持有者每轮续约，中间错过一轮也不会丢锁
+type leader struct {
+	cmd  redis.Cmdable
+	key  string
+	ttl  time.Duration
+	lock *redisx.Lock
+}
+
+func newLeader(cmd redis.Cmdable, key string, interval time.Duration) *leader {
+	return &leader{
+		cmd: cmd,
+		key: key,
+		ttl: interval * 2,
+	}
+}
+
+func (l *leader) acquire(ctx context.Context) bool {
+	if l.lock != nil {
+		err := l.lock.Refresh(ctx)
+		if err == nil {
+			return true
+		}
+		log.Printf("refresh lock %s failed: %v", l.key, err)
+		l.lock = nil
+	}
+
+	lock, err := redisx.TryLock(ctx, l.cmd, l.key, l.ttl)
+	if err != nil {
+		if !errors.Is(err, redisx.ErrLockHeld) {
+			log.Printf("acquire lock %s failed: %v", l.key, err)
+		}
+		return false
+	}
+	l.lock = lock
+
+	return true
+}
+
+func (l *leader) release() {
+	if l.lock == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := l.lock.Unlock(ctx); err != nil {
+		log.Printf("release lock %s failed: %v", l.key, err)
+	}
+	l.lock = nil
+}
@@ -0,0 +1,95 @@
+/*
+热榜计算任务
+多个实例同时运行时通过 Redis 分布式锁选出一个实例计算，拿到锁的实例每轮续约并一直计算下去，
+宕机后锁过期，其他实例在下一轮接手
+*/
+
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+)
+
+const rankingLockKey = "job:ranking:article"
+
+type RankingJob struct {
+	svc      service.RankingService
+	leader   *leader
+	interval time.Duration
+	// timeout 单次计算的超时时间
+	timeout time.Duration
+
+	duration *prometheus.SummaryVec
+}
+
+func NewRankingJob(svc service.RankingService, cmd redis.Cmdable, interval time.Duration) *RankingJob {
+	if interval <= 0 {
+		interval = time.Minute * 3
+	}
+
+	duration := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "cfc_studio_frank",
+		Subsystem: "onlinejudge",
+		Name:      "ranking_job_run_time",
+		Help:      "热榜计算任务的耗时，单位 ms",
+		Objectives: map[float64]float64{
+			0.5:  0.01,
+			0.9:  0.01,
+			0.99: 0.001,
+		},
+	}, []string{"result"})
+	prometheus.MustRegister(duration)
+
+	return &RankingJob{
+		svc:      svc,
+		leader:   newLeader(cmd, rankingLockKey, interval),
+		interval: interval,
+		timeout:  interval / 2,
+		duration: duration,
+	}
+}
+
+// Run 阻塞执行，直到 ctx 结束，退出时释放锁
+func (j *RankingJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	defer j.leader.release()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			log.Printf("rank hot articles failed: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce 没拿到锁时直接返回，由持有锁的实例计算
+func (j *RankingJob) RunOnce(ctx context.Context) error {
+	if !j.leader.acquire(ctx) {
+		return nil
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+
+	err := j.svc.RankTopN(ctx)
+	result := "success"
+	if err != nil {
+		result = "failed"
+	}
+	j.duration.WithLabelValues(result).Observe(float64(time.Since(start).Milliseconds()))
+
+	return err
+}
@@ -0,0 +1,90 @@
+package migrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+// CopyProgress 全量复制的进度，游标是已经复制完的最大 id
+type CopyProgress struct {
+	// Base 从哪一边复制到另一边，src 或者 dst
+	Base        string `json:"base"`
+	DraftCursor uint64 `json:"draft_cursor"`
+	PubCursor   uint64 `json:"pub_cursor"`
+	Drafts      int64  `json:"drafts"`
+	Pubs        int64  `json:"pubs"`
+	Done        bool   `json:"done"`
+	Err         string `json:"err"`
+	Utime       int64  `json:"utime"`
+}
+
+// base 之前的记录没有方向，都是从源库复制的
+func (p CopyProgress) base() string {
+	if p.Base == "" {
+		return "src"
+	}
+	return p.Base
+}
+
+// copier 从源库按 id 升序复制到目标库，每批复制完记录一次断点并续约锁
+type copier struct {
+	src       dao.MigrateDAO
+	dst       dao.MigrateDAO
+	batchSize int
+	// checkpoint 保存进度并续约锁
+	checkpoint func(ctx context.Context, p CopyProgress) error
+}
+
+func (c *copier) run(ctx context.Context, p CopyProgress) error {
+	p.Done, p.Err = false, ""
+
+	err := copyTable(ctx, c, &p, &p.DraftCursor, &p.Drafts, c.src.DraftsAfter, c.dst.UpsertDraft,
+		func(art dao.Article) uint64 { return art.ID })
+	if err == nil {
+		err = copyTable(ctx, c, &p, &p.PubCursor, &p.Pubs, c.src.PubsAfter, c.dst.UpsertPub,
+			func(art dao.OnlineArticle) uint64 { return art.ID })
+	}
+	if err != nil {
+		p.Err = err.Error()
+	} else {
+		p.Done = true
+	}
+
+	// 任务可能是因为 ctx 取消退出的，最后的进度用新的 ctx 保存
+	saveCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if saveErr := c.checkpoint(saveCtx, p); err == nil {
+		err = saveErr
+	}
+
+	return err
+}
+
+func copyTable[T any](ctx context.Context, c *copier, p *CopyProgress, cursor *uint64, cnt *int64,
+	fetch func(ctx context.Context, id uint64, limit int) ([]T, error),
+	upsert func(ctx context.Context, row T) error,
+	id func(T) uint64) error {
+	for {
+		rows, err := fetch(ctx, *cursor, c.batchSize)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err = upsert(ctx, row); err != nil {
+				return err
+			}
+			*cursor = id(row)
+			*cnt++
+		}
+		if len(rows) > 0 {
+			if err = c.checkpoint(ctx, *p); err != nil {
+				return err
+			}
+		}
+		if len(rows) < c.batchSize {
+			return nil
+		}
+	}
+}
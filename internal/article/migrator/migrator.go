@@ -0,0 +1,310 @@
+/*
+文章在不同存储之间的在线迁移
+1. src_only -> src_first：开始双写
+2. 全量复制，按 id 分批从源库复制到目标库，进度写 Redis，中断后从断点继续
+3. 校验，逐行比较两边，可以选择修复（全量复制和双写并发时可能写入旧数据，一定要在复制完成后校验一次，没有差异才允许切到以目标库为准）
+4. src_first -> dst_first -> dst_only：切换读写，dst_first 阶段仍然同步回源库，出问题还能切回去
+5. 回滚：dst_first 可以直接切回 src_*；dst_only 已经不再写源库，要先从目标库全量复制回源库并校验干净才能切回
+读写模式保存在 Redis，管理员在任意一个实例上切换，其他实例定期同步
+*/
+
+package migrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+	"github.com/crazyfrankie/onlinejudge/pkg/redisx"
+)
+
+const (
+	patternKey  = "migrate:article:pattern"
+	copyKey     = "migrate:article:copy"
+	validateKey = "migrate:article:validate"
+	// lockKey 复制和校验共用一把锁，同一时间只跑一个任务
+	lockKey = "job:migrate:article"
+)
+
+var (
+	ErrTaskRunning    = errors.New("migration task is running")
+	ErrCopyNotDone    = errors.New("full copy has not finished")
+	ErrNotValidated   = errors.New("no clean validation after the full copy")
+	ErrUnknownPattern = dao.ErrUnknownPattern
+)
+
+type Status struct {
+	Pattern  string         `json:"pattern"`
+	Running  bool           `json:"running"`
+	Copy     CopyProgress   `json:"copy"`
+	Validate ValidateReport `json:"validate"`
+}
+
+type Migrator struct {
+	dw        *dao.DoubleWriteDAO
+	cmd       redis.Cmdable
+	batchSize int
+	// interval 同步读写模式的间隔
+	interval time.Duration
+	lockTTL  time.Duration
+
+	// ctx 后台任务使用，Run 退出时取消
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewMigrator(dw *dao.DoubleWriteDAO, cmd redis.Cmdable, batchSize int) *Migrator {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Migrator{
+		dw:        dw,
+		cmd:       cmd,
+		batchSize: batchSize,
+		interval:  time.Second * 5,
+		lockTTL:   time.Second * 30,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Run 阻塞执行，定期从 Redis 同步读写模式，退出时停止后台任务
+func (m *Migrator) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	defer func() {
+		m.cancel()
+		m.wg.Wait()
+	}()
+
+	for {
+		m.syncPattern(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Migrator) syncPattern(ctx context.Context) {
+	pattern, err := m.cmd.Get(ctx, patternKey).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("load migration pattern failed: %v", err)
+		}
+		return
+	}
+	if pattern == m.dw.Pattern() {
+		return
+	}
+	if err = m.dw.UpdatePattern(pattern); err != nil {
+		log.Printf("apply migration pattern %q failed: %v", pattern, err)
+		return
+	}
+	log.Printf("article storage pattern switched to %s", pattern)
+}
+
+// SetPattern 从源库切到以目标库为准，或者从 dst_only 切回要写源库的模式之前，
+// 必须按这个方向完成全量复制，并且复制之后校验过一次没有任何差异
+// 复制和双写并发时可能写入旧数据，只看复制是否完成不够
+func (m *Migrator) SetPattern(ctx context.Context, pattern string) error {
+	if !dao.ValidPattern(pattern) {
+		return ErrUnknownPattern
+	}
+	if base, ok := requiredCopy(m.dw.Pattern(), pattern); ok {
+		if err := m.checkCopied(ctx, base); err != nil {
+			return err
+		}
+	}
+
+	if err := m.cmd.Set(ctx, patternKey, pattern, 0).Err(); err != nil {
+		return err
+	}
+
+	return m.dw.UpdatePattern(pattern)
+}
+
+// requiredCopy 从 from 切到 to 之前需要从哪一边完成复制和校验
+// dst_first 阶段仍然双写，两边都是最新的，从 dst_first 切回源库不需要
+// dst_only 阶段源库落后了，切到任何要写源库的模式都要先复制回去，包括 dst_first
+func requiredCopy(from, to string) (string, bool) {
+	fromDst := from == dao.PatternDstFirst || from == dao.PatternDstOnly
+	toDst := to == dao.PatternDstFirst || to == dao.PatternDstOnly
+	switch {
+	case from == to:
+		return "", false
+	case !fromDst && toDst:
+		return "src", true
+	case from == dao.PatternDstOnly:
+		return "dst", true
+	default:
+		return "", false
+	}
+}
+
+// checkCopied 确认最近一次复制和校验都是以 base 为准，复制完成之后校验干净
+func (m *Migrator) checkCopied(ctx context.Context, base string) error {
+	p, err := m.copyProgress(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.Done || p.base() != base {
+		return ErrCopyNotDone
+	}
+	var r ValidateReport
+	if err = m.load(ctx, validateKey, &r); err != nil {
+		return err
+	}
+	// 复制和校验共用一把锁，校验在复制之后结束就说明是在复制完成之后开始的
+	if !r.Clean() || r.Base != base || r.Utime <= p.Utime {
+		return ErrNotValidated
+	}
+
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	res := Status{Pattern: m.dw.Pattern()}
+
+	n, err := m.cmd.Exists(ctx, lockKey).Result()
+	if err != nil {
+		return Status{}, err
+	}
+	res.Running = n > 0
+
+	if res.Copy, err = m.copyProgress(ctx); err != nil {
+		return Status{}, err
+	}
+	if err = m.load(ctx, validateKey, &res.Validate); err != nil {
+		return Status{}, err
+	}
+
+	return res, nil
+}
+
+// StartCopy 在后台开始全量复制，restart 为 false 时从上次的断点继续
+// 以目标库为准的阶段反过来从目标库复制回源库，为回滚做准备
+func (m *Migrator) StartCopy(ctx context.Context, restart bool) error {
+	from, to, base := m.dw.Src(), m.dw.Dst(), "src"
+	if p := m.dw.Pattern(); p == dao.PatternDstFirst || p == dao.PatternDstOnly {
+		from, to, base = m.dw.Dst(), m.dw.Src(), "dst"
+	}
+
+	return m.start(ctx, func(ctx context.Context, lock *redisx.Lock) error {
+		var p CopyProgress
+		if !restart {
+			var err error
+			if p, err = m.copyProgress(ctx); err != nil {
+				return err
+			}
+		}
+		// 方向变了，之前的断点没有意义
+		if p.base() != base {
+			p = CopyProgress{}
+		}
+		p.Base = base
+
+		c := &copier{
+			src:       from,
+			dst:       to,
+			batchSize: m.batchSize,
+			checkpoint: func(ctx context.Context, p CopyProgress) error {
+				p.Utime = time.Now().UnixMilli()
+				if err := m.save(ctx, copyKey, p); err != nil {
+					return err
+				}
+				return lock.Refresh(ctx)
+			},
+		}
+		return c.run(ctx, p)
+	})
+}
+
+// StartValidate 在后台比较两边的数据，以当前为准的一边为基准，repair 时修复目标库
+func (m *Migrator) StartValidate(ctx context.Context, repair bool) error {
+	base, target, baseName := m.dw.Src(), m.dw.Dst(), "src"
+	if p := m.dw.Pattern(); p == dao.PatternDstFirst || p == dao.PatternDstOnly {
+		base, target, baseName = m.dw.Dst(), m.dw.Src(), "dst"
+	}
+
+	return m.start(ctx, func(ctx context.Context, lock *redisx.Lock) error {
+		v := &validator{
+			base:      base,
+			target:    target,
+			batchSize: m.batchSize,
+			checkpoint: func(ctx context.Context, r ValidateReport) error {
+				r.Utime = time.Now().UnixMilli()
+				if err := m.save(ctx, validateKey, r); err != nil {
+					return err
+				}
+				return lock.Refresh(ctx)
+			},
+		}
+		return v.run(ctx, ValidateReport{Base: baseName, Repair: repair})
+	})
+}
+
+// start 拿到锁之后在后台执行任务，其他实例或者本实例已经在跑时返回 ErrTaskRunning
+func (m *Migrator) start(ctx context.Context, task func(ctx context.Context, lock *redisx.Lock) error) error {
+	lock, err := redisx.TryLock(ctx, m.cmd, lockKey, m.lockTTL)
+	if errors.Is(err, redisx.ErrLockHeld) {
+		return ErrTaskRunning
+	}
+	if err != nil {
+		return err
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := task(m.ctx, lock); err != nil {
+			log.Printf("article migration task failed: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := lock.Unlock(ctx); err != nil {
+			log.Printf("release migration lock failed: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+func (m *Migrator) copyProgress(ctx context.Context) (CopyProgress, error) {
+	var p CopyProgress
+	err := m.load(ctx, copyKey, &p)
+	return p, err
+}
+
+func (m *Migrator) save(ctx context.Context, key string, val any) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	return m.cmd.Set(ctx, key, data, 0).Err()
+}
+
+// load 没有记录时保持零值
+func (m *Migrator) load(ctx context.Context, key string, val any) error {
+	data, err := m.cmd.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, val)
+}
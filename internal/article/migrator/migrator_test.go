@@ -0,0 +1,36 @@
+package migrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+func TestRequiredCopy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		from, to string
+		wantBase string
+		wantOk   bool
+	}{
+		{name: "start double write", from: dao.PatternSrcOnly, to: dao.PatternSrcFirst},
+		{name: "switch to dst", from: dao.PatternSrcFirst, to: dao.PatternDstFirst, wantBase: "src", wantOk: true},
+		{name: "skip dst first", from: dao.PatternSrcOnly, to: dao.PatternDstOnly, wantBase: "src", wantOk: true},
+		{name: "stop double write", from: dao.PatternDstFirst, to: dao.PatternDstOnly},
+		{name: "rollback from dst first", from: dao.PatternDstFirst, to: dao.PatternSrcFirst},
+		{name: "rollback from dst only", from: dao.PatternDstOnly, to: dao.PatternSrcFirst, wantBase: "dst", wantOk: true},
+		{name: "rollback to src only", from: dao.PatternDstOnly, to: dao.PatternSrcOnly, wantBase: "dst", wantOk: true},
+		{name: "resume double write on dst", from: dao.PatternDstOnly, to: dao.PatternDstFirst, wantBase: "dst", wantOk: true},
+		{name: "unchanged", from: dao.PatternDstOnly, to: dao.PatternDstOnly},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base, ok := requiredCopy(tc.from, tc.to)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantBase, base)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package migrator
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+const (
+	// DiffMissing 目标库缺少
+	DiffMissing = "missing"
+	// DiffMismatch 两边内容不一致
+	DiffMismatch = "mismatch"
+	// DiffExtra 目标库多出来的
+	DiffExtra = "extra"
+
+	// maxSamples 报告里最多保留的差异条数，完整的差异看日志
+	maxSamples = 100
+)
+
+type Diff struct {
+	// Table draft 或者 pub
+	Table string `json:"table"`
+	ID    uint64 `json:"id"`
+	Kind  string `json:"kind"`
+}
+
+type ValidateReport struct {
+	// Base 以哪一边为准，src 或者 dst
+	Base     string `json:"base"`
+	Repair   bool   `json:"repair"`
+	Checked  int64  `json:"checked"`
+	Missing  int64  `json:"missing"`
+	Mismatch int64  `json:"mismatch"`
+	Extra    int64  `json:"extra"`
+	Repaired int64  `json:"repaired"`
+	Samples  []Diff `json:"samples"`
+	Done     bool   `json:"done"`
+	Err      string `json:"err"`
+	Utime    int64  `json:"utime"`
+}
+
+// Clean 校验跑完并且没有发现任何差异，修复过的不算，需要再校验一次
+func (r *ValidateReport) Clean() bool {
+	return r.Done && r.Err == "" && r.Missing == 0 && r.Mismatch == 0 && r.Extra == 0
+}
+
+func (r *ValidateReport) add(d Diff) {
+	switch d.Kind {
+	case DiffMissing:
+		r.Missing++
+	case DiffMismatch:
+		r.Mismatch++
+	case DiffExtra:
+		r.Extra++
+	}
+	if len(r.Samples) < maxSamples {
+		r.Samples = append(r.Samples, d)
+	}
+}
+
+// validator 两边同时按 id 升序遍历做归并比较，一趟就能找出缺少、不一致和多出来的行
+type validator struct {
+	base      dao.MigrateDAO
+	target    dao.MigrateDAO
+	batchSize int
+	// checkpoint 保存进度并续约锁
+	checkpoint func(ctx context.Context, r ValidateReport) error
+}
+
+func (v *validator) run(ctx context.Context, r ValidateReport) error {
+	err := compareTable(ctx, v, &r, "draft",
+		v.base.DraftsAfter, v.target.DraftsAfter, v.target.UpsertDraft, v.target.DeleteDraft,
+		func(art dao.Article) uint64 { return art.ID })
+	if err == nil {
+		err = compareTable(ctx, v, &r, "pub",
+			v.base.PubsAfter, v.target.PubsAfter, v.target.UpsertPub, v.target.DeletePub,
+			func(art dao.OnlineArticle) uint64 { return art.ID })
+	}
+	if err != nil {
+		r.Err = err.Error()
+	} else {
+		r.Done = true
+	}
+
+	saveCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if saveErr := v.checkpoint(saveCtx, r); err == nil {
+		err = saveErr
+	}
+
+	return err
+}
+
+func compareTable[T comparable](ctx context.Context, v *validator, r *ValidateReport, table string,
+	fetchBase, fetchTarget func(ctx context.Context, id uint64, limit int) ([]T, error),
+	upsert func(ctx context.Context, row T) error,
+	del func(ctx context.Context, id uint64) error,
+	id func(T) uint64) error {
+	base := &stream[T]{fetch: fetchBase, id: id, limit: v.batchSize}
+	target := &stream[T]{fetch: fetchTarget, id: id, limit: v.batchSize}
+
+	for n := 1; ; n++ {
+		b, okBase, err := base.peek(ctx)
+		if err != nil {
+			return err
+		}
+		t, okTarget, err := target.peek(ctx)
+		if err != nil {
+			return err
+		}
+
+		var diff Diff
+		switch {
+		case !okBase && !okTarget:
+			return nil
+		case okBase && (!okTarget || id(b) < id(t)):
+			diff = Diff{Table: table, ID: id(b), Kind: DiffMissing}
+			base.next()
+		case okTarget && (!okBase || id(t) < id(b)):
+			diff = Diff{Table: table, ID: id(t), Kind: DiffExtra}
+			target.next()
+		default:
+			if b != t {
+				diff = Diff{Table: table, ID: id(b), Kind: DiffMismatch}
+			}
+			base.next()
+			target.next()
+		}
+		r.Checked++
+
+		if diff.Kind != "" {
+			log.Printf("article migration diff: %s %d %s", diff.Table, diff.ID, diff.Kind)
+			r.add(diff)
+			if r.Repair {
+				// 目标库已经读到的位置之前写入或删除，不会影响后面的遍历
+				if diff.Kind == DiffExtra {
+					err = del(ctx, diff.ID)
+				} else {
+					err = upsert(ctx, b)
+				}
+				if err != nil {
+					return err
+				}
+				r.Repaired++
+			}
+		}
+
+		if n%v.batchSize == 0 {
+			if err = v.checkpoint(ctx, *r); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// stream 分批读取的游标
+type stream[T any] struct {
+	fetch  func(ctx context.Context, id uint64, limit int) ([]T, error)
+	id     func(T) uint64
+	limit  int
+	buf    []T
+	cursor uint64
+	eof    bool
+}
+
+func (s *stream[T]) peek(ctx context.Context) (T, bool, error) {
+	if len(s.buf) == 0 && !s.eof {
+		rows, err := s.fetch(ctx, s.cursor, s.limit)
+		if err != nil {
+			var zero T
+			return zero, false, err
+		}
+		if len(rows) < s.limit {
+			s.eof = true
+		}
+		if len(rows) > 0 {
+			s.cursor = s.id(rows[len(rows)-1])
+		}
+		s.buf = rows
+	}
+	if len(s.buf) == 0 {
+		var zero T
+		return zero, false, nil
+	}
+
+	return s.buf[0], true, nil
+}
+
+func (s *stream[T]) next() {
+	s.buf = s.buf[1:]
+}
@@ -0,0 +1,141 @@
+package migrator
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+// memDAO 只实现迁移用到的方法
+type memDAO struct {
+	dao.MigrateDAO
+	drafts map[uint64]dao.Article
+	pubs   map[uint64]dao.OnlineArticle
+}
+
+func newMemDAO(drafts ...dao.Article) *memDAO {
+	m := &memDAO{drafts: map[uint64]dao.Article{}, pubs: map[uint64]dao.OnlineArticle{}}
+	for _, art := range drafts {
+		m.drafts[art.ID] = art
+	}
+	return m
+}
+
+func (m *memDAO) UpsertDraft(ctx context.Context, art dao.Article) error {
+	m.drafts[art.ID] = art
+	return nil
+}
+
+func (m *memDAO) UpsertPub(ctx context.Context, art dao.OnlineArticle) error {
+	m.pubs[art.ID] = art
+	return nil
+}
+
+func (m *memDAO) DeleteDraft(ctx context.Context, id uint64) error {
+	delete(m.drafts, id)
+	return nil
+}
+
+func (m *memDAO) DeletePub(ctx context.Context, id uint64) error {
+	delete(m.pubs, id)
+	return nil
+}
+
+func (m *memDAO) DraftsAfter(ctx context.Context, id uint64, limit int) ([]dao.Article, error) {
+	return after(m.drafts, id, limit), nil
+}
+
+func (m *memDAO) PubsAfter(ctx context.Context, id uint64, limit int) ([]dao.OnlineArticle, error) {
+	return after(m.pubs, id, limit), nil
+}
+
+func after[T any](rows map[uint64]T, id uint64, limit int) []T {
+	var ids []uint64
+	for k := range rows {
+		if k > id {
+			ids = append(ids, k)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	res := make([]T, 0, len(ids))
+	for _, k := range ids {
+		res = append(res, rows[k])
+	}
+	return res
+}
+
+func TestValidator(t *testing.T) {
+	testCases := []struct {
+		name   string
+		repair bool
+	}{
+		{name: "report only"},
+		{name: "repair", repair: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base := newMemDAO(
+				dao.Article{ID: 1, Title: "a"},
+				dao.Article{ID: 2, Title: "b"},
+				dao.Article{ID: 3, Title: "c"},
+				dao.Article{ID: 5, Title: "e"},
+				dao.Article{ID: 6, Title: "f"},
+			)
+			base.pubs[2] = dao.OnlineArticle{ID: 2, Title: "b"}
+			target := newMemDAO(
+				dao.Article{ID: 1, Title: "a"},
+				dao.Article{ID: 2, Title: "b2"},
+				dao.Article{ID: 4, Title: "d"},
+				dao.Article{ID: 5, Title: "e"},
+				dao.Article{ID: 7, Title: "g"},
+			)
+
+			var saved []ValidateReport
+			v := &validator{
+				base:   base,
+				target: target,
+				// 比行数小，覆盖分批读取
+				batchSize: 2,
+				checkpoint: func(ctx context.Context, r ValidateReport) error {
+					saved = append(saved, r)
+					return nil
+				},
+			}
+			err := v.run(context.Background(), ValidateReport{Base: "src", Repair: tc.repair})
+			require.NoError(t, err)
+
+			r := saved[len(saved)-1]
+			assert.True(t, r.Done)
+			assert.Equal(t, int64(8), r.Checked)
+			assert.Equal(t, int64(3), r.Missing)
+			assert.Equal(t, int64(1), r.Mismatch)
+			assert.Equal(t, int64(2), r.Extra)
+			assert.ElementsMatch(t, []Diff{
+				{Table: "draft", ID: 2, Kind: DiffMismatch},
+				{Table: "draft", ID: 3, Kind: DiffMissing},
+				{Table: "draft", ID: 4, Kind: DiffExtra},
+				{Table: "draft", ID: 6, Kind: DiffMissing},
+				{Table: "draft", ID: 7, Kind: DiffExtra},
+				{Table: "pub", ID: 2, Kind: DiffMissing},
+			}, r.Samples)
+
+			if tc.repair {
+				assert.Equal(t, int64(6), r.Repaired)
+				assert.Equal(t, base.drafts, target.drafts)
+				assert.Equal(t, base.pubs, target.pubs)
+			} else {
+				assert.Zero(t, r.Repaired)
+				assert.Len(t, target.drafts, 5)
+			}
+		})
+	}
+}
@@ -2,23 +2,29 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 )
 
 var (
 	ErrRecordNotFound = dao.ErrRecordNotFound
 )
 
+type PublishEvents = dao.PublishEvents
+
 type ArticleRepository struct {
-	dao *dao.GORMArticleDao
+	dao    dao.ArticleDAO
+	outbox *outbox.Outbox
 }
 
-func NewArticleRepository(dao *dao.GORMArticleDao) *ArticleRepository {
+func NewArticleRepository(dao dao.ArticleDAO, outbox *outbox.Outbox) *ArticleRepository {
 	return &ArticleRepository{
-		dao: dao,
+		dao:    dao,
+		outbox: outbox,
 	}
 }
 
@@ -30,8 +36,37 @@ func (repo *ArticleRepository) UpdateDraft(ctx context.Context, art domain.Artic
 	return repo.dao.UpdateDraftByID(ctx, repo.articleDomainToDao(art))
 }
 
-func (repo *ArticleRepository) Sync(ctx context.Context, art domain.Article) (uint64, error) {
-	return repo.dao.SyncToPublish(ctx, repo.articleDomainToDao(art))
+// RestoreDraft 标题和正文原样写入制作库，恢复到空标题或者空正文的版本时也会覆盖
+func (repo *ArticleRepository) RestoreDraft(ctx context.Context, art domain.Article) error {
+	return repo.dao.RestoreDraftByID(ctx, repo.articleDomainToDao(art))
+}
+
+// Sync events 不为 nil 时发布和写发件箱在同一个事务里完成
+// 存储不支持时（MongoDB）只能在发布成功之后再写，两步之间出错会返回错误，重新发布即可补上
+func (repo *ArticleRepository) Sync(ctx context.Context, art domain.Article, events PublishEvents) (uint64, error) {
+	a := repo.articleDomainToDao(art)
+	if d, ok := repo.dao.(dao.OutboxDAO); ok {
+		id, err := d.SyncToPublishWithEvents(ctx, a, events)
+		if !errors.Is(err, dao.ErrNoOutbox) {
+			return id, err
+		}
+	}
+
+	id, err := repo.dao.SyncToPublish(ctx, a)
+	if err != nil || events == nil {
+		return id, err
+	}
+	pub, err := repo.dao.GetPubByID(ctx, id)
+	if err != nil {
+		return id, err
+	}
+
+	return id, repo.outbox.Append(ctx, events(id, pub.Ctime)...)
+}
+
+// AppendEvents 没有业务数据要一起提交的事件直接写入发件箱
+func (repo *ArticleRepository) AppendEvents(ctx context.Context, evts ...outbox.Event) error {
+	return repo.outbox.Append(ctx, evts...)
 }
 
 func (repo *ArticleRepository) onlineArticleDaoToDomain(art dao.OnlineArticle) domain.Article {
@@ -104,6 +139,20 @@ func (repo *ArticleRepository) GetPubListByID(ctx context.Context, offset, limit
 	return arts, nil
 }
 
+func (repo *ArticleRepository) ListPubSince(ctx context.Context, start time.Time, offset, limit int) ([]domain.Article, error) {
+	res, err := repo.dao.ListPubSince(ctx, start.UnixMilli(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	arts := make([]domain.Article, 0, len(res))
+	for _, art := range res {
+		arts = append(arts, repo.onlineArticleDaoToDomain(art))
+	}
+
+	return arts, nil
+}
+
 func (repo *ArticleRepository) GetByID(ctx context.Context, aid uint64) (domain.Article, error) {
 	art, err := repo.dao.GetByID(ctx, aid)
 	if err != nil {
@@ -121,3 +170,18 @@ func (repo *ArticleRepository) GetPubByID(ctx context.Context, aid uint64) (doma
 
 	return repo.onlineArticleDaoToDomain(art), nil
 }
+
+// GetPubByIDs 不保证带正文，不存在的 id 不会出现在结果中
+func (repo *ArticleRepository) GetPubByIDs(ctx context.Context, ids []uint64) (map[uint64]domain.Article, error) {
+	res, err := repo.dao.GetPubByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	arts := make(map[uint64]domain.Article, len(res))
+	for _, art := range res {
+		arts[art.ID] = repo.onlineArticleDaoToDomain(art)
+	}
+
+	return arts, nil
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+// AutosaveCache 编辑器定时提交的草稿只放在 Redis 里，
+// 不写制作库，所以不会改动文章的 utime，也不会产生版本
+type AutosaveCache struct {
+	cmd        redis.Cmdable
+	expiration time.Duration
+}
+
+func NewAutosaveCache(cmd redis.Cmdable) *AutosaveCache {
+	return &AutosaveCache{
+		cmd:        cmd,
+		expiration: time.Hour * 24 * 7,
+	}
+}
+
+func (cache *AutosaveCache) Set(ctx context.Context, as domain.Autosave) error {
+	val, err := json.Marshal(as)
+	if err != nil {
+		return err
+	}
+
+	return cache.cmd.Set(ctx, cache.key(as.ArticleID), val, cache.expiration).Err()
+}
+
+// Get 没有自动保存的内容时返回 redis.Nil
+func (cache *AutosaveCache) Get(ctx context.Context, aid uint64) (domain.Autosave, error) {
+	val, err := cache.cmd.Get(ctx, cache.key(aid)).Bytes()
+	if err != nil {
+		return domain.Autosave{}, err
+	}
+
+	var as domain.Autosave
+	err = json.Unmarshal(val, &as)
+	return as, err
+}
+
+func (cache *AutosaveCache) Del(ctx context.Context, aid uint64) error {
+	return cache.cmd.Del(ctx, cache.key(aid)).Err()
+}
+
+func (cache *AutosaveCache) key(aid uint64) string {
+	return fmt.Sprintf("article:autosave:%d", aid)
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+// FeedCache 关注动态的收件箱和发件箱，都是 zset，member 是文章 id，score 是发布时间
+// 发件箱每个作者一个，不过期；收件箱只给活跃用户维护，和活跃标记一起过期
+type FeedCache struct {
+	cmd redis.Cmdable
+	// size 收件箱和发件箱保留的文章数
+	size   int
+	active time.Duration
+}
+
+func NewFeedCache(cmd redis.Cmdable, size int, active time.Duration) *FeedCache {
+	return &FeedCache{
+		cmd:    cmd,
+		size:   size,
+		active: active,
+	}
+}
+
+// AddOutbox 重复发布时保留第一次的时间
+func (cache *FeedCache) AddOutbox(ctx context.Context, author uint64, item domain.FeedItem) error {
+	key := cache.outboxKey(author)
+	pipe := cache.cmd.Pipeline()
+	pipe.ZAddNX(ctx, key, cache.member(item))
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-cache.size-1))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Push 写入多个用户的收件箱
+func (cache *FeedCache) Push(ctx context.Context, uids []uint64, items ...domain.FeedItem) error {
+	if len(uids) == 0 || len(items) == 0 {
+		return nil
+	}
+
+	members := make([]redis.Z, 0, len(items))
+	for _, item := range items {
+		members = append(members, cache.member(item))
+	}
+	pipe := cache.cmd.Pipeline()
+	for _, uid := range uids {
+		key := cache.inboxKey(uid)
+		pipe.ZAddNX(ctx, key, members...)
+		pipe.ZRemRangeByRank(ctx, key, 0, int64(-cache.size-1))
+		pipe.Expire(ctx, key, cache.active)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Inbox 分数小于 before 的最多 limit 项，新的在前
+func (cache *FeedCache) Inbox(ctx context.Context, uid uint64, before int64, limit int) ([]domain.FeedItem, error) {
+	return cache.rangeBefore(ctx, cache.inboxKey(uid), before, limit)
+}
+
+func (cache *FeedCache) Outbox(ctx context.Context, author uint64, before int64, limit int) ([]domain.FeedItem, error) {
+	return cache.rangeBefore(ctx, cache.outboxKey(author), before, limit)
+}
+
+func (cache *FeedCache) HasInbox(ctx context.Context, uid uint64) (bool, error) {
+	n, err := cache.cmd.Exists(ctx, cache.inboxKey(uid)).Result()
+	return n > 0, err
+}
+
+// RemoveAuthor 取消关注时把这个作者的文章从收件箱里去掉
+func (cache *FeedCache) RemoveAuthor(ctx context.Context, uid, author uint64) error {
+	aids, err := cache.cmd.ZRange(ctx, cache.outboxKey(author), 0, -1).Result()
+	if err != nil || len(aids) == 0 {
+		return err
+	}
+
+	members := make([]any, 0, len(aids))
+	for _, aid := range aids {
+		members = append(members, aid)
+	}
+	return cache.cmd.ZRem(ctx, cache.inboxKey(uid), members...).Err()
+}
+
+func (cache *FeedCache) MarkActive(ctx context.Context, uid uint64) error {
+	return cache.cmd.Set(ctx, cache.activeKey(uid), 1, cache.active).Err()
+}
+
+// FilterActive 返回其中活跃的用户
+func (cache *FeedCache) FilterActive(ctx context.Context, uids []uint64) ([]uint64, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	pipe := cache.cmd.Pipeline()
+	cmds := make([]*redis.IntCmd, 0, len(uids))
+	for _, uid := range uids {
+		cmds = append(cmds, pipe.Exists(ctx, cache.activeKey(uid)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	var res []uint64
+	for i, cmd := range cmds {
+		if cmd.Val() > 0 {
+			res = append(res, uids[i])
+		}
+	}
+
+	return res, nil
+}
+
+func (cache *FeedCache) rangeBefore(ctx context.Context, key string, before int64, limit int) ([]domain.FeedItem, error) {
+	zs, err := cache.cmd.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Max:   "(" + strconv.FormatInt(before, 10),
+		Min:   "-inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.FeedItem, 0, len(zs))
+	for _, z := range zs {
+		aid, err := strconv.ParseUint(z.Member.(string), 10, 64)
+		if err != nil {
+			continue
+		}
+		items = append(items, domain.FeedItem{ArticleID: aid, Ctime: int64(z.Score)})
+	}
+
+	return items, nil
+}
+
+func (cache *FeedCache) member(item domain.FeedItem) redis.Z {
+	return redis.Z{
+		Score:  float64(item.Ctime),
+		Member: strconv.FormatUint(item.ArticleID, 10),
+	}
+}
+
+func (cache *FeedCache) inboxKey(uid uint64) string {
+	return fmt.Sprintf("feed:inbox:%d", uid)
+}
+
+func (cache *FeedCache) outboxKey(author uint64) string {
+	return fmt.Sprintf("feed:outbox:%d", author)
+}
+
+func (cache *FeedCache) activeKey(uid uint64) string {
+	return fmt.Sprintf("feed:active:%d", uid)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -13,13 +14,39 @@ import (
 	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
 )
 
-//go:embed lua/interactive.lua
-var incrCntLua string
+var (
+	//go:embed lua/interactive.lua
+	incrCntLua string
+	//go:embed lua/interactive_delta.lua
+	addDeltaLua string
+	//go:embed lua/interactive_ack.lua
+	ackDeltaLua string
+	//go:embed lua/interactive_warm.lua
+	warmCntLua string
+)
 
 var (
 	ErrKeyNotExists = errors.New("key not found")
 )
 
+const (
+	// interactiveDirtyKey 有增量还没落库的资源，分数是第一次变脏的时间（毫秒）
+	interactiveDirtyKey = "interactive:dirty"
+	interactiveTTL      = time.Minute * 5
+)
+
+// DirtyItem 一个有增量待落库的资源
+type DirtyItem struct {
+	Biz   string
+	BizID uint64
+	// Since 第一次产生增量的时间
+	Since time.Time
+}
+
+// InteractiveCache 计数采用写回策略：
+// 阅读和点赞只累加到 Redis 里的增量上，由定时任务批量落库；
+// 计数 interactive:{biz}:{id} 是数据库的值加上增量，带过期时间，被淘汰后读的时候回填；
+// 增量和待落库集合不设置过期时间，Redis 使用 volatile-* 淘汰策略时不会被淘汰
 type InteractiveCache struct {
 	cmd redis.Cmdable
 }
@@ -29,20 +56,34 @@ func NewInteractiveCache(cmd redis.Cmdable) *InteractiveCache {
 }
 
 func (cache *InteractiveCache) IncrReadCnt(ctx context.Context, biz string, bizId uint64) error {
-	return cache.cmd.Eval(ctx, incrCntLua, []string{cache.key(biz, bizId)}, "read_cnt", 1).Err()
+	return cache.addDelta(ctx, cache.cmd, biz, bizId, "read_cnt", 1).Err()
+}
+
+func (cache *InteractiveCache) BatchIncrReadCnt(ctx context.Context, biz string, cnts map[uint64]int64) error {
+	pipe := cache.cmd.Pipeline()
+	for id, cnt := range cnts {
+		cache.addDelta(ctx, pipe, biz, id, "read_cnt", cnt)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 func (cache *InteractiveCache) IncrLikeCnt(ctx context.Context, biz string, bizId uint64) error {
-	return cache.cmd.Eval(ctx, incrCntLua, []string{cache.key(biz, bizId)}, "like_cnt", 1).Err()
+	return cache.addDelta(ctx, cache.cmd, biz, bizId, "like_cnt", 1).Err()
 }
 
 func (cache *InteractiveCache) DecrLikeCnt(ctx context.Context, biz string, bizId uint64) error {
-	return cache.cmd.Eval(ctx, incrCntLua, []string{cache.key(biz, bizId)}, "like_cnt", -1).Err()
+	return cache.addDelta(ctx, cache.cmd, biz, bizId, "like_cnt", -1).Err()
+}
+
+// IncrCollectCnt 收藏数在数据库里同步更新，这里只更新已经缓存的计数
+func (cache *InteractiveCache) IncrCollectCnt(ctx context.Context, biz string, bizId uint64) error {
+	return cache.cmd.Eval(ctx, incrCntLua, []string{cache.key(biz, bizId)}, "collect_cnt", 1).Err()
 }
 
-//func (cache *InteractiveCache) DelReadCnt(ctx context.Context, biz string, bizId uint64) error {
-//	return cache.cmd.Del(ctx, cache.key(biz, bizId)).Err()
-//}
+func (cache *InteractiveCache) DecrCollectCnt(ctx context.Context, biz string, bizId uint64) error {
+	return cache.cmd.Eval(ctx, incrCntLua, []string{cache.key(biz, bizId)}, "collect_cnt", -1).Err()
+}
 
 func (cache *InteractiveCache) GetInteractive(ctx context.Context, biz string, bizId uint64) (domain.Interactive, error) {
 	data, err := cache.cmd.HGetAll(ctx, cache.key(biz, bizId)).Result()
@@ -54,38 +95,212 @@ func (cache *InteractiveCache) GetInteractive(ctx context.Context, biz string, b
 		return domain.Interactive{}, ErrKeyNotExists
 	}
 
-	likeCnt, _ := strconv.ParseInt(data["like_cnt"], 10, 64)
-	readCnt, _ := strconv.ParseInt(data["read_cnt"], 10, 64)
-	liked, _ := strconv.ParseBool(data["liked"])
+	return toInteractive(data), nil
+}
 
-	return domain.Interactive{
-		Liked:   liked,
-		LikeCnt: likeCnt,
-		ReadCnt: readCnt,
-	}, nil
+// BatchGetInteractive 没有缓存的资源不会出现在结果里
+func (cache *InteractiveCache) BatchGetInteractive(ctx context.Context, biz string, ids []uint64) (map[uint64]domain.Interactive, error) {
+	pipe := cache.cmd.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, 0, len(ids))
+	for _, id := range ids {
+		cmds = append(cmds, pipe.HGetAll(ctx, cache.key(biz, id)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]domain.Interactive, len(ids))
+	for i, cmd := range cmds {
+		if data := cmd.Val(); len(data) > 0 {
+			res[ids[i]] = toInteractive(data)
+		}
+	}
+
+	return res, nil
+}
+
+// WarmInteractive 用数据库中的计数加上还没落库的增量回填缓存，返回回填后的计数
+func (cache *InteractiveCache) WarmInteractive(ctx context.Context, biz string, bizId uint64, inter domain.Interactive) (domain.Interactive, error) {
+	res, err := cache.cmd.Eval(ctx, warmCntLua,
+		[]string{cache.key(biz, bizId), cache.deltaKey(biz, bizId)},
+		inter.ReadCnt, inter.LikeCnt, inter.CollectCnt, int(interactiveTTL.Seconds())).StringSlice()
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+
+	return toInteractive(pairsToMap(res)), nil
 }
 
-func (cache *InteractiveCache) SetInteractive(ctx context.Context, biz string, bizId uint64, inter domain.Interactive) error {
-	val := []interface{}{
-		"read_cnt", inter.ReadCnt,
-		"like_cnt", inter.LikeCnt,
-		"liked", inter.Liked,
+// BatchWarmInteractive 与 WarmInteractive 相同，一次回填多个资源
+func (cache *InteractiveCache) BatchWarmInteractive(ctx context.Context, biz string, inters map[uint64]domain.Interactive) (map[uint64]domain.Interactive, error) {
+	pipe := cache.cmd.Pipeline()
+	cmds := make(map[uint64]*redis.Cmd, len(inters))
+	for id, inter := range inters {
+		cmds[id] = pipe.Eval(ctx, warmCntLua,
+			[]string{cache.key(biz, id), cache.deltaKey(biz, id)},
+			inter.ReadCnt, inter.LikeCnt, inter.CollectCnt, int(interactiveTTL.Seconds()))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]domain.Interactive, len(cmds))
+	for id, cmd := range cmds {
+		pairs, err := cmd.StringSlice()
+		if err != nil {
+			return nil, err
+		}
+		res[id] = toInteractive(pairsToMap(pairs))
 	}
 
-	err := cache.cmd.HSet(ctx, cache.key(biz, bizId), val...).Err()
+	return res, nil
+}
+
+func (cache *InteractiveCache) DelInteractive(ctx context.Context, biz string, bizId uint64) error {
+	return cache.cmd.Del(ctx, cache.key(biz, bizId)).Err()
+}
+
+// DirtyItems 取出最早变脏的 limit 个资源
+func (cache *InteractiveCache) DirtyItems(ctx context.Context, limit int) ([]DirtyItem, error) {
+	zs, err := cache.cmd.ZRangeWithScores(ctx, interactiveDirtyKey, 0, int64(limit-1)).Result()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// 设置过期时间
-	err = cache.cmd.Expire(ctx, cache.key(biz, bizId), time.Minute*5).Err()
+	res := make([]DirtyItem, 0, len(zs))
+	for _, z := range zs {
+		member, _ := z.Member.(string)
+		biz, bizId, ok := parseMember(member)
+		if !ok {
+			continue
+		}
+		res = append(res, DirtyItem{
+			Biz:   biz,
+			BizID: bizId,
+			Since: time.UnixMilli(int64(z.Score)),
+		})
+	}
+
+	return res, nil
+}
+
+// OldestDirty 返回最早一个待落库增量产生的时间，没有待落库的增量时返回零值
+func (cache *InteractiveCache) OldestDirty(ctx context.Context) (time.Time, error) {
+	zs, err := cache.cmd.ZRangeWithScores(ctx, interactiveDirtyKey, 0, 0).Result()
+	if err != nil || len(zs) == 0 {
+		return time.Time{}, err
+	}
+
+	return time.UnixMilli(int64(zs[0].Score)), nil
+}
+
+// Dirty 返回 ids 中哪些资源还有增量没有落库
+func (cache *InteractiveCache) Dirty(ctx context.Context, biz string, ids []uint64) (map[uint64]bool, error) {
+	members := make([]string, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, cache.member(biz, id))
+	}
+	scores, err := cache.cmd.ZMScore(ctx, interactiveDirtyKey, members...).Result()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	res := make(map[uint64]bool, len(ids))
+	for i, score := range scores {
+		if score > 0 {
+			res[ids[i]] = true
+		}
 	}
 
-	return nil
+	return res, nil
+}
+
+// GetDeltas 读取资源待落库的增量，结果和 items 一一对应
+// 只读不删，落库成功之后再用 AckDeltas 扣掉，中途崩溃时增量还在，下一轮会重新落库
+func (cache *InteractiveCache) GetDeltas(ctx context.Context, items []DirtyItem) ([]domain.Interactive, error) {
+	pipe := cache.cmd.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, 0, len(items))
+	for _, item := range items {
+		cmds = append(cmds, pipe.HGetAll(ctx, cache.deltaKey(item.Biz, item.BizID)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Interactive, 0, len(items))
+	for _, cmd := range cmds {
+		res = append(res, toInteractive(cmd.Val()))
+	}
+
+	return res, nil
+}
+
+// AckDeltas 从增量里扣掉已经落库的部分，扣完为 0 的资源移出待落库集合
+// 落库期间新产生的增量会留下来
+func (cache *InteractiveCache) AckDeltas(ctx context.Context, items []DirtyItem, deltas []domain.Interactive) error {
+	pipe := cache.cmd.Pipeline()
+	now := time.Now().UnixMilli()
+	for i, item := range items {
+		args := []any{cache.member(item.Biz, item.BizID), now}
+		if deltas[i].ReadCnt != 0 {
+			args = append(args, "read_cnt", deltas[i].ReadCnt)
+		}
+		if deltas[i].LikeCnt != 0 {
+			args = append(args, "like_cnt", deltas[i].LikeCnt)
+		}
+		pipe.Eval(ctx, ackDeltaLua, []string{cache.deltaKey(item.Biz, item.BizID), interactiveDirtyKey}, args...)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (cache *InteractiveCache) addDelta(ctx context.Context, cmd redis.Cmdable, biz string, bizId uint64, field string, delta int64) *redis.Cmd {
+	return cmd.Eval(ctx, addDeltaLua,
+		[]string{cache.key(biz, bizId), cache.deltaKey(biz, bizId), interactiveDirtyKey},
+		field, delta, cache.member(biz, bizId), time.Now().UnixMilli())
 }
 
 func (cache *InteractiveCache) key(biz string, bizId uint64) string {
 	return fmt.Sprintf("interactive:%s:%d", biz, bizId)
 }
+
+func (cache *InteractiveCache) deltaKey(biz string, bizId uint64) string {
+	return fmt.Sprintf("interactive:delta:%s:%d", biz, bizId)
+}
+
+func (cache *InteractiveCache) member(biz string, bizId uint64) string {
+	return fmt.Sprintf("%s:%d", biz, bizId)
+}
+
+func parseMember(member string) (string, uint64, bool) {
+	i := strings.LastIndexByte(member, ':')
+	if i < 0 {
+		return "", 0, false
+	}
+	bizId, err := strconv.ParseUint(member[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return member[:i], bizId, true
+}
+
+func pairsToMap(pairs []string) map[string]string {
+	res := make(map[string]string, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		res[pairs[i]] = pairs[i+1]
+	}
+	return res
+}
+
+func toInteractive(data map[string]string) domain.Interactive {
+	likeCnt, _ := strconv.ParseInt(data["like_cnt"], 10, 64)
+	readCnt, _ := strconv.ParseInt(data["read_cnt"], 10, 64)
+	collectCnt, _ := strconv.ParseInt(data["collect_cnt"], 10, 64)
+
+	return domain.Interactive{
+		LikeCnt:    likeCnt,
+		ReadCnt:    readCnt,
+		CollectCnt: collectCnt,
+	}
+}
@@ -0,0 +1,16 @@
+-- KEYS[1] 待落库的增量 KEYS[2] 待落库的资源集合
+-- ARGV[1] 集合成员 ARGV[2] 当前时间（毫秒），只在成员不在集合里时使用
+-- ARGV[3..] 已经落库的字段和增量，成对出现
+for i = 3, #ARGV, 2 do
+    redis.call("HINCRBY", KEYS[1], ARGV[i], -tonumber(ARGV[i + 1]))
+end
+for _, v in ipairs(redis.call("HVALS", KEYS[1])) do
+    if tonumber(v) ~= 0 then
+        -- 落库期间又有新的增量，留在集合里等下一轮，保留原来的分数，落库延迟从第一次变脏算起
+        redis.call("ZADD", KEYS[2], "NX", ARGV[2], ARGV[1])
+        return 0
+    end
+end
+redis.call("DEL", KEYS[1])
+redis.call("ZREM", KEYS[2], ARGV[1])
+return 1
@@ -0,0 +1,9 @@
+-- KEYS[1] 计数 KEYS[2] 待落库的增量 KEYS[3] 待落库的资源集合
+-- ARGV[1] 字段 ARGV[2] 增量 ARGV[3] 集合成员 ARGV[4] 当前时间（毫秒）
+redis.call("HINCRBY", KEYS[2], ARGV[1], ARGV[2])
+if redis.call("EXISTS", KEYS[1]) == 1 then
+    redis.call("HINCRBY", KEYS[1], ARGV[1], ARGV[2])
+end
+-- 只记录第一次变脏的时间，用来计算落库延迟
+redis.call("ZADD", KEYS[3], "NX", ARGV[4], ARGV[3])
+return 1
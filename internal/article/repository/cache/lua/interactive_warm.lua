@@ -0,0 +1,17 @@
+-- KEYS[1] 计数 KEYS[2] 待落库的增量
+-- ARGV[1..3] 数据库中的 read_cnt like_cnt collect_cnt，ARGV[4] 过期时间（秒）
+-- 计数已经存在时不覆盖，说明别的请求已经回填过
+if redis.call("EXISTS", KEYS[1]) == 0 then
+    local cnts = {
+        read_cnt = tonumber(ARGV[1]),
+        like_cnt = tonumber(ARGV[2]),
+        collect_cnt = tonumber(ARGV[3]),
+    }
+    local delta = redis.call("HGETALL", KEYS[2])
+    for i = 1, #delta, 2 do
+        cnts[delta[i]] = (cnts[delta[i]] or 0) + tonumber(delta[i + 1])
+    end
+    redis.call("HSET", KEYS[1], "read_cnt", cnts.read_cnt, "like_cnt", cnts.like_cnt, "collect_cnt", cnts.collect_cnt)
+    redis.call("EXPIRE", KEYS[1], ARGV[4])
+end
+return redis.call("HGETALL", KEYS[1])
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+const rankingKey = "ranking:article:hot"
+
+var ErrRankingExpired = errors.New("local ranking expired")
+
+type RankingCache struct {
+	cmd        redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRankingCache(cmd redis.Cmdable) *RankingCache {
+	return &RankingCache{
+		cmd: cmd,
+		// 比计算任务的间隔长得多，任务偶尔失败几次也不会让榜单消失
+		expiration: time.Hour,
+	}
+}
+
+func (cache *RankingCache) Set(ctx context.Context, arts []domain.Article) error {
+	val, err := json.Marshal(arts)
+	if err != nil {
+		return err
+	}
+
+	return cache.cmd.Set(ctx, rankingKey, val, cache.expiration).Err()
+}
+
+func (cache *RankingCache) Get(ctx context.Context) ([]domain.Article, error) {
+	val, err := cache.cmd.Get(ctx, rankingKey).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var arts []domain.Article
+	err = json.Unmarshal(val, &arts)
+	return arts, err
+}
+
+// LocalRankingCache 进程内的榜单，Redis 不可用时兜底
+type LocalRankingCache struct {
+	mu         sync.RWMutex
+	arts       []domain.Article
+	ddl        time.Time
+	expiration time.Duration
+}
+
+func NewLocalRankingCache() *LocalRankingCache {
+	return &LocalRankingCache{
+		expiration: time.Minute,
+	}
+}
+
+func (cache *LocalRankingCache) Set(arts []domain.Article) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.arts = arts
+	cache.ddl = time.Now().Add(cache.expiration)
+}
+
+// Get 过期后返回 ErrRankingExpired，调用方应该回源到 Redis
+func (cache *LocalRankingCache) Get() ([]domain.Article, error) {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+	if len(cache.arts) == 0 || time.Now().After(cache.ddl) {
+		return nil, ErrRankingExpired
+	}
+	return cache.arts, nil
+}
+
+// ForceGet 忽略过期时间，Redis 出错时宁可返回旧榜单
+func (cache *LocalRankingCache) ForceGet() ([]domain.Article, error) {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+	if len(cache.arts) == 0 {
+		return nil, ErrRankingExpired
+	}
+	return cache.arts, nil
+}
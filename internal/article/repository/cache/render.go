@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+// RenderCache 渲染结果按文章版本缓存，重新发布后用新的 key，旧的等过期
+type RenderCache struct {
+	cmd        redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRenderCache(cmd redis.Cmdable) *RenderCache {
+	return &RenderCache{
+		cmd:        cmd,
+		expiration: time.Hour * 24,
+	}
+}
+
+func (cache *RenderCache) Set(ctx context.Context, r domain.Rendered) error {
+	val, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return cache.cmd.Set(ctx, cache.key(r.ArticleID, r.Version), val, cache.expiration).Err()
+}
+
+// Get 没有缓存时返回 redis.Nil
+func (cache *RenderCache) Get(ctx context.Context, aid uint64, version int64) (domain.Rendered, error) {
+	val, err := cache.cmd.Get(ctx, cache.key(aid, version)).Bytes()
+	if err != nil {
+		return domain.Rendered{}, err
+	}
+
+	var r domain.Rendered
+	err = json.Unmarshal(val, &r)
+	return r, err
+}
+
+func (cache *RenderCache) key(aid uint64, version int64) string {
+	return fmt.Sprintf("article:render:%d:%d", aid, version)
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+var (
+	ErrNoUserCollect  = dao.ErrNoUserCollect
+	ErrFolderNotFound = dao.ErrFolderNotFound
+	ErrFolderExists   = dao.ErrFolderExists
+)
+
+type CollectRepository struct {
+	dao *dao.CollectDao
+}
+
+func NewCollectRepository(dao *dao.CollectDao) *CollectRepository {
+	return &CollectRepository{
+		dao: dao,
+	}
+}
+
+func (r *CollectRepository) CreateFolder(ctx context.Context, f domain.CollectFolder) (uint64, error) {
+	return r.dao.CreateFolder(ctx, dao.CollectFolder{
+		UID:         f.Uid,
+		Name:        f.Name,
+		Description: f.Description,
+	})
+}
+
+func (r *CollectRepository) FindFolder(ctx context.Context, uid, fid uint64) (domain.CollectFolder, error) {
+	f, err := r.dao.FindFolder(ctx, uid, fid)
+	if err != nil {
+		return domain.CollectFolder{}, err
+	}
+
+	return r.folderToDomain(f, 0), nil
+}
+
+func (r *CollectRepository) ListFolders(ctx context.Context, uid uint64) ([]domain.CollectFolder, error) {
+	fs, err := r.dao.ListFolders(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.CollectFolder, 0, len(fs))
+	for _, f := range fs {
+		res = append(res, r.folderToDomain(f.CollectFolder, f.Cnt))
+	}
+
+	return res, nil
+}
+
+func (r *CollectRepository) ListItems(ctx context.Context, uid, fid uint64, offset, limit int) ([]domain.CollectItem, error) {
+	items, err := r.dao.ListItems(ctx, uid, fid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.CollectItem, 0, len(items))
+	for _, item := range items {
+		res = append(res, domain.CollectItem{
+			Biz:      item.Biz,
+			BizID:    item.BizID,
+			FolderID: item.FolderID,
+			Ctime:    time.UnixMilli(item.Utime),
+		})
+	}
+
+	return res, nil
+}
+
+func (r *CollectRepository) folderToDomain(f dao.CollectFolder, cnt int64) domain.CollectFolder {
+	return domain.CollectFolder{
+		ID:          f.ID,
+		Uid:         f.UID,
+		Name:        f.Name,
+		Description: f.Description,
+		Cnt:         cnt,
+		Ctime:       time.UnixMilli(f.Ctime),
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+var ErrCommentNotFound = dao.ErrCommentNotFound
+
+type CommentRepository struct {
+	dao *dao.CommentDao
+}
+
+func NewCommentRepository(dao *dao.CommentDao) *CommentRepository {
+	return &CommentRepository{
+		dao: dao,
+	}
+}
+
+func (r *CommentRepository) Create(ctx context.Context, c domain.Comment) (uint64, error) {
+	return r.dao.Insert(ctx, dao.Comment{
+		UID:     c.Commentator.Id,
+		Biz:     c.Biz,
+		BizID:   c.BizID,
+		Content: c.Content,
+		RootID:  c.RootID,
+		PID:     c.PID,
+		Status:  c.Status.ToUint8(),
+	})
+}
+
+func (r *CommentRepository) FindByID(ctx context.Context, id uint64) (domain.Comment, error) {
+	c, err := r.dao.FindByID(ctx, id)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	return r.toDomain(c), nil
+}
+
+func (r *CommentRepository) UpdateContent(ctx context.Context, id, uid uint64, content string) error {
+	return r.dao.UpdateContent(ctx, id, uid, content)
+}
+
+func (r *CommentRepository) UpdateStatus(ctx context.Context, id uint64, status domain.CommentStatus) error {
+	return r.dao.UpdateStatus(ctx, id, status.ToUint8())
+}
+
+// FindByBiz 顶级评论，同时带上每条评论的回复数
+func (r *CommentRepository) FindByBiz(ctx context.Context, biz string, bizId, cursor uint64, limit int) ([]domain.Comment, error) {
+	cs, err := r.dao.FindByBiz(ctx, biz, bizId, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(cs))
+	for _, c := range cs {
+		ids = append(ids, c.ID)
+	}
+	cnt, err := r.dao.CountReplies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Comment, 0, len(cs))
+	for _, c := range cs {
+		dc := r.toDomain(c)
+		dc.ReplyCnt = cnt[c.ID]
+		res = append(res, dc)
+	}
+
+	return res, nil
+}
+
+func (r *CommentRepository) FindReplies(ctx context.Context, rootId, cursor uint64, limit int) ([]domain.Comment, error) {
+	cs, err := r.dao.FindReplies(ctx, rootId, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Comment, 0, len(cs))
+	for _, c := range cs {
+		res = append(res, r.toDomain(c))
+	}
+
+	return res, nil
+}
+
+func (r *CommentRepository) toDomain(c dao.Comment) domain.Comment {
+	return domain.Comment{
+		ID:    c.ID,
+		Biz:   c.Biz,
+		BizID: c.BizID,
+		Commentator: domain.Author{
+			Id: c.UID,
+		},
+		Content: c.Content,
+		RootID:  c.RootID,
+		PID:     c.PID,
+		Status:  domain.CommentStatus(c.Status),
+		Ctime:   time.UnixMilli(c.Ctime),
+		Utime:   time.UnixMilli(c.Utime),
+	}
+}
@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
+)
+
+var ErrNoOutbox = errors.New("storage does not support outbox")
+
+// ArticleDAO 文章的存储，制作库与线上库分开
+// GORMArticleDao 全部存 MySQL，MongoArticleDao 存 MongoDB 并对大文章分片，
+// OSSArticleDao 元数据存 MySQL、线上正文存对象存储，通过配置 article.storage 选择
+type ArticleDAO interface {
+	CreateDraft(ctx context.Context, art Article) (uint64, error)
+	// UpdateDraftByID 只能更新自己的文章，空的标题和正文不会覆盖原有内容
+	UpdateDraftByID(ctx context.Context, art Article) error
+	// RestoreDraftByID 与 UpdateDraftByID 相同，但标题和正文总是覆盖，空的也一样
+	RestoreDraftByID(ctx context.Context, art Article) error
+	// SyncToPublish 保存制作库并同步到线上库，返回文章 id
+	SyncToPublish(ctx context.Context, art Article) (uint64, error)
+	SyncStatus(ctx context.Context, id uint64, authorId uint64, status uint8) error
+
+	GetListByID(ctx context.Context, offset, limit int) ([]Article, error)
+	GetByID(ctx context.Context, id uint64) (Article, error)
+	// GetPubListByID 列表不保证带正文，需要正文时用 GetPubByID
+	GetPubListByID(ctx context.Context, offset, limit int) ([]OnlineArticle, error)
+	// ListPubSince start 之后第一次发布的已发布文章，ctime 是第一次发布的时间
+	ListPubSince(ctx context.Context, start int64, offset, limit int) ([]OnlineArticle, error)
+	GetPubByID(ctx context.Context, aid uint64) (OnlineArticle, error)
+	// GetPubByIDs 与 GetPubListByID 一样不保证带正文，不存在的 id 直接忽略
+	GetPubByIDs(ctx context.Context, ids []uint64) ([]OnlineArticle, error)
+}
+
+// MigrateDAO 在不同存储之间迁移文章时用到的能力
+// 写入时保留原来的 id 和时间，遍历时按 id 升序
+type MigrateDAO interface {
+	ArticleDAO
+	UpsertDraft(ctx context.Context, art Article) error
+	UpsertPub(ctx context.Context, art OnlineArticle) error
+	DeleteDraft(ctx context.Context, id uint64) error
+	DeletePub(ctx context.Context, id uint64) error
+	// DraftsAfter 返回 id 大于 id 的最多 limit 篇草稿
+	DraftsAfter(ctx context.Context, id uint64, limit int) ([]Article, error)
+	// PubsAfter 与 DraftsAfter 相同，但总是带完整正文
+	PubsAfter(ctx context.Context, id uint64, limit int) ([]OnlineArticle, error)
+}
+
+// PublishEvents 根据发布后的文章 id 和第一次发布的时间生成要写入发件箱的事件
+type PublishEvents func(id uint64, ctime int64) []outbox.Event
+
+// OutboxDAO 线上库元数据在 MySQL 中的存储，发布和写发件箱在同一个事务里完成
+// MongoArticleDao 不支持，DoubleWriteDAO 取决于当前为准的一边
+type OutboxDAO interface {
+	// SyncToPublishWithEvents 与 SyncToPublish 相同，events 为 nil 时不写发件箱
+	SyncToPublishWithEvents(ctx context.Context, art Article, events PublishEvents) (uint64, error)
+}
+
+var (
+	_ OutboxDAO  = (*GORMArticleDao)(nil)
+	_ OutboxDAO  = (*OSSArticleDao)(nil)
+	_ OutboxDAO  = (*DoubleWriteDAO)(nil)
+	_ MigrateDAO = (*GORMArticleDao)(nil)
+	_ MigrateDAO = (*MongoArticleDao)(nil)
+	_ MigrateDAO = (*OSSArticleDao)(nil)
+	_ ArticleDAO = (*DoubleWriteDAO)(nil)
+)
@@ -0,0 +1,113 @@
+package dao_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	snowflake "github.com/crazyfrankie/snow-flake"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao/daotest"
+)
+
+// 一致性测试依赖本地的 MySQL 和 MongoDB（副本集），没有配置时跳过
+// ARTICLE_TEST_MYSQL_DSN=root:root@tcp(localhost:3306)/oj_test
+// ARTICLE_TEST_MONGO_URI=mongodb://localhost:27017/?replicaSet=rs0
+
+// 制作库的 content 是 BLOB，最大 64KB
+const mysqlMaxContent = 16 * 1024
+
+func openMySQL(t *testing.T) *gorm.DB {
+	db := tryMySQL(t)
+	if db == nil {
+		t.Skip("ARTICLE_TEST_MYSQL_DSN not set")
+	}
+
+	return db
+}
+
+// tryMySQL 没有配置时返回 nil，MongoDB 的用例只在题解相关的部分用到 MySQL
+func tryMySQL(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("ARTICLE_TEST_MYSQL_DSN")
+	if dsn == "" {
+		return nil
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&dao.Article{}, &dao.OnlineArticle{}, &dao.Solution{}, &dao.Interactive{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func resetMySQL(t *testing.T, db *gorm.DB) {
+	for _, table := range []string{"articles", "online_articles", "solutions", "interactives"} {
+		if err := db.Exec("TRUNCATE TABLE " + table).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGORMArticleDao(t *testing.T) {
+	db := openMySQL(t)
+	daotest.RunArticleDAOSuite(t, func(t *testing.T) dao.ArticleDAO {
+		resetMySQL(t, db)
+		return dao.NewArticleDao(db)
+	}, daotest.Options{MaxContent: mysqlMaxContent, SolutionDB: db})
+}
+
+func TestOSSArticleDao(t *testing.T) {
+	db := openMySQL(t)
+	daotest.RunArticleDAOSuite(t, func(t *testing.T) dao.ArticleDAO {
+		resetMySQL(t, db)
+		return dao.NewOSSArticleDao(daotest.NewFakeS3(t), db, "article")
+	}, daotest.Options{MaxContent: mysqlMaxContent, SolutionDB: db})
+}
+
+func TestMongoArticleDao(t *testing.T) {
+	uri := os.Getenv("ARTICLE_TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("ARTICLE_TEST_MONGO_URI not set")
+	}
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = client.Disconnect(context.Background())
+	})
+	sqlDB := tryMySQL(t)
+	node, err := snowflake.NewNode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	daotest.RunArticleDAOSuite(t, func(t *testing.T) dao.ArticleDAO {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		db := client.Database("oj_article_test")
+		if err := db.Drop(ctx); err != nil {
+			t.Fatal(err)
+		}
+		// 事务中不能隐式建集合，先建好
+		for _, name := range []string{"articles", "published_articles", "article_chunks"} {
+			if err := db.CreateCollection(ctx, name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if sqlDB != nil {
+			resetMySQL(t, sqlDB)
+		}
+		return dao.NewMongoArticleDao(db, node)
+	}, daotest.Options{MaxContent: dao.ChunkSize + 1024, SolutionDB: sqlDB})
+}
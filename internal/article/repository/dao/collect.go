@@ -0,0 +1,171 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNoUserCollect  = errors.New("no user collect this resource")
+	ErrFolderNotFound = errors.New("collect folder not found")
+	ErrFolderExists   = errors.New("collect folder already exists")
+)
+
+// FolderCnt 收藏夹及其中的收藏数
+type FolderCnt struct {
+	CollectFolder
+	Cnt int64
+}
+
+// InsertCollectInfo 已经收藏过时只是换一个收藏夹，收藏数不变
+// 返回是否新增了收藏
+func (dao *InteractiveDao) InsertCollectInfo(ctx context.Context, biz string, bizId, uid, fid uint64) (bool, error) {
+	now := time.Now().UnixMilli()
+	created := false
+
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先插入再判断，并发的第一次收藏只会有一个插入成功，其余的按换收藏夹处理
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&UserCollect{
+			UID:      uid,
+			Biz:      biz,
+			BizID:    bizId,
+			FolderID: fid,
+			Ctime:    now,
+			Utime:    now,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return tx.Model(&UserCollect{}).
+				Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+				Updates(map[string]any{
+					"folder_id": fid,
+					"utime":     now,
+				}).Error
+		}
+		created = true
+
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"collect_cnt": gorm.Expr("collect_cnt + 1"),
+				"utime":       now,
+			}),
+		}).Create(&Interactive{
+			BizID:      bizId,
+			Biz:        biz,
+			CollectCnt: 1,
+			Ctime:      now,
+			Utime:      now,
+		}).Error
+	})
+
+	return created, err
+}
+
+func (dao *InteractiveDao) DeleteCollectInfo(ctx context.Context, biz string, bizId, uid uint64) error {
+	now := time.Now().UnixMilli()
+
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).Delete(&UserCollect{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNoUserCollect
+		}
+
+		return tx.Model(&Interactive{}).
+			Where("biz = ? AND biz_id = ?", biz, bizId).
+			Updates(map[string]any{
+				"utime":       now,
+				"collect_cnt": gorm.Expr("collect_cnt - 1"),
+			}).Error
+	})
+}
+
+func (dao *InteractiveDao) Collected(ctx context.Context, biz string, bizId, uid uint64) (bool, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&UserCollect{}).
+		Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+type CollectDao struct {
+	db *gorm.DB
+}
+
+func NewCollectDao(db *gorm.DB) *CollectDao {
+	return &CollectDao{
+		db: db,
+	}
+}
+
+func (dao *CollectDao) CreateFolder(ctx context.Context, f CollectFolder) (uint64, error) {
+	now := time.Now().UnixMilli()
+	f.Ctime, f.Utime = now, now
+
+	err := dao.db.WithContext(ctx).Create(&f).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return 0, ErrFolderExists
+	}
+	return f.ID, err
+}
+
+func (dao *CollectDao) FindFolder(ctx context.Context, uid, fid uint64) (CollectFolder, error) {
+	var f CollectFolder
+	err := dao.db.WithContext(ctx).Where("id = ? AND uid = ?", fid, uid).First(&f).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return f, ErrFolderNotFound
+	}
+	return f, err
+}
+
+// ListFolders 默认收藏夹不在表里，它的收藏数以 ID 为 0 的一项返回
+func (dao *CollectDao) ListFolders(ctx context.Context, uid uint64) ([]FolderCnt, error) {
+	var folders []CollectFolder
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).Order("id").Find(&folders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var cnts []struct {
+		FolderID uint64
+		Cnt      int64
+	}
+	err = dao.db.WithContext(ctx).Model(&UserCollect{}).
+		Select("folder_id, COUNT(*) AS cnt").
+		Where("uid = ?", uid).
+		Group("folder_id").
+		Scan(&cnts).Error
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[uint64]int64, len(cnts))
+	for _, c := range cnts {
+		m[c.FolderID] = c.Cnt
+	}
+
+	res := make([]FolderCnt, 0, len(folders)+1)
+	res = append(res, FolderCnt{Cnt: m[0]})
+	for _, f := range folders {
+		res = append(res, FolderCnt{CollectFolder: f, Cnt: m[f.ID]})
+	}
+
+	return res, nil
+}
+
+func (dao *CollectDao) ListItems(ctx context.Context, uid, fid uint64, offset, limit int) ([]UserCollect, error) {
+	var items []UserCollect
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND folder_id = ?", uid, fid).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&items).Error
+	return items, err
+}
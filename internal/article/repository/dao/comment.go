@@ -0,0 +1,109 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrCommentNotFound = errors.New("comment not found")
+
+type CommentDao struct {
+	db *gorm.DB
+}
+
+func NewCommentDao(db *gorm.DB) *CommentDao {
+	return &CommentDao{
+		db: db,
+	}
+}
+
+func (dao *CommentDao) Insert(ctx context.Context, c Comment) (uint64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime, c.Utime = now, now
+	err := dao.db.WithContext(ctx).Create(&c).Error
+	return c.ID, err
+}
+
+func (dao *CommentDao) FindByID(ctx context.Context, id uint64) (Comment, error) {
+	var c Comment
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c, ErrCommentNotFound
+	}
+	return c, err
+}
+
+// UpdateContent 只能修改自己未删除的评论
+func (dao *CommentDao) UpdateContent(ctx context.Context, id, uid uint64, content string) error {
+	res := dao.db.WithContext(ctx).Model(&Comment{}).
+		Where("id = ? AND uid = ? AND status = 0", id, uid).
+		Updates(map[string]any{
+			"content": content,
+			"utime":   time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+func (dao *CommentDao) UpdateStatus(ctx context.Context, id uint64, status uint8) error {
+	return dao.db.WithContext(ctx).Model(&Comment{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status": status,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+// FindByBiz 顶级评论按 id 倒序，cursor 为上一页最后一条的 id，0 表示第一页
+func (dao *CommentDao) FindByBiz(ctx context.Context, biz string, bizId, cursor uint64, limit int) ([]Comment, error) {
+	var res []Comment
+	db := dao.db.WithContext(ctx).Where("biz = ? AND biz_id = ? AND root_id = 0", biz, bizId)
+	if cursor > 0 {
+		db = db.Where("id < ?", cursor)
+	}
+	err := db.Order("id DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+// FindReplies 回复按时间正序，cursor 为上一页最后一条的 id
+func (dao *CommentDao) FindReplies(ctx context.Context, rootId, cursor uint64, limit int) ([]Comment, error) {
+	var res []Comment
+	err := dao.db.WithContext(ctx).
+		Where("root_id = ? AND id > ?", rootId, cursor).
+		Order("id ASC").Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *CommentDao) CountReplies(ctx context.Context, rootIds []uint64) (map[uint64]int64, error) {
+	res := make(map[uint64]int64, len(rootIds))
+	if len(rootIds) == 0 {
+		return res, nil
+	}
+
+	var rows []struct {
+		RootID uint64
+		Cnt    int64
+	}
+	err := dao.db.WithContext(ctx).Model(&Comment{}).
+		Select("root_id, COUNT(*) AS cnt").
+		Where("root_id IN ?", rootIds).
+		Group("root_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		res[r.RootID] = r.Cnt
+	}
+
+	return res, nil
+}
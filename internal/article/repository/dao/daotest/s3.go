@@ -0,0 +1,73 @@
+package daotest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// fakeS3 进程内的对象存储替身，只支持按 path style 读写单个对象
+type fakeS3 struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		f.mu.Lock()
+		f.objects[key] = body
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		f.mu.RLock()
+		body, ok := f.objects[key]
+		f.mu.RUnlock()
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+			return
+		}
+		_, _ = w.Write(body)
+	case http.MethodDelete:
+		f.mu.Lock()
+		delete(f.objects, key)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// NewFakeS3 启动一个进程内的 S3 替身，测试结束时自动关闭
+func NewFakeS3(t *testing.T) *s3.S3 {
+	srv := httptest.NewServer(&fakeS3{objects: make(map[string][]byte)})
+	t.Cleanup(srv.Close)
+
+	sess, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials("test", "test", ""),
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(srv.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		DisableSSL:       aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s3.New(sess)
+}
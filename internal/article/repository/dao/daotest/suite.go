@@ -0,0 +1,299 @@
+// Package daotest 文章存储的一致性测试，每种 ArticleDAO 实现都要通过同一套用例
+package daotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+// Factory 每个用例调用一次，返回一个数据已清空的实现
+type Factory func(t *testing.T) dao.ArticleDAO
+
+type Options struct {
+	// MaxContent 后端能保存的最大正文长度（字符数），超过 dao.ChunkSize 时会覆盖分片的情况
+	MaxContent int
+	// SolutionDB 题解表所在的 MySQL，每个用例前由调用方清空，为 nil 时跳过题解相关的用例
+	SolutionDB *gorm.DB
+}
+
+func RunArticleDAOSuite(t *testing.T, newDAO Factory, opts Options) {
+	published := domain.ArticleStatusPublished.ToUint8()
+	private := domain.ArticleStatusPrivate.ToUint8()
+
+	t.Run("CreateAndGetDraft", func(t *testing.T) {
+		d, ctx := newDAO(t), context.Background()
+		id, err := d.CreateDraft(ctx, dao.Article{Title: "title", Content: "content", AuthorID: 1})
+		require.NoError(t, err)
+		require.NotZero(t, id)
+
+		art, err := d.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "title", art.Title)
+		assert.Equal(t, "content", art.Content)
+		assert.Equal(t, uint64(1), art.AuthorID)
+		assert.NotZero(t, art.Ctime)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		d, ctx := newDAO(t), context.Background()
+		_, err := d.GetByID(ctx, 404)
+		assert.ErrorIs(t, err, dao.ErrRecordNotFound)
+		_, err = d.GetPubByID(ctx, 404)
+		assert.ErrorIs(t, err, dao.ErrRecordNotFound)
+	})
+
+	t.Run("UpdateDraft", func(t *testing.T) {
+		d, ctx := newDAO(t), context.Background()
+		id, err := d.CreateDraft(ctx, dao.Article{Title: "title", Content: "content", AuthorID: 1})
+		require.NoError(t, err)
+
+		// 空字段不覆盖
+		err = d.UpdateDraftByID(ctx, dao.Article{ID: id, Title: "new title", AuthorID: 1})
+		require.NoError(t, err)
+		art, err := d.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "new title", art.Title)
+		assert.Equal(t, "content", art.Content)
+
+		// 不能改别人的文章
+		err = d.UpdateDraftByID(ctx, dao.Article{ID: id, Title: "hacked", AuthorID: 2})
+		assert.Error(t, err)
+		art, err = d.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "new title", art.Title)
+	})
+
+	t.Run("RestoreDraft", func(t *testing.T) {
+		d, ctx := newDAO(t), context.Background()
+		id, err := d.CreateDraft(ctx, dao.Article{Title: "title", Content: "content", AuthorID: 1})
+		require.NoError(t, err)
+
+		// 空字段也要覆盖
+		err = d.RestoreDraftByID(ctx, dao.Article{ID: id, Title: "old title", AuthorID: 1, Status: 1})
+		require.NoError(t, err)
+		art, err := d.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "old title", art.Title)
+		assert.Equal(t, "", art.Content)
+
+		err = d.RestoreDraftByID(ctx, dao.Article{ID: id, Title: "hacked", AuthorID: 2, Status: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("PublishNew", func(t *testing.T) {
+		d, ctx := newDAO(t), context.Background()
+		id, err := d.SyncToPublish(ctx, dao.Article{Title: "title", Content: "content", AuthorID: 1, Status: published})
+		require.NoError(t, err)
+		require.NotZero(t, id)
+
+		draft, err := d.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "content", draft.Content)
+
+		pub, err := d.GetPubByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, pub.ID)
+		assert.Equal(t, "title", pub.Title)
+		assert.Equal(t, "content", pub.Content)
+		assert.Equal(t, uint64(1), pub.AuthorID)
+		assert.Equal(t, published, pub.Status)
+	})
+
+	t.Run("PublishExisting", func(t *testing.T) {
+		d, ctx := newDAO(t), context.Background()
+		id, err := d.CreateDraft(ctx, dao.Article{Title: "draft", Content: "draft content", AuthorID: 1})
+		require.NoError(t, err)
+
+		got, err := d.SyncToPublish(ctx, dao.Article{ID: id, Title: "title", Content: "content", AuthorID: 1, Status: published})
+		require.NoError(t, err)
+		assert.Equal(t, id, got)
+
+		_, err = d.SyncToPublish(ctx, dao.Article{ID: id, Title: "title v2", Content: "content v2", AuthorID: 1, Status: published})
+		require.NoError(t, err)
+
+		pub, err := d.GetPubByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "title v2", pub.Title)
+		assert.Equal(t, "content v2", pub.Content)
+
+		// 别人不能借发布改文章
+		_, err = d.SyncToPublish(ctx, dao.Article{ID: id, Title: "hacked", Content: "hacked", AuthorID: 2, Status: published})
+		assert.Error(t, err)
+		pub, err = d.GetPubByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "title v2", pub.Title)
+	})
+
+	t.Run("LargeContent", func(t *testing.T) {
+		d, ctx := newDAO(t), context.Background()
+		// 多字节字符，确认不会在字符中间截断
+		content := strings.Repeat("题", opts.MaxContent/2) + strings.Repeat("a", opts.MaxContent-opts.MaxContent/2)
+		id, err := d.SyncToPublish(ctx, dao.Article{Title: "large", Content: content, AuthorID: 1, Status: published})
+		require.NoError(t, err)
+
+		pub, err := d.GetPubByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, len(content), len(pub.Content))
+		assert.True(t, content == pub.Content)
+
+		// 变短之后不能残留旧的分片
+		_, err = d.SyncToPublish(ctx, dao.Article{ID: id, Title: "large", Content: "short", AuthorID: 1, Status: published})
+		require.NoError(t, err)
+		pub, err = d.GetPubByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "short", pub.Content)
+	})
+
+	t.Run("SyncStatus", func(t *testing.T) {
+		d, ctx := newDAO(t), context.Background()
+		id, err := d.SyncToPublish(ctx, dao.Article{Title: "title", Content: "content", AuthorID: 1, Status: published})
+		require.NoError(t, err)
+
+		assert.Error(t, d.SyncStatus(ctx, id, 2, private))
+
+		require.NoError(t, d.SyncStatus(ctx, id, 1, private))
+		draft, err := d.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, private, draft.Status)
+		pub, err := d.GetPubByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, private, pub.Status)
+	})
+
+	t.Run("Lists", func(t *testing.T) {
+		d, ctx := newDAO(t), context.Background()
+		var ids []uint64
+		for _, title := range []string{"a", "b", "c"} {
+			id, err := d.SyncToPublish(ctx, dao.Article{Title: title, Content: title, AuthorID: 1, Status: published})
+			require.NoError(t, err)
+			ids = append(ids, id)
+			// utime 是毫秒，隔开一点保证顺序确定
+			time.Sleep(time.Millisecond * 5)
+		}
+		_, err := d.CreateDraft(ctx, dao.Article{Title: "draft", Content: "draft", AuthorID: 1})
+		require.NoError(t, err)
+		require.NoError(t, d.SyncStatus(ctx, ids[0], 1, private))
+
+		drafts, err := d.GetListByID(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, drafts, 4)
+
+		// 线上库按更新时间倒序，ids[0] 刚改过状态排在最前
+		pubs, err := d.GetPubListByID(ctx, 0, 2)
+		require.NoError(t, err)
+		require.Len(t, pubs, 2)
+		assert.Equal(t, ids[0], pubs[0].ID)
+		assert.Equal(t, ids[2], pubs[1].ID)
+
+		// 只返回已发布的
+		since, err := d.ListPubSince(ctx, 0, 0, 10)
+		require.NoError(t, err)
+		var got []uint64
+		for _, art := range since {
+			got = append(got, art.ID)
+		}
+		assert.ElementsMatch(t, ids[1:], got)
+
+		since, err = d.ListPubSince(ctx, time.Now().Add(time.Hour).UnixMilli(), 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, since)
+
+		// 不存在的 id 直接忽略
+		byIDs, err := d.GetPubByIDs(ctx, []uint64{ids[0], ids[2], ids[2] + 1000})
+		require.NoError(t, err)
+		got = got[:0]
+		for _, art := range byIDs {
+			got = append(got, art.ID)
+		}
+		assert.ElementsMatch(t, []uint64{ids[0], ids[2]}, got)
+	})
+
+	t.Run("Solutions", func(t *testing.T) {
+		if opts.SolutionDB == nil {
+			t.Skip("no solution db")
+		}
+		d, ctx := newDAO(t), context.Background()
+		sd := dao.NewSolutionDao(opts.SolutionDB, d)
+
+		var ids []uint64
+		for _, title := range []string{"a", "b", "c"} {
+			id, err := d.SyncToPublish(ctx, dao.Article{Title: title, Content: title + " content", AuthorID: 1, Status: published})
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+		require.NoError(t, d.SyncStatus(ctx, ids[1], 1, private))
+		// 只有草稿、从没发布过的文章
+		draft, err := d.CreateDraft(ctx, dao.Article{Title: "draft", Content: "draft", AuthorID: 1})
+		require.NoError(t, err)
+		for _, aid := range append(ids, draft) {
+			require.NoError(t, sd.Upsert(ctx, dao.Solution{ArticleID: aid, ProblemID: 7, AuthorID: 1, Languages: "go"}))
+		}
+
+		rows, err := sd.List(ctx, domain.SolutionQuery{ProblemID: 7, Sort: domain.SolutionSortNew, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, ids[2], rows[0].ArticleID)
+		assert.Equal(t, "c", rows[0].Title)
+		assert.Equal(t, uint64(1), rows[0].AuthorID)
+		assert.True(t, strings.HasPrefix(rows[0].Content, "c"))
+		assert.Equal(t, ids[0], rows[1].ArticleID)
+
+		rows, err = sd.List(ctx, domain.SolutionQuery{ProblemID: 8, Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+
+	t.Run("Migrate", func(t *testing.T) {
+		d, ok := newDAO(t).(dao.MigrateDAO)
+		if !ok {
+			t.Skip("not a MigrateDAO")
+		}
+		ctx := context.Background()
+
+		// 原样保留 id 和时间
+		draft := dao.Article{ID: 1000, Title: "draft", Content: "draft", AuthorID: 1, Status: private, Ctime: 1, Utime: 2}
+		pub := dao.OnlineArticle{ID: 1000, Title: "pub", Content: "pub", AuthorID: 1, Status: published, Ctime: 3, Utime: 4}
+		require.NoError(t, d.UpsertDraft(ctx, draft))
+		require.NoError(t, d.UpsertPub(ctx, pub))
+		require.NoError(t, d.UpsertDraft(ctx, dao.Article{ID: 1001, Title: "other", AuthorID: 1, Ctime: 5, Utime: 5}))
+
+		got, err := d.GetByID(ctx, draft.ID)
+		require.NoError(t, err)
+		assert.Equal(t, draft, got)
+		gotPub, err := d.GetPubByID(ctx, pub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, pub, gotPub)
+
+		// 再写一次是覆盖
+		draft.Title = "draft v2"
+		require.NoError(t, d.UpsertDraft(ctx, draft))
+
+		drafts, err := d.DraftsAfter(ctx, 0, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []dao.Article{draft}, drafts)
+		drafts, err = d.DraftsAfter(ctx, draft.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, drafts, 1)
+		assert.Equal(t, uint64(1001), drafts[0].ID)
+
+		pubs, err := d.PubsAfter(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []dao.OnlineArticle{pub}, pubs)
+
+		require.NoError(t, d.DeleteDraft(ctx, draft.ID))
+		require.NoError(t, d.DeletePub(ctx, pub.ID))
+		_, err = d.GetByID(ctx, draft.ID)
+		assert.ErrorIs(t, err, dao.ErrRecordNotFound)
+		_, err = d.GetPubByID(ctx, pub.ID)
+		assert.ErrorIs(t, err, dao.ErrRecordNotFound)
+	})
+}
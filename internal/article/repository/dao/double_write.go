@@ -0,0 +1,209 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+)
+
+const (
+	// PatternSrcOnly 只读写源库，迁移开始前的状态
+	PatternSrcOnly = "src_only"
+	// PatternSrcFirst 以源库为准，写成功后同步到目标库
+	PatternSrcFirst = "src_first"
+	// PatternDstFirst 以目标库为准，写成功后同步回源库，出问题时还能切回去
+	PatternDstFirst = "dst_first"
+	// PatternDstOnly 只读写目标库，迁移完成
+	PatternDstOnly = "dst_only"
+)
+
+var ErrUnknownPattern = errors.New("unknown double write pattern")
+
+func ValidPattern(pattern string) bool {
+	switch pattern {
+	case PatternSrcOnly, PatternSrcFirst, PatternDstFirst, PatternDstOnly:
+		return true
+	}
+	return false
+}
+
+// DoubleWriteDAO 迁移期间包在两个存储外面
+// 读只走当前为准的一边；写先写为准的一边，成功后把整行读出来原样写到另一边，
+// 这样两边的 id 和时间一致。另一边失败只记录日志，由校验任务修复
+type DoubleWriteDAO struct {
+	src     MigrateDAO
+	dst     MigrateDAO
+	pattern atomic.Value
+}
+
+func NewDoubleWriteDAO(src, dst MigrateDAO, pattern string) *DoubleWriteDAO {
+	d := &DoubleWriteDAO{
+		src: src,
+		dst: dst,
+	}
+	if !ValidPattern(pattern) {
+		pattern = PatternSrcOnly
+	}
+	d.pattern.Store(pattern)
+
+	return d
+}
+
+func (d *DoubleWriteDAO) Src() MigrateDAO {
+	return d.src
+}
+
+func (d *DoubleWriteDAO) Dst() MigrateDAO {
+	return d.dst
+}
+
+func (d *DoubleWriteDAO) Pattern() string {
+	return d.pattern.Load().(string)
+}
+
+func (d *DoubleWriteDAO) UpdatePattern(pattern string) error {
+	if !ValidPattern(pattern) {
+		return ErrUnknownPattern
+	}
+	d.pattern.Store(pattern)
+
+	return nil
+}
+
+// sides 返回当前为准的一边，以及需要同步的另一边（不需要双写时为 nil）
+func (d *DoubleWriteDAO) sides() (MigrateDAO, MigrateDAO) {
+	switch d.Pattern() {
+	case PatternSrcFirst:
+		return d.src, d.dst
+	case PatternDstFirst:
+		return d.dst, d.src
+	case PatternDstOnly:
+		return d.dst, nil
+	default:
+		return d.src, nil
+	}
+}
+
+func (d *DoubleWriteDAO) CreateDraft(ctx context.Context, art Article) (uint64, error) {
+	primary, secondary := d.sides()
+	id, err := primary.CreateDraft(ctx, art)
+	if err == nil && secondary != nil {
+		d.copyDraft(ctx, primary, secondary, id)
+	}
+
+	return id, err
+}
+
+func (d *DoubleWriteDAO) UpdateDraftByID(ctx context.Context, art Article) error {
+	primary, secondary := d.sides()
+	err := primary.UpdateDraftByID(ctx, art)
+	if err == nil && secondary != nil {
+		d.copyDraft(ctx, primary, secondary, art.ID)
+	}
+
+	return err
+}
+
+func (d *DoubleWriteDAO) RestoreDraftByID(ctx context.Context, art Article) error {
+	primary, secondary := d.sides()
+	err := primary.RestoreDraftByID(ctx, art)
+	if err == nil && secondary != nil {
+		d.copyDraft(ctx, primary, secondary, art.ID)
+	}
+
+	return err
+}
+
+func (d *DoubleWriteDAO) SyncToPublish(ctx context.Context, art Article) (uint64, error) {
+	primary, secondary := d.sides()
+	id, err := primary.SyncToPublish(ctx, art)
+	if err == nil && secondary != nil {
+		d.copyDraft(ctx, primary, secondary, id)
+		d.copyPub(ctx, primary, secondary, id)
+	}
+
+	return id, err
+}
+
+// SyncToPublishWithEvents 为准的一边不支持发件箱时返回 ErrNoOutbox
+func (d *DoubleWriteDAO) SyncToPublishWithEvents(ctx context.Context, art Article, events PublishEvents) (uint64, error) {
+	primary, secondary := d.sides()
+	p, ok := primary.(OutboxDAO)
+	if !ok {
+		return 0, ErrNoOutbox
+	}
+
+	id, err := p.SyncToPublishWithEvents(ctx, art, events)
+	if err == nil && secondary != nil {
+		d.copyDraft(ctx, primary, secondary, id)
+		d.copyPub(ctx, primary, secondary, id)
+	}
+
+	return id, err
+}
+
+func (d *DoubleWriteDAO) SyncStatus(ctx context.Context, id uint64, authorId uint64, status uint8) error {
+	primary, secondary := d.sides()
+	err := primary.SyncStatus(ctx, id, authorId, status)
+	if err == nil && secondary != nil {
+		d.copyDraft(ctx, primary, secondary, id)
+		d.copyPub(ctx, primary, secondary, id)
+	}
+
+	return err
+}
+
+func (d *DoubleWriteDAO) copyDraft(ctx context.Context, from, to MigrateDAO, id uint64) {
+	art, err := from.GetByID(ctx, id)
+	if err == nil {
+		err = to.UpsertDraft(ctx, art)
+	}
+	if err != nil {
+		log.Printf("double write draft %d failed: %v", id, err)
+	}
+}
+
+func (d *DoubleWriteDAO) copyPub(ctx context.Context, from, to MigrateDAO, id uint64) {
+	art, err := from.GetPubByID(ctx, id)
+	if errors.Is(err, ErrRecordNotFound) {
+		// 还没发布过
+		return
+	}
+	if err == nil {
+		err = to.UpsertPub(ctx, art)
+	}
+	if err != nil {
+		log.Printf("double write online article %d failed: %v", id, err)
+	}
+}
+
+func (d *DoubleWriteDAO) GetListByID(ctx context.Context, offset, limit int) ([]Article, error) {
+	primary, _ := d.sides()
+	return primary.GetListByID(ctx, offset, limit)
+}
+
+func (d *DoubleWriteDAO) GetByID(ctx context.Context, id uint64) (Article, error) {
+	primary, _ := d.sides()
+	return primary.GetByID(ctx, id)
+}
+
+func (d *DoubleWriteDAO) GetPubListByID(ctx context.Context, offset, limit int) ([]OnlineArticle, error) {
+	primary, _ := d.sides()
+	return primary.GetPubListByID(ctx, offset, limit)
+}
+
+func (d *DoubleWriteDAO) ListPubSince(ctx context.Context, start int64, offset, limit int) ([]OnlineArticle, error) {
+	primary, _ := d.sides()
+	return primary.ListPubSince(ctx, start, offset, limit)
+}
+
+func (d *DoubleWriteDAO) GetPubByID(ctx context.Context, aid uint64) (OnlineArticle, error) {
+	primary, _ := d.sides()
+	return primary.GetPubByID(ctx, aid)
+}
+
+func (d *DoubleWriteDAO) GetPubByIDs(ctx context.Context, ids []uint64) ([]OnlineArticle, error) {
+	primary, _ := d.sides()
+	return primary.GetPubByIDs(ctx, ids)
+}
@@ -0,0 +1,155 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNotFollowed = errors.New("not followed")
+
+type FollowDao struct {
+	db *gorm.DB
+}
+
+func NewFollowDao(db *gorm.DB) *FollowDao {
+	return &FollowDao{
+		db: db,
+	}
+}
+
+// Follow 已经关注过时什么都不做，返回是否新增了关注
+func (dao *FollowDao) Follow(ctx context.Context, follower, followee uint64) (bool, error) {
+	now := time.Now().UnixMilli()
+	created := false
+
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&UserFollow{
+			Follower: follower,
+			Followee: followee,
+			Ctime:    now,
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		created = true
+
+		return dao.incrStats(tx, follower, followee, 1, now)
+	})
+
+	return created, err
+}
+
+func (dao *FollowDao) Unfollow(ctx context.Context, follower, followee uint64) error {
+	now := time.Now().UnixMilli()
+
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("follower = ? AND followee = ?", follower, followee).Delete(&UserFollow{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFollowed
+		}
+
+		return dao.incrStats(tx, follower, followee, -1, now)
+	})
+}
+
+// incrStats follower 的关注数和 followee 的粉丝数一起加减
+func (dao *FollowDao) incrStats(tx *gorm.DB, follower, followee uint64, delta int64, now int64) error {
+	err := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"followees": gorm.Expr("followees + ?", delta),
+			"utime":     now,
+		}),
+	}).Create(&FollowStats{
+		UID:       follower,
+		Followees: max(delta, 0),
+		Ctime:     now,
+		Utime:     now,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"followers": gorm.Expr("followers + ?", delta),
+			"utime":     now,
+		}),
+	}).Create(&FollowStats{
+		UID:       followee,
+		Followers: max(delta, 0),
+		Ctime:     now,
+		Utime:     now,
+	}).Error
+}
+
+// Stats 没有记录时返回零值
+func (dao *FollowDao) Stats(ctx context.Context, uid uint64) (FollowStats, error) {
+	var stats FollowStats
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).First(&stats).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return FollowStats{UID: uid}, nil
+	}
+
+	return stats, err
+}
+
+func (dao *FollowDao) IsFollowing(ctx context.Context, follower, followee uint64) (bool, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&UserFollow{}).
+		Where("follower = ? AND followee = ?", follower, followee).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+// Followees 最近关注的在前
+func (dao *FollowDao) Followees(ctx context.Context, uid uint64, offset, limit int) ([]UserFollow, error) {
+	var res []UserFollow
+	err := dao.db.WithContext(ctx).
+		Where("follower = ?", uid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+// Followers 最近关注的在前
+func (dao *FollowDao) Followers(ctx context.Context, uid uint64, offset, limit int) ([]UserFollow, error) {
+	var res []UserFollow
+	err := dao.db.WithContext(ctx).
+		Where("followee = ?", uid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+// FollowersAfter 按 id 升序遍历粉丝，推送动态时使用
+func (dao *FollowDao) FollowersAfter(ctx context.Context, uid uint64, id uint64, limit int) ([]UserFollow, error) {
+	var res []UserFollow
+	err := dao.db.WithContext(ctx).
+		Where("followee = ? AND id > ?", uid, id).
+		Order("id").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+// BigFollowees 关注的人里粉丝数超过 threshold 的
+func (dao *FollowDao) BigFollowees(ctx context.Context, uid uint64, threshold int64, limit int) ([]uint64, error) {
+	var ids []uint64
+	err := dao.db.WithContext(ctx).
+		Table("user_follow uf").
+		Joins("JOIN follow_stats fs ON fs.uid = uf.followee").
+		Where("uf.follower = ? AND fs.followers > ?", uid, threshold).
+		Order("uf.id DESC").
+		Limit(limit).
+		Pluck("uf.followee", &ids).Error
+	return ids, err
+}
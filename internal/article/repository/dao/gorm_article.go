@@ -8,6 +8,9 @@ import (
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 )
 
 var (
@@ -45,6 +48,9 @@ func (dao *GORMArticleDao) UpdateDraftByID(ctx context.Context, art Article) err
 	if art.Content != "" {
 		updates["content"] = art.Content
 	}
+	if art.Status != 0 {
+		updates["status"] = art.Status
+	}
 	updates["utime"] = art.Utime
 
 	result := dao.db.WithContext(ctx).Model(&Article{}).
@@ -57,7 +63,30 @@ func (dao *GORMArticleDao) UpdateDraftByID(ctx context.Context, art Article) err
 	return result.Error
 }
 
+func (dao *GORMArticleDao) RestoreDraftByID(ctx context.Context, art Article) error {
+	result := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ?", art.ID, art.AuthorID).
+		Updates(map[string]any{
+			"title":   art.Title,
+			"content": art.Content,
+			"status":  art.Status,
+			"utime":   time.Now().UnixMilli(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("恢复失败，可能是创作者非法 id :%d, author_id: %d", art.ID, art.AuthorID)
+	}
+
+	return nil
+}
+
 func (dao *GORMArticleDao) SyncToPublish(ctx context.Context, art Article) (uint64, error) {
+	return dao.SyncToPublishWithEvents(ctx, art, nil)
+}
+
+func (dao *GORMArticleDao) SyncToPublishWithEvents(ctx context.Context, art Article, events PublishEvents) (uint64, error) {
 	var (
 		id = art.ID
 	)
@@ -76,16 +105,37 @@ func (dao *GORMArticleDao) SyncToPublish(ctx context.Context, art Article) (uint
 			return err
 		}
 
-		return txDao.Upsert(ctx, OnlineArticle{
-			ID:       art.ID,
+		err = txDao.Upsert(ctx, OnlineArticle{
+			ID:       id,
 			Title:    art.Title,
 			Content:  art.Content,
 			AuthorID: art.AuthorID,
+			Status:   art.Status,
 		})
+		if err != nil {
+			return err
+		}
+
+		return appendEvents(ctx, tx, id, events)
 	})
 	return id, err
 }
 
+// appendEvents 在发布的事务里写入发件箱，ctime 以线上库里保存的为准
+func appendEvents(ctx context.Context, tx *gorm.DB, id uint64, events PublishEvents) error {
+	if events == nil {
+		return nil
+	}
+
+	var pub OnlineArticle
+	err := tx.WithContext(ctx).Select("ctime").Where("id = ?", id).First(&pub).Error
+	if err != nil {
+		return err
+	}
+
+	return outbox.Append(tx.WithContext(ctx), events(id, pub.Ctime)...)
+}
+
 func (dao *GORMArticleDao) Upsert(ctx context.Context, art OnlineArticle) error {
 	// 实现 INSERT OR UPDATE
 	// SQL:
@@ -159,6 +209,19 @@ func (dao *GORMArticleDao) GetPubListByID(ctx context.Context, offset int, limit
 	return arts, err
 }
 
+// ListPubSince 按 id 分批遍历 start 之后第一次发布的文章，之后修改或者重新发布都不算
+func (dao *GORMArticleDao) ListPubSince(ctx context.Context, start int64, offset int, limit int) ([]OnlineArticle, error) {
+	var arts []OnlineArticle
+	err := dao.db.WithContext(ctx).Model(&OnlineArticle{}).
+		Where("ctime > ? AND status = ?", start, domain.ArticleStatusPublished.ToUint8()).
+		Order("id").
+		Offset(offset).
+		Limit(limit).
+		Find(&arts).Error
+
+	return arts, err
+}
+
 func (dao *GORMArticleDao) GetByID(ctx context.Context, id uint64) (Article, error) {
 	var art Article
 
@@ -188,3 +251,56 @@ func (dao *GORMArticleDao) GetPubByID(ctx context.Context, aid uint64) (OnlineAr
 
 	return art, nil
 }
+
+func (dao *GORMArticleDao) GetPubByIDs(ctx context.Context, ids []uint64) ([]OnlineArticle, error) {
+	var arts []OnlineArticle
+	err := dao.db.WithContext(ctx).Model(&OnlineArticle{}).Where("id IN ?", ids).Find(&arts).Error
+
+	return arts, err
+}
+
+// UpsertDraft 迁移用，按原 id 原样写入
+func (dao *GORMArticleDao) UpsertDraft(ctx context.Context, art Article) error {
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		UpdateAll: true,
+	}).Create(&art).Error
+}
+
+// UpsertPub 迁移用，与 Upsert 不同，保留原来的时间
+func (dao *GORMArticleDao) UpsertPub(ctx context.Context, art OnlineArticle) error {
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		UpdateAll: true,
+	}).Create(&art).Error
+}
+
+func (dao *GORMArticleDao) DeleteDraft(ctx context.Context, id uint64) error {
+	return dao.db.WithContext(ctx).Where("id = ?", id).Delete(&Article{}).Error
+}
+
+func (dao *GORMArticleDao) DeletePub(ctx context.Context, id uint64) error {
+	return dao.db.WithContext(ctx).Where("id = ?", id).Delete(&OnlineArticle{}).Error
+}
+
+func (dao *GORMArticleDao) DraftsAfter(ctx context.Context, id uint64, limit int) ([]Article, error) {
+	var arts []Article
+	err := dao.db.WithContext(ctx).
+		Where("id > ?", id).
+		Order("id").
+		Limit(limit).
+		Find(&arts).Error
+
+	return arts, err
+}
+
+func (dao *GORMArticleDao) PubsAfter(ctx context.Context, id uint64, limit int) ([]OnlineArticle, error) {
+	var arts []OnlineArticle
+	err := dao.db.WithContext(ctx).
+		Where("id > ?", id).
+		Order("id").
+		Limit(limit).
+		Find(&arts).Error
+
+	return arts, err
+}
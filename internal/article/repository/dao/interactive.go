@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -25,115 +26,192 @@ func NewInteractiveDao(db *gorm.DB) *InteractiveDao {
 	}
 }
 
-func (dao *InteractiveDao) IncrReadCnt(ctx context.Context, biz string, bizId uint64) error {
+// BatchIncrCnt 一条 upsert 语句把多个资源的阅读数和点赞数增量落库，deltas 是资源 id 到增量的映射
+// 按 id 排序写入，避免并发的批次互相死锁
+func (dao *InteractiveDao) BatchIncrCnt(ctx context.Context, biz string, deltas map[uint64]domain.Interactive) error {
+	if len(deltas) == 0 {
+		return nil
+	}
 	now := time.Now().UnixMilli()
 
+	ids := make([]uint64, 0, len(deltas))
+	for id := range deltas {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	inters := make([]Interactive, 0, len(ids))
+	for _, id := range ids {
+		inters = append(inters, Interactive{
+			BizID:   id,
+			Biz:     biz,
+			ReadCnt: deltas[id].ReadCnt,
+			LikeCnt: deltas[id].LikeCnt,
+			Ctime:   now,
+			Utime:   now,
+		})
+	}
+
 	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
 		DoUpdates: clause.Assignments(map[string]interface{}{
-			"read_cnt": gorm.Expr("read_cnt + 1"),
+			"read_cnt": gorm.Expr("read_cnt + VALUES(read_cnt)"),
+			"like_cnt": gorm.Expr("like_cnt + VALUES(like_cnt)"),
 			"utime":    now,
 		}),
-	}).Create(&Interactive{
-		BizID:   bizId,
-		Biz:     biz,
-		ReadCnt: 1,
-		Ctime:   now,
-		Utime:   now,
-	}).Error
+	}).Create(&inters).Error
 }
 
-func (dao *InteractiveDao) InsertLikeInfo(ctx context.Context, biz string, bizId, uid uint64) error {
+// InsertLikeInfo 只记录点赞关系，点赞数由调用方通过缓存累加后定时落库
+// 返回点赞状态是否发生了变化，重复点赞返回 false
+func (dao *InteractiveDao) InsertLikeInfo(ctx context.Context, biz string, bizId, uid uint64) (bool, error) {
 	now := time.Now().UnixMilli()
 
-	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := tx.WithContext(ctx).Clauses(clause.OnConflict{
-			DoUpdates: clause.Assignments(
-				map[string]any{
-					"utime":  now,
-					"status": 1,
-				}),
-		}).Create(&UserLike{
-			Biz:    biz,
-			BizID:  bizId,
-			UID:    uid,
-			Ctime:  now,
-			Utime:  now,
-			Status: 1,
-		}).Error
-
-		if err != nil {
-			return err
-		}
-
-		return tx.WithContext(ctx).Clauses(clause.OnConflict{
-			DoUpdates: clause.Assignments(map[string]interface{}{
-				"like_cnt": gorm.Expr("like_cnt + 1"),
-				"utime":    now,
-			}),
-		}).Create(&Interactive{
-			BizID:   bizId,
-			Biz:     biz,
-			LikeCnt: 1,
-			Ctime:   now,
-			Utime:   now,
-		}).Error
+	res := dao.db.WithContext(ctx).Model(&UserLike{}).
+		Where("uid = ? AND biz = ? AND biz_id = ? AND status = ?", uid, biz, bizId, 0).
+		Updates(map[string]any{
+			"utime":  now,
+			"status": 1,
+		})
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.RowsAffected > 0, res.Error
+	}
+
+	res = dao.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&UserLike{
+		Biz:    biz,
+		BizID:  bizId,
+		UID:    uid,
+		Ctime:  now,
+		Utime:  now,
+		Status: 1,
 	})
-}
 
-func (dao *InteractiveDao) DeleteLikeInfo(ctx context.Context, biz string, bizId uint64, uid uint64) error {
-	now := time.Now().UnixMilli()
+	return res.RowsAffected > 0, res.Error
+}
 
-	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := tx.WithContext(ctx).Model(&UserLike{}).Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).Updates(map[string]any{
-			"utime":  now,
+// DeleteLikeInfo 软删除点赞记录，返回点赞状态是否发生了变化
+func (dao *InteractiveDao) DeleteLikeInfo(ctx context.Context, biz string, bizId uint64, uid uint64) (bool, error) {
+	res := dao.db.WithContext(ctx).Model(&UserLike{}).
+		Where("uid = ? AND biz = ? AND biz_id = ? AND status = ?", uid, biz, bizId, 1).
+		Updates(map[string]any{
+			"utime":  time.Now().UnixMilli(),
 			"status": 0,
-		}).Error
-		if err != nil {
-			return err
-		}
-
-		return tx.WithContext(ctx).
-			Model(&Interactive{}).
-			Where("biz = ? AND biz_id = ?", biz, bizId).
-			Updates(map[string]any{
-				"utime":    now,
-				"like_cnt": gorm.Expr("like_cnt - 1"),
-			}).Error
-	})
-}
+		})
 
-func (dao *InteractiveDao) GetInteractiveByID(ctx context.Context, biz string, bizId, uid uint64) (domain.Interactive, error) {
-	var inter Interactive
-	var userLike UserLike
+	return res.RowsAffected > 0, res.Error
+}
 
-	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := tx.WithContext(ctx).Model(&Interactive{}).Where("biz = ? AND biz_id = ?", biz, bizId).First(&inter).Error
-		if err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				return err
-			}
+func (dao *InteractiveDao) Liked(ctx context.Context, biz string, bizId, uid uint64) (bool, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&UserLike{}).
+		Where("uid = ? AND biz = ? AND biz_id = ? AND status = ?", uid, biz, bizId, 1).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
 
-			inter.ReadCnt = 0
-		}
+// LikedIDs 返回 ids 中 uid 点过赞的资源
+func (dao *InteractiveDao) LikedIDs(ctx context.Context, biz string, ids []uint64, uid uint64) (map[uint64]bool, error) {
+	var liked []uint64
+	err := dao.db.WithContext(ctx).Model(&UserLike{}).
+		Where("uid = ? AND biz = ? AND biz_id IN ? AND status = ?", uid, biz, ids, 1).
+		Pluck("biz_id", &liked).Error
+	if err != nil {
+		return nil, err
+	}
 
-		err = tx.WithContext(ctx).Model(&UserLike{}).Where("biz = ? AND biz_id = ? AND uid = ?", biz, bizId, uid).First(&userLike).Error
-		if err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				return err
-			}
+	res := make(map[uint64]bool, len(liked))
+	for _, id := range liked {
+		res[id] = true
+	}
 
-			inter.LikeCnt = 0
-		}
+	return res, nil
+}
 
-		return nil
-	})
-	if err != nil {
+// FindInteractive 没有记录时返回全 0 的计数
+func (dao *InteractiveDao) FindInteractive(ctx context.Context, biz string, bizId uint64) (domain.Interactive, error) {
+	var inter Interactive
+	err := dao.db.WithContext(ctx).Where("biz = ? AND biz_id = ?", biz, bizId).First(&inter).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return domain.Interactive{}, err
 	}
 
 	return domain.Interactive{
-		Liked:   userLike.Status == 1,
-		LikeCnt: inter.LikeCnt,
-		ReadCnt: inter.ReadCnt,
+		LikeCnt:    inter.LikeCnt,
+		ReadCnt:    inter.ReadCnt,
+		CollectCnt: inter.CollectCnt,
 	}, nil
 }
+
+// ListInteractives 按主键顺序分批遍历计数表，供对账使用
+func (dao *InteractiveDao) ListInteractives(ctx context.Context, afterID uint64, limit int) ([]Interactive, error) {
+	var res []Interactive
+	err := dao.db.WithContext(ctx).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+type bizCnt struct {
+	BizID uint64
+	Cnt   int64
+}
+
+// CountLikes 按点赞记录重新统计点赞数，没有点赞的资源不会出现在结果里
+func (dao *InteractiveDao) CountLikes(ctx context.Context, biz string, ids []uint64) (map[uint64]int64, error) {
+	var cnts []bizCnt
+	err := dao.db.WithContext(ctx).Model(&UserLike{}).
+		Select("biz_id, COUNT(*) AS cnt").
+		Where("biz = ? AND biz_id IN ? AND status = ?", biz, ids, 1).
+		Group("biz_id").
+		Scan(&cnts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return toCntMap(cnts), nil
+}
+
+// CountCollects 按收藏记录重新统计收藏数
+func (dao *InteractiveDao) CountCollects(ctx context.Context, biz string, ids []uint64) (map[uint64]int64, error) {
+	var cnts []bizCnt
+	err := dao.db.WithContext(ctx).Model(&UserCollect{}).
+		Select("biz_id, COUNT(*) AS cnt").
+		Where("biz = ? AND biz_id IN ?", biz, ids).
+		Group("biz_id").
+		Scan(&cnts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return toCntMap(cnts), nil
+}
+
+// ResetCnt 用重新统计的结果覆盖点赞数和收藏数
+// 只有计数还和 row 一样时才覆盖，统计期间有新的点赞落库或者收藏时返回 false，留给下一轮
+func (dao *InteractiveDao) ResetCnt(ctx context.Context, row Interactive, likeCnt, collectCnt int64) (bool, error) {
+	res := dao.db.WithContext(ctx).Model(&Interactive{}).
+		Where("id = ? AND like_cnt = ? AND collect_cnt = ?", row.ID, row.LikeCnt, row.CollectCnt).
+		Updates(map[string]any{
+			"like_cnt":    likeCnt,
+			"collect_cnt": collectCnt,
+			"utime":       time.Now().UnixMilli(),
+		})
+
+	return res.RowsAffected > 0, res.Error
+}
+
+func toCntMap(cnts []bizCnt) map[uint64]int64 {
+	res := make(map[uint64]int64, len(cnts))
+	for _, c := range cnts {
+		res[c.BizID] = c.Cnt
+	}
+	return res
+}
+
+func (dao *InteractiveDao) GetByIDs(ctx context.Context, biz string, ids []uint64) ([]Interactive, error) {
+	var res []Interactive
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id IN ?", biz, ids).
+		Find(&res).Error
+	return res, err
+}
@@ -50,12 +50,13 @@ type OnlineArticle struct {
 type Interactive struct {
 	ID uint64 `gorm:"primaryKey,autoIncrement"`
 	// 业务标识符
-	BizID   uint64 `gorm:"uniqueIndex:biz_id_type"`
-	Biz     string `gorm:"uniqueIndex:biz_id_type;type:varchar(128)"`
-	ReadCnt int64
-	LikeCnt int64
-	Ctime   int64
-	Utime   int64
+	BizID      uint64 `gorm:"uniqueIndex:biz_id_type"`
+	Biz        string `gorm:"uniqueIndex:biz_id_type;type:varchar(128)"`
+	ReadCnt    int64
+	LikeCnt    int64
+	CollectCnt int64
+	Ctime      int64
+	Utime      int64
 }
 
 type UserLike struct {
@@ -68,3 +69,128 @@ type UserLike struct {
 	// 软删除
 	Status uint8
 }
+
+// CollectFolder 用户自建的收藏夹，FolderID 为 0 的收藏放在默认收藏夹里
+type CollectFolder struct {
+	ID          uint64 `gorm:"primaryKey,autoIncrement"`
+	UID         uint64 `gorm:"uniqueIndex:uid_name"`
+	Name        string `gorm:"uniqueIndex:uid_name;type:varchar(128)"`
+	Description string `gorm:"type:varchar(512)"`
+	Ctime       int64
+	Utime       int64
+}
+
+// UserCollect 同一个资源只能被用户收藏到一个收藏夹里
+type UserCollect struct {
+	ID       uint64 `gorm:"primaryKey,autoIncrement"`
+	UID      uint64 `gorm:"uniqueIndex:uid_biz_id_type;index:uid_folder"`
+	BizID    uint64 `gorm:"uniqueIndex:uid_biz_id_type"`
+	Biz      string `gorm:"uniqueIndex:uid_biz_id_type;type:varchar(128)"`
+	FolderID uint64 `gorm:"index:uid_folder"`
+	Ctime    int64
+	Utime    int64
+}
+
+type Comment struct {
+	ID      uint64 `gorm:"primaryKey,autoIncrement"`
+	UID     uint64 `gorm:"index"`
+	Biz     string `gorm:"index:biz_type_id_root;type:varchar(128)"`
+	BizID   uint64 `gorm:"index:biz_type_id_root"`
+	Content string `gorm:"type:text"`
+	// RootID 顶级评论为 0
+	RootID uint64 `gorm:"index:biz_type_id_root;index"`
+	// PID 直接回复的评论，顶级评论为 0
+	PID    uint64
+	Status uint8
+	Ctime  int64
+	Utime  int64
+}
+
+// Solution 文章与题目的关联，一篇文章只能作为一道题的题解
+type Solution struct {
+	ID        uint64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID uint64 `gorm:"uniqueIndex"`
+	ProblemID uint64 `gorm:"index"`
+	AuthorID  uint64
+	// Languages 逗号分隔，例如 go,cpp
+	Languages string `gorm:"type:varchar(256)"`
+	Ctime     int64
+	Utime     int64
+}
+
+// SolutionReveal 用户主动选择查看某道题的题解
+type SolutionReveal struct {
+	ID        uint64 `gorm:"primaryKey,autoIncrement"`
+	UID       uint64 `gorm:"uniqueIndex:uid_pid"`
+	ProblemID uint64 `gorm:"uniqueIndex:uid_pid"`
+	Ctime     int64
+}
+
+// ArticleRevision 文章的历史版本，保存完整快照和与上一个版本的差异
+type ArticleRevision struct {
+	ID        uint64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID uint64 `gorm:"uniqueIndex:aid_version"`
+	Version   int64  `gorm:"uniqueIndex:aid_version"`
+	AuthorID  uint64
+	Title     string `gorm:"type:varchar(1024)"`
+	Content   string `gorm:"type:MEDIUMTEXT"`
+	Kind      uint8
+	// Diff unified diff 文本
+	Diff      string `gorm:"type:MEDIUMTEXT"`
+	Additions int
+	Deletions int
+	Ctime     int64
+}
+
+// Tag 标签表和题目共用，由题目模块创建和改名，这里只读
+type Tag struct {
+	ID   uint64
+	Name string
+}
+
+// ArticleTag 文章和标签的关联
+// Status 冗余文章的线上状态，按标签列文章和计数时只看已发布的
+type ArticleTag struct {
+	ID        uint64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID uint64 `gorm:"uniqueIndex:aid_tid"`
+	TagID     uint64 `gorm:"uniqueIndex:aid_tid;index:tid_status_utime"`
+	Status    uint8  `gorm:"index:tid_status_utime"`
+	Utime     int64  `gorm:"index:tid_status_utime"`
+	Ctime     int64
+}
+
+// UserFollow 关注关系，Follower 关注了 Followee
+type UserFollow struct {
+	ID       uint64 `gorm:"primaryKey,autoIncrement"`
+	Follower uint64 `gorm:"uniqueIndex:follower_followee"`
+	Followee uint64 `gorm:"uniqueIndex:follower_followee;index"`
+	Ctime    int64
+}
+
+// FollowStats 用户的粉丝数和关注数，和关注关系在同一个事务里更新
+type FollowStats struct {
+	UID       uint64 `gorm:"primaryKey,autoIncrement:false"`
+	Followers int64
+	Followees int64
+	Ctime     int64
+	Utime     int64
+}
+
+// ArticleReview 文章的人工审核记录，每篇文章一行，重新提交时覆盖
+// 提交审核的版本保存在这里，通过之前线上库仍然是上一次发布的版本
+type ArticleReview struct {
+	ID        uint64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID uint64 `gorm:"uniqueIndex"`
+	AuthorID  uint64
+	Title     string `gorm:"type:varchar(1024)"`
+	Content   string `gorm:"type:longtext"`
+	// Tags 标签 id 的 JSON 数组，为空表示不修改标签
+	Tags   string `gorm:"type:varchar(1024)"`
+	Status uint8  `gorm:"index:status_ctime"`
+	// Reasons 自动审核命中的规则，每行一条
+	Reasons  string `gorm:"type:text"`
+	Reviewer uint64
+	Note     string `gorm:"type:varchar(512)"`
+	Ctime    int64  `gorm:"index:status_ctime"`
+	Utime    int64
+}
@@ -4,14 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	snowflake "github.com/crazyfrankie/snow-flake"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/pkg/markdown"
 )
 
+// MongoArticleDao 制作库存在 articles，线上库存在 published_articles
+// 线上正文超过 ChunkSize 时拆到 article_chunks 中，published_articles 只留摘要
 type MongoArticleDao struct {
 	col      *mongo.Collection
 	liveCol  *mongo.Collection
@@ -28,174 +35,361 @@ func NewMongoArticleDao(db *mongo.Database, node *snowflake.Node) *MongoArticleD
 	}
 }
 
-func (m *MongoArticleDao) Create(ctx context.Context, art MongoArticle) (int64, error) {
-	now := time.Now().Unix()
+func (m *MongoArticleDao) CreateDraft(ctx context.Context, art Article) (uint64, error) {
+	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
-	id := m.Node.GenerateCode()
-	art.ID = uint64(id)
+	art.ID = uint64(m.Node.GenerateCode())
 
-	// 不需要分片，直接存储
-	art.ChunkCount = 0
 	_, err := m.col.InsertOne(ctx, art)
 	if err != nil {
 		return 0, err
 	}
 
-	return id, nil
+	return art.ID, nil
 }
 
-func (m *MongoArticleDao) UpdateById(ctx context.Context, art MongoArticle) error {
-	// 操作制作库
-	filter := bson.M{"id": art.ID, "author_id": art.AuthorID}
-	update := bson.D{
-		bson.E{
-			Key: "$set",
-			Value: bson.M{
-				"title":   art.Title,
-				"content": art.Content,
-				"utime":   time.Now().Unix(),
-				"status":  art.Status,
-			}},
+func (m *MongoArticleDao) UpdateDraftByID(ctx context.Context, art Article) error {
+	// 与 GORM 实现保持一致，只更新非空字段
+	set := bson.M{"utime": time.Now().UnixMilli()}
+	if art.Title != "" {
+		set["title"] = art.Title
+	}
+	if art.Content != "" {
+		set["content"] = art.Content
+	}
+	if art.Status != 0 {
+		set["status"] = art.Status
 	}
 
-	res, err := m.col.UpdateOne(ctx, filter, update)
+	res, err := m.col.UpdateOne(ctx,
+		bson.M{"id": art.ID, "author_id": art.AuthorID},
+		bson.M{"$set": set})
 	if err != nil {
 		return err
 	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("更新失败，可能是创作者非法 id :%d, author_id: %d", art.ID, art.AuthorID)
+	}
+
+	return nil
+}
 
-	if res.ModifiedCount == 0 {
-		return errors.New("更新数据失败")
+func (m *MongoArticleDao) RestoreDraftByID(ctx context.Context, art Article) error {
+	res, err := m.col.UpdateOne(ctx,
+		bson.M{"id": art.ID, "author_id": art.AuthorID},
+		bson.M{"$set": bson.M{
+			"title":   art.Title,
+			"content": art.Content,
+			"status":  art.Status,
+			"utime":   time.Now().UnixMilli(),
+		}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("恢复失败，可能是创作者非法 id :%d, author_id: %d", art.ID, art.AuthorID)
 	}
 
 	return nil
 }
 
-func (m *MongoArticleDao) Sync(ctx context.Context, art MongoArticle) (int64, error) {
+// SyncToPublish 依赖 MongoDB 的多文档事务，需要部署为副本集
+func (m *MongoArticleDao) SyncToPublish(ctx context.Context, art Article) (uint64, error) {
 	session, err := m.col.Database().Client().StartSession()
 	if err != nil {
 		return 0, fmt.Errorf("start session failed: %w", err)
 	}
 	defer session.EndSession(ctx)
 
-	id := int64(art.ID)
+	id := art.ID
 	_, err = session.WithTransaction(ctx, func(sessCtx context.Context) (any, error) {
-		// 处理制作库
 		var err error
 		if id > 0 {
-			err = m.UpdateById(sessCtx, art)
+			err = m.UpdateDraftByID(sessCtx, art)
 		} else {
-			id, err = m.Create(sessCtx, art)
-			art.ID = uint64(id)
+			id, err = m.CreateDraft(sessCtx, art)
 		}
 		if err != nil {
 			return nil, err
 		}
 
-		// 分片处理
-		now := time.Now().Unix()
-		contentChunks := splitContent(art.Content)
-		abstract := art.Content
-		if len(contentChunks) > 1 {
-			abstract = GenerateAbstract(contentChunks[0], 50)
-			if err := m.replaceChunks(sessCtx, art.ID, contentChunks, now); err != nil {
-				return nil, err
-			}
-		}
-
-		// 同步线上库
-		_, err = m.liveCol.UpdateOne(
-			sessCtx,
-			bson.M{"id": art.ID},
-			bson.D{
-				{"$set", bson.M{
-					"title":    art.Title,
-					"abstract": abstract,
-					"chunked":  len(contentChunks) > 1,
-					"utime":    now,
-				}},
-				{"$setOnInsert", bson.M{"ctime": now}},
-			},
-			options.UpdateOne().SetUpsert(true),
-		)
-		return nil, err
+		now := time.Now().UnixMilli()
+		return nil, m.savePub(sessCtx, OnlineArticle{
+			ID:       id,
+			Title:    art.Title,
+			Content:  art.Content,
+			AuthorID: art.AuthorID,
+			Status:   art.Status,
+			Ctime:    now,
+			Utime:    now,
+		}, false)
 	})
 
 	return id, err
 }
 
+// savePub 同步线上库，大文章分片存储
+// 已经存在的文章默认保留原来的 ctime，迁移时用 overwriteCtime 原样覆盖
+func (m *MongoArticleDao) savePub(ctx context.Context, art OnlineArticle, overwriteCtime bool) error {
+	chunks := splitContent(art.Content)
+	live := bson.M{
+		"title":       art.Title,
+		"author_id":   art.AuthorID,
+		"status":      art.Status,
+		"abstract":    GenerateAbstract(art.Content, 50),
+		"content":     art.Content,
+		"chunk_count": 0,
+		"utime":       art.Utime,
+	}
+	if len(chunks) > 1 {
+		live["content"] = ""
+		live["chunk_count"] = len(chunks)
+	}
+	if err := m.replaceChunks(ctx, art.ID, chunks, art.Utime); err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": live}
+	if overwriteCtime {
+		live["ctime"] = art.Ctime
+	} else {
+		update["$setOnInsert"] = bson.M{"ctime": art.Ctime}
+	}
+	_, err := m.liveCol.UpdateOne(ctx, bson.M{"id": art.ID}, update, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+// replaceChunks 先删掉旧的分片，只有一片时正文直接存在线上库里
 func (m *MongoArticleDao) replaceChunks(ctx context.Context, articleID uint64, chunks []string, now int64) error {
 	models := []mongo.WriteModel{
 		mongo.NewDeleteManyModel().SetFilter(bson.M{"article_id": articleID}),
 	}
-	for i, chunk := range chunks {
-		models = append(models, mongo.NewInsertOneModel().SetDocument(ArticleChunk{
-			ID:        m.Node.GenerateCode(),
-			ArticleID: articleID,
-			Content:   chunk,
-			Order:     i,
-			Ctime:     now,
-			Utime:     now,
-		}))
+	if len(chunks) > 1 {
+		for i, chunk := range chunks {
+			models = append(models, mongo.NewInsertOneModel().SetDocument(ArticleChunk{
+				ID:        m.Node.GenerateCode(),
+				ArticleID: articleID,
+				Content:   chunk,
+				Order:     i,
+				Ctime:     now,
+				Utime:     now,
+			}))
+		}
 	}
 	_, err := m.chunkCol.BulkWrite(ctx, models)
 	return err
 }
 
-func (m *MongoArticleDao) GetArticleByID(ctx context.Context, id int64) (MongoArticle, error) {
-	filter := bson.M{"id": id}
-	cursor, err := m.liveCol.Find(ctx, filter)
+func (m *MongoArticleDao) SyncStatus(ctx context.Context, id uint64, authorId uint64, status uint8) error {
+	now := time.Now().UnixMilli()
+	res, err := m.col.UpdateOne(ctx,
+		bson.M{"id": id, "author_id": authorId},
+		bson.M{"$set": bson.M{"status": status, "utime": now}})
 	if err != nil {
-		return MongoArticle{}, err
+		return err
 	}
-	var res MongoArticle
-	if err := cursor.All(ctx, &res); err != nil {
-		return MongoArticle{}, err
+	if res.MatchedCount != 1 {
+		return fmt.Errorf("有人误操作,uid :%d", id)
 	}
-	// 查询经过分片后的结果
-	chunks, err := m.chunkCol.Find(ctx, bson.M{"article_id": id})
+
+	_, err = m.liveCol.UpdateOne(ctx,
+		bson.M{"id": id},
+		bson.M{"$set": bson.M{"status": status, "utime": now}})
+	return err
+}
+
+func (m *MongoArticleDao) GetListByID(ctx context.Context, offset, limit int) ([]Article, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, bson.M{}, opts)
 	if err != nil {
-		return MongoArticle{}, err
+		return nil, err
 	}
-	defer chunks.Close(ctx)
 
-	// 组装内容
-	var contents = make([]string, res.ChunkCount)
-	for chunks.Next(ctx) {
-		var chunk ArticleChunk
-		if err := chunks.Decode(&chunk); err != nil {
-			return MongoArticle{}, err
-		}
-		contents[chunk.Order] = chunk.Content
+	var arts []Article
+	err = cursor.All(ctx, &arts)
+	return arts, err
+}
+
+func (m *MongoArticleDao) GetByID(ctx context.Context, id uint64) (Article, error) {
+	var art Article
+	err := m.col.FindOne(ctx, bson.M{"id": id}).Decode(&art)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Article{}, ErrRecordNotFound
 	}
+	return art, err
+}
+
+// GetPubListByID 分片的文章只带摘要
+func (m *MongoArticleDao) GetPubListByID(ctx context.Context, offset int, limit int) ([]OnlineArticle, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "utime", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	return m.findPub(ctx, bson.M{}, opts)
+}
 
-	res.Content = ""
-	for _, content := range contents {
-		res.Content += content
+func (m *MongoArticleDao) ListPubSince(ctx context.Context, start int64, offset int, limit int) ([]OnlineArticle, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "id", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	filter := bson.M{
+		"ctime":  bson.M{"$gt": start},
+		"status": domain.ArticleStatusPublished.ToUint8(),
+	}
+	return m.findPub(ctx, filter, opts)
+}
+
+func (m *MongoArticleDao) GetPubByIDs(ctx context.Context, ids []uint64) ([]OnlineArticle, error) {
+	return m.findPub(ctx, bson.M{"id": bson.M{"$in": ids}}, options.Find())
+}
+
+func (m *MongoArticleDao) findPub(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]OnlineArticle, error) {
+	cursor, err := m.liveCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var arts []MongoArticle
+	if err = cursor.All(ctx, &arts); err != nil {
+		return nil, err
+	}
+
+	res := make([]OnlineArticle, 0, len(arts))
+	for _, art := range arts {
+		if art.ChunkCount > 0 {
+			art.Content = art.Abstract
+		}
+		res = append(res, art.toOnline())
 	}
 
 	return res, nil
 }
 
-func (m *MongoArticleDao) GetArticleList(ctx context.Context, page int, limit int) ([]MongoArticle, error) {
-	offset := (page - 1) * limit
+func (m *MongoArticleDao) GetPubByID(ctx context.Context, aid uint64) (OnlineArticle, error) {
+	var art MongoArticle
+	err := m.liveCol.FindOne(ctx, bson.M{"id": aid}).Decode(&art)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return OnlineArticle{}, ErrRecordNotFound
+	}
+	if err != nil {
+		return OnlineArticle{}, err
+	}
+	if err = m.loadChunks(ctx, &art); err != nil {
+		return OnlineArticle{}, err
+	}
+
+	return art.toOnline(), nil
+}
+
+// loadChunks 按顺序拼接分片，没有分片的文章不做处理
+func (m *MongoArticleDao) loadChunks(ctx context.Context, art *MongoArticle) error {
+	if art.ChunkCount == 0 {
+		return nil
+	}
+
+	cursor, err := m.chunkCol.Find(ctx, bson.M{"article_id": art.ID})
+	if err != nil {
+		return err
+	}
+	var chunks []ArticleChunk
+	if err = cursor.All(ctx, &chunks); err != nil {
+		return err
+	}
+	if len(chunks) != art.ChunkCount {
+		return fmt.Errorf("article %d expects %d chunks, got %d", art.ID, art.ChunkCount, len(chunks))
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].Order < chunks[j].Order
+	})
+
+	var sb strings.Builder
+	for _, chunk := range chunks {
+		sb.WriteString(chunk.Content)
+	}
+	art.Content = sb.String()
+
+	return nil
+}
+
+// UpsertDraft 迁移用，按原 id 原样写入
+func (m *MongoArticleDao) UpsertDraft(ctx context.Context, art Article) error {
+	_, err := m.col.ReplaceOne(ctx, bson.M{"id": art.ID}, art, options.Replace().SetUpsert(true))
+	return err
+}
 
+// UpsertPub 迁移用，分片和线上库不在同一个事务里，中途失败靠校验修复
+func (m *MongoArticleDao) UpsertPub(ctx context.Context, art OnlineArticle) error {
+	return m.savePub(ctx, art, true)
+}
+
+func (m *MongoArticleDao) DeleteDraft(ctx context.Context, id uint64) error {
+	_, err := m.col.DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
+
+func (m *MongoArticleDao) DeletePub(ctx context.Context, id uint64) error {
+	if _, err := m.liveCol.DeleteOne(ctx, bson.M{"id": id}); err != nil {
+		return err
+	}
+	_, err := m.chunkCol.DeleteMany(ctx, bson.M{"article_id": id})
+	return err
+}
+
+func (m *MongoArticleDao) DraftsAfter(ctx context.Context, id uint64, limit int) ([]Article, error) {
 	opts := options.Find().
-		SetSort(bson.D{{"utime", -1}}).
-		SetSkip(int64(offset)).
+		SetSort(bson.D{{Key: "id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, bson.M{"id": bson.M{"$gt": id}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var arts []Article
+	err = cursor.All(ctx, &arts)
+	return arts, err
+}
+
+func (m *MongoArticleDao) PubsAfter(ctx context.Context, id uint64, limit int) ([]OnlineArticle, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "id", Value: 1}}).
 		SetLimit(int64(limit))
-	cursor, err := m.liveCol.Find(ctx, bson.M{}, opts)
+	cursor, err := m.liveCol.Find(ctx, bson.M{"id": bson.M{"$gt": id}}, opts)
 	if err != nil {
 		return nil, err
 	}
-	res := make([]MongoArticle, limit)
-	if err := cursor.All(ctx, &res); err != nil {
+	var arts []MongoArticle
+	if err = cursor.All(ctx, &arts); err != nil {
 		return nil, err
 	}
 
+	res := make([]OnlineArticle, 0, len(arts))
+	for i := range arts {
+		if err = m.loadChunks(ctx, &arts[i]); err != nil {
+			return nil, err
+		}
+		res = append(res, arts[i].toOnline())
+	}
+
 	return res, nil
 }
 
+func (a MongoArticle) toOnline() OnlineArticle {
+	return OnlineArticle{
+		ID:       a.ID,
+		Title:    a.Title,
+		Content:  a.Content,
+		AuthorID: a.AuthorID,
+		Status:   a.Status,
+		Ctime:    a.Ctime,
+		Utime:    a.Utime,
+	}
+}
+
 // splitContent 将内容分片
 func splitContent(content string) []string {
 	runes := []rune(content)
@@ -216,7 +410,9 @@ func splitContent(content string) []string {
 	return chunks
 }
 
+// GenerateAbstract 取渲染后的纯文本，截断时加省略号
 func GenerateAbstract(text string, maxChars int) string {
+	text = markdown.Abstract(text, maxChars+1)
 	runes := []rune(text)
 	if len(runes) <= maxChars {
 		return text
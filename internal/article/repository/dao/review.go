@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+var ErrReviewNotFound = errors.New("review not found")
+
+type ReviewDao struct {
+	db *gorm.DB
+}
+
+func NewReviewDao(db *gorm.DB) *ReviewDao {
+	return &ReviewDao{
+		db: db,
+	}
+}
+
+// Submit 重新提交时清空上一次的审核结果，按新的提交时间排队
+func (dao *ReviewDao) Submit(ctx context.Context, r ArticleReview) error {
+	now := time.Now().UnixMilli()
+	r.Status = domain.ReviewStatusPending.ToUint8()
+	r.Ctime = now
+	r.Utime = now
+
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "article_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"author_id": r.AuthorID,
+			"title":     r.Title,
+			"content":   r.Content,
+			"tags":      r.Tags,
+			"status":    r.Status,
+			"reasons":   r.Reasons,
+			"reviewer":  0,
+			"note":      "",
+			"ctime":     now,
+			"utime":     now,
+		}),
+	}).Create(&r).Error
+}
+
+// ListPending 先提交的先审，列表不带正文
+func (dao *ReviewDao) ListPending(ctx context.Context, offset, limit int) ([]ArticleReview, error) {
+	var res []ArticleReview
+	err := dao.db.WithContext(ctx).
+		Omit("content").
+		Where("status = ?", domain.ReviewStatusPending.ToUint8()).
+		Order("ctime").
+		Offset(offset).
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *ReviewDao) FindPending(ctx context.Context, aid uint64) (ArticleReview, error) {
+	var res ArticleReview
+	err := dao.db.WithContext(ctx).
+		Where("article_id = ? AND status = ?", aid, domain.ReviewStatusPending.ToUint8()).
+		First(&res).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ArticleReview{}, ErrReviewNotFound
+	}
+	return res, err
+}
+
+// Resolve 只处理还在等待审核的记录，已经被别人处理或者撤回时返回 ErrReviewNotFound
+func (dao *ReviewDao) Resolve(ctx context.Context, aid uint64, status uint8, reviewer uint64, note string) error {
+	res := dao.db.WithContext(ctx).Model(&ArticleReview{}).
+		Where("article_id = ? AND status = ?", aid, domain.ReviewStatusPending.ToUint8()).
+		Updates(map[string]any{
+			"status":   status,
+			"reviewer": reviewer,
+			"note":     note,
+			"utime":    time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrReviewNotFound
+	}
+	return nil
+}
+
+// Cancel 作者撤回或者重新发布并通过了自动审核时，从队列中移除
+func (dao *ReviewDao) Cancel(ctx context.Context, aid uint64) error {
+	return dao.db.WithContext(ctx).
+		Where("article_id = ? AND status = ?", aid, domain.ReviewStatusPending.ToUint8()).
+		Delete(&ArticleReview{}).Error
+}
@@ -0,0 +1,66 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+type RevisionDao struct {
+	db *gorm.DB
+}
+
+func NewRevisionDao(db *gorm.DB) *RevisionDao {
+	return &RevisionDao{
+		db: db,
+	}
+}
+
+// Insert 版本号由调用方在最新版本上加一，并发写同一个版本时返回 gorm.ErrDuplicatedKey
+func (dao *RevisionDao) Insert(ctx context.Context, rev ArticleRevision) error {
+	rev.Ctime = time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Create(&rev).Error
+}
+
+func (dao *RevisionDao) Latest(ctx context.Context, aid uint64) (ArticleRevision, error) {
+	var rev ArticleRevision
+	err := dao.db.WithContext(ctx).
+		Where("article_id = ?", aid).
+		Order("version DESC").
+		First(&rev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ArticleRevision{}, ErrRevisionNotFound
+	}
+
+	return rev, err
+}
+
+func (dao *RevisionDao) FindByVersion(ctx context.Context, aid uint64, version int64) (ArticleRevision, error) {
+	var rev ArticleRevision
+	err := dao.db.WithContext(ctx).
+		Where("article_id = ? AND version = ?", aid, version).
+		First(&rev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ArticleRevision{}, ErrRevisionNotFound
+	}
+
+	return rev, err
+}
+
+// List 列表不带正文和差异文本
+func (dao *RevisionDao) List(ctx context.Context, aid uint64, offset, limit int) ([]ArticleRevision, error) {
+	var revs []ArticleRevision
+	err := dao.db.WithContext(ctx).
+		Omit("content", "diff").
+		Where("article_id = ?", aid).
+		Order("version DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&revs).Error
+
+	return revs, err
+}
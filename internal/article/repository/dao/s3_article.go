@@ -3,31 +3,40 @@ package dao
 import (
 	"bytes"
 	"context"
-	"gorm.io/gorm/clause"
+	"io"
 	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go/service/s3"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// OSSArticleDao 制作库与线上库的元数据都在 MySQL 中
+// 线上库的 content 列只保存摘要，完整正文放在对象存储，key 为文章 id
 type OSSArticleDao struct {
 	oss *s3.S3
 	GORMArticleDao
 	bucket *string
 }
 
-func NewOSSArticleDao(oss *s3.S3, db *gorm.DB) *OSSArticleDao {
+func NewOSSArticleDao(oss *s3.S3, db *gorm.DB, bucket string) *OSSArticleDao {
 	return &OSSArticleDao{
 		oss: oss,
 		GORMArticleDao: GORMArticleDao{
 			db: db,
 		},
-		bucket: nil,
+		bucket: ToPtr(bucket),
 	}
 }
 
-func (o *OSSArticleDao) Sync(ctx context.Context, art Article) (uint64, error) {
+func (o *OSSArticleDao) SyncToPublish(ctx context.Context, art Article) (uint64, error) {
+	return o.SyncToPublishWithEvents(ctx, art, nil)
+}
+
+// SyncToPublishWithEvents 事件随元数据一起提交，正文在事务之后才上传，
+// 消费方可能先于正文收到事件，需要正文时自己重试
+func (o *OSSArticleDao) SyncToPublishWithEvents(ctx context.Context, art Article, events PublishEvents) (uint64, error) {
 	// 保存制作库
 	// 保存线上库
 	// 把 Content 上传到 OSS
@@ -47,31 +56,34 @@ func (o *OSSArticleDao) Sync(ctx context.Context, art Article) (uint64, error) {
 			return err
 		}
 
-		art.ID = id
+		now := time.Now().UnixMilli()
+		abstract := GenerateAbstract(art.Content, 100)
 		publishArt := OnlineArticle{
-			ID:       art.ID,
+			ID:       id,
 			Title:    art.Title,
+			Content:  abstract,
 			AuthorID: art.AuthorID,
 			Status:   art.Status,
+			Ctime:    now,
+			Utime:    now,
 		}
-		now := time.Now().UnixMilli()
-		publishArt.Ctime = now
-		publishArt.Utime = now
 
-		// 线上库不保存 Content, 要准备上传到 OSS
-		publishArt.Content = ""
-		return tx.WithContext(ctx).Clauses(clause.OnConflict{
+		err = tx.WithContext(ctx).Clauses(clause.OnConflict{
 			// Columns 哪些列冲突
 			Columns: []clause.Column{{Name: "id"}},
 			// 如果是更新，则更新以下字段
 			DoUpdates: clause.Assignments(map[string]interface{}{
-				"title":  art.Title,
-				"utime":  now,
-				"status": art.Status,
+				"title":   art.Title,
+				"content": abstract,
+				"utime":   now,
+				"status":  art.Status,
 			}),
-			// DoNothing: 数据冲突了啥也不干
-			// Where: 数据冲突了，并且符合 WHERE 条件的就会执行更新
-		}).Create(&art).Error
+		}).Create(&publishArt).Error
+		if err != nil {
+			return err
+		}
+
+		return appendEvents(ctx, tx, id, events)
 	})
 	if err != nil {
 		return 0, err
@@ -81,7 +93,7 @@ func (o *OSSArticleDao) Sync(ctx context.Context, art Article) (uint64, error) {
 	// 需要有监控，重试，补偿机制
 	_, err = o.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
 		Bucket:      o.bucket,
-		Key:         ToPtr(strconv.FormatInt(int64(id), 10)),
+		Key:         o.key(id),
 		Body:        bytes.NewReader([]byte(art.Content)),
 		ContentType: ToPtr("text/plain;charset=utf-8"),
 	})
@@ -89,39 +101,89 @@ func (o *OSSArticleDao) Sync(ctx context.Context, art Article) (uint64, error) {
 	return id, err
 }
 
-//func (o *OSSArticleDao) SyncStatus(ctx context.Context, id uint64, authorId uint64, status uint8) error {
-//	now := time.Now().UnixMilli()
-//	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-//		res := tx.Model(&Article{}).
-//			Where("id = ? AND author_id = ?", id, authorId).
-//			Updates(map[string]any{
-//				"status": status,
-//				"utime":  now,
-//			})
-//
-//		if res.Error != nil {
-//			// 数据库有问题
-//			return res.Error
-//		}
-//		if res.RowsAffected != 1 {
-//			return fmt.Errorf("有人误操作,uid :%d", id)
-//		}
-//
-//		if status == domain.ArticleStatusPrivate.ToUint8() {
-//			o.oss.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
-//				Bucket: o.bucket,
-//				Key:    ToPtr(strconv.FormatInt(int64(id), 10)),
-//			})
-//		}
-//
-//		return tx.Model(&OnlineArticle{}).
-//			Where("id = ? ", id).
-//			Updates(map[string]any{
-//				"status": status,
-//				"utime":  now,
-//			}).Error
-//	})
-//}
+// GetPubByID 元数据从 MySQL 取，正文从 OSS 取
+func (o *OSSArticleDao) GetPubByID(ctx context.Context, aid uint64) (OnlineArticle, error) {
+	art, err := o.GORMArticleDao.GetPubByID(ctx, aid)
+	if err != nil {
+		return OnlineArticle{}, err
+	}
+
+	art.Content, err = o.content(ctx, aid)
+	if err != nil {
+		return OnlineArticle{}, err
+	}
+
+	return art, nil
+}
+
+// UpsertPub 迁移用，摘要写 MySQL，正文写 OSS
+func (o *OSSArticleDao) UpsertPub(ctx context.Context, art OnlineArticle) error {
+	content := art.Content
+	art.Content = GenerateAbstract(content, 100)
+	if err := o.GORMArticleDao.UpsertPub(ctx, art); err != nil {
+		return err
+	}
+
+	_, err := o.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      o.bucket,
+		Key:         o.key(art.ID),
+		Body:        bytes.NewReader([]byte(content)),
+		ContentType: ToPtr("text/plain;charset=utf-8"),
+	})
+	return err
+}
+
+func (o *OSSArticleDao) DeletePub(ctx context.Context, id uint64) error {
+	if err := o.GORMArticleDao.DeletePub(ctx, id); err != nil {
+		return err
+	}
+
+	_, err := o.oss.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: o.bucket,
+		Key:    o.key(id),
+	})
+	return err
+}
+
+// PubsAfter 逐篇从 OSS 取正文，只在迁移时使用
+func (o *OSSArticleDao) PubsAfter(ctx context.Context, id uint64, limit int) ([]OnlineArticle, error) {
+	arts, err := o.GORMArticleDao.PubsAfter(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range arts {
+		content, err := o.content(ctx, arts[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		arts[i].Content = content
+	}
+
+	return arts, nil
+}
+
+func (o *OSSArticleDao) content(ctx context.Context, id uint64) (string, error) {
+	out, err := o.oss.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: o.bucket,
+		Key:    o.key(id),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer out.Body.Close()
+
+	content, err := io.ReadAll(out.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+func (o *OSSArticleDao) key(id uint64) *string {
+	return ToPtr(strconv.FormatUint(id, 10))
+}
 
 func ToPtr(s string) *string {
 	return &s
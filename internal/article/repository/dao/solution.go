@@ -0,0 +1,145 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+var ErrSolutionNotFound = errors.New("solution not found")
+
+// SolutionRow 列表查询得到的一行，文章相关的字段取自 ArticleDAO
+type SolutionRow struct {
+	ArticleID uint64
+	ProblemID uint64
+	Languages string
+	Ctime     int64
+	Title     string
+	Content   string
+	AuthorID  uint64
+	LikeCnt   int64
+	ReadCnt   int64
+}
+
+type SolutionDao struct {
+	db *gorm.DB
+	// artDAO 文章可能不在 MySQL 里，只能通过 ArticleDAO 读取
+	artDAO ArticleDAO
+}
+
+func NewSolutionDao(db *gorm.DB, artDAO ArticleDAO) *SolutionDao {
+	return &SolutionDao{
+		db:     db,
+		artDAO: artDAO,
+	}
+}
+
+// Upsert 同一篇文章重复挂载时改挂到新的题目上
+func (dao *SolutionDao) Upsert(ctx context.Context, sol Solution) error {
+	now := time.Now().UnixMilli()
+	sol.Ctime, sol.Utime = now, now
+
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"problem_id": sol.ProblemID,
+			"languages":  sol.Languages,
+			"utime":      now,
+		}),
+	}).Create(&sol).Error
+}
+
+func (dao *SolutionDao) Delete(ctx context.Context, aid, authorId uint64) error {
+	res := dao.db.WithContext(ctx).
+		Where("article_id = ? AND author_id = ?", aid, authorId).
+		Delete(&Solution{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrSolutionNotFound
+	}
+	return nil
+}
+
+func (dao *SolutionDao) FindByArticle(ctx context.Context, aid uint64) (Solution, error) {
+	var sol Solution
+	err := dao.db.WithContext(ctx).Where("article_id = ?", aid).First(&sol).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return sol, ErrSolutionNotFound
+	}
+	return sol, err
+}
+
+// List 只列出已发布的文章，点赞数、阅读数取自 interactives
+// 先按题解分页，再通过 ArticleDAO 取文章并过滤掉未发布的，所以一页可能少于 limit 条，返回空才表示没有更多
+func (dao *SolutionDao) List(ctx context.Context, q domain.SolutionQuery) ([]SolutionRow, error) {
+	var rows []SolutionRow
+
+	tx := dao.db.WithContext(ctx).Model(&Solution{}).
+		Select("solutions.article_id, solutions.problem_id, solutions.languages, solutions.ctime, "+
+			"COALESCE(interactives.like_cnt, 0) AS like_cnt, COALESCE(interactives.read_cnt, 0) AS read_cnt").
+		Joins("LEFT JOIN interactives ON interactives.biz = ? AND interactives.biz_id = solutions.article_id", "article").
+		Where("solutions.problem_id = ?", q.ProblemID)
+	if q.Language != "" {
+		tx = tx.Where("FIND_IN_SET(?, solutions.languages) > 0", q.Language)
+	}
+	if q.Sort == domain.SolutionSortHot {
+		tx = tx.Order("like_cnt DESC")
+	}
+
+	err := tx.Order("solutions.id DESC").
+		Offset(q.Offset).Limit(q.Limit).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return rows, err
+	}
+
+	ids := make([]uint64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ArticleID)
+	}
+	arts, err := dao.artDAO.GetPubByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	pubs := make(map[uint64]OnlineArticle, len(arts))
+	for _, art := range arts {
+		if art.Status == domain.ArticleStatusPublished.ToUint8() {
+			pubs[art.ID] = art
+		}
+	}
+
+	res := rows[:0]
+	for _, row := range rows {
+		art, ok := pubs[row.ArticleID]
+		if !ok {
+			continue
+		}
+		row.Title, row.Content, row.AuthorID = art.Title, art.Content, art.AuthorID
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
+func (dao *SolutionDao) Reveal(ctx context.Context, uid, pid uint64) error {
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&SolutionReveal{
+			UID:       uid,
+			ProblemID: pid,
+			Ctime:     time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *SolutionDao) Revealed(ctx context.Context, uid, pid uint64) (bool, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&SolutionReveal{}).
+		Where("uid = ? AND problem_id = ?", uid, pid).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
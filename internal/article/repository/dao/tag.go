@@ -0,0 +1,117 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TagCnt 标签下已发布的文章数
+type TagCnt struct {
+	TagID uint64
+	Name  string
+	Cnt   int64
+}
+
+type TagDao struct {
+	db *gorm.DB
+}
+
+func NewTagDao(db *gorm.DB) *TagDao {
+	return &TagDao{
+		db: db,
+	}
+}
+
+func (dao *TagDao) FindByNames(ctx context.Context, names []string) ([]Tag, error) {
+	var tags []Tag
+	err := dao.db.WithContext(ctx).Where("name IN ?", names).Find(&tags).Error
+	return tags, err
+}
+
+func (dao *TagDao) FindByName(ctx context.Context, name string) (Tag, error) {
+	var tag Tag
+	err := dao.db.WithContext(ctx).Where("name = ?", name).First(&tag).Error
+	return tag, err
+}
+
+// SetArticleTags 整体替换文章的标签，status 是文章当前的线上状态
+func (dao *TagDao) SetArticleTags(ctx context.Context, aid uint64, tagIDs []uint64, status uint8) error {
+	now := time.Now().UnixMilli()
+
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id = ?", aid).Delete(&ArticleTag{}).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		rows := make([]ArticleTag, 0, len(tagIDs))
+		for _, tid := range tagIDs {
+			rows = append(rows, ArticleTag{
+				ArticleID: aid,
+				TagID:     tid,
+				Status:    status,
+				Ctime:     now,
+				Utime:     now,
+			})
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+// SyncStatus 文章发布或者撤回时同步线上状态
+func (dao *TagDao) SyncStatus(ctx context.Context, aid uint64, status uint8) error {
+	return dao.db.WithContext(ctx).Model(&ArticleTag{}).
+		Where("article_id = ?", aid).
+		Updates(map[string]any{
+			"status": status,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *TagDao) FindByArticle(ctx context.Context, aid uint64) ([]Tag, error) {
+	var tags []Tag
+	err := dao.db.WithContext(ctx).
+		Table("tag t").
+		Select("t.id, t.name").
+		Joins("JOIN article_tag atg ON atg.tag_id = t.id").
+		Where("atg.article_id = ?", aid).
+		Order("atg.id").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// ArticleIDs 标签下已发布的文章，最近发布的在前
+func (dao *TagDao) ArticleIDs(ctx context.Context, tid uint64, status uint8, offset, limit int) ([]uint64, error) {
+	var ids []uint64
+	err := dao.db.WithContext(ctx).Model(&ArticleTag{}).
+		Where("tag_id = ? AND status = ?", tid, status).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Pluck("article_id", &ids).Error
+	return ids, err
+}
+
+func (dao *TagDao) CountArticles(ctx context.Context, tid uint64, status uint8) (int64, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&ArticleTag{}).
+		Where("tag_id = ? AND status = ?", tid, status).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+// CountByTag 所有标签都返回，没有文章的计数为 0
+func (dao *TagDao) CountByTag(ctx context.Context, status uint8) ([]TagCnt, error) {
+	var res []TagCnt
+	err := dao.db.WithContext(ctx).
+		Table("tag t").
+		Select("t.id AS tag_id, t.name, COUNT(atg.id) AS cnt").
+		Joins("LEFT JOIN article_tag atg ON atg.tag_id = t.id AND atg.status = ?", status).
+		Group("t.id, t.name").
+		Order("t.id").
+		Scan(&res).Error
+	return res, err
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/cache"
+)
+
+type FeedRepository struct {
+	cache *cache.FeedCache
+}
+
+func NewFeedRepository(cache *cache.FeedCache) *FeedRepository {
+	return &FeedRepository{
+		cache: cache,
+	}
+}
+
+func (r *FeedRepository) AddOutbox(ctx context.Context, author uint64, item domain.FeedItem) error {
+	return r.cache.AddOutbox(ctx, author, item)
+}
+
+func (r *FeedRepository) Push(ctx context.Context, uids []uint64, items ...domain.FeedItem) error {
+	return r.cache.Push(ctx, uids, items...)
+}
+
+func (r *FeedRepository) Inbox(ctx context.Context, uid uint64, before int64, limit int) ([]domain.FeedItem, error) {
+	return r.cache.Inbox(ctx, uid, before, limit)
+}
+
+func (r *FeedRepository) Outbox(ctx context.Context, author uint64, before int64, limit int) ([]domain.FeedItem, error) {
+	return r.cache.Outbox(ctx, author, before, limit)
+}
+
+func (r *FeedRepository) HasInbox(ctx context.Context, uid uint64) (bool, error) {
+	return r.cache.HasInbox(ctx, uid)
+}
+
+func (r *FeedRepository) RemoveAuthor(ctx context.Context, uid, author uint64) error {
+	return r.cache.RemoveAuthor(ctx, uid, author)
+}
+
+func (r *FeedRepository) MarkActive(ctx context.Context, uid uint64) error {
+	return r.cache.MarkActive(ctx, uid)
+}
+
+func (r *FeedRepository) FilterActive(ctx context.Context, uids []uint64) ([]uint64, error) {
+	return r.cache.FilterActive(ctx, uids)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+var ErrNotFollowed = dao.ErrNotFollowed
+
+type FollowRepository struct {
+	dao *dao.FollowDao
+}
+
+func NewFollowRepository(dao *dao.FollowDao) *FollowRepository {
+	return &FollowRepository{
+		dao: dao,
+	}
+}
+
+func (r *FollowRepository) Follow(ctx context.Context, follower, followee uint64) (bool, error) {
+	return r.dao.Follow(ctx, follower, followee)
+}
+
+func (r *FollowRepository) Unfollow(ctx context.Context, follower, followee uint64) error {
+	return r.dao.Unfollow(ctx, follower, followee)
+}
+
+func (r *FollowRepository) Stats(ctx context.Context, uid uint64) (domain.FollowStats, error) {
+	stats, err := r.dao.Stats(ctx, uid)
+	if err != nil {
+		return domain.FollowStats{}, err
+	}
+
+	return domain.FollowStats{
+		Uid:       stats.UID,
+		Followers: stats.Followers,
+		Followees: stats.Followees,
+	}, nil
+}
+
+func (r *FollowRepository) IsFollowing(ctx context.Context, follower, followee uint64) (bool, error) {
+	return r.dao.IsFollowing(ctx, follower, followee)
+}
+
+func (r *FollowRepository) Followees(ctx context.Context, uid uint64, offset, limit int) ([]domain.Follow, error) {
+	res, err := r.dao.Followees(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toDomain(res), nil
+}
+
+func (r *FollowRepository) Followers(ctx context.Context, uid uint64, offset, limit int) ([]domain.Follow, error) {
+	res, err := r.dao.Followers(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toDomain(res), nil
+}
+
+// FollowersAfter 返回这一批粉丝的 id 和下一批的游标，没有更多时返回空
+func (r *FollowRepository) FollowersAfter(ctx context.Context, uid uint64, cursor uint64, limit int) ([]uint64, uint64, error) {
+	res, err := r.dao.FollowersAfter(ctx, uid, cursor, limit)
+	if err != nil || len(res) == 0 {
+		return nil, 0, err
+	}
+
+	uids := make([]uint64, 0, len(res))
+	for _, f := range res {
+		uids = append(uids, f.Follower)
+	}
+
+	return uids, res[len(res)-1].ID, nil
+}
+
+func (r *FollowRepository) BigFollowees(ctx context.Context, uid uint64, threshold int64, limit int) ([]uint64, error) {
+	return r.dao.BigFollowees(ctx, uid, threshold, limit)
+}
+
+func (r *FollowRepository) toDomain(fs []dao.UserFollow) []domain.Follow {
+	res := make([]domain.Follow, 0, len(fs))
+	for _, f := range fs {
+		res = append(res, domain.Follow{
+			Follower: f.Follower,
+			Followee: f.Followee,
+			Ctime:    time.UnixMilli(f.Ctime),
+		})
+	}
+
+	return res
+}
@@ -2,15 +2,35 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository/cache"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
 )
 
+// interactiveDAO InteractiveArtRepository 用到的数据库操作，测试时替换成内存实现
+type interactiveDAO interface {
+	BatchIncrCnt(ctx context.Context, biz string, deltas map[uint64]domain.Interactive) error
+	InsertLikeInfo(ctx context.Context, biz string, bizId, uid uint64) (bool, error)
+	DeleteLikeInfo(ctx context.Context, biz string, bizId uint64, uid uint64) (bool, error)
+	Liked(ctx context.Context, biz string, bizId, uid uint64) (bool, error)
+	LikedIDs(ctx context.Context, biz string, ids []uint64, uid uint64) (map[uint64]bool, error)
+	InsertCollectInfo(ctx context.Context, biz string, bizId, uid, fid uint64) (bool, error)
+	DeleteCollectInfo(ctx context.Context, biz string, bizId, uid uint64) error
+	Collected(ctx context.Context, biz string, bizId, uid uint64) (bool, error)
+	FindInteractive(ctx context.Context, biz string, bizId uint64) (domain.Interactive, error)
+	GetByIDs(ctx context.Context, biz string, ids []uint64) ([]dao.Interactive, error)
+	ListInteractives(ctx context.Context, afterID uint64, limit int) ([]dao.Interactive, error)
+	CountLikes(ctx context.Context, biz string, ids []uint64) (map[uint64]int64, error)
+	CountCollects(ctx context.Context, biz string, ids []uint64) (map[uint64]int64, error)
+	ResetCnt(ctx context.Context, row dao.Interactive, likeCnt, collectCnt int64) (bool, error)
+}
+
 type InteractiveArtRepository struct {
-	dao   *dao.InteractiveDao
+	dao   interactiveDAO
 	cache *cache.InteractiveCache
 }
 
@@ -21,19 +41,19 @@ func NewInteractiveArtRepository(dao *dao.InteractiveDao, cache *cache.Interacti
 	}
 }
 
+// IncrReadCnt 阅读数只累加到缓存，由 Flush 定时落库
 func (r *InteractiveArtRepository) IncrReadCnt(ctx context.Context, biz string, bizId uint64) error {
-	err := r.dao.IncrReadCnt(ctx, biz, bizId)
-	if err != nil {
-		return err
-	}
-
 	return r.cache.IncrReadCnt(ctx, biz, bizId)
 }
 
+func (r *InteractiveArtRepository) BatchIncrReadCnt(ctx context.Context, biz string, cnts map[uint64]int64) error {
+	return r.cache.BatchIncrReadCnt(ctx, biz, cnts)
+}
+
+// IncrLikeCnt 点赞记录同步写库，点赞数只累加到缓存，重复点赞不计数
 func (r *InteractiveArtRepository) IncrLikeCnt(ctx context.Context, biz string, bizId, uid uint64) error {
-	// 先插入点赞，然后更新点赞数据，最后更新缓存
-	err := r.dao.InsertLikeInfo(ctx, biz, bizId, uid)
-	if err != nil {
+	changed, err := r.dao.InsertLikeInfo(ctx, biz, bizId, uid)
+	if err != nil || !changed {
 		return err
 	}
 
@@ -41,33 +61,292 @@ func (r *InteractiveArtRepository) IncrLikeCnt(ctx context.Context, biz string,
 }
 
 func (r *InteractiveArtRepository) DecrLikeCnt(ctx context.Context, biz string, bizId uint64, uid uint64) error {
-	// 先软删除点赞记录，然后更新点赞数据，最后更新缓存
-	err := r.dao.DeleteLikeInfo(ctx, biz, bizId, uid)
-	if err != nil {
+	changed, err := r.dao.DeleteLikeInfo(ctx, biz, bizId, uid)
+	if err != nil || !changed {
 		return err
 	}
 
 	return r.cache.DecrLikeCnt(ctx, biz, bizId)
 }
 
+// IncrCollectCnt 只是换收藏夹时不改收藏数
+func (r *InteractiveArtRepository) IncrCollectCnt(ctx context.Context, biz string, bizId, uid, fid uint64) error {
+	created, err := r.dao.InsertCollectInfo(ctx, biz, bizId, uid, fid)
+	if err != nil || !created {
+		return err
+	}
+
+	return r.cache.IncrCollectCnt(ctx, biz, bizId)
+}
+
+func (r *InteractiveArtRepository) DecrCollectCnt(ctx context.Context, biz string, bizId, uid uint64) error {
+	err := r.dao.DeleteCollectInfo(ctx, biz, bizId, uid)
+	if err != nil {
+		return err
+	}
+
+	return r.cache.DecrCollectCnt(ctx, biz, bizId)
+}
+
 func (r *InteractiveArtRepository) GetInteractive(ctx context.Context, biz string, bizId, uid uint64) (domain.Interactive, error) {
-	// 先从缓存中拿
+	// 点赞和收藏状态因人而异，不放进按资源共享的缓存里
+	liked, err := r.dao.Liked(ctx, biz, bizId, uid)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	collected, err := r.dao.Collected(ctx, biz, bizId, uid)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+
+	inter, err := r.getCnt(ctx, biz, bizId)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	inter.Liked = liked
+	inter.Collected = collected
+
+	return inter, nil
+}
+
+// BatchGetInteractive 批量取计数和 uid 的点赞状态，不含收藏状态
+// 没有缓存的资源一次性从数据库取出后回填
+func (r *InteractiveArtRepository) BatchGetInteractive(ctx context.Context, biz string, ids []uint64, uid uint64) (map[uint64]domain.Interactive, error) {
+	if len(ids) == 0 {
+		return map[uint64]domain.Interactive{}, nil
+	}
+	liked, err := r.dao.LikedIDs(ctx, biz, ids, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.cache.BatchGetInteractive(ctx, biz, ids)
+	if err != nil {
+		res = make(map[uint64]domain.Interactive, len(ids))
+	}
+	var missing []uint64
+	for _, id := range ids {
+		if _, ok := res[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		inters, err := r.dao.GetByIDs(ctx, biz, missing)
+		if err != nil {
+			return nil, err
+		}
+		// 数据库里没有记录的资源计数全为 0，也要回填，否则还没落库的增量会漏掉
+		cnts := make(map[uint64]domain.Interactive, len(missing))
+		for _, id := range missing {
+			cnts[id] = domain.Interactive{}
+		}
+		for _, inter := range inters {
+			cnts[inter.BizID] = domain.Interactive{
+				LikeCnt:    inter.LikeCnt,
+				ReadCnt:    inter.ReadCnt,
+				CollectCnt: inter.CollectCnt,
+			}
+		}
+
+		warmed, err := r.cache.BatchWarmInteractive(ctx, biz, cnts)
+		if err != nil {
+			// 只是少了还没落库的那部分，不影响使用
+			log.Printf("回写缓存失败:%s", err)
+			warmed = cnts
+		}
+		for id, inter := range warmed {
+			res[id] = inter
+		}
+	}
+
+	for id, inter := range res {
+		inter.Liked = liked[id]
+		res[id] = inter
+	}
+
+	return res, nil
+}
+
+// getCnt 缓存被淘汰后用数据库的值加上还没落库的增量回填
+func (r *InteractiveArtRepository) getCnt(ctx context.Context, biz string, bizId uint64) (domain.Interactive, error) {
 	inter, err := r.cache.GetInteractive(ctx, biz, bizId)
 	if err == nil {
 		return inter, nil
 	}
 
-	inter, err = r.dao.GetInteractiveByID(ctx, biz, bizId, uid)
+	inter, err = r.dao.FindInteractive(ctx, biz, bizId)
 	if err != nil {
 		return domain.Interactive{}, err
 	}
 
-	go func() {
-		er := r.cache.SetInteractive(context.Background(), biz, bizId, inter)
-		if er != nil {
-			log.Printf("回写缓存失败:%s", er)
+	warmed, err := r.cache.WarmInteractive(ctx, biz, bizId, inter)
+	if err != nil {
+		// 只是少了还没落库的那部分，不影响使用
+		log.Printf("回写缓存失败:%s", err)
+		return inter, nil
+	}
+
+	return warmed, nil
+}
+
+// GetByIDs 批量取计数，不查缓存也不含还没落库的增量，没有记录的资源不会出现在结果里
+func (r *InteractiveArtRepository) GetByIDs(ctx context.Context, biz string, ids []uint64) (map[uint64]domain.Interactive, error) {
+	inters, err := r.dao.GetByIDs(ctx, biz, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]domain.Interactive, len(inters))
+	for _, inter := range inters {
+		res[inter.BizID] = domain.Interactive{
+			LikeCnt:    inter.LikeCnt,
+			ReadCnt:    inter.ReadCnt,
+			CollectCnt: inter.CollectCnt,
+		}
+	}
+
+	return res, nil
+}
+
+// Flush 把最早变脏的 limit 个资源的增量落库，返回落库的资源数
+// 增量在落库成功之后才从缓存中扣掉，落库失败时原样留着，下一轮重试；
+// 落库成功但是没来得及扣掉时下一轮会重复落库，宁可多算也不丢
+func (r *InteractiveArtRepository) Flush(ctx context.Context, limit int) (int, error) {
+	items, err := r.cache.DirtyItems(ctx, limit)
+	if err != nil || len(items) == 0 {
+		return 0, err
+	}
+	deltas, err := r.cache.GetDeltas(ctx, items)
+	if err != nil {
+		return 0, err
+	}
+
+	type group struct {
+		items  []cache.DirtyItem
+		deltas []domain.Interactive
+	}
+	groups := make(map[string]*group)
+	for i, item := range items {
+		g, ok := groups[item.Biz]
+		if !ok {
+			g = &group{}
+			groups[item.Biz] = g
+		}
+		g.items = append(g.items, item)
+		g.deltas = append(g.deltas, deltas[i])
+	}
+
+	flushed := 0
+	var errs []error
+	for biz, g := range groups {
+		cnts := make(map[uint64]domain.Interactive, len(g.items))
+		for i, item := range g.items {
+			d := cnts[item.BizID]
+			d.ReadCnt += g.deltas[i].ReadCnt
+			d.LikeCnt += g.deltas[i].LikeCnt
+			cnts[item.BizID] = d
 		}
-	}()
 
-	return inter, nil
+		if err := r.dao.BatchIncrCnt(ctx, biz, cnts); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// 已经落库了，请求被取消也要扣掉
+		if err := r.cache.AckDeltas(context.WithoutCancel(ctx), g.items, g.deltas); err != nil {
+			// 这批增量下一轮会再落库一次，点赞数由对账修正
+			log.Printf("扣除已落库的计数增量失败:%s:biz:%s:size:%d", err, biz, len(g.items))
+			errs = append(errs, err)
+			continue
+		}
+		flushed += len(g.items)
+	}
+
+	return flushed, errors.Join(errs...)
+}
+
+// FlushLag 最早一个还没落库的增量已经等了多久
+func (r *InteractiveArtRepository) FlushLag(ctx context.Context) (time.Duration, error) {
+	since, err := r.cache.OldestDirty(ctx)
+	if err != nil || since.IsZero() {
+		return 0, err
+	}
+
+	return time.Since(since), nil
+}
+
+// Reconcile 对账计数表中 id 在 afterID 之后的 limit 行，返回这批最后一行的 id，没有更多数据时返回 0
+// 点赞数和收藏数按用户记录重新统计，不一致时以统计结果为准，阅读数没有明细，只校验缓存
+// 先统计再检查是否有增量没落库，有的跳过，等落库后的下一轮再对
+// 反过来的话，检查之后进来的点赞会算进统计结果，落库时又加一次
+func (r *InteractiveArtRepository) Reconcile(ctx context.Context, afterID uint64, limit int) (next uint64, dbFixed int, cacheFixed int, err error) {
+	rows, err := r.dao.ListInteractives(ctx, afterID, limit)
+	if err != nil || len(rows) == 0 {
+		return 0, 0, 0, err
+	}
+	next = rows[len(rows)-1].ID
+
+	byBiz := make(map[string][]dao.Interactive)
+	for _, row := range rows {
+		byBiz[row.Biz] = append(byBiz[row.Biz], row)
+	}
+
+	for biz, rows := range byBiz {
+		ids := make([]uint64, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row.BizID)
+		}
+
+		likes, err := r.dao.CountLikes(ctx, biz, ids)
+		if err != nil {
+			return next, dbFixed, cacheFixed, err
+		}
+		collects, err := r.dao.CountCollects(ctx, biz, ids)
+		if err != nil {
+			return next, dbFixed, cacheFixed, err
+		}
+		dirty, err := r.cache.Dirty(ctx, biz, ids)
+		if err != nil {
+			return next, dbFixed, cacheFixed, err
+		}
+		cached, err := r.cache.BatchGetInteractive(ctx, biz, ids)
+		if err != nil {
+			return next, dbFixed, cacheFixed, err
+		}
+
+		for _, row := range rows {
+			if dirty[row.BizID] {
+				continue
+			}
+
+			want := domain.Interactive{
+				ReadCnt:    row.ReadCnt,
+				LikeCnt:    likes[row.BizID],
+				CollectCnt: collects[row.BizID],
+			}
+			if row.LikeCnt != want.LikeCnt || row.CollectCnt != want.CollectCnt {
+				log.Printf("计数与明细不一致:biz:%s:id:%d:like:%d->%d:collect:%d->%d",
+					biz, row.BizID, row.LikeCnt, want.LikeCnt, row.CollectCnt, want.CollectCnt)
+				var ok bool
+				ok, err = r.dao.ResetCnt(ctx, row, want.LikeCnt, want.CollectCnt)
+				if err != nil {
+					return next, dbFixed, cacheFixed, err
+				}
+				if !ok {
+					// 统计期间计数变了，这一行留给下一轮
+					continue
+				}
+				dbFixed++
+			}
+
+			// 没有待落库的增量时缓存应该和数据库一致，不一致就删掉等下次读的时候回填
+			if c, ok := cached[row.BizID]; ok && c != want {
+				if err = r.cache.DelInteractive(ctx, biz, row.BizID); err != nil {
+					return next, dbFixed, cacheFixed, err
+				}
+				cacheFixed++
+			}
+		}
+	}
+
+	return next, dbFixed, cacheFixed, nil
 }
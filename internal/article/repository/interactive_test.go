@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/cache"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+const (
+	dirtyKey = "interactive:dirty"
+	member   = "article:1"
+	cntKey   = "interactive:article:1"
+	deltaKey = "interactive:delta:article:1"
+)
+
+// fakeInteractiveDAO 计数表放在内存里，只实现计数和对账用到的方法
+type fakeInteractiveDAO struct {
+	interactiveDAO
+	rows []*dao.Interactive
+	// likes 按点赞记录统计出来的点赞数
+	likes map[uint64]int64
+	// beforeIncr 落库之前调用，模拟落库期间进来的请求
+	beforeIncr func()
+	// resetMiss 模拟统计期间计数被别的请求改掉
+	resetMiss bool
+}
+
+func (f *fakeInteractiveDAO) row(bizId uint64) *dao.Interactive {
+	for _, r := range f.rows {
+		if r.BizID == bizId {
+			return r
+		}
+	}
+	r := &dao.Interactive{ID: uint64(len(f.rows) + 1), Biz: "article", BizID: bizId}
+	f.rows = append(f.rows, r)
+	return r
+}
+
+func (f *fakeInteractiveDAO) BatchIncrCnt(ctx context.Context, biz string, deltas map[uint64]domain.Interactive) error {
+	if f.beforeIncr != nil {
+		f.beforeIncr()
+	}
+	for id, d := range deltas {
+		r := f.row(id)
+		r.ReadCnt += d.ReadCnt
+		r.LikeCnt += d.LikeCnt
+	}
+	return nil
+}
+
+func (f *fakeInteractiveDAO) InsertLikeInfo(ctx context.Context, biz string, bizId, uid uint64) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeInteractiveDAO) Liked(ctx context.Context, biz string, bizId, uid uint64) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeInteractiveDAO) Collected(ctx context.Context, biz string, bizId, uid uint64) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeInteractiveDAO) FindInteractive(ctx context.Context, biz string, bizId uint64) (domain.Interactive, error) {
+	r := f.row(bizId)
+	return domain.Interactive{ReadCnt: r.ReadCnt, LikeCnt: r.LikeCnt, CollectCnt: r.CollectCnt}, nil
+}
+
+func (f *fakeInteractiveDAO) ListInteractives(ctx context.Context, afterID uint64, limit int) ([]dao.Interactive, error) {
+	var res []dao.Interactive
+	for _, r := range f.rows {
+		if r.ID > afterID && len(res) < limit {
+			res = append(res, *r)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeInteractiveDAO) CountLikes(ctx context.Context, biz string, ids []uint64) (map[uint64]int64, error) {
+	res := make(map[uint64]int64)
+	for _, id := range ids {
+		if cnt, ok := f.likes[id]; ok {
+			res[id] = cnt
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeInteractiveDAO) CountCollects(ctx context.Context, biz string, ids []uint64) (map[uint64]int64, error) {
+	return map[uint64]int64{}, nil
+}
+
+func (f *fakeInteractiveDAO) ResetCnt(ctx context.Context, row dao.Interactive, likeCnt, collectCnt int64) (bool, error) {
+	r := f.row(row.BizID)
+	if f.resetMiss || r.LikeCnt != row.LikeCnt || r.CollectCnt != row.CollectCnt {
+		return false, nil
+	}
+	r.LikeCnt, r.CollectCnt = likeCnt, collectCnt
+	return true, nil
+}
+
+func newInteractiveRepo(t *testing.T) (*InteractiveArtRepository, *fakeInteractiveDAO, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+
+	d := &fakeInteractiveDAO{}
+	return &InteractiveArtRepository{dao: d, cache: cache.NewInteractiveCache(client)}, d, mr
+}
+
+func TestInteractiveArtRepository_Flush(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deltas during flush", func(t *testing.T) {
+		repo, d, mr := newInteractiveRepo(t)
+		require.NoError(t, repo.IncrReadCnt(ctx, "article", 1))
+		require.NoError(t, repo.IncrReadCnt(ctx, "article", 1))
+		// 第一次变脏的时间
+		_, err := mr.ZAdd(dirtyKey, 1000, member)
+		require.NoError(t, err)
+		d.beforeIncr = func() {
+			require.NoError(t, repo.IncrReadCnt(ctx, "article", 1))
+		}
+
+		n, err := repo.Flush(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, int64(2), d.row(1).ReadCnt)
+		// 落库期间进来的增量留到下一轮，落库延迟仍然从第一次变脏算起
+		assert.Equal(t, "1", mr.HGet(deltaKey, "read_cnt"))
+		score, err := mr.ZScore(dirtyKey, member)
+		require.NoError(t, err)
+		assert.Equal(t, float64(1000), score)
+
+		d.beforeIncr = nil
+		n, err = repo.Flush(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, int64(3), d.row(1).ReadCnt)
+		assert.False(t, mr.Exists(deltaKey))
+		assert.False(t, mr.Exists(dirtyKey))
+	})
+
+	t.Run("failed ack flushes again", func(t *testing.T) {
+		repo, d, mr := newInteractiveRepo(t)
+		require.NoError(t, repo.IncrLikeCnt(ctx, "article", 1, 100))
+		d.beforeIncr = func() {
+			mr.SetError("LOADING")
+		}
+
+		// 落库成功但没扣掉增量
+		n, err := repo.Flush(ctx, 10)
+		assert.Error(t, err)
+		assert.Zero(t, n)
+		assert.Equal(t, int64(1), d.row(1).LikeCnt)
+
+		mr.SetError("")
+		d.beforeIncr = nil
+		n, err = repo.Flush(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		// 重复落库，宁可多算也不丢
+		assert.Equal(t, int64(2), d.row(1).LikeCnt)
+		assert.False(t, mr.Exists(dirtyKey))
+
+		// 点赞数由对账按明细修正
+		d.likes = map[uint64]int64{1: 1}
+		_, dbFixed, _, err := repo.Reconcile(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, dbFixed)
+		assert.Equal(t, int64(1), d.row(1).LikeCnt)
+	})
+}
+
+func TestInteractiveArtRepository_WarmAfterEviction(t *testing.T) {
+	ctx := context.Background()
+	repo, d, mr := newInteractiveRepo(t)
+	r := d.row(1)
+	r.ReadCnt, r.LikeCnt = 10, 5
+
+	// 计数没有缓存，增量只记在待落库的部分
+	require.NoError(t, repo.IncrReadCnt(ctx, "article", 1))
+	inter, err := repo.GetInteractive(ctx, "article", 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), inter.ReadCnt)
+	assert.Equal(t, int64(5), inter.LikeCnt)
+
+	require.NoError(t, repo.IncrReadCnt(ctx, "article", 1))
+	mr.Del(cntKey)
+	inter, err = repo.GetInteractive(ctx, "article", 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), inter.ReadCnt)
+
+	// 落库之后再被淘汰，不会重复加上增量
+	_, err = repo.Flush(ctx, 10)
+	require.NoError(t, err)
+	mr.Del(cntKey)
+	inter, err = repo.GetInteractive(ctx, "article", 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), inter.ReadCnt)
+}
+
+func TestInteractiveArtRepository_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	repo, d, mr := newInteractiveRepo(t)
+	d.row(1).LikeCnt = 3
+	d.row(2).LikeCnt = 9
+	d.likes = map[uint64]int64{1: 2, 2: 1}
+	// 缓存里是错误的计数
+	_, err := repo.GetInteractive(ctx, "article", 1, 100)
+	require.NoError(t, err)
+	// 还有增量没落库的跳过
+	require.NoError(t, repo.IncrReadCnt(ctx, "article", 2))
+
+	d.resetMiss = true
+	next, dbFixed, cacheFixed, err := repo.Reconcile(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), next)
+	assert.Zero(t, dbFixed)
+	assert.Zero(t, cacheFixed)
+	assert.Equal(t, int64(3), d.row(1).LikeCnt)
+	// 数据库没改成功，缓存也不动
+	assert.True(t, mr.Exists(cntKey))
+
+	d.resetMiss = false
+	_, dbFixed, cacheFixed, err = repo.Reconcile(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dbFixed)
+	assert.Equal(t, 1, cacheFixed)
+	assert.Equal(t, int64(2), d.row(1).LikeCnt)
+	assert.Equal(t, int64(9), d.row(2).LikeCnt)
+	assert.False(t, mr.Exists(cntKey))
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/cache"
+)
+
+type RankingRepository struct {
+	cache *cache.RankingCache
+	local *cache.LocalRankingCache
+}
+
+func NewRankingRepository(cache *cache.RankingCache, local *cache.LocalRankingCache) *RankingRepository {
+	return &RankingRepository{
+		cache: cache,
+		local: local,
+	}
+}
+
+func (r *RankingRepository) ReplaceTopN(ctx context.Context, arts []domain.Article) error {
+	r.local.Set(arts)
+	return r.cache.Set(ctx, arts)
+}
+
+// GetTopN 本地缓存 -> Redis -> 过期的本地缓存
+func (r *RankingRepository) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	arts, err := r.local.Get()
+	if err == nil {
+		return arts, nil
+	}
+
+	arts, err = r.cache.Get(ctx)
+	if err != nil {
+		if res, er := r.local.ForceGet(); er == nil {
+			return res, nil
+		}
+		return nil, err
+	}
+	r.local.Set(arts)
+
+	return arts, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/cache"
+)
+
+var ErrRenderNotFound = errors.New("rendered article not found")
+
+type RenderRepository struct {
+	cache *cache.RenderCache
+}
+
+func NewRenderRepository(cache *cache.RenderCache) *RenderRepository {
+	return &RenderRepository{
+		cache: cache,
+	}
+}
+
+func (r *RenderRepository) Get(ctx context.Context, aid uint64, version int64) (domain.Rendered, error) {
+	res, err := r.cache.Get(ctx, aid, version)
+	if errors.Is(err, redis.Nil) {
+		return domain.Rendered{}, ErrRenderNotFound
+	}
+
+	return res, err
+}
+
+func (r *RenderRepository) Set(ctx context.Context, rendered domain.Rendered) error {
+	return r.cache.Set(ctx, rendered)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+var ErrReviewNotFound = dao.ErrReviewNotFound
+
+type ReviewRepository struct {
+	dao *dao.ReviewDao
+}
+
+func NewReviewRepository(dao *dao.ReviewDao) *ReviewRepository {
+	return &ReviewRepository{
+		dao: dao,
+	}
+}
+
+func (r *ReviewRepository) Submit(ctx context.Context, review domain.Review) error {
+	var tags string
+	if review.Tags != nil {
+		ids := make([]uint64, 0, len(review.Tags))
+		for _, t := range review.Tags {
+			ids = append(ids, t.ID)
+		}
+		data, err := json.Marshal(ids)
+		if err != nil {
+			return err
+		}
+		tags = string(data)
+	}
+
+	return r.dao.Submit(ctx, dao.ArticleReview{
+		ArticleID: review.ArticleID,
+		AuthorID:  review.AuthorID,
+		Title:     review.Title,
+		Content:   review.Content,
+		Tags:      tags,
+		Reasons:   strings.Join(review.Reasons, "\n"),
+	})
+}
+
+func (r *ReviewRepository) ListPending(ctx context.Context, offset, limit int) ([]domain.Review, error) {
+	res, err := r.dao.ListPending(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]domain.Review, 0, len(res))
+	for _, review := range res {
+		reviews = append(reviews, r.toDomain(review))
+	}
+
+	return reviews, nil
+}
+
+func (r *ReviewRepository) FindPending(ctx context.Context, aid uint64) (domain.Review, error) {
+	res, err := r.dao.FindPending(ctx, aid)
+	if err != nil {
+		return domain.Review{}, err
+	}
+
+	return r.toDomain(res), nil
+}
+
+func (r *ReviewRepository) Resolve(ctx context.Context, aid uint64, status domain.ReviewStatus, reviewer uint64, note string) error {
+	return r.dao.Resolve(ctx, aid, status.ToUint8(), reviewer, note)
+}
+
+func (r *ReviewRepository) Cancel(ctx context.Context, aid uint64) error {
+	return r.dao.Cancel(ctx, aid)
+}
+
+func (r *ReviewRepository) toDomain(review dao.ArticleReview) domain.Review {
+	var reasons []string
+	if review.Reasons != "" {
+		reasons = strings.Split(review.Reasons, "\n")
+	}
+	var tags []domain.Tag
+	if review.Tags != "" {
+		var ids []uint64
+		_ = json.Unmarshal([]byte(review.Tags), &ids)
+		tags = make([]domain.Tag, 0, len(ids))
+		for _, id := range ids {
+			tags = append(tags, domain.Tag{ID: id})
+		}
+	}
+
+	return domain.Review{
+		ArticleID: review.ArticleID,
+		AuthorID:  review.AuthorID,
+		Title:     review.Title,
+		Content:   review.Content,
+		Tags:      tags,
+		Status:    domain.ReviewStatus(review.Status),
+		Reasons:   reasons,
+		Reviewer:  review.Reviewer,
+		Note:      review.Note,
+		Ctime:     time.UnixMilli(review.Ctime),
+		Utime:     time.UnixMilli(review.Utime),
+	}
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/cache"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+var (
+	ErrRevisionNotFound = dao.ErrRevisionNotFound
+	ErrAutosaveNotFound = errors.New("autosave not found")
+)
+
+type RevisionRepository struct {
+	dao   *dao.RevisionDao
+	cache *cache.AutosaveCache
+}
+
+func NewRevisionRepository(dao *dao.RevisionDao, cache *cache.AutosaveCache) *RevisionRepository {
+	return &RevisionRepository{
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+// Create 在最新版本之后追加一个版本，内容和标题都没变时不追加，返回 false
+// 同一篇文章并发保存时版本号冲突，重新读最新版本再试
+func (r *RevisionRepository) Create(ctx context.Context, art domain.Article, kind domain.RevisionKind) (bool, error) {
+	const retries = 3
+
+	var err error
+	for i := 0; i < retries; i++ {
+		var created bool
+		created, err = r.create(ctx, art, kind)
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return created, err
+		}
+	}
+
+	return false, err
+}
+
+func (r *RevisionRepository) create(ctx context.Context, art domain.Article, kind domain.RevisionKind) (bool, error) {
+	prev, err := r.dao.Latest(ctx, art.ID)
+	if err != nil && !errors.Is(err, dao.ErrRevisionNotFound) {
+		return false, err
+	}
+	// 发布和恢复即使内容没变也要记录
+	if err == nil && kind == domain.RevisionKindSave && prev.Title == art.Title && prev.Content == art.Content {
+		return false, nil
+	}
+
+	diff, err := domain.DiffContent(prev.Content, art.Content)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.dao.Insert(ctx, dao.ArticleRevision{
+		ArticleID: art.ID,
+		Version:   prev.Version + 1,
+		AuthorID:  art.Author.Id,
+		Title:     art.Title,
+		Content:   art.Content,
+		Kind:      kind.ToUint8(),
+		Diff:      diff.Unified,
+		Additions: diff.Additions,
+		Deletions: diff.Deletions,
+	})
+
+	return err == nil, err
+}
+
+func (r *RevisionRepository) List(ctx context.Context, aid uint64, offset, limit int) ([]domain.Revision, error) {
+	revs, err := r.dao.List(ctx, aid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Revision, 0, len(revs))
+	for _, rev := range revs {
+		res = append(res, r.toDomain(rev))
+	}
+
+	return res, nil
+}
+
+func (r *RevisionRepository) FindByVersion(ctx context.Context, aid uint64, version int64) (domain.Revision, error) {
+	rev, err := r.dao.FindByVersion(ctx, aid, version)
+	if err != nil {
+		return domain.Revision{}, err
+	}
+
+	return r.toDomain(rev), nil
+}
+
+func (r *RevisionRepository) SetAutosave(ctx context.Context, as domain.Autosave) error {
+	return r.cache.Set(ctx, as)
+}
+
+func (r *RevisionRepository) GetAutosave(ctx context.Context, aid uint64) (domain.Autosave, error) {
+	as, err := r.cache.Get(ctx, aid)
+	if errors.Is(err, redis.Nil) {
+		return domain.Autosave{}, ErrAutosaveNotFound
+	}
+
+	return as, err
+}
+
+func (r *RevisionRepository) DelAutosave(ctx context.Context, aid uint64) error {
+	return r.cache.Del(ctx, aid)
+}
+
+func (r *RevisionRepository) toDomain(rev dao.ArticleRevision) domain.Revision {
+	return domain.Revision{
+		ArticleID: rev.ArticleID,
+		Version:   rev.Version,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		Author: domain.Author{
+			Id: rev.AuthorID,
+		},
+		Kind: domain.RevisionKind(rev.Kind),
+		Diff: domain.ContentDiff{
+			Unified:   rev.Diff,
+			Additions: rev.Additions,
+			Deletions: rev.Deletions,
+		},
+		Ctime: time.UnixMilli(rev.Ctime),
+	}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+var ErrSolutionNotFound = dao.ErrSolutionNotFound
+
+type SolutionRepository struct {
+	dao *dao.SolutionDao
+}
+
+func NewSolutionRepository(dao *dao.SolutionDao) *SolutionRepository {
+	return &SolutionRepository{
+		dao: dao,
+	}
+}
+
+func (r *SolutionRepository) Attach(ctx context.Context, sol domain.Solution) error {
+	return r.dao.Upsert(ctx, dao.Solution{
+		ArticleID: sol.ArticleID,
+		ProblemID: sol.ProblemID,
+		AuthorID:  sol.Author.Id,
+		Languages: strings.Join(sol.Languages, ","),
+	})
+}
+
+func (r *SolutionRepository) Detach(ctx context.Context, aid, authorId uint64) error {
+	return r.dao.Delete(ctx, aid, authorId)
+}
+
+func (r *SolutionRepository) FindByArticle(ctx context.Context, aid uint64) (domain.Solution, error) {
+	sol, err := r.dao.FindByArticle(ctx, aid)
+	if err != nil {
+		return domain.Solution{}, err
+	}
+
+	return domain.Solution{
+		ArticleID: sol.ArticleID,
+		ProblemID: sol.ProblemID,
+		Author: domain.Author{
+			Id: sol.AuthorID,
+		},
+		Languages: splitLanguages(sol.Languages),
+		Ctime:     time.UnixMilli(sol.Ctime),
+	}, nil
+}
+
+func (r *SolutionRepository) List(ctx context.Context, q domain.SolutionQuery) ([]domain.Solution, error) {
+	rows, err := r.dao.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Solution, 0, len(rows))
+	for _, row := range rows {
+		art := domain.Article{Content: row.Content}
+		res = append(res, domain.Solution{
+			ArticleID: row.ArticleID,
+			ProblemID: row.ProblemID,
+			Title:     row.Title,
+			Abstract:  art.Abstract(),
+			Author: domain.Author{
+				Id: row.AuthorID,
+			},
+			Languages: splitLanguages(row.Languages),
+			Inter: domain.Interactive{
+				LikeCnt: row.LikeCnt,
+				ReadCnt: row.ReadCnt,
+			},
+			Ctime: time.UnixMilli(row.Ctime),
+		})
+	}
+
+	return res, nil
+}
+
+func (r *SolutionRepository) Reveal(ctx context.Context, uid, pid uint64) error {
+	return r.dao.Reveal(ctx, uid, pid)
+}
+
+func (r *SolutionRepository) Revealed(ctx context.Context, uid, pid uint64) (bool, error) {
+	return r.dao.Revealed(ctx, uid, pid)
+}
+
+func splitLanguages(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
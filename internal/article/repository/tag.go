@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+var ErrTagNotFound = errors.New("tag not found")
+
+type TagRepository struct {
+	dao *dao.TagDao
+}
+
+func NewTagRepository(dao *dao.TagDao) *TagRepository {
+	return &TagRepository{
+		dao: dao,
+	}
+}
+
+func (r *TagRepository) FindByNames(ctx context.Context, names []string) ([]domain.Tag, error) {
+	tags, err := r.dao.FindByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toDomain(tags), nil
+}
+
+func (r *TagRepository) FindByName(ctx context.Context, name string) (domain.Tag, error) {
+	tag, err := r.dao.FindByName(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.Tag{}, ErrTagNotFound
+	}
+	if err != nil {
+		return domain.Tag{}, err
+	}
+
+	return domain.Tag{ID: tag.ID, Name: tag.Name}, nil
+}
+
+func (r *TagRepository) SetArticleTags(ctx context.Context, aid uint64, tags []domain.Tag, status domain.ArticleStatus) error {
+	ids := make([]uint64, 0, len(tags))
+	for _, t := range tags {
+		ids = append(ids, t.ID)
+	}
+
+	return r.dao.SetArticleTags(ctx, aid, ids, status.ToUint8())
+}
+
+func (r *TagRepository) SyncStatus(ctx context.Context, aid uint64, status domain.ArticleStatus) error {
+	return r.dao.SyncStatus(ctx, aid, status.ToUint8())
+}
+
+func (r *TagRepository) FindByArticle(ctx context.Context, aid uint64) ([]domain.Tag, error) {
+	tags, err := r.dao.FindByArticle(ctx, aid)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toDomain(tags), nil
+}
+
+// PubArticleIDs 标签下已发布的文章 id
+func (r *TagRepository) PubArticleIDs(ctx context.Context, tid uint64, offset, limit int) ([]uint64, error) {
+	return r.dao.ArticleIDs(ctx, tid, domain.ArticleStatusPublished.ToUint8(), offset, limit)
+}
+
+func (r *TagRepository) CountPub(ctx context.Context, tid uint64) (int64, error) {
+	return r.dao.CountArticles(ctx, tid, domain.ArticleStatusPublished.ToUint8())
+}
+
+// PubCounts 每个标签下已发布的文章数
+func (r *TagRepository) PubCounts(ctx context.Context) ([]domain.TagCount, error) {
+	res, err := r.dao.CountByTag(ctx, domain.ArticleStatusPublished.ToUint8())
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]domain.TagCount, 0, len(res))
+	for _, c := range res {
+		counts = append(counts, domain.TagCount{
+			Tag:        domain.Tag{ID: c.TagID, Name: c.Name},
+			ArticleCnt: c.Cnt,
+		})
+	}
+
+	return counts, nil
+}
+
+func (r *TagRepository) toDomain(tags []dao.Tag) []domain.Tag {
+	res := make([]domain.Tag, 0, len(tags))
+	for _, t := range tags {
+		res = append(res, domain.Tag{ID: t.ID, Name: t.Name})
+	}
+
+	return res
+}
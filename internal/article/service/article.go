@@ -11,8 +11,12 @@ import (
 	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
 	"github.com/crazyfrankie/onlinejudge/internal/article/event"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service/moderation"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 )
 
+const maxReviewPage = 50
+
 type ArticleService interface {
 	// B 端接口
 	SaveDraft(ctx context.Context, art domain.Article) (uint64, error)
@@ -24,17 +28,30 @@ type ArticleService interface {
 	// C 端接口
 	PubDetail(ctx context.Context, uid uint64, artID string) (domain.Article, error)
 	PubList(ctx context.Context, offset, limit int) ([]domain.Article, error)
+
+	// 审核接口
+	ReviewList(ctx context.Context, offset, limit int) ([]domain.Review, error)
+	Review(ctx context.Context, aid, reviewer uint64, approve bool, note string) error
 }
 
 type articleService struct {
-	repo     *repository.ArticleRepository
-	producer event.Producer
+	repo       *repository.ArticleRepository
+	revRepo    *repository.RevisionRepository
+	tagRepo    *repository.TagRepository
+	reviewRepo *repository.ReviewRepository
+	render     RenderService
+	moderator  moderation.Moderator
 }
 
-func NewArticleService(repo *repository.ArticleRepository, producer event.Producer) ArticleService {
+func NewArticleService(repo *repository.ArticleRepository, revRepo *repository.RevisionRepository, tagRepo *repository.TagRepository,
+	reviewRepo *repository.ReviewRepository, render RenderService, moderator moderation.Moderator) ArticleService {
 	return &articleService{
-		repo:     repo,
-		producer: producer,
+		repo:       repo,
+		revRepo:    revRepo,
+		tagRepo:    tagRepo,
+		reviewRepo: reviewRepo,
+		render:     render,
+		moderator:  moderator,
 	}
 }
 
@@ -43,41 +60,207 @@ func NewArticleService(repo *repository.ArticleRepository, producer event.Produc
 // 如果 art 的 ID 小于等于0,说明是新建的草稿,创建草稿到制作库即代表保存
 func (svc *articleService) SaveDraft(ctx context.Context, art domain.Article) (uint64, error) {
 	art.Status = domain.ArticleStatusUnPublished
+	if err := svc.resolveTags(ctx, &art); err != nil {
+		return 0, err
+	}
+
 	if art.ID > 0 {
-		err := svc.repo.UpdateDraft(ctx, art)
+		if err := editDraft(ctx, svc.reviewRepo, svc.repo.UpdateDraft, art); err != nil {
+			return 0, err
+		}
+	} else {
+		id, err := svc.repo.CreateDraft(ctx, art)
 		if err != nil {
 			return 0, er.NewBizError(constant.ErrArticleInternalServer)
 		}
-
-		return art.ID, nil
+		art.ID = id
 	}
+	svc.recordRevision(ctx, art.ID, domain.RevisionKindSave)
 
-	id, err := svc.repo.CreateDraft(ctx, art)
-	if err != nil {
-		return 0, er.NewBizError(constant.ErrArticleInternalServer)
+	// 保存草稿不影响线上状态，标签沿用线上库里的状态
+	if art.Tags != nil {
+		status := domain.ArticleStatusUnPublished
+		if pub, err := svc.repo.GetPubByID(ctx, art.ID); err == nil {
+			status = pub.Status
+		}
+		if err := svc.tagRepo.SetArticleTags(ctx, art.ID, art.Tags, status); err != nil {
+			return 0, er.NewBizError(constant.ErrTagInternalServer)
+		}
 	}
 
-	return id, nil
+	return art.ID, nil
 }
 
 // Publish 接口先改制作库，然后同步到线上库
 // 如果 art 的 ID 大于0,说明是更新，先修改制作库，然后同步到线上库作为发表
 // 如果 art 的 ID 小于等于0,说明是新建，先去制作库创建，然后同步到线上库作为发表
+// 发布前先经过自动审核，存疑的版本进入人工审核，通过后才同步到线上库
 func (svc *articleService) Publish(ctx context.Context, art domain.Article) (uint64, error) {
-	id, err := svc.repo.Sync(ctx, art)
+	if err := svc.resolveTags(ctx, &art); err != nil {
+		return 0, err
+	}
+
+	res := svc.moderate(ctx, art)
+	switch res.Verdict {
+	case moderation.VerdictReject:
+		return 0, er.NewBizError(constant.ErrArticleRejected)
+	case moderation.VerdictReview:
+		return svc.submitReview(ctx, art, res.Reasons)
+	}
+
+	art.Status = domain.ArticleStatusPublished
+	id, err := svc.repo.Sync(ctx, art, publishEvents(art.Author.Id))
 	if err != nil {
 		return 0, er.NewBizError(constant.ErrArticleInternalServer)
 	}
+	svc.recordRevision(ctx, id, domain.RevisionKindPublish)
+	svc.warmRender(ctx, id)
+	// 之前提交过的版本不用再审
+	if err = svc.reviewRepo.Cancel(ctx, id); err != nil {
+		log.Printf("移出审核队列失败:%s:aid:%d", err.Error(), id)
+	}
+
+	if art.Tags != nil {
+		err = svc.tagRepo.SetArticleTags(ctx, id, art.Tags, domain.ArticleStatusPublished)
+	} else {
+		err = svc.tagRepo.SyncStatus(ctx, id, domain.ArticleStatusPublished)
+	}
+	if err != nil {
+		return 0, er.NewBizError(constant.ErrTagInternalServer)
+	}
 
 	return id, nil
 }
 
+// moderate 自动审核出错时转人工，不直接放行
+func (svc *articleService) moderate(ctx context.Context, art domain.Article) moderation.Result {
+	res, err := svc.moderator.Moderate(ctx, art)
+	if err != nil {
+		log.Printf("自动审核失败:%s:aid:%d", err.Error(), art.ID)
+		if res.Verdict < moderation.VerdictReview {
+			res.Verdict = moderation.VerdictReview
+		}
+		res.Reasons = append(res.Reasons, "自动审核失败")
+	}
+
+	return res
+}
+
+// submitReview 存疑的版本只保存到制作库，连同标签一起放进审核记录
+// 线上库和标签都不动，已经发布的文章在审核期间继续展示上一次发布的版本
+func (svc *articleService) submitReview(ctx context.Context, art domain.Article, reasons []string) (uint64, error) {
+	art.Status = domain.ArticleStatusPending
+	id := art.ID
+	var err error
+	if id > 0 {
+		err = svc.repo.UpdateDraft(ctx, art)
+	} else {
+		id, err = svc.repo.CreateDraft(ctx, art)
+	}
+	if err != nil {
+		return 0, er.NewBizError(constant.ErrArticleInternalServer)
+	}
+	svc.recordRevision(ctx, id, domain.RevisionKindPublish)
+
+	// 更新时空的标题和正文沿用原来的，以保存后制作库里的内容为准
+	draft, err := svc.repo.GetByID(ctx, id)
+	if err != nil {
+		return 0, er.NewBizError(constant.ErrArticleInternalServer)
+	}
+	err = svc.reviewRepo.Submit(ctx, domain.Review{
+		ArticleID: id,
+		AuthorID:  art.Author.Id,
+		Title:     draft.Title,
+		Content:   draft.Content,
+		Tags:      art.Tags,
+		Reasons:   reasons,
+	})
+	if err != nil {
+		return 0, er.NewBizError(constant.ErrReviewInternalServer)
+	}
+
+	return id, nil
+}
+
+// editDraft 作者修改已有的草稿都走这里，保存草稿和恢复历史版本都算
+// 草稿改过之后，审核中的版本已经过时了，一并撤回，需要重新发布
+func editDraft(ctx context.Context, reviewRepo *repository.ReviewRepository,
+	write func(ctx context.Context, art domain.Article) error, art domain.Article) error {
+	if err := write(ctx, art); err != nil {
+		return er.NewBizError(constant.ErrArticleInternalServer)
+	}
+	if err := reviewRepo.Cancel(ctx, art.ID); err != nil {
+		return er.NewBizError(constant.ErrReviewInternalServer)
+	}
+
+	return nil
+}
+
+func publishEvents(uid uint64) repository.PublishEvents {
+	return func(id uint64, ctime int64) []outbox.Event {
+		return []outbox.Event{event.NewPublishOutbox(event.PublishEvent{
+			Aid:   id,
+			Uid:   uid,
+			Ctime: ctime,
+		})}
+	}
+}
+
+// resolveTags 带了标签时先确认标签都存在，再保存文章
+func (svc *articleService) resolveTags(ctx context.Context, art *domain.Article) error {
+	if art.Tags == nil {
+		return nil
+	}
+
+	tags, err := resolveTags(ctx, svc.tagRepo, art.Tags)
+	if err != nil {
+		return err
+	}
+	art.Tags = tags
+
+	return nil
+}
+
+// warmRender 发布后按新版本渲染一次写入缓存，读者第一次打开时不用再渲染
+func (svc *articleService) warmRender(ctx context.Context, aid uint64) {
+	art, err := svc.repo.GetPubByID(ctx, aid)
+	if err != nil {
+		log.Printf("预渲染文章失败:%s:aid:%d", err.Error(), aid)
+		return
+	}
+
+	svc.render.Render(ctx, art)
+}
+
+// recordRevision 以保存后制作库里的内容生成版本，并丢弃自动保存的内容
+// 版本只是辅助功能，失败不影响保存
+func (svc *articleService) recordRevision(ctx context.Context, aid uint64, kind domain.RevisionKind) {
+	art, err := svc.repo.GetByID(ctx, aid)
+	if err == nil {
+		_, err = svc.revRepo.Create(ctx, art, kind)
+	}
+	if err != nil {
+		log.Printf("记录文章版本失败:%s:aid:%d", err.Error(), aid)
+	}
+
+	if err = svc.revRepo.DelAutosave(ctx, aid); err != nil {
+		log.Printf("删除自动保存失败:%s:aid:%d", err.Error(), aid)
+	}
+}
+
 func (svc *articleService) WithDraw(ctx context.Context, art domain.Article) error {
 	err := svc.repo.SyncStatus(ctx, art.ID, art.Author.Id, domain.ArticleStatusPrivate)
 	if err != nil {
 		return er.NewBizError(constant.ErrArticleInternalServer)
 	}
 
+	if err = svc.tagRepo.SyncStatus(ctx, art.ID, domain.ArticleStatusPrivate); err != nil {
+		return er.NewBizError(constant.ErrTagInternalServer)
+	}
+	if err = svc.reviewRepo.Cancel(ctx, art.ID); err != nil {
+		return er.NewBizError(constant.ErrReviewInternalServer)
+	}
+
 	return nil
 }
 
@@ -90,13 +273,21 @@ func (svc *articleService) List(ctx context.Context, offset, limit int) ([]domai
 	return res, nil
 }
 
+// PubList 线上库里还有撤回的文章，这里过滤掉，一页可能不满 limit 篇
 func (svc *articleService) PubList(ctx context.Context, offset, limit int) ([]domain.Article, error) {
 	res, err := svc.repo.GetPubListByID(ctx, offset, limit)
 	if err != nil {
 		return []domain.Article{}, er.NewBizError(constant.ErrArticleInternalServer)
 	}
 
-	return res, nil
+	arts := make([]domain.Article, 0, len(res))
+	for _, art := range res {
+		if !art.Status.NonPublished() {
+			arts = append(arts, art)
+		}
+	}
+
+	return arts, nil
 }
 
 func (svc *articleService) Detail(ctx context.Context, uid uint64, artID string) (domain.Article, error) {
@@ -129,13 +320,90 @@ func (svc *articleService) PubDetail(ctx context.Context, uid uint64, artID stri
 
 		return domain.Article{}, er.NewBizError(constant.ErrArticleInternalServer)
 	}
+	// 没发布的文章只有作者自己能看到
+	if art.Status.NonPublished() && art.Author.Id != uid {
+		return domain.Article{}, er.NewBizError(constant.ErrArticleNotFound)
+	}
 
-	go func() {
-		er := svc.producer.ProduceReadEvent(ctx, event.ReadEvent{Aid: uint64(id), Uid: uid})
-		if er != nil {
-			log.Printf("增加阅读计数失败:%s:aid:%d:uid:%d", er.Error(), id, uid)
-		}
-	}()
+	// 阅读事件写入发件箱，由 Relay 投递到 article_read，写入失败也不影响阅读
+	if err = svc.repo.AppendEvents(ctx, event.NewReadOutbox(event.ReadEvent{Aid: uint64(id), Uid: uid})); err != nil {
+		log.Printf("增加阅读计数失败:%s:aid:%d:uid:%d", err.Error(), id, uid)
+	}
 
 	return art, nil
 }
+
+func (svc *articleService) ReviewList(ctx context.Context, offset, limit int) ([]domain.Review, error) {
+	if limit <= 0 || limit > maxReviewPage {
+		limit = maxReviewPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	res, err := svc.reviewRepo.ListPending(ctx, offset, limit)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrReviewInternalServer)
+	}
+
+	return res, nil
+}
+
+// Review 审核通过时按发布处理：改状态、发事件、预渲染；拒绝时只改状态，作者修改后可以重新发布
+// 先改文章再结束审核记录，中途出错时记录还在队列里，可以重新审核
+func (svc *articleService) Review(ctx context.Context, aid, reviewer uint64, approve bool, note string) error {
+	review, err := svc.reviewRepo.FindPending(ctx, aid)
+	if err != nil {
+		if errors.Is(err, repository.ErrReviewNotFound) {
+			return er.NewBizError(constant.ErrReviewNotFound)
+		}
+		return er.NewBizError(constant.ErrReviewInternalServer)
+	}
+
+	reviewStatus := domain.ReviewStatusRejected
+	if approve {
+		reviewStatus = domain.ReviewStatusApproved
+		// 发布审核记录里的版本，作者之后又改了草稿时审核记录已经被撤回，走不到这里
+		_, err = svc.repo.Sync(ctx, domain.Article{
+			ID:      aid,
+			Title:   review.Title,
+			Content: review.Content,
+			Author:  domain.Author{Id: review.AuthorID},
+			Status:  domain.ArticleStatusPublished,
+		}, publishEvents(review.AuthorID))
+		if err != nil {
+			return er.NewBizError(constant.ErrArticleInternalServer)
+		}
+		if review.Tags != nil {
+			err = svc.tagRepo.SetArticleTags(ctx, aid, review.Tags, domain.ArticleStatusPublished)
+		} else {
+			err = svc.tagRepo.SyncStatus(ctx, aid, domain.ArticleStatusPublished)
+		}
+		if err != nil {
+			return er.NewBizError(constant.ErrTagInternalServer)
+		}
+	} else {
+		// 只标记制作库，线上库里之前发布的版本不受影响
+		err = svc.repo.UpdateDraft(ctx, domain.Article{
+			ID:     aid,
+			Author: domain.Author{Id: review.AuthorID},
+			Status: domain.ArticleStatusRejected,
+		})
+		if err != nil {
+			return er.NewBizError(constant.ErrArticleInternalServer)
+		}
+	}
+
+	err = svc.reviewRepo.Resolve(ctx, aid, reviewStatus, reviewer, note)
+	if err != nil {
+		if errors.Is(err, repository.ErrReviewNotFound) {
+			return er.NewBizError(constant.ErrReviewNotFound)
+		}
+		return er.NewBizError(constant.ErrReviewInternalServer)
+	}
+	if approve {
+		svc.warmRender(ctx, aid)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+	pmrepo "github.com/crazyfrankie/onlinejudge/internal/problem/repository"
+)
+
+const (
+	maxFolderNameLen = 64
+	maxFolderDescLen = 256
+	maxCollectPage   = 50
+)
+
+type CollectService interface {
+	CreateFolder(ctx context.Context, f domain.CollectFolder) (uint64, error)
+	// Folders 第一项总是默认收藏夹
+	Folders(ctx context.Context, uid uint64) ([]domain.CollectFolder, error)
+	Items(ctx context.Context, uid, fid uint64, offset, limit int) ([]domain.CollectItem, error)
+	// Collect 已经收藏过时移动到 fid 收藏夹
+	Collect(ctx context.Context, uid uint64, biz string, bizId, fid uint64) error
+	Uncollect(ctx context.Context, uid uint64, biz string, bizId uint64) error
+}
+
+type collectService struct {
+	repo      *repository.CollectRepository
+	interRepo *repository.InteractiveArtRepository
+	artRepo   *repository.ArticleRepository
+	pmRepo    pmrepo.ProblemRepository
+}
+
+func NewCollectService(repo *repository.CollectRepository, interRepo *repository.InteractiveArtRepository, artRepo *repository.ArticleRepository, pmRepo pmrepo.ProblemRepository) CollectService {
+	return &collectService{
+		repo:      repo,
+		interRepo: interRepo,
+		artRepo:   artRepo,
+		pmRepo:    pmRepo,
+	}
+}
+
+func (svc *collectService) CreateFolder(ctx context.Context, f domain.CollectFolder) (uint64, error) {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" || utf8.RuneCountInString(f.Name) > maxFolderNameLen ||
+		utf8.RuneCountInString(f.Description) > maxFolderDescLen {
+		return 0, er.NewBizError(constant.ErrCollectInvalidParams)
+	}
+
+	id, err := svc.repo.CreateFolder(ctx, f)
+	if errors.Is(err, repository.ErrFolderExists) {
+		return 0, er.NewBizError(constant.ErrCollectFolderExists)
+	}
+	if err != nil {
+		return 0, er.NewBizError(constant.ErrCollectInternalServer)
+	}
+
+	return id, nil
+}
+
+func (svc *collectService) Folders(ctx context.Context, uid uint64) ([]domain.CollectFolder, error) {
+	res, err := svc.repo.ListFolders(ctx, uid)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrCollectInternalServer)
+	}
+
+	return res, nil
+}
+
+func (svc *collectService) Items(ctx context.Context, uid, fid uint64, offset, limit int) ([]domain.CollectItem, error) {
+	if err := svc.checkFolder(ctx, uid, fid); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxCollectPage {
+		limit = maxCollectPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := svc.repo.ListItems(ctx, uid, fid, offset, limit)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrCollectInternalServer)
+	}
+
+	// 资源被删除或下线时标题留空，收藏本身保留
+	titles := svc.titles(ctx, items)
+	for i := range items {
+		items[i].Title = titles[items[i].Biz][items[i].BizID]
+	}
+
+	return items, nil
+}
+
+func (svc *collectService) Collect(ctx context.Context, uid uint64, biz string, bizId, fid uint64) error {
+	if _, ok := bizs[biz]; !ok || bizId == 0 {
+		return er.NewBizError(constant.ErrCollectInvalidParams)
+	}
+	if err := svc.checkFolder(ctx, uid, fid); err != nil {
+		return err
+	}
+	if _, err := svc.title(ctx, biz, bizId); err != nil {
+		return er.NewBizError(constant.ErrCollectTargetNotFound)
+	}
+
+	if err := svc.interRepo.IncrCollectCnt(ctx, biz, bizId, uid, fid); err != nil {
+		return er.NewBizError(constant.ErrCollectInternalServer)
+	}
+
+	return nil
+}
+
+func (svc *collectService) Uncollect(ctx context.Context, uid uint64, biz string, bizId uint64) error {
+	err := svc.interRepo.DecrCollectCnt(ctx, biz, bizId, uid)
+	if errors.Is(err, repository.ErrNoUserCollect) {
+		return er.NewBizError(constant.ErrCollectNotCollected)
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrCollectInternalServer)
+	}
+
+	return nil
+}
+
+// checkFolder 默认收藏夹不用校验
+func (svc *collectService) checkFolder(ctx context.Context, uid, fid uint64) error {
+	if fid == 0 {
+		return nil
+	}
+
+	_, err := svc.repo.FindFolder(ctx, uid, fid)
+	if errors.Is(err, repository.ErrFolderNotFound) {
+		return er.NewBizError(constant.ErrCollectFolderNotFound)
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrCollectInternalServer)
+	}
+
+	return nil
+}
+
+func (svc *collectService) title(ctx context.Context, biz string, bizId uint64) (string, error) {
+	return bizTitle(ctx, svc.artRepo, svc.pmRepo, biz, bizId)
+}
+
+// titles 按业务分组批量查询标题，查询失败的业务标题留空
+func (svc *collectService) titles(ctx context.Context, items []domain.CollectItem) map[string]map[uint64]string {
+	ids := make(map[string][]uint64)
+	for _, item := range items {
+		ids[item.Biz] = append(ids[item.Biz], item.BizID)
+	}
+
+	res := make(map[string]map[uint64]string, len(ids))
+	if len(ids["article"]) > 0 {
+		arts, err := svc.artRepo.GetPubByIDs(ctx, ids["article"])
+		if err == nil {
+			m := make(map[uint64]string, len(arts))
+			for id, art := range arts {
+				m[id] = art.Title
+			}
+			res["article"] = m
+		}
+	}
+	if len(ids["problem"]) > 0 {
+		pms, err := svc.pmRepo.FindProblemsByIDs(ctx, ids["problem"])
+		if err == nil {
+			m := make(map[uint64]string, len(pms))
+			for _, pm := range pms {
+				m[pm.Id] = pm.Title
+			}
+			res["problem"] = m
+		}
+	}
+
+	return res
+}
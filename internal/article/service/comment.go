@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+	pmrepo "github.com/crazyfrankie/onlinejudge/internal/problem/repository"
+)
+
+const (
+	// CommentBiz 评论自身的点赞也走 InteractiveArtRepository
+	CommentBiz = "comment"
+
+	maxCommentLen  = 2000
+	maxCommentPage = 50
+)
+
+// bizs 允许评论、收藏的资源类型
+var bizs = map[string]struct{}{
+	"article": {},
+	"problem": {},
+}
+
+// ModerateAction 资源作者对评论的管理操作
+type ModerateAction string
+
+const (
+	ModerateHide   ModerateAction = "hide"
+	ModerateUnhide ModerateAction = "unhide"
+	ModerateDelete ModerateAction = "delete"
+)
+
+type CommentService interface {
+	Create(ctx context.Context, c domain.Comment) (uint64, error)
+	Edit(ctx context.Context, uid, id uint64, content string) error
+	Delete(ctx context.Context, uid, id uint64) error
+	Moderate(ctx context.Context, uid, id uint64, action ModerateAction) error
+	// List 顶级评论，返回下一页的游标，为 0 表示没有更多
+	List(ctx context.Context, uid uint64, biz string, bizId, cursor uint64, limit int) ([]domain.Comment, uint64, error)
+	Replies(ctx context.Context, uid, rootId, cursor uint64, limit int) ([]domain.Comment, uint64, error)
+	Like(ctx context.Context, uid, id uint64, like bool) error
+}
+
+type commentService struct {
+	repo      *repository.CommentRepository
+	artRepo   *repository.ArticleRepository
+	interRepo *repository.InteractiveArtRepository
+	pmRepo    pmrepo.ProblemRepository
+}
+
+func NewCommentService(repo *repository.CommentRepository, artRepo *repository.ArticleRepository, interRepo *repository.InteractiveArtRepository, pmRepo pmrepo.ProblemRepository) CommentService {
+	return &commentService{
+		repo:      repo,
+		artRepo:   artRepo,
+		interRepo: interRepo,
+		pmRepo:    pmRepo,
+	}
+}
+
+func (svc *commentService) Create(ctx context.Context, c domain.Comment) (uint64, error) {
+	if _, ok := bizs[c.Biz]; !ok || c.BizID == 0 || !validContent(c.Content) {
+		return 0, er.NewBizError(constant.ErrCommentInvalidParams)
+	}
+
+	// 回复挂到父评论所在的顶级评论下
+	if c.PID > 0 {
+		parent, err := svc.find(ctx, c.PID)
+		if err != nil {
+			return 0, err
+		}
+		if parent.Biz != c.Biz || parent.BizID != c.BizID || !parent.Status.Visible() {
+			return 0, er.NewBizError(constant.ErrCommentInvalidParams)
+		}
+		c.RootID = parent.RootID
+		if c.RootID == 0 {
+			c.RootID = parent.ID
+		}
+	} else if _, err := bizTitle(ctx, svc.artRepo, svc.pmRepo, c.Biz, c.BizID); err != nil {
+		// 回复的资源已经在父评论上校验过，顶级评论才需要确认资源存在
+		return 0, er.NewBizError(constant.ErrCommentTargetNotFound)
+	}
+	c.Status = domain.CommentStatusNormal
+
+	id, err := svc.repo.Create(ctx, c)
+	if err != nil {
+		return 0, er.NewBizError(constant.ErrCommentInternalServer)
+	}
+
+	return id, nil
+}
+
+func (svc *commentService) Edit(ctx context.Context, uid, id uint64, content string) error {
+	if !validContent(content) {
+		return er.NewBizError(constant.ErrCommentInvalidParams)
+	}
+
+	err := svc.repo.UpdateContent(ctx, id, uid, content)
+	if errors.Is(err, repository.ErrCommentNotFound) {
+		return er.NewBizError(constant.ErrCommentNotFound)
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrCommentInternalServer)
+	}
+
+	return nil
+}
+
+// Delete 评论者本人或资源作者都可以删除，软删除保证楼中楼的结构不被破坏
+func (svc *commentService) Delete(ctx context.Context, uid, id uint64) error {
+	c, err := svc.find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Commentator.Id != uid && !svc.isOwner(ctx, uid, c) {
+		return er.NewBizError(constant.ErrCommentForbidden)
+	}
+
+	return svc.updateStatus(ctx, id, domain.CommentStatusDeleted)
+}
+
+func (svc *commentService) Moderate(ctx context.Context, uid, id uint64, action ModerateAction) error {
+	c, err := svc.find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !svc.isOwner(ctx, uid, c) {
+		return er.NewBizError(constant.ErrCommentForbidden)
+	}
+	if c.Status == domain.CommentStatusDeleted {
+		return er.NewBizError(constant.ErrCommentNotFound)
+	}
+
+	switch action {
+	case ModerateHide:
+		return svc.updateStatus(ctx, id, domain.CommentStatusHidden)
+	case ModerateUnhide:
+		return svc.updateStatus(ctx, id, domain.CommentStatusNormal)
+	case ModerateDelete:
+		return svc.updateStatus(ctx, id, domain.CommentStatusDeleted)
+	}
+
+	return er.NewBizError(constant.ErrCommentInvalidParams)
+}
+
+func (svc *commentService) List(ctx context.Context, uid uint64, biz string, bizId, cursor uint64, limit int) ([]domain.Comment, uint64, error) {
+	limit = pageSize(limit)
+	cs, err := svc.repo.FindByBiz(ctx, biz, bizId, cursor, limit)
+	if err != nil {
+		return nil, 0, er.NewBizError(constant.ErrCommentInternalServer)
+	}
+
+	return svc.fill(ctx, uid, cs, limit)
+}
+
+func (svc *commentService) Replies(ctx context.Context, uid, rootId, cursor uint64, limit int) ([]domain.Comment, uint64, error) {
+	limit = pageSize(limit)
+	cs, err := svc.repo.FindReplies(ctx, rootId, cursor, limit)
+	if err != nil {
+		return nil, 0, er.NewBizError(constant.ErrCommentInternalServer)
+	}
+
+	return svc.fill(ctx, uid, cs, limit)
+}
+
+func (svc *commentService) Like(ctx context.Context, uid, id uint64, like bool) error {
+	c, err := svc.find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !c.Status.Visible() {
+		return er.NewBizError(constant.ErrCommentNotFound)
+	}
+
+	if like {
+		err = svc.interRepo.IncrLikeCnt(ctx, CommentBiz, id, uid)
+	} else {
+		err = svc.interRepo.DecrLikeCnt(ctx, CommentBiz, id, uid)
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrInteractiveInternalServer)
+	}
+
+	return nil
+}
+
+// fill 补上点赞信息，并抹掉已删除、已隐藏评论的内容，只保留占位
+func (svc *commentService) fill(ctx context.Context, uid uint64, cs []domain.Comment, limit int) ([]domain.Comment, uint64, error) {
+	ids := make([]uint64, 0, len(cs))
+	for i := range cs {
+		if !cs[i].Status.Visible() {
+			cs[i].Content = ""
+			continue
+		}
+		ids = append(ids, cs[i].ID)
+	}
+
+	// 点赞信息取不到时不影响评论本身
+	inters, _ := svc.interRepo.BatchGetInteractive(ctx, CommentBiz, ids, uid)
+	for i := range cs {
+		if inter, ok := inters[cs[i].ID]; ok {
+			cs[i].Inter = inter
+		}
+	}
+
+	var next uint64
+	if len(cs) == limit {
+		next = cs[len(cs)-1].ID
+	}
+
+	return cs, next, nil
+}
+
+func (svc *commentService) find(ctx context.Context, id uint64) (domain.Comment, error) {
+	c, err := svc.repo.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrCommentNotFound) {
+		return domain.Comment{}, er.NewBizError(constant.ErrCommentNotFound)
+	}
+	if err != nil {
+		return domain.Comment{}, er.NewBizError(constant.ErrCommentInternalServer)
+	}
+
+	return c, nil
+}
+
+func (svc *commentService) updateStatus(ctx context.Context, id uint64, status domain.CommentStatus) error {
+	if err := svc.repo.UpdateStatus(ctx, id, status); err != nil {
+		return er.NewBizError(constant.ErrCommentInternalServer)
+	}
+	return nil
+}
+
+// isOwner 资源作者可以管理自己资源下的评论，目前只有文章记录了作者
+func (svc *commentService) isOwner(ctx context.Context, uid uint64, c domain.Comment) bool {
+	if c.Biz != "article" {
+		return false
+	}
+
+	art, err := svc.artRepo.GetPubByID(ctx, c.BizID)
+	return err == nil && art.Author.Id == uid
+}
+
+// bizTitle 查询被评论、收藏的资源的标题，资源不存在时返回错误
+func bizTitle(ctx context.Context, artRepo *repository.ArticleRepository, pmRepo pmrepo.ProblemRepository, biz string, bizId uint64) (string, error) {
+	switch biz {
+	case "article":
+		art, err := artRepo.GetPubByID(ctx, bizId)
+		return art.Title, err
+	case "problem":
+		pm, err := pmRepo.FindProblemByID(ctx, bizId)
+		return pm.Title, err
+	}
+
+	return "", errors.New("unknown biz")
+}
+
+func validContent(content string) bool {
+	content = strings.TrimSpace(content)
+	return content != "" && utf8.RuneCountInString(content) <= maxCommentLen
+}
+
+func pageSize(limit int) int {
+	if limit <= 0 || limit > maxCommentPage {
+		return maxCommentPage
+	}
+	return limit
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+)
+
+const (
+	maxFeedPage = 50
+	// feedRebuildAuthors 重建收件箱时最多拉取的关注数，更早关注的作者等他们发新文章再推
+	feedRebuildAuthors = 100
+	// feedRebuildPerAuthor 重建收件箱或新关注时每个作者补充的文章数
+	feedRebuildPerAuthor = 20
+	// feedBigAuthors 读动态时最多拉取的大 V 发件箱数
+	feedBigAuthors = 100
+)
+
+// FeedService 关注动态，推拉结合
+// 普通作者发文时由 event.FeedConsumer 推到活跃粉丝的收件箱
+// 粉丝数超过 bigAuthor 的作者不推送，读的时候从他们的发件箱拉，再和收件箱合并
+type FeedService interface {
+	// Feed 返回 cursor 之前发布的文章，新的在前，cursor 为 0 表示从最新开始
+	// 返回的游标为 0 表示没有更多
+	Feed(ctx context.Context, uid uint64, cursor int64, limit int) ([]domain.Article, int64, error)
+}
+
+type feedService struct {
+	repo       *repository.FeedRepository
+	followRepo *repository.FollowRepository
+	artRepo    *repository.ArticleRepository
+	bigAuthor  int64
+}
+
+func NewFeedService(repo *repository.FeedRepository, followRepo *repository.FollowRepository, artRepo *repository.ArticleRepository, bigAuthor int64) FeedService {
+	return &feedService{
+		repo:       repo,
+		followRepo: followRepo,
+		artRepo:    artRepo,
+		bigAuthor:  bigAuthor,
+	}
+}
+
+func (svc *feedService) Feed(ctx context.Context, uid uint64, cursor int64, limit int) ([]domain.Article, int64, error) {
+	if limit <= 0 || limit > maxFeedPage {
+		limit = maxFeedPage
+	}
+	if cursor <= 0 {
+		cursor = math.MaxInt64
+	}
+
+	// 先标记活跃，这之后发布的文章会推到收件箱
+	if err := svc.repo.MarkActive(ctx, uid); err != nil {
+		log.Printf("标记动态活跃用户失败:%s:uid:%d", err.Error(), uid)
+	}
+	if err := svc.rebuild(ctx, uid); err != nil {
+		return nil, 0, er.NewBizError(constant.ErrFollowInternalServer)
+	}
+
+	items, err := svc.collect(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, 0, er.NewBizError(constant.ErrFollowInternalServer)
+	}
+	arts, err := svc.articles(ctx, items)
+	if err != nil {
+		return nil, 0, er.NewBizError(constant.ErrArticleInternalServer)
+	}
+
+	var next int64
+	if len(items) == limit {
+		next = items[len(items)-1].Ctime
+	}
+
+	return arts, next, nil
+}
+
+// rebuild 不活跃的用户收件箱已经过期，从关注的人的发件箱里拉最近的文章重新建立
+func (svc *feedService) rebuild(ctx context.Context, uid uint64) error {
+	ok, err := svc.repo.HasInbox(ctx, uid)
+	if err != nil || ok {
+		return err
+	}
+
+	follows, err := svc.followRepo.Followees(ctx, uid, 0, feedRebuildAuthors)
+	if err != nil || len(follows) == 0 {
+		return err
+	}
+
+	var items []domain.FeedItem
+	for _, f := range follows {
+		res, err := svc.repo.Outbox(ctx, f.Followee, math.MaxInt64, feedRebuildPerAuthor)
+		if err != nil {
+			return err
+		}
+		items = append(items, res...)
+	}
+
+	return svc.repo.Push(ctx, []uint64{uid}, items...)
+}
+
+// collect 合并收件箱和大 V 的发件箱，按发布时间倒序取前 limit 项
+func (svc *feedService) collect(ctx context.Context, uid uint64, cursor int64, limit int) ([]domain.FeedItem, error) {
+	items, err := svc.repo.Inbox(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	bigs, err := svc.followRepo.BigFollowees(ctx, uid, svc.bigAuthor, feedBigAuthors)
+	if err != nil {
+		return nil, err
+	}
+	for _, author := range bigs {
+		res, err := svc.repo.Outbox(ctx, author, cursor, limit)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, res...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Ctime > items[j].Ctime
+	})
+	res := make([]domain.FeedItem, 0, limit)
+	seen := make(map[uint64]struct{}, len(items))
+	for _, item := range items {
+		if _, ok := seen[item.ArticleID]; ok {
+			continue
+		}
+		seen[item.ArticleID] = struct{}{}
+		res = append(res, item)
+		if len(res) == limit {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// articles 撤回或删除的文章还留在收件箱里，取不到或者不是发布状态的直接跳过
+func (svc *feedService) articles(ctx context.Context, items []domain.FeedItem) ([]domain.Article, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ArticleID)
+	}
+	arts, err := svc.artRepo.GetPubByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按收件箱的顺序返回
+	res := make([]domain.Article, 0, len(items))
+	for _, item := range items {
+		art, ok := arts[item.ArticleID]
+		if ok && art.Status == domain.ArticleStatusPublished {
+			res = append(res, art)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+)
+
+const maxFollowPage = 50
+
+type FollowService interface {
+	Follow(ctx context.Context, follower, followee uint64) error
+	Unfollow(ctx context.Context, follower, followee uint64) error
+	// Stats viewer 为 0 时不查询是否已关注
+	Stats(ctx context.Context, viewer, uid uint64) (domain.FollowStats, error)
+	Followees(ctx context.Context, uid uint64, offset, limit int) ([]domain.Follow, error)
+	Followers(ctx context.Context, uid uint64, offset, limit int) ([]domain.Follow, error)
+}
+
+type followService struct {
+	repo     *repository.FollowRepository
+	feedRepo *repository.FeedRepository
+}
+
+func NewFollowService(repo *repository.FollowRepository, feedRepo *repository.FeedRepository) FollowService {
+	return &followService{
+		repo:     repo,
+		feedRepo: feedRepo,
+	}
+}
+
+// Follow 重复关注直接返回成功
+// 新关注时把作者最近的文章补进收件箱，收件箱还没建立时等第一次读动态再建
+func (svc *followService) Follow(ctx context.Context, follower, followee uint64) error {
+	if followee == 0 {
+		return er.NewBizError(constant.ErrFollowInvalidParams)
+	}
+	if follower == followee {
+		return er.NewBizError(constant.ErrFollowSelf)
+	}
+
+	created, err := svc.repo.Follow(ctx, follower, followee)
+	if err != nil {
+		return er.NewBizError(constant.ErrFollowInternalServer)
+	}
+	if !created {
+		return nil
+	}
+
+	if err = svc.backfill(ctx, follower, followee); err != nil {
+		log.Printf("补充关注动态失败:%s:uid:%d:author:%d", err.Error(), follower, followee)
+	}
+
+	return nil
+}
+
+func (svc *followService) backfill(ctx context.Context, uid, author uint64) error {
+	ok, err := svc.feedRepo.HasInbox(ctx, uid)
+	if err != nil || !ok {
+		return err
+	}
+
+	items, err := svc.feedRepo.Outbox(ctx, author, math.MaxInt64, feedRebuildPerAuthor)
+	if err != nil {
+		return err
+	}
+
+	return svc.feedRepo.Push(ctx, []uint64{uid}, items...)
+}
+
+func (svc *followService) Unfollow(ctx context.Context, follower, followee uint64) error {
+	if followee == 0 {
+		return er.NewBizError(constant.ErrFollowInvalidParams)
+	}
+
+	err := svc.repo.Unfollow(ctx, follower, followee)
+	if errors.Is(err, repository.ErrNotFollowed) {
+		return er.NewBizError(constant.ErrNotFollowed)
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrFollowInternalServer)
+	}
+
+	if err = svc.feedRepo.RemoveAuthor(ctx, follower, followee); err != nil {
+		log.Printf("清理关注动态失败:%s:uid:%d:author:%d", err.Error(), follower, followee)
+	}
+
+	return nil
+}
+
+func (svc *followService) Stats(ctx context.Context, viewer, uid uint64) (domain.FollowStats, error) {
+	if uid == 0 {
+		return domain.FollowStats{}, er.NewBizError(constant.ErrFollowInvalidParams)
+	}
+
+	var (
+		eg        errgroup.Group
+		stats     domain.FollowStats
+		following bool
+	)
+	eg.Go(func() error {
+		var err error
+		stats, err = svc.repo.Stats(ctx, uid)
+		return err
+	})
+	if viewer > 0 && viewer != uid {
+		eg.Go(func() error {
+			var err error
+			following, err = svc.repo.IsFollowing(ctx, viewer, uid)
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return domain.FollowStats{}, er.NewBizError(constant.ErrFollowInternalServer)
+	}
+	stats.Following = following
+
+	return stats, nil
+}
+
+func (svc *followService) Followees(ctx context.Context, uid uint64, offset, limit int) ([]domain.Follow, error) {
+	offset, limit = followPage(offset, limit)
+	res, err := svc.repo.Followees(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrFollowInternalServer)
+	}
+
+	return res, nil
+}
+
+func (svc *followService) Followers(ctx context.Context, uid uint64, offset, limit int) ([]domain.Follow, error) {
+	offset, limit = followPage(offset, limit)
+	res, err := svc.repo.Followers(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrFollowInternalServer)
+	}
+
+	return res, nil
+}
+
+func followPage(offset, limit int) (int, int) {
+	if limit <= 0 || limit > maxFollowPage {
+		limit = maxFollowPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return offset, limit
+}
@@ -0,0 +1,82 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+type hookReq struct {
+	ID       uint64 `json:"id"`
+	AuthorID uint64 `json:"author_id"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+}
+
+type hookResp struct {
+	// Verdict 取值 pass、review、reject
+	Verdict string   `json:"verdict"`
+	Reasons []string `json:"reasons"`
+}
+
+// HookModerator 把文章 POST 给外部审核服务，按返回的结论处理
+// 超时或者返回无法识别的内容都算出错，由调用方决定是否转人工
+type HookModerator struct {
+	url    string
+	client *http.Client
+}
+
+func NewHookModerator(url string, timeout time.Duration) *HookModerator {
+	if timeout <= 0 {
+		timeout = time.Second * 3
+	}
+
+	return &HookModerator{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *HookModerator) Moderate(ctx context.Context, art domain.Article) (Result, error) {
+	body, err := json.Marshal(hookReq{
+		ID:       art.ID,
+		AuthorID: art.Author.Id,
+		Title:    art.Title,
+		Content:  art.Content,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("moderation hook returned %s", resp.Status)
+	}
+
+	var res hookResp
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return Result{}, err
+	}
+	verdict, ok := ParseVerdict(res.Verdict)
+	if !ok {
+		return Result{}, fmt.Errorf("unknown moderation verdict %q", res.Verdict)
+	}
+
+	return Result{Verdict: verdict, Reasons: res.Reasons}, nil
+}
@@ -0,0 +1,96 @@
+/*
+文章内容审核
+发布前依次经过多个 Moderator：敏感词、链接和刷屏规则、可选的外部审核服务，
+取其中最严重的结论：通过直接发布，存疑进入人工审核队列，命中拒绝规则时不允许发布
+*/
+
+package moderation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+type Verdict uint8
+
+const (
+	VerdictPass Verdict = iota
+	VerdictReview
+	VerdictReject
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictPass:
+		return "pass"
+	case VerdictReview:
+		return "review"
+	case VerdictReject:
+		return "reject"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseVerdict 用于配置和外部审核服务的返回值
+func ParseVerdict(s string) (Verdict, bool) {
+	switch s {
+	case "pass":
+		return VerdictPass, true
+	case "review":
+		return VerdictReview, true
+	case "reject":
+		return VerdictReject, true
+	default:
+		return VerdictPass, false
+	}
+}
+
+type Result struct {
+	Verdict Verdict
+	// Reasons 命中的规则，给审核人员参考
+	Reasons []string
+}
+
+// merge 取更严重的结论，原因都保留
+func (r Result) merge(o Result) Result {
+	if o.Verdict > r.Verdict {
+		r.Verdict = o.Verdict
+	}
+	r.Reasons = append(r.Reasons, o.Reasons...)
+	return r
+}
+
+type Moderator interface {
+	Moderate(ctx context.Context, art domain.Article) (Result, error)
+}
+
+// Chain 依次执行，命中拒绝时不再继续
+// 某一个出错时继续执行后面的，返回已有的结论和所有错误，由调用方决定怎么处理
+type Chain []Moderator
+
+func NewChain(ms ...Moderator) Chain {
+	return ms
+}
+
+func (c Chain) Moderate(ctx context.Context, art domain.Article) (Result, error) {
+	var (
+		res  Result
+		errs []error
+	)
+	for _, m := range c {
+		r, err := m.Moderate(ctx, art)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		res = res.merge(r)
+		if res.Verdict == VerdictReject {
+			break
+		}
+	}
+
+	return res, errors.Join(errs...)
+}
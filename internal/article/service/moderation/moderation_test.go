@@ -0,0 +1,158 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+func TestWordFilter_Match(t *testing.T) {
+	f := NewWordFilter([]string{"中国", "国人", "中国人民", "人民", "敏感词", "Spam", "ass", "buy now"}, VerdictReview)
+
+	testCases := []struct {
+		name string
+		text string
+		hits []string
+	}{
+		{
+			name: "overlapping words",
+			text: "中国人民银行",
+			hits: []string{"中国", "国人", "中国人民", "人民"},
+		},
+		{
+			name: "fail transition",
+			text: "国中国人",
+			hits: []string{"中国", "国人"},
+		},
+		{
+			name: "separators ignored for cjk",
+			text: "这是 敏-感 词",
+			hits: []string{"敏感词"},
+		},
+		{
+			name: "latin words ignore case",
+			text: "SPAM, 敏感词 and spam",
+			hits: []string{"spam", "敏感词"},
+		},
+		{
+			name: "latin word inside other words",
+			text: "first class pass",
+			hits: nil,
+		},
+		{
+			name: "latin word split by spaces",
+			text: "S p A m",
+			hits: nil,
+		},
+		{
+			name: "latin phrase",
+			text: "Buy  now!",
+			hits: []string{"buy now"},
+		},
+		{
+			name: "latin word next to cjk",
+			text: "这是spam吗",
+			hits: []string{"spam"},
+		},
+		{
+			name: "no hit",
+			text: "good morning",
+			hits: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.hits, f.Match(tc.text))
+		})
+	}
+}
+
+func TestSpamFilter(t *testing.T) {
+	f := NewSpamFilter(SpamConfig{
+		MaxLinks:       2,
+		MaxRepeat:      2,
+		BlockedDomains: []string{"bad.com"},
+	})
+	normal := strings.Repeat("正常的正文内容，", 5)
+
+	testCases := []struct {
+		name    string
+		content string
+		verdict Verdict
+	}{
+		{
+			name:    "plain text",
+			content: normal,
+			verdict: VerdictPass,
+		},
+		{
+			name:    "blocked subdomain",
+			content: normal + "[x](https://www.Bad.com/a)",
+			verdict: VerdictReject,
+		},
+		{
+			name:    "similar domain not blocked",
+			content: normal + "https://notbad.com",
+			verdict: VerdictPass,
+		},
+		{
+			name:    "too many links",
+			content: normal + "https://a.com https://b.com https://c.com",
+			verdict: VerdictReview,
+		},
+		{
+			name:    "links only",
+			content: "看这里 https://a.com",
+			verdict: VerdictReview,
+		},
+		{
+			name:    "repeated lines",
+			content: normal + "\n加群领资料\n加群领资料\n加群领资料",
+			verdict: VerdictReview,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := f.Moderate(context.Background(), domain.Article{Title: "标题", Content: tc.content})
+			require.NoError(t, err)
+			assert.Equal(t, tc.verdict, res.Verdict, res.Reasons)
+		})
+	}
+}
+
+type moderatorFunc func(ctx context.Context, art domain.Article) (Result, error)
+
+func (f moderatorFunc) Moderate(ctx context.Context, art domain.Article) (Result, error) {
+	return f(ctx, art)
+}
+
+func TestChain(t *testing.T) {
+	review := moderatorFunc(func(ctx context.Context, art domain.Article) (Result, error) {
+		return Result{Verdict: VerdictReview, Reasons: []string{"review"}}, nil
+	})
+	reject := moderatorFunc(func(ctx context.Context, art domain.Article) (Result, error) {
+		return Result{Verdict: VerdictReject, Reasons: []string{"reject"}}, nil
+	})
+	failed := moderatorFunc(func(ctx context.Context, art domain.Article) (Result, error) {
+		return Result{}, errors.New("hook down")
+	})
+	called := false
+	after := moderatorFunc(func(ctx context.Context, art domain.Article) (Result, error) {
+		called = true
+		return Result{}, nil
+	})
+
+	res, err := NewChain(review, failed, reject, after).Moderate(context.Background(), domain.Article{})
+	assert.Error(t, err)
+	assert.Equal(t, VerdictReject, res.Verdict)
+	assert.Equal(t, []string{"review", "reject"}, res.Reasons)
+	assert.False(t, called)
+}
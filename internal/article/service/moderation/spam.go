@@ -0,0 +1,136 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+var linkRe = regexp.MustCompile(`https?://[^\s<>()\[\]"']+`)
+
+type SpamConfig struct {
+	// MaxLinks 链接数超过这个值进入人工审核，默认 10
+	MaxLinks int
+	// MaxRepeat 同一行内容重复超过这个次数算刷屏，默认 5
+	MaxRepeat int
+	// MinText 有链接时去掉链接后至少要有多少字，否则算纯广告，默认 20
+	MinText int
+	// BlockedDomains 链接指向这些域名或者它们的子域名时直接拒绝
+	BlockedDomains []string
+}
+
+// SpamFilter 链接和刷屏的简单规则，不依赖词典
+type SpamFilter struct {
+	cfg     SpamConfig
+	blocked map[string]struct{}
+}
+
+func NewSpamFilter(cfg SpamConfig) *SpamFilter {
+	if cfg.MaxLinks <= 0 {
+		cfg.MaxLinks = 10
+	}
+	if cfg.MaxRepeat <= 0 {
+		cfg.MaxRepeat = 5
+	}
+	if cfg.MinText <= 0 {
+		cfg.MinText = 20
+	}
+
+	blocked := make(map[string]struct{}, len(cfg.BlockedDomains))
+	for _, d := range cfg.BlockedDomains {
+		blocked[strings.ToLower(strings.TrimPrefix(d, "."))] = struct{}{}
+	}
+
+	return &SpamFilter{
+		cfg:     cfg,
+		blocked: blocked,
+	}
+}
+
+func (f *SpamFilter) Moderate(ctx context.Context, art domain.Article) (Result, error) {
+	text := art.Title + "\n" + art.Content
+	links := linkRe.FindAllString(text, -1)
+
+	var res Result
+	for _, link := range links {
+		if host := linkHost(link); f.isBlocked(host) {
+			return Result{
+				Verdict: VerdictReject,
+				Reasons: []string{"屏蔽的域名:" + host},
+			}, nil
+		}
+	}
+
+	if len(links) > f.cfg.MaxLinks {
+		res = res.merge(Result{
+			Verdict: VerdictReview,
+			Reasons: []string{fmt.Sprintf("链接过多:%d", len(links))},
+		})
+	}
+	if len(links) > 0 {
+		rest := strings.TrimSpace(linkRe.ReplaceAllString(art.Content, ""))
+		if utf8.RuneCountInString(rest) < f.cfg.MinText {
+			res = res.merge(Result{
+				Verdict: VerdictReview,
+				Reasons: []string{"正文几乎只有链接"},
+			})
+		}
+	}
+	if line, n := mostRepeated(art.Content); n > f.cfg.MaxRepeat {
+		res = res.merge(Result{
+			Verdict: VerdictReview,
+			Reasons: []string{fmt.Sprintf("重复内容:%q 出现 %d 次", line, n)},
+		})
+	}
+
+	return res, nil
+}
+
+// isBlocked 依次去掉最左边一级，子域名也算命中
+func (f *SpamFilter) isBlocked(host string) bool {
+	for host != "" {
+		if _, ok := f.blocked[host]; ok {
+			return true
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			return false
+		}
+		host = host[i+1:]
+	}
+	return false
+}
+
+func linkHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// mostRepeated 返回出现次数最多的非空行，太短的行（比如代码里的括号）不算
+func mostRepeated(content string) (string, int) {
+	cnts := make(map[string]int)
+	var (
+		line string
+		max  int
+	)
+	for _, l := range strings.Split(content, "\n") {
+		l = strings.TrimSpace(l)
+		if utf8.RuneCountInString(l) < 5 {
+			continue
+		}
+		cnts[l]++
+		if cnts[l] > max {
+			line, max = l, cnts[l]
+		}
+	}
+
+	return line, max
+}
@@ -0,0 +1,274 @@
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+// WordFilter 词典分成两部分匹配
+// 中文等不用空格分词的词用 Aho–Corasick 自动机一次扫描，匹配前统一转小写并跳过空白和标点，"敏 感"、"敏-感" 和 "敏感" 一样会命中；
+// 只由拉丁字母和数字组成的词按整词匹配，"ass" 不会命中 "class"，"s p a m" 也不会命中 "spam"，多个单词组成的词要求单词连续出现
+type WordFilter struct {
+	root *acNode
+	// latin 拉丁字母的词，key 是用空格连接的小写单词
+	latin map[string]struct{}
+	// maxWords 拉丁字母的词最多由几个单词组成
+	maxWords int
+	verdict  Verdict
+}
+
+type acNode struct {
+	next map[rune]*acNode
+	fail *acNode
+	// out 以这个节点结尾的词，包括沿失败指针能到达的
+	out []string
+}
+
+func newACNode() *acNode {
+	return &acNode{next: make(map[rune]*acNode)}
+}
+
+// NewWordFilter 命中任意一个词时给出 verdict
+func NewWordFilter(words []string, verdict Verdict) *WordFilter {
+	root := newACNode()
+	latin := make(map[string]struct{})
+	maxWords := 0
+	for _, w := range words {
+		if isLatin(w) {
+			ws := latinWords(w)
+			if len(ws) == 0 {
+				continue
+			}
+			latin[strings.Join(spans(w, ws), " ")] = struct{}{}
+			maxWords = max(maxWords, len(ws))
+			continue
+		}
+
+		rs := normalize(w)
+		if len(rs) == 0 {
+			continue
+		}
+		node := root
+		for _, r := range rs {
+			nxt, ok := node.next[r]
+			if !ok {
+				nxt = newACNode()
+				node.next[r] = nxt
+			}
+			node = nxt
+		}
+		if len(node.out) == 0 {
+			node.out = append(node.out, string(rs))
+		}
+	}
+
+	// 按层构造失败指针，父节点的失败指针总是先于子节点算好
+	queue := make([]*acNode, 0, len(root.next))
+	for _, child := range root.next {
+		child.fail = root
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for r, child := range node.next {
+			f := node.fail
+			for f != root && f.next[r] == nil {
+				f = f.fail
+			}
+			if nxt, ok := f.next[r]; ok && nxt != child {
+				child.fail = nxt
+			} else {
+				child.fail = root
+			}
+			child.out = append(child.out, child.fail.out...)
+			queue = append(queue, child)
+		}
+	}
+
+	return &WordFilter{
+		root:     root,
+		latin:    latin,
+		maxWords: maxWords,
+		verdict:  verdict,
+	}
+}
+
+type hit struct {
+	// end 命中位置结尾的字节偏移，用来按出现顺序排序
+	end  int
+	word string
+}
+
+// Match 按出现顺序返回命中的词，每个词只出现一次
+func (f *WordFilter) Match(text string) []string {
+	hits := append(f.matchAC(text), f.matchLatin(text)...)
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].end < hits[j].end
+	})
+
+	var res []string
+	seen := make(map[string]struct{})
+	for _, h := range hits {
+		if _, ok := seen[h.word]; !ok {
+			seen[h.word] = struct{}{}
+			res = append(res, h.word)
+		}
+	}
+
+	return res
+}
+
+func (f *WordFilter) matchAC(text string) []hit {
+	var res []hit
+	node := f.root
+	for i, r := range text {
+		c, ok := fold(r)
+		if !ok {
+			continue
+		}
+		for node != f.root && node.next[c] == nil {
+			node = node.fail
+		}
+		if nxt, ok := node.next[c]; ok {
+			node = nxt
+		}
+		for _, w := range node.out {
+			res = append(res, hit{end: i + utf8.RuneLen(r), word: w})
+		}
+	}
+
+	return res
+}
+
+// matchLatin 把正文切成拉丁字母的单词，依次检查从每个单词开始的连续几个单词
+func (f *WordFilter) matchLatin(text string) []hit {
+	if len(f.latin) == 0 {
+		return nil
+	}
+
+	ws := latinWords(text)
+	words := spans(text, ws)
+	var res []hit
+	for i := range words {
+		for n := 1; n <= f.maxWords && i+n <= len(words); n++ {
+			w := strings.Join(words[i:i+n], " ")
+			if _, ok := f.latin[w]; ok {
+				res = append(res, hit{end: ws[i+n-1][1], word: w})
+			}
+		}
+	}
+
+	return res
+}
+
+func (f *WordFilter) Moderate(ctx context.Context, art domain.Article) (Result, error) {
+	hits := f.Match(art.Title + "\n" + art.Content)
+	if len(hits) == 0 {
+		return Result{}, nil
+	}
+
+	reasons := make([]string, 0, len(hits))
+	for _, w := range hits {
+		reasons = append(reasons, "敏感词:"+w)
+	}
+
+	return Result{Verdict: f.verdict, Reasons: reasons}, nil
+}
+
+// LoadWords 读取词典文件，每行一个词，# 开头的行是注释
+func LoadWords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+
+	return words, scanner.Err()
+}
+
+func normalize(s string) []rune {
+	res := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r, ok := fold(r); ok {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// fold 只保留字母和数字，并转成小写
+func fold(r rune) (rune, bool) {
+	if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		return 0, false
+	}
+	return unicode.ToLower(r), true
+}
+
+func isLatinRune(r rune) bool {
+	return unicode.Is(unicode.Latin, r) || unicode.IsDigit(r)
+}
+
+// isLatin 词里的字母和数字都是拉丁字母或者数字
+func isLatin(s string) bool {
+	found := false
+	for _, r := range s {
+		if _, ok := fold(r); !ok {
+			continue
+		}
+		if !isLatinRune(r) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// latinWords 返回连续的拉丁字母和数字的起止字节偏移，其他字符都是分隔
+func latinWords(s string) [][2]int {
+	var (
+		res   [][2]int
+		start = -1
+	)
+	for i, r := range s {
+		if isLatinRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			res = append(res, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		res = append(res, [2]int{start, len(s)})
+	}
+	return res
+}
+
+func spans(s string, ws [][2]int) []string {
+	res := make([]string, 0, len(ws))
+	for _, w := range ws {
+		res = append(res, strings.ToLower(s[w[0]:w[1]]))
+	}
+	return res
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+)
+
+const (
+	// rankingWindow 发布太久的文章衰减后分数已经很低，不再参与计算
+	rankingWindow = time.Hour * 24 * 7
+
+	likeWeight = 1.0
+	readWeight = 0.05
+	// gravity 越大，分数随时间衰减得越快
+	gravity = 1.5
+)
+
+type RankingService interface {
+	// TopN 热榜，由 RankTopN 定期计算
+	TopN(ctx context.Context) ([]domain.Article, error)
+	RankTopN(ctx context.Context) error
+}
+
+type RankingConfig struct {
+	N         int
+	BatchSize int
+}
+
+type BatchRankingService struct {
+	artRepo   *repository.ArticleRepository
+	interRepo *repository.InteractiveArtRepository
+	repo      *repository.RankingRepository
+	cfg       RankingConfig
+	now       func() time.Time
+}
+
+func NewBatchRankingService(artRepo *repository.ArticleRepository, interRepo *repository.InteractiveArtRepository, repo *repository.RankingRepository, cfg RankingConfig) RankingService {
+	if cfg.N <= 0 {
+		cfg.N = 100
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	return &BatchRankingService{
+		artRepo:   artRepo,
+		interRepo: interRepo,
+		repo:      repo,
+		cfg:       cfg,
+		now:       time.Now,
+	}
+}
+
+func (svc *BatchRankingService) TopN(ctx context.Context) ([]domain.Article, error) {
+	arts, err := svc.repo.GetTopN(ctx)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrArticleInternalServer)
+	}
+
+	return arts, nil
+}
+
+func (svc *BatchRankingService) RankTopN(ctx context.Context) error {
+	arts, err := svc.topN(ctx)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.ReplaceTopN(ctx, arts)
+}
+
+func (svc *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error) {
+	now := svc.now()
+	top := &rankHeap{}
+
+	for offset := 0; ; offset += svc.cfg.BatchSize {
+		arts, err := svc.artRepo.ListPubSince(ctx, now.Add(-rankingWindow), offset, svc.cfg.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(arts) == 0 {
+			break
+		}
+
+		ids := make([]uint64, 0, len(arts))
+		for _, art := range arts {
+			ids = append(ids, art.ID)
+		}
+		inters, err := svc.interRepo.GetByIDs(ctx, "article", ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, art := range arts {
+			inter := inters[art.ID]
+			// 按第一次发布的时间衰减，修改或者重新发布不会让文章重新变新
+			s := hotScore(inter.LikeCnt, inter.ReadCnt, now.Sub(art.Ctime))
+			// 榜单只展示摘要，不带正文
+			art.Content = art.Abstract()
+			if top.Len() < svc.cfg.N {
+				heap.Push(top, rankItem{art: art, score: s})
+				continue
+			}
+			if s > (*top)[0].score {
+				(*top)[0] = rankItem{art: art, score: s}
+				heap.Fix(top, 0)
+			}
+		}
+
+		if len(arts) < svc.cfg.BatchSize {
+			break
+		}
+	}
+
+	res := make([]domain.Article, top.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i] = heap.Pop(top).(rankItem).art
+	}
+
+	return res, nil
+}
+
+// hotScore 参照 Hacker News：互动越多分数越高，随发布时间按 gravity 衰减
+func hotScore(likeCnt, readCnt int64, age time.Duration) float64 {
+	points := float64(likeCnt)*likeWeight + float64(readCnt)*readWeight
+	return points / math.Pow(age.Hours()+2, gravity)
+}
+
+type rankItem struct {
+	art   domain.Article
+	score float64
+}
+
+// rankHeap 小顶堆，堆顶是当前榜单里分数最低的文章
+type rankHeap []rankItem
+
+func (h rankHeap) Len() int           { return len(h) }
+func (h rankHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h rankHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *rankHeap) Push(x any) {
+	*h = append(*h, x.(rankItem))
+}
+
+func (h *rankHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"container/heap"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+)
+
+func TestHotScore(t *testing.T) {
+	// 互动相同时越新的分数越高
+	assert.Greater(t, hotScore(10, 100, time.Hour), hotScore(10, 100, time.Hour*24))
+	// 时间相同时互动越多分数越高
+	assert.Greater(t, hotScore(20, 100, time.Hour), hotScore(10, 100, time.Hour))
+	assert.Greater(t, hotScore(10, 200, time.Hour), hotScore(10, 100, time.Hour))
+	assert.Equal(t, float64(0), hotScore(0, 0, time.Hour))
+}
+
+func TestRankHeap(t *testing.T) {
+	h := &rankHeap{}
+	for i, s := range []float64{3, 1, 4, 1, 5, 9, 2, 6} {
+		heap.Push(h, rankItem{art: domain.Article{ID: uint64(i)}, score: s})
+	}
+
+	var scores []float64
+	for h.Len() > 0 {
+		scores = append(scores, heap.Pop(h).(rankItem).score)
+	}
+	assert.Equal(t, []float64{1, 1, 2, 3, 4, 5, 6, 9}, scores)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+)
+
+// RenderService 线上文章的 Markdown 渲染，先查缓存，没有再渲染并回写
+// 渲染本身不会失败，缓存出错只记录日志
+type RenderService interface {
+	Render(ctx context.Context, art domain.Article) domain.Rendered
+}
+
+type renderService struct {
+	repo *repository.RenderRepository
+}
+
+func NewRenderService(repo *repository.RenderRepository) RenderService {
+	return &renderService{
+		repo: repo,
+	}
+}
+
+func (svc *renderService) Render(ctx context.Context, art domain.Article) domain.Rendered {
+	version := art.Utime.UnixMilli()
+	res, err := svc.repo.Get(ctx, art.ID, version)
+	if err == nil {
+		return res
+	}
+	if !errors.Is(err, repository.ErrRenderNotFound) {
+		log.Printf("读取文章渲染缓存失败:%s:aid:%d", err.Error(), art.ID)
+	}
+
+	res = art.Render()
+	if err = svc.repo.Set(ctx, res); err != nil {
+		log.Printf("写入文章渲染缓存失败:%s:aid:%d", err.Error(), art.ID)
+	}
+
+	return res
+}
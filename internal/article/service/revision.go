@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+)
+
+// RevisionService 文章的历史版本和自动保存，只有作者本人可以操作
+// 版本由 ArticleService 在保存和发布时写入
+type RevisionService interface {
+	List(ctx context.Context, uid, aid uint64, offset, limit int) ([]domain.Revision, error)
+	Detail(ctx context.Context, uid, aid uint64, version int64) (domain.Revision, error)
+	Compare(ctx context.Context, uid, aid uint64, from, to int64) (domain.RevisionCompare, error)
+	// Restore 把草稿恢复成某个版本的内容，并追加一个恢复版本，不会自动发布
+	Restore(ctx context.Context, uid, aid uint64, version int64) error
+
+	Autosave(ctx context.Context, uid uint64, as domain.Autosave) error
+	GetAutosave(ctx context.Context, uid, aid uint64) (domain.Autosave, error)
+}
+
+type revisionService struct {
+	repo       *repository.RevisionRepository
+	artRepo    *repository.ArticleRepository
+	reviewRepo *repository.ReviewRepository
+}
+
+func NewRevisionService(repo *repository.RevisionRepository, artRepo *repository.ArticleRepository,
+	reviewRepo *repository.ReviewRepository) RevisionService {
+	return &revisionService{
+		repo:       repo,
+		artRepo:    artRepo,
+		reviewRepo: reviewRepo,
+	}
+}
+
+func (svc *revisionService) List(ctx context.Context, uid, aid uint64, offset, limit int) ([]domain.Revision, error) {
+	if _, err := svc.checkAuthor(ctx, uid, aid); err != nil {
+		return nil, err
+	}
+
+	res, err := svc.repo.List(ctx, aid, offset, limit)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrRevisionInternalServer)
+	}
+
+	return res, nil
+}
+
+func (svc *revisionService) Detail(ctx context.Context, uid, aid uint64, version int64) (domain.Revision, error) {
+	if _, err := svc.checkAuthor(ctx, uid, aid); err != nil {
+		return domain.Revision{}, err
+	}
+
+	return svc.find(ctx, aid, version)
+}
+
+func (svc *revisionService) Compare(ctx context.Context, uid, aid uint64, from, to int64) (domain.RevisionCompare, error) {
+	if _, err := svc.checkAuthor(ctx, uid, aid); err != nil {
+		return domain.RevisionCompare{}, err
+	}
+
+	fromRev, err := svc.find(ctx, aid, from)
+	if err != nil {
+		return domain.RevisionCompare{}, err
+	}
+	toRev, err := svc.find(ctx, aid, to)
+	if err != nil {
+		return domain.RevisionCompare{}, err
+	}
+
+	diff, err := domain.DiffContent(fromRev.Content, toRev.Content)
+	if err != nil {
+		return domain.RevisionCompare{}, er.NewBizError(constant.ErrRevisionInternalServer)
+	}
+	fromRev.Content, fromRev.Diff.Unified = "", ""
+	toRev.Content, toRev.Diff.Unified = "", ""
+
+	return domain.RevisionCompare{
+		From: fromRev,
+		To:   toRev,
+		Diff: diff,
+	}, nil
+}
+
+func (svc *revisionService) Restore(ctx context.Context, uid, aid uint64, version int64) error {
+	if _, err := svc.checkAuthor(ctx, uid, aid); err != nil {
+		return err
+	}
+	rev, err := svc.find(ctx, aid, version)
+	if err != nil {
+		return err
+	}
+
+	// 恢复之前提交的审核不能再通过，否则会把旧的内容发布出去
+	err = editDraft(ctx, svc.reviewRepo, svc.artRepo.RestoreDraft, domain.Article{
+		ID:      aid,
+		Title:   rev.Title,
+		Content: rev.Content,
+		Author: domain.Author{
+			Id: uid,
+		},
+		Status: domain.ArticleStatusUnPublished,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 以恢复后制作库里的内容为准
+	art, err := svc.artRepo.GetByID(ctx, aid)
+	if err != nil {
+		return er.NewBizError(constant.ErrRevisionInternalServer)
+	}
+	if _, err = svc.repo.Create(ctx, art, domain.RevisionKindRestore); err != nil {
+		return er.NewBizError(constant.ErrRevisionInternalServer)
+	}
+	_ = svc.repo.DelAutosave(ctx, aid)
+
+	return nil
+}
+
+func (svc *revisionService) Autosave(ctx context.Context, uid uint64, as domain.Autosave) error {
+	if _, err := svc.checkAuthor(ctx, uid, as.ArticleID); err != nil {
+		return err
+	}
+
+	as.Utime = time.Now().UnixMilli()
+	if err := svc.repo.SetAutosave(ctx, as); err != nil {
+		return er.NewBizError(constant.ErrRevisionInternalServer)
+	}
+
+	return nil
+}
+
+func (svc *revisionService) GetAutosave(ctx context.Context, uid, aid uint64) (domain.Autosave, error) {
+	art, err := svc.checkAuthor(ctx, uid, aid)
+	if err != nil {
+		return domain.Autosave{}, err
+	}
+
+	as, err := svc.repo.GetAutosave(ctx, aid)
+	if err != nil {
+		if errors.Is(err, repository.ErrAutosaveNotFound) {
+			return domain.Autosave{}, er.NewBizError(constant.ErrAutosaveNotFound)
+		}
+		return domain.Autosave{}, er.NewBizError(constant.ErrRevisionInternalServer)
+	}
+	// 之后已经手动保存过，自动保存的内容过时了
+	if as.Utime <= art.Utime.UnixMilli() {
+		return domain.Autosave{}, er.NewBizError(constant.ErrAutosaveNotFound)
+	}
+
+	return as, nil
+}
+
+func (svc *revisionService) find(ctx context.Context, aid uint64, version int64) (domain.Revision, error) {
+	rev, err := svc.repo.FindByVersion(ctx, aid, version)
+	if err != nil {
+		if errors.Is(err, repository.ErrRevisionNotFound) {
+			return domain.Revision{}, er.NewBizError(constant.ErrRevisionNotFound)
+		}
+		return domain.Revision{}, er.NewBizError(constant.ErrRevisionInternalServer)
+	}
+
+	return rev, nil
+}
+
+func (svc *revisionService) checkAuthor(ctx context.Context, uid, aid uint64) (domain.Article, error) {
+	art, err := svc.artRepo.GetByID(ctx, aid)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return domain.Article{}, er.NewBizError(constant.ErrArticleNotFound)
+		}
+		return domain.Article{}, er.NewBizError(constant.ErrRevisionInternalServer)
+	}
+	if art.Author.Id != uid {
+		return domain.Article{}, er.NewBizError(constant.ErrRevisionForbidden)
+	}
+
+	return art, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+	judgerepo "github.com/crazyfrankie/onlinejudge/internal/judgement/repository"
+	pmrepo "github.com/crazyfrankie/onlinejudge/internal/problem/repository"
+)
+
+const (
+	maxSolutionLangs   = 8
+	maxSolutionLangLen = 32
+	maxSolutionPage    = 50
+)
+
+type SolutionService interface {
+	// Attach 作者把自己的文章挂到题目下作为题解，重复调用会改挂到新的题目
+	Attach(ctx context.Context, sol domain.Solution) error
+	Detach(ctx context.Context, uid, aid uint64) error
+	// List 只有通过了这道题或主动选择查看题解的用户才能看到
+	List(ctx context.Context, uid uint64, q domain.SolutionQuery) ([]domain.Solution, error)
+	Reveal(ctx context.Context, uid, pid uint64) error
+	// CheckVisible 文章作为题解时同样受限，避免通过文章详情绕过
+	CheckVisible(ctx context.Context, uid, aid uint64) error
+}
+
+type solutionService struct {
+	repo       *repository.SolutionRepository
+	artRepo    *repository.ArticleRepository
+	pmRepo     pmrepo.ProblemRepository
+	submitRepo judgerepo.LocalSubmitRepo
+}
+
+func NewSolutionService(repo *repository.SolutionRepository, artRepo *repository.ArticleRepository, pmRepo pmrepo.ProblemRepository, submitRepo judgerepo.LocalSubmitRepo) SolutionService {
+	return &solutionService{
+		repo:       repo,
+		artRepo:    artRepo,
+		pmRepo:     pmRepo,
+		submitRepo: submitRepo,
+	}
+}
+
+func (svc *solutionService) Attach(ctx context.Context, sol domain.Solution) error {
+	langs, ok := normalizeLangs(sol.Languages)
+	if !ok || sol.ArticleID == 0 || sol.ProblemID == 0 {
+		return er.NewBizError(constant.ErrSolutionInvalidParams)
+	}
+	sol.Languages = langs
+
+	// 只能挂已经发布的文章，草稿或者撤回的文章读者看不到
+	art, err := svc.artRepo.GetPubByID(ctx, sol.ArticleID)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		return er.NewBizError(constant.ErrArticleNotFound)
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+	if art.Status != domain.ArticleStatusPublished {
+		return er.NewBizError(constant.ErrArticleNotFound)
+	}
+	if art.Author.Id != sol.Author.Id {
+		return er.NewBizError(constant.ErrSolutionForbidden)
+	}
+
+	_, err = svc.pmRepo.FindProblemByID(ctx, sol.ProblemID)
+	if errors.Is(err, pmrepo.ErrProblemNotFound) {
+		return er.NewBizError(constant.ErrProblemNotFound)
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+
+	if err = svc.repo.Attach(ctx, sol); err != nil {
+		return er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+
+	return nil
+}
+
+func (svc *solutionService) Detach(ctx context.Context, uid, aid uint64) error {
+	err := svc.repo.Detach(ctx, aid, uid)
+	if errors.Is(err, repository.ErrSolutionNotFound) {
+		return er.NewBizError(constant.ErrSolutionNotFound)
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+
+	return nil
+}
+
+func (svc *solutionService) List(ctx context.Context, uid uint64, q domain.SolutionQuery) ([]domain.Solution, error) {
+	if err := svc.checkUnlocked(ctx, uid, q.ProblemID); err != nil {
+		return nil, err
+	}
+
+	if q.Sort != domain.SolutionSortNew {
+		q.Sort = domain.SolutionSortHot
+	}
+	if q.Limit <= 0 || q.Limit > maxSolutionPage {
+		q.Limit = maxSolutionPage
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	q.Language = strings.ToLower(strings.TrimSpace(q.Language))
+
+	res, err := svc.repo.List(ctx, q)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+
+	return res, nil
+}
+
+func (svc *solutionService) Reveal(ctx context.Context, uid, pid uint64) error {
+	_, err := svc.pmRepo.FindProblemByID(ctx, pid)
+	if errors.Is(err, pmrepo.ErrProblemNotFound) {
+		return er.NewBizError(constant.ErrProblemNotFound)
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+
+	if err = svc.repo.Reveal(ctx, uid, pid); err != nil {
+		return er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+
+	return nil
+}
+
+func (svc *solutionService) CheckVisible(ctx context.Context, uid, aid uint64) error {
+	sol, err := svc.repo.FindByArticle(ctx, aid)
+	if errors.Is(err, repository.ErrSolutionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+	if sol.Author.Id == uid {
+		return nil
+	}
+
+	return svc.checkUnlocked(ctx, uid, sol.ProblemID)
+}
+
+func (svc *solutionService) checkUnlocked(ctx context.Context, uid, pid uint64) error {
+	ok, err := svc.submitRepo.HasAccepted(ctx, uid, pid)
+	if err != nil {
+		return er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+	if ok {
+		return nil
+	}
+
+	ok, err = svc.repo.Revealed(ctx, uid, pid)
+	if err != nil {
+		return er.NewBizError(constant.ErrSolutionInternalServer)
+	}
+	if !ok {
+		return er.NewBizError(constant.ErrSolutionLocked)
+	}
+
+	return nil
+}
+
+// normalizeLangs 统一小写并去重，逗号是存储时的分隔符不能出现在语言名里
+func normalizeLangs(langs []string) ([]string, bool) {
+	if len(langs) > maxSolutionLangs {
+		return nil, false
+	}
+
+	seen := make(map[string]struct{}, len(langs))
+	res := make([]string, 0, len(langs))
+	for _, l := range langs {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || len(l) > maxSolutionLangLen || strings.Contains(l, ",") {
+			return nil, false
+		}
+		if _, ok := seen[l]; ok {
+			continue
+		}
+		seen[l] = struct{}{}
+		res = append(res, l)
+	}
+
+	return res, true
+}
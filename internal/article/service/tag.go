@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
+	pmrepo "github.com/crazyfrankie/onlinejudge/internal/problem/repository"
+)
+
+const (
+	maxArticleTags = 5
+	maxTagFeedPage = 50
+	// tagPageArticles 标签页上展示的文章数，更多的走 Feed 分页
+	tagPageArticles = 10
+)
+
+// TagService 标签由题目模块维护，文章只能挂已有的标签
+type TagService interface {
+	ArticleTags(ctx context.Context, aid uint64) ([]domain.Tag, error)
+	// Feed 标签下已发布的文章，最近发布的在前
+	Feed(ctx context.Context, name string, offset, limit int) ([]domain.Article, error)
+	Page(ctx context.Context, name string) (domain.TagPage, error)
+	// Counts 每个标签下的题目数和已发布的文章数
+	Counts(ctx context.Context) ([]domain.TagCount, error)
+}
+
+type tagService struct {
+	repo    *repository.TagRepository
+	artRepo *repository.ArticleRepository
+	pmRepo  pmrepo.ProblemRepository
+}
+
+func NewTagService(repo *repository.TagRepository, artRepo *repository.ArticleRepository, pmRepo pmrepo.ProblemRepository) TagService {
+	return &tagService{
+		repo:    repo,
+		artRepo: artRepo,
+		pmRepo:  pmRepo,
+	}
+}
+
+func (svc *tagService) ArticleTags(ctx context.Context, aid uint64) ([]domain.Tag, error) {
+	tags, err := svc.repo.FindByArticle(ctx, aid)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrTagInternalServer)
+	}
+
+	return tags, nil
+}
+
+func (svc *tagService) Feed(ctx context.Context, name string, offset, limit int) ([]domain.Article, error) {
+	tag, err := svc.tag(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxTagFeedPage {
+		limit = maxTagFeedPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return svc.feed(ctx, tag.ID, offset, limit)
+}
+
+func (svc *tagService) Page(ctx context.Context, name string) (domain.TagPage, error) {
+	tag, err := svc.tag(ctx, name)
+	if err != nil {
+		return domain.TagPage{}, err
+	}
+
+	page := domain.TagPage{TagCount: domain.TagCount{Tag: tag}}
+	var eg errgroup.Group
+	eg.Go(func() error {
+		pms, err := svc.pmRepo.FindProblemsByName(ctx, tag.Name)
+		if err != nil {
+			return err
+		}
+		for _, pm := range pms {
+			page.Problems = append(page.Problems, domain.TagProblem{
+				ID:       pm.Id,
+				Title:    pm.Title,
+				PassRate: pm.PassRate,
+			})
+		}
+		page.ProblemCnt = int64(len(pms))
+		return nil
+	})
+	eg.Go(func() error {
+		var err error
+		page.ArticleCnt, err = svc.repo.CountPub(ctx, tag.ID)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		page.Articles, err = svc.feed(ctx, tag.ID, 0, tagPageArticles)
+		return err
+	})
+	if err = eg.Wait(); err != nil {
+		return domain.TagPage{}, er.NewBizError(constant.ErrTagInternalServer)
+	}
+
+	return page, nil
+}
+
+func (svc *tagService) Counts(ctx context.Context) ([]domain.TagCount, error) {
+	counts, err := svc.repo.PubCounts(ctx)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrTagInternalServer)
+	}
+
+	pmCounts, err := svc.pmRepo.FindCountInTag(ctx)
+	if err != nil && !errors.Is(err, pmrepo.ErrNoTags) {
+		return nil, er.NewBizError(constant.ErrTagInternalServer)
+	}
+	byID := make(map[uint64]int64, len(pmCounts))
+	for _, c := range pmCounts {
+		byID[c.TagID] = int64(c.ProblemCount)
+	}
+	for i := range counts {
+		counts[i].ProblemCnt = byID[counts[i].ID]
+	}
+
+	return counts, nil
+}
+
+func (svc *tagService) tag(ctx context.Context, name string) (domain.Tag, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.Tag{}, er.NewBizError(constant.ErrTagInvalidParams)
+	}
+
+	tag, err := svc.repo.FindByName(ctx, name)
+	if errors.Is(err, repository.ErrTagNotFound) {
+		return domain.Tag{}, er.NewBizError(constant.ErrTagNotFound)
+	}
+	if err != nil {
+		return domain.Tag{}, er.NewBizError(constant.ErrTagInternalServer)
+	}
+
+	return tag, nil
+}
+
+// feed 关联表里的状态和线上库之间可能有短暂的不一致，取不到的文章直接跳过
+func (svc *tagService) feed(ctx context.Context, tid uint64, offset, limit int) ([]domain.Article, error) {
+	ids, err := svc.repo.PubArticleIDs(ctx, tid, offset, limit)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrTagInternalServer)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	arts, err := svc.artRepo.GetPubByIDs(ctx, ids)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrTagInternalServer)
+	}
+
+	// 按关联表的顺序返回
+	res := make([]domain.Article, 0, len(ids))
+	for _, id := range ids {
+		art, ok := arts[id]
+		if ok && art.Status == domain.ArticleStatusPublished {
+			res = append(res, art)
+		}
+	}
+
+	return res, nil
+}
+
+// resolveTags 按名字找到已有的标签，去掉空白和重复，有不存在的标签时返回错误
+func resolveTags(ctx context.Context, repo *repository.TagRepository, tags []domain.Tag) ([]domain.Tag, error) {
+	names := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, t := range tags {
+		name := strings.TrimSpace(t.Name)
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	if len(names) > maxArticleTags {
+		return nil, er.NewBizError(constant.ErrTagTooMany)
+	}
+	if len(names) == 0 {
+		return []domain.Tag{}, nil
+	}
+
+	res, err := repo.FindByNames(ctx, names)
+	if err != nil {
+		return nil, er.NewBizError(constant.ErrTagInternalServer)
+	}
+	if len(res) != len(names) {
+		return nil, er.NewBizError(constant.ErrTagNotFound)
+	}
+
+	return res, nil
+}
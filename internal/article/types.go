@@ -2,15 +2,42 @@ package article
 
 import (
 	"github.com/crazyfrankie/onlinejudge/internal/article/event"
+	"github.com/crazyfrankie/onlinejudge/internal/article/job"
+	"github.com/crazyfrankie/onlinejudge/internal/article/migrator"
 	"github.com/crazyfrankie/onlinejudge/internal/article/web"
 )
 
 type Handler = web.ArticleHandler
 type AdminHandler = web.AdminHandler
+type CommentHandler = web.CommentHandler
+type SolutionHandler = web.SolutionHandler
+type CollectHandler = web.CollectHandler
+type RankingHandler = web.RankingHandler
+type MigrateHandler = web.MigrateHandler
+type RevisionHandler = web.RevisionHandler
+type TagHandler = web.TagHandler
+type FollowHandler = web.FollowHandler
 type Consumer = event.Consumer
+type FeedConsumer = event.FeedConsumer
 
 type Module struct {
-	Hdl      *Handler
-	AdminHdl *AdminHandler
-	Consumer Consumer
+	Hdl        *Handler
+	AdminHdl   *AdminHandler
+	CommentHdl *CommentHandler
+	SolHdl     *SolutionHandler
+	CollectHdl *CollectHandler
+	RankingHdl *RankingHandler
+	MigrateHdl *MigrateHandler
+	RevHdl     *RevisionHandler
+	TagHdl     *TagHandler
+	FollowHdl  *FollowHandler
+	Consumer   Consumer
+	// FeedConsumer 把发布的文章推到粉丝的收件箱
+	FeedConsumer *FeedConsumer
+	// RankingJob 热榜计算任务，由 main 启动
+	RankingJob *job.RankingJob
+	// InteractiveJob 计数落库和对账任务，由 main 启动
+	InteractiveJob *job.InteractiveJob
+	// Migrator 存储迁移，没有配置迁移目标时为 nil
+	Migrator *migrator.Migrator
 }
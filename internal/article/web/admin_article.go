@@ -2,6 +2,7 @@ package web
 
 import (
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/crazyfrankie/onlinejudge/infra/contract/token"
 	"github.com/gin-gonic/gin"
@@ -37,6 +38,12 @@ func (ctl *AdminHandler) RegisterRoute(r *gin.Engine) {
 		admin.POST("detail/:id", ctl.Detail())
 		admin.POST("publish", ctl.Publish())
 	}
+
+	review := r.Group("api/admin/article/review")
+	{
+		review.GET("", ctl.ReviewList())
+		review.POST(":id", ctl.Review())
+	}
 }
 
 func (ctl *AdminHandler) Edit() gin.HandlerFunc {
@@ -184,3 +191,62 @@ func (ctl *AdminHandler) Detail() gin.HandlerFunc {
 		response.SuccessWithLog(c, resp, name, success)
 	}
 }
+
+// ReviewList 等待人工审核的文章，先提交的排在前面
+func (ctl *AdminHandler) ReviewList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Article/Admin/ReviewList"
+		var req ReviewListReq
+		if err := c.ShouldBindQuery(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrReviewInvalidParams))
+			return
+		}
+
+		res, err := ctl.svc.ReviewList(c.Request.Context(), req.Offset, req.Limit)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := make([]ReviewResp, 0, len(res))
+		for _, r := range res {
+			resp = append(resp, ReviewResp{
+				ArticleID: r.ArticleID,
+				AuthorID:  r.AuthorID,
+				Title:     r.Title,
+				Reasons:   r.Reasons,
+				Ctime:     r.Ctime.String(),
+			})
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
+
+// Review 通过后文章对读者可见，拒绝后作者修改了可以重新发布
+func (ctl *AdminHandler) Review() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Article/Admin/Review"
+		aid, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrReviewInvalidParams))
+			return
+		}
+		var req ReviewReq
+		if err = c.ShouldBind(&req); err != nil || utf8.RuneCountInString(req.Note) > 512 {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrReviewInvalidParams))
+			return
+		}
+
+		claims := c.MustGet("claims")
+		claim := claims.(*token.Claims)
+
+		err = ctl.svc.Review(c.Request.Context(), aid, claim.Id, req.Approve, req.Note)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
@@ -1,6 +1,8 @@
 package web
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"golang.org/x/sync/errgroup"
 
@@ -17,14 +19,21 @@ const (
 )
 
 type ArticleHandler struct {
-	svc      service.ArticleService
-	interSvc *service.InteractiveService
+	svc       service.ArticleService
+	interSvc  *service.InteractiveService
+	solSvc    service.SolutionService
+	renderSvc service.RenderService
+	tagSvc    service.TagService
 }
 
-func NewArticleHandler(svc service.ArticleService, interSvc *service.InteractiveService) *ArticleHandler {
+func NewArticleHandler(svc service.ArticleService, interSvc *service.InteractiveService, solSvc service.SolutionService,
+	renderSvc service.RenderService, tagSvc service.TagService) *ArticleHandler {
 	return &ArticleHandler{
-		svc:      svc,
-		interSvc: interSvc,
+		svc:       svc,
+		interSvc:  interSvc,
+		solSvc:    solSvc,
+		renderSvc: renderSvc,
+		tagSvc:    tagSvc,
 	}
 }
 
@@ -46,7 +55,15 @@ func (ctl *ArticleHandler) PubList() gin.HandlerFunc {
 			return
 		}
 
-		res, err := ctl.svc.PubList(c.Request.Context(), req.Offset, req.Limit)
+		var (
+			res []domain.Article
+			err error
+		)
+		if req.Tag != "" {
+			res, err = ctl.tagSvc.Feed(c.Request.Context(), req.Tag, req.Offset, req.Limit)
+		} else {
+			res, err = ctl.svc.PubList(c.Request.Context(), req.Offset, req.Limit)
+		}
 		if err != nil {
 			response.ErrorWithLog(c, name, bizError, err)
 			return
@@ -54,13 +71,7 @@ func (ctl *ArticleHandler) PubList() gin.HandlerFunc {
 
 		var resp []PubListResp
 		for _, art := range res {
-			resp = append(resp, PubListResp{
-				ID:     art.ID,
-				Title:  art.Title,
-				Status: art.Status.ToUint8(),
-				Ctime:  art.Ctime.String(),
-				Utime:  art.Utime.String(),
-			})
+			resp = append(resp, toPubListResp(art))
 		}
 
 		response.SuccessWithLog(c, resp, name, success)
@@ -75,8 +86,16 @@ func (ctl *ArticleHandler) PubDetail() gin.HandlerFunc {
 		claim := claims.(*token.Claims)
 
 		artID := c.Param("id")
+		aid, err := strconv.ParseUint(artID, 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrArticleInvalidParams))
+			return
+		}
+		if err = ctl.solSvc.CheckVisible(c.Request.Context(), claim.Id, aid); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
 
-		var err error
 		var eg errgroup.Group
 		var art domain.Article
 		eg.Go(func() error {
@@ -90,20 +109,32 @@ func (ctl *ArticleHandler) PubDetail() gin.HandlerFunc {
 			return err
 		})
 
+		var tags []domain.Tag
+		eg.Go(func() error {
+			var err error
+			tags, err = ctl.tagSvc.ArticleTags(c.Request.Context(), aid)
+			return err
+		})
+
 		err = eg.Wait()
 		if err != nil {
 			response.ErrorWithLog(c, name, bizError, err)
 			return
 		}
 
+		rendered := ctl.renderSvc.Render(c.Request.Context(), art)
 		interResp := Interactive{
-			LikeCnt: inter.LikeCnt,
-			ReadCnt: inter.ReadCnt + 1,
+			LikeCnt:    inter.LikeCnt,
+			ReadCnt:    inter.ReadCnt + 1,
+			CollectCnt: inter.CollectCnt,
 		}
 		resp := PubDetailResp{
 			ID:         art.ID,
 			Title:      art.Title,
 			Content:    art.Content,
+			HTML:       rendered.HTML,
+			TOC:        rendered.TOC,
+			Tags:       tagNames(tags),
 			AuthorID:   art.Author.Id,
 			AuthorName: art.Author.Name,
 			Ctime:      art.Ctime.String(),
@@ -111,6 +142,7 @@ func (ctl *ArticleHandler) PubDetail() gin.HandlerFunc {
 			Status:     art.Status.ToUint8(),
 			Inter:      interResp,
 			Liked:      inter.Liked,
+			Collected:  inter.Collected,
 		}
 
 		response.SuccessWithLog(c, resp, name, success)
@@ -143,3 +175,12 @@ func (ctl *ArticleHandler) Like() gin.HandlerFunc {
 		response.SuccessWithLog(c, nil, name, success)
 	}
 }
+
+func tagNames(tags []domain.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,160 @@
+package web
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	"github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/infra/contract/token"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+)
+
+type CollectHandler struct {
+	svc service.CollectService
+}
+
+func NewCollectHandler(svc service.CollectService) *CollectHandler {
+	return &CollectHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *CollectHandler) RegisterRoute(r *gin.Engine) {
+	collect := r.Group("api/collections")
+	{
+		collect.POST("", ctl.Collect())
+		collect.DELETE("", ctl.Uncollect())
+		collect.POST("folders", ctl.CreateFolder())
+		collect.GET("folders", ctl.Folders())
+		// 默认收藏夹的 id 为 0
+		collect.GET("folders/:id", ctl.Items())
+	}
+}
+
+func (ctl *CollectHandler) CreateFolder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Collect/CreateFolder"
+		var req FolderReq
+		if err := c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCollectInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		id, err := ctl.svc.CreateFolder(c.Request.Context(), domain.CollectFolder{
+			Uid:         claim.Id,
+			Name:        req.Name,
+			Description: req.Description,
+		})
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, id, name, success)
+	}
+}
+
+func (ctl *CollectHandler) Folders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Collect/Folders"
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, err := ctl.svc.Folders(c.Request.Context(), claim.Id)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := make([]FolderResp, 0, len(res))
+		for _, f := range res {
+			resp = append(resp, FolderResp{
+				ID:          f.ID,
+				Name:        f.Name,
+				Description: f.Description,
+				Cnt:         f.Cnt,
+			})
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
+
+func (ctl *CollectHandler) Items() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Collect/Items"
+		fid, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCollectInvalidParams))
+			return
+		}
+		offset, _ := strconv.Atoi(c.Query("offset"))
+		limit, _ := strconv.Atoi(c.Query("limit"))
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, err := ctl.svc.Items(c.Request.Context(), claim.Id, fid, offset, limit)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := make([]CollectItemResp, 0, len(res))
+		for _, item := range res {
+			resp = append(resp, CollectItemResp{
+				Biz:   item.Biz,
+				BizID: item.BizID,
+				Title: item.Title,
+				Ctime: item.Ctime.String(),
+			})
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
+
+func (ctl *CollectHandler) Collect() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Collect/Collect"
+		var req CollectReq
+		if err := c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCollectInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		err := ctl.svc.Collect(c.Request.Context(), claim.Id, req.Biz, req.BizID, req.FolderID)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *CollectHandler) Uncollect() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Collect/Uncollect"
+		bizId, err := strconv.ParseUint(c.Query("biz_id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCollectInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		if err = ctl.svc.Uncollect(c.Request.Context(), claim.Id, c.Query("biz"), bizId); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
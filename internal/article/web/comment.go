@@ -0,0 +1,213 @@
+package web
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	"github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/infra/contract/token"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+)
+
+type CommentHandler struct {
+	svc service.CommentService
+}
+
+func NewCommentHandler(svc service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *CommentHandler) RegisterRoute(r *gin.Engine) {
+	comment := r.Group("api/comments")
+	{
+		comment.POST("", ctl.Create())
+		comment.GET("", ctl.List())
+		comment.GET(":id/replies", ctl.Replies())
+		comment.PUT(":id", ctl.Edit())
+		comment.DELETE(":id", ctl.Delete())
+		comment.POST(":id/like", ctl.Like())
+		comment.POST(":id/moderate", ctl.Moderate())
+	}
+}
+
+func (ctl *CommentHandler) Create() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Comment/Create"
+		var req CommentReq
+		if err := c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		id, err := ctl.svc.Create(c.Request.Context(), domain.Comment{
+			Biz:   req.Biz,
+			BizID: req.BizID,
+			Commentator: domain.Author{
+				Id: claim.Id,
+			},
+			Content: req.Content,
+			PID:     req.PID,
+		})
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, id, name, success)
+	}
+}
+
+func (ctl *CommentHandler) List() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Comment/List"
+		bizId, err := strconv.ParseUint(c.Query("biz_id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+		cursor, limit := page(c)
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, next, err := ctl.svc.List(c.Request.Context(), claim.Id, c.Query("biz"), bizId, cursor, limit)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, toCommentListResp(res, next), name, success)
+	}
+}
+
+func (ctl *CommentHandler) Replies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Comment/Replies"
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+		cursor, limit := page(c)
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, next, err := ctl.svc.Replies(c.Request.Context(), claim.Id, id, cursor, limit)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, toCommentListResp(res, next), name, success)
+	}
+}
+
+func (ctl *CommentHandler) Edit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Comment/Edit"
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+		var req EditCommentReq
+		if err = c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		if err = ctl.svc.Edit(c.Request.Context(), claim.Id, id, req.Content); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *CommentHandler) Delete() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Comment/Delete"
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		if err = ctl.svc.Delete(c.Request.Context(), claim.Id, id); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *CommentHandler) Like() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Comment/Like"
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+		var req LikeCommentReq
+		if err = c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		if err = ctl.svc.Like(c.Request.Context(), claim.Id, id, req.Like); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *CommentHandler) Moderate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Comment/Moderate"
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+		var req ModerateReq
+		if err = c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrCommentInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		err = ctl.svc.Moderate(c.Request.Context(), claim.Id, id, service.ModerateAction(req.Action))
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+// page 游标分页参数，cursor 为上一页返回的 next_cursor
+func page(c *gin.Context) (uint64, int) {
+	cursor, _ := strconv.ParseUint(c.Query("cursor"), 10, 64)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return cursor, limit
+}
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	"github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/infra/contract/token"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+)
+
+type FollowHandler struct {
+	svc     service.FollowService
+	feedSvc service.FeedService
+}
+
+func NewFollowHandler(svc service.FollowService, feedSvc service.FeedService) *FollowHandler {
+	return &FollowHandler{
+		svc:     svc,
+		feedSvc: feedSvc,
+	}
+}
+
+func (ctl *FollowHandler) RegisterRoute(r *gin.Engine) {
+	followGroup := r.Group("api/follow")
+	{
+		followGroup.POST(":uid", ctl.Follow())
+		followGroup.DELETE(":uid", ctl.Unfollow())
+		followGroup.GET(":uid/stats", ctl.Stats())
+		followGroup.GET(":uid/followees", ctl.Followees())
+		followGroup.GET(":uid/followers", ctl.Followers())
+	}
+	r.GET("api/feed", ctl.Feed())
+}
+
+func (ctl *FollowHandler) Follow() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Follow/Follow"
+		uid, err := strconv.ParseUint(c.Param("uid"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrFollowInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		if err = ctl.svc.Follow(c.Request.Context(), claim.Id, uid); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *FollowHandler) Unfollow() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Follow/Unfollow"
+		uid, err := strconv.ParseUint(c.Param("uid"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrFollowInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		if err = ctl.svc.Unfollow(c.Request.Context(), claim.Id, uid); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *FollowHandler) Stats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Follow/Stats"
+		uid, err := strconv.ParseUint(c.Param("uid"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrFollowInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, err := ctl.svc.Stats(c.Request.Context(), claim.Id, uid)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, FollowStatsResp{
+			Uid:       res.Uid,
+			Followers: res.Followers,
+			Followees: res.Followees,
+			Following: res.Following,
+		}, name, success)
+	}
+}
+
+func (ctl *FollowHandler) Followees() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Follow/Followees"
+		uid, req, ok := ctl.listReq(c, name)
+		if !ok {
+			return
+		}
+
+		res, err := ctl.svc.Followees(c.Request.Context(), uid, req.Offset, req.Limit)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := make([]FollowResp, 0, len(res))
+		for _, f := range res {
+			resp = append(resp, FollowResp{Uid: f.Followee, Ctime: f.Ctime.String()})
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
+
+func (ctl *FollowHandler) Followers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Follow/Followers"
+		uid, req, ok := ctl.listReq(c, name)
+		if !ok {
+			return
+		}
+
+		res, err := ctl.svc.Followers(c.Request.Context(), uid, req.Offset, req.Limit)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := make([]FollowResp, 0, len(res))
+		for _, f := range res {
+			resp = append(resp, FollowResp{Uid: f.Follower, Ctime: f.Ctime.String()})
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
+
+func (ctl *FollowHandler) listReq(c *gin.Context, name string) (uint64, FollowListReq, bool) {
+	var req FollowListReq
+	uid, err := strconv.ParseUint(c.Param("uid"), 10, 64)
+	if err == nil {
+		err = c.ShouldBindQuery(&req)
+	}
+	if err != nil {
+		response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrFollowInvalidParams))
+		return 0, req, false
+	}
+
+	return uid, req, true
+}
+
+// Feed 关注的人发布的文章，按发布时间倒序，用游标翻页
+func (ctl *FollowHandler) Feed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Follow/Feed"
+		var req FeedReq
+		if err := c.ShouldBindQuery(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrFollowInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		arts, next, err := ctl.feedSvc.Feed(c.Request.Context(), claim.Id, req.Cursor, req.Limit)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, toFeedResp(arts, next), name, success)
+	}
+}
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/internal/article/migrator"
+)
+
+// MigrateHandler 文章存储迁移的管理接口，没有配置迁移目标时 mig 为 nil
+type MigrateHandler struct {
+	mig *migrator.Migrator
+}
+
+func NewMigrateHandler(mig *migrator.Migrator) *MigrateHandler {
+	return &MigrateHandler{
+		mig: mig,
+	}
+}
+
+func (ctl *MigrateHandler) RegisterRoute(r *gin.Engine) {
+	migrateGroup := r.Group("api/admin/article/migration")
+	{
+		migrateGroup.GET("", ctl.Status())
+		migrateGroup.POST("pattern", ctl.Pattern())
+		migrateGroup.POST("copy", ctl.Copy())
+		migrateGroup.POST("validate", ctl.Validate())
+	}
+}
+
+func (ctl *MigrateHandler) Status() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Article/Migrate/Status"
+		if ctl.mig == nil {
+			response.ErrorWithLog(c, name, bizError, er.NewBizError(constant.ErrMigrateDisabled))
+			return
+		}
+
+		res, err := ctl.mig.Status(c.Request.Context())
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, er.NewBizError(constant.ErrMigrateInternalServer))
+			return
+		}
+
+		response.SuccessWithLog(c, res, name, success)
+	}
+}
+
+func (ctl *MigrateHandler) Pattern() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Article/Migrate/Pattern"
+		var req MigratePatternReq
+		if err := c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, er.NewBizError(constant.ErrMigrateInvalidParams))
+			return
+		}
+		if ctl.mig == nil {
+			response.ErrorWithLog(c, name, bizError, er.NewBizError(constant.ErrMigrateDisabled))
+			return
+		}
+
+		err := ctl.mig.SetPattern(c.Request.Context(), req.Pattern)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, migrateError(err))
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *MigrateHandler) Copy() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Article/Migrate/Copy"
+		var req MigrateCopyReq
+		if err := c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, er.NewBizError(constant.ErrMigrateInvalidParams))
+			return
+		}
+		if ctl.mig == nil {
+			response.ErrorWithLog(c, name, bizError, er.NewBizError(constant.ErrMigrateDisabled))
+			return
+		}
+
+		err := ctl.mig.StartCopy(c.Request.Context(), req.Restart)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, migrateError(err))
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *MigrateHandler) Validate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Article/Migrate/Validate"
+		var req MigrateValidateReq
+		if err := c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, er.NewBizError(constant.ErrMigrateInvalidParams))
+			return
+		}
+		if ctl.mig == nil {
+			response.ErrorWithLog(c, name, bizError, er.NewBizError(constant.ErrMigrateDisabled))
+			return
+		}
+
+		err := ctl.mig.StartValidate(c.Request.Context(), req.Repair)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, migrateError(err))
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func migrateError(err error) error {
+	switch {
+	case errors.Is(err, migrator.ErrUnknownPattern):
+		return er.NewBizError(constant.ErrMigrateInvalidParams)
+	case errors.Is(err, migrator.ErrTaskRunning):
+		return er.NewBizError(constant.ErrMigrateRunning)
+	case errors.Is(err, migrator.ErrCopyNotDone):
+		return er.NewBizError(constant.ErrMigrateCopyNotDone)
+	case errors.Is(err, migrator.ErrNotValidated):
+		return er.NewBizError(constant.ErrMigrateNotValidated)
+	}
+
+	return er.NewBizError(constant.ErrMigrateInternalServer)
+}
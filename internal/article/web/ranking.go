@@ -0,0 +1,52 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+)
+
+type RankingHandler struct {
+	svc service.RankingService
+}
+
+func NewRankingHandler(svc service.RankingService) *RankingHandler {
+	return &RankingHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *RankingHandler) RegisterRoute(r *gin.Engine) {
+	rankGroup := r.Group("api/article")
+	{
+		rankGroup.GET("pub/hot", ctl.Hot())
+	}
+}
+
+func (ctl *RankingHandler) Hot() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Article/Hot"
+
+		res, err := ctl.svc.TopN(c.Request.Context())
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := make([]PubListResp, 0, len(res))
+		for _, art := range res {
+			resp = append(resp, PubListResp{
+				ID:       art.ID,
+				Title:    art.Title,
+				Abstract: art.Content,
+				AuthorID: art.Author.Id,
+				Status:   art.Status.ToUint8(),
+				Ctime:    art.Ctime.String(),
+				Utime:    art.Utime.String(),
+			})
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
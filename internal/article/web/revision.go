@@ -0,0 +1,203 @@
+package web
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	"github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/infra/contract/token"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+)
+
+type RevisionHandler struct {
+	svc service.RevisionService
+}
+
+func NewRevisionHandler(svc service.RevisionService) *RevisionHandler {
+	return &RevisionHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *RevisionHandler) RegisterRoute(r *gin.Engine) {
+	artGroup := r.Group("api/articles")
+	{
+		artGroup.POST("autosave", ctl.Autosave())
+		artGroup.GET("autosave/:id", ctl.GetAutosave())
+		artGroup.GET("revisions/:id", ctl.List())
+		artGroup.GET("revisions/:id/detail", ctl.Detail())
+		artGroup.GET("revisions/:id/compare", ctl.Compare())
+		artGroup.POST("revisions/:id/restore", ctl.Restore())
+	}
+}
+
+func (ctl *RevisionHandler) Autosave() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Revision/Autosave"
+		var req ArticleReq
+		if err := c.ShouldBind(&req); err != nil || req.ID == 0 {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		err := ctl.svc.Autosave(c.Request.Context(), claim.Id, domain.Autosave{
+			ArticleID: req.ID,
+			Title:     req.Title,
+			Content:   req.Content,
+		})
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *RevisionHandler) GetAutosave() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Revision/GetAutosave"
+		aid, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, err := ctl.svc.GetAutosave(c.Request.Context(), claim.Id, aid)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, res, name, success)
+	}
+}
+
+func (ctl *RevisionHandler) List() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Revision/List"
+		aid, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+		offset, _ := strconv.Atoi(c.Query("offset"))
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		if limit <= 0 || limit > 100 {
+			limit = 20
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, err := ctl.svc.List(c.Request.Context(), claim.Id, aid, offset, limit)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := make([]RevisionResp, 0, len(res))
+		for _, rev := range res {
+			resp = append(resp, toRevisionResp(rev))
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
+
+func (ctl *RevisionHandler) Detail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Revision/Detail"
+		aid, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+		version, err := strconv.ParseInt(c.Query("version"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		rev, err := ctl.svc.Detail(c.Request.Context(), claim.Id, aid, version)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := toRevisionResp(rev)
+		resp.Content = rev.Content
+		resp.Diff = rev.Diff.Unified
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
+
+func (ctl *RevisionHandler) Compare() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Revision/Compare"
+		aid, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+		from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+		to, err := strconv.ParseInt(c.Query("to"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, err := ctl.svc.Compare(c.Request.Context(), claim.Id, aid, from, to)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, CompareResp{
+			From: toRevisionResp(res.From),
+			To:   toRevisionResp(res.To),
+			Diff: res.Diff,
+		}, name, success)
+	}
+}
+
+func (ctl *RevisionHandler) Restore() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Revision/Restore"
+		aid, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+		var req RestoreReq
+		if err = c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrRevisionInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		if err = ctl.svc.Restore(c.Request.Context(), claim.Id, aid, req.Version); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
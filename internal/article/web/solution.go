@@ -0,0 +1,154 @@
+package web
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	"github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/infra/contract/token"
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+)
+
+type SolutionHandler struct {
+	svc service.SolutionService
+}
+
+func NewSolutionHandler(svc service.SolutionService) *SolutionHandler {
+	return &SolutionHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *SolutionHandler) RegisterRoute(r *gin.Engine) {
+	// 通配符名需要和 problems/:name/description 保持一致，这里传的是题目 id
+	pmGroup := r.Group("api/problems")
+	{
+		pmGroup.GET(":name/solutions", ctl.List())
+		pmGroup.POST(":name/solutions/reveal", ctl.Reveal())
+	}
+
+	artGroup := r.Group("api/articles")
+	{
+		artGroup.POST("solution/:id", ctl.Attach())
+		artGroup.DELETE("solution/:id", ctl.Detach())
+	}
+}
+
+func (ctl *SolutionHandler) List() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Solution/List"
+		pid, err := strconv.ParseUint(c.Param("name"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrSolutionInvalidParams))
+			return
+		}
+		offset, _ := strconv.Atoi(c.Query("offset"))
+		limit, _ := strconv.Atoi(c.Query("limit"))
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, err := ctl.svc.List(c.Request.Context(), claim.Id, domain.SolutionQuery{
+			ProblemID: pid,
+			Language:  c.Query("lang"),
+			Sort:      domain.SolutionSort(c.Query("sort")),
+			Offset:    offset,
+			Limit:     limit,
+		})
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := make([]SolutionResp, 0, len(res))
+		for _, sol := range res {
+			resp = append(resp, SolutionResp{
+				ArticleID: sol.ArticleID,
+				Title:     sol.Title,
+				Abstract:  sol.Abstract,
+				AuthorID:  sol.Author.Id,
+				Languages: sol.Languages,
+				Inter:     sol.Inter,
+				Ctime:     sol.Ctime.String(),
+			})
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
+
+func (ctl *SolutionHandler) Reveal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Solution/Reveal"
+		pid, err := strconv.ParseUint(c.Param("name"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrSolutionInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		if err = ctl.svc.Reveal(c.Request.Context(), claim.Id, pid); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *SolutionHandler) Attach() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Solution/Attach"
+		aid, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrSolutionInvalidParams))
+			return
+		}
+		var req AttachSolutionReq
+		if err = c.ShouldBind(&req); err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrSolutionInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		err = ctl.svc.Attach(c.Request.Context(), domain.Solution{
+			ArticleID: aid,
+			ProblemID: req.ProblemID,
+			Author: domain.Author{
+				Id: claim.Id,
+			},
+			Languages: req.Languages,
+		})
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *SolutionHandler) Detach() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Solution/Detach"
+		aid, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, errors.NewBizError(constant.ErrSolutionInvalidParams))
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+
+		if err = ctl.svc.Detach(c.Request.Context(), claim.Id, aid); err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
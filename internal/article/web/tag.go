@@ -0,0 +1,77 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+)
+
+// TagHandler 标签页，标签本身在题目模块的 tags 接口里管理
+type TagHandler struct {
+	svc service.TagService
+}
+
+func NewTagHandler(svc service.TagService) *TagHandler {
+	return &TagHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *TagHandler) RegisterRoute(r *gin.Engine) {
+	tagGroup := r.Group("api/tags")
+	{
+		tagGroup.GET("", ctl.Counts())
+		tagGroup.GET(":name", ctl.Page())
+	}
+}
+
+func (ctl *TagHandler) Counts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Tag/Counts"
+
+		res, err := ctl.svc.Counts(c.Request.Context())
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := make([]TagResp, 0, len(res))
+		for _, t := range res {
+			resp = append(resp, toTagResp(t))
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
+
+// Page 标签下的题目和最近的文章，更多文章走 pub/list 按标签分页
+func (ctl *TagHandler) Page() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Tag/Page"
+
+		page, err := ctl.svc.Page(c.Request.Context(), c.Param("name"))
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		resp := TagPageResp{
+			TagResp:  toTagResp(page.TagCount),
+			Problems: make([]TagProblemResp, 0, len(page.Problems)),
+			Articles: make([]PubListResp, 0, len(page.Articles)),
+		}
+		for _, pm := range page.Problems {
+			resp.Problems = append(resp.Problems, TagProblemResp{
+				ID:       pm.ID,
+				Title:    pm.Title,
+				PassRate: pm.PassRate,
+			})
+		}
+		for _, art := range page.Articles {
+			resp.Articles = append(resp.Articles, toPubListResp(art))
+		}
+
+		response.SuccessWithLog(c, resp, name, success)
+	}
+}
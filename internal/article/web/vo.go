@@ -1,22 +1,29 @@
 package web
 
-import "github.com/crazyfrankie/onlinejudge/internal/article/domain"
+import (
+	"github.com/crazyfrankie/onlinejudge/internal/article/domain"
+	"github.com/crazyfrankie/onlinejudge/pkg/markdown"
+)
 
 // View Object
 
 type ListReq struct {
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
+	// Tag 只在线上列表中使用，按标签筛选
+	Tag string `json:"tag"`
 }
 
 type ArticleReq struct {
 	ID      uint64 `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// Tags 不传时保留原来的标签，传空数组时清空
+	Tags []string `json:"tags"`
 }
 
 func (req ArticleReq) toDomain(uid uint64) domain.Article {
-	return domain.Article{
+	art := domain.Article{
 		ID: req.ID,
 		Author: domain.Author{
 			Id: uid,
@@ -24,6 +31,14 @@ func (req ArticleReq) toDomain(uid uint64) domain.Article {
 		Title:   req.Title,
 		Content: req.Content,
 	}
+	if req.Tags != nil {
+		art.Tags = make([]domain.Tag, 0, len(req.Tags))
+		for _, name := range req.Tags {
+			art.Tags = append(art.Tags, domain.Tag{Name: name})
+		}
+	}
+
+	return art
 }
 
 type ListResp struct {
@@ -45,6 +60,18 @@ type PubListResp struct {
 	Utime      string `json:"utime"`
 }
 
+func toPubListResp(art domain.Article) PubListResp {
+	return PubListResp{
+		ID:       art.ID,
+		Title:    art.Title,
+		Abstract: art.Abstract(),
+		AuthorID: art.Author.Id,
+		Status:   art.Status.ToUint8(),
+		Ctime:    art.Ctime.String(),
+		Utime:    art.Utime.String(),
+	}
+}
+
 type DetailResp struct {
 	ID      uint64 `json:"ID"`
 	Title   string `json:"title"`
@@ -55,24 +82,263 @@ type DetailResp struct {
 }
 
 type PubDetailResp struct {
-	ID         uint64      `json:"ID"`
-	Title      string      `json:"title"`
-	Content    string      `json:"content"`
-	AuthorID   uint64      `json:"author_id"`
-	AuthorName string      `json:"author_name"`
-	Status     uint8       `json:"status"`
-	Ctime      string      `json:"ctime"`
-	Utime      string      `json:"utime"`
-	Inter      Interactive `json:"inter"`
-	Liked      bool        `json:"liked"`
+	ID      uint64 `json:"ID"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// HTML 服务端渲染并过滤过的正文，可以直接展示
+	HTML       string             `json:"html"`
+	TOC        []markdown.Heading `json:"toc"`
+	Tags       []string           `json:"tags"`
+	AuthorID   uint64             `json:"author_id"`
+	AuthorName string             `json:"author_name"`
+	Status     uint8              `json:"status"`
+	Ctime      string             `json:"ctime"`
+	Utime      string             `json:"utime"`
+	Inter      Interactive        `json:"inter"`
+	Liked      bool               `json:"liked"`
+	Collected  bool               `json:"collected"`
 }
 
 type Interactive struct {
-	LikeCnt int64 `json:"like_cnt"`
-	ReadCnt int64 `json:"read_cnt"`
+	LikeCnt    int64 `json:"like_cnt"`
+	ReadCnt    int64 `json:"read_cnt"`
+	CollectCnt int64 `json:"collect_cnt"`
 }
 
 type LikeReq struct {
 	ID   uint64 `json:"id"`
 	Like bool   `json:"like"`
 }
+
+type CommentReq struct {
+	Biz     string `json:"biz"`
+	BizID   uint64 `json:"biz_id"`
+	Content string `json:"content"`
+	PID     uint64 `json:"pid"`
+}
+
+type EditCommentReq struct {
+	Content string `json:"content"`
+}
+
+type LikeCommentReq struct {
+	Like bool `json:"like"`
+}
+
+type ModerateReq struct {
+	Action string `json:"action"`
+}
+
+type CommentResp struct {
+	ID       uint64             `json:"id"`
+	UID      uint64             `json:"uid"`
+	Content  string             `json:"content"`
+	RootID   uint64             `json:"root_id"`
+	PID      uint64             `json:"pid"`
+	Status   uint8              `json:"status"`
+	ReplyCnt int64              `json:"reply_cnt"`
+	Inter    domain.Interactive `json:"inter"`
+	Ctime    string             `json:"ctime"`
+	Utime    string             `json:"utime"`
+}
+
+type CommentListResp struct {
+	Comments   []CommentResp `json:"comments"`
+	NextCursor uint64        `json:"next_cursor"`
+}
+
+func toCommentListResp(cs []domain.Comment, next uint64) CommentListResp {
+	resp := CommentListResp{
+		Comments:   make([]CommentResp, 0, len(cs)),
+		NextCursor: next,
+	}
+	for _, c := range cs {
+		resp.Comments = append(resp.Comments, CommentResp{
+			ID:       c.ID,
+			UID:      c.Commentator.Id,
+			Content:  c.Content,
+			RootID:   c.RootID,
+			PID:      c.PID,
+			Status:   c.Status.ToUint8(),
+			ReplyCnt: c.ReplyCnt,
+			Inter:    c.Inter,
+			Ctime:    c.Ctime.String(),
+			Utime:    c.Utime.String(),
+		})
+	}
+
+	return resp
+}
+
+type AttachSolutionReq struct {
+	ProblemID uint64   `json:"problem_id"`
+	Languages []string `json:"languages"`
+}
+
+type SolutionResp struct {
+	ArticleID uint64             `json:"article_id"`
+	Title     string             `json:"title"`
+	Abstract  string             `json:"abstract"`
+	AuthorID  uint64             `json:"author_id"`
+	Languages []string           `json:"languages"`
+	Inter     domain.Interactive `json:"inter"`
+	Ctime     string             `json:"ctime"`
+}
+
+type FolderReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CollectReq struct {
+	Biz      string `json:"biz"`
+	BizID    uint64 `json:"biz_id"`
+	FolderID uint64 `json:"folder_id"`
+}
+
+type FolderResp struct {
+	ID          uint64 `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Cnt         int64  `json:"cnt"`
+}
+
+type CollectItemResp struct {
+	Biz   string `json:"biz"`
+	BizID uint64 `json:"biz_id"`
+	Title string `json:"title"`
+	Ctime string `json:"ctime"`
+}
+
+type MigratePatternReq struct {
+	Pattern string `json:"pattern"`
+}
+
+type MigrateCopyReq struct {
+	// Restart 丢弃断点重新复制
+	Restart bool `json:"restart"`
+}
+
+type MigrateValidateReq struct {
+	Repair bool `json:"repair"`
+}
+
+type RestoreReq struct {
+	Version int64 `json:"version"`
+}
+
+type RevisionResp struct {
+	Version   int64  `json:"version"`
+	Title     string `json:"title"`
+	Kind      string `json:"kind"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	// Content 和 Diff 只在详情中返回
+	Content string `json:"content,omitempty"`
+	Diff    string `json:"diff,omitempty"`
+	Ctime   string `json:"ctime"`
+}
+
+func toRevisionResp(rev domain.Revision) RevisionResp {
+	return RevisionResp{
+		Version:   rev.Version,
+		Title:     rev.Title,
+		Kind:      rev.Kind.String(),
+		Additions: rev.Diff.Additions,
+		Deletions: rev.Diff.Deletions,
+		Ctime:     rev.Ctime.String(),
+	}
+}
+
+type CompareResp struct {
+	From RevisionResp       `json:"from"`
+	To   RevisionResp       `json:"to"`
+	Diff domain.ContentDiff `json:"diff"`
+}
+
+type TagResp struct {
+	ID         uint64 `json:"id"`
+	Name       string `json:"name"`
+	ProblemCnt int64  `json:"problem_cnt"`
+	ArticleCnt int64  `json:"article_cnt"`
+}
+
+func toTagResp(c domain.TagCount) TagResp {
+	return TagResp{
+		ID:         c.ID,
+		Name:       c.Name,
+		ProblemCnt: c.ProblemCnt,
+		ArticleCnt: c.ArticleCnt,
+	}
+}
+
+type TagProblemResp struct {
+	ID       uint64 `json:"id"`
+	Title    string `json:"title"`
+	PassRate string `json:"pass_rate"`
+}
+
+type TagPageResp struct {
+	TagResp
+	Problems []TagProblemResp `json:"problems"`
+	Articles []PubListResp    `json:"articles"`
+}
+
+type FollowListReq struct {
+	Offset int `form:"offset"`
+	Limit  int `form:"limit"`
+}
+
+type FollowResp struct {
+	Uid   uint64 `json:"uid"`
+	Ctime string `json:"ctime"`
+}
+
+type FollowStatsResp struct {
+	Uid       uint64 `json:"uid"`
+	Followers int64  `json:"followers"`
+	Followees int64  `json:"followees"`
+	Following bool   `json:"following"`
+}
+
+type FeedReq struct {
+	// Cursor 上一页返回的 next_cursor，第一页不传
+	Cursor int64 `form:"cursor"`
+	Limit  int   `form:"limit"`
+}
+
+type FeedResp struct {
+	Articles   []PubListResp `json:"articles"`
+	NextCursor int64         `json:"next_cursor"`
+}
+
+func toFeedResp(arts []domain.Article, next int64) FeedResp {
+	resp := FeedResp{
+		Articles:   make([]PubListResp, 0, len(arts)),
+		NextCursor: next,
+	}
+	for _, art := range arts {
+		resp.Articles = append(resp.Articles, toPubListResp(art))
+	}
+
+	return resp
+}
+
+type ReviewListReq struct {
+	Offset int `form:"offset"`
+	Limit  int `form:"limit"`
+}
+
+type ReviewResp struct {
+	ArticleID uint64   `json:"article_id"`
+	AuthorID  uint64   `json:"author_id"`
+	Title     string   `json:"title"`
+	Reasons   []string `json:"reasons"`
+	Ctime     string   `json:"ctime"`
+}
+
+type ReviewReq struct {
+	Approve bool `json:"approve"`
+	// Note 审核意见，拒绝时告诉作者原因，最多 512 个字
+	Note string `json:"note"`
+}
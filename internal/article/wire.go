@@ -3,17 +3,26 @@
 package article
 
 import (
+	"time"
+
 	"github.com/IBM/sarama"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 
+	"github.com/crazyfrankie/onlinejudge/config"
 	"github.com/crazyfrankie/onlinejudge/internal/article/event"
+	"github.com/crazyfrankie/onlinejudge/internal/article/job"
+	"github.com/crazyfrankie/onlinejudge/internal/article/migrator"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository/cache"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
 	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service/moderation"
 	"github.com/crazyfrankie/onlinejudge/internal/article/web"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement"
+	"github.com/crazyfrankie/onlinejudge/internal/problem"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 	"github.com/crazyfrankie/onlinejudge/pkg/zapx"
 )
 
@@ -26,26 +35,163 @@ func NewSyncProducer(client sarama.Client) sarama.SyncProducer {
 	return res
 }
 
-func InitModule(db *gorm.DB, cmd redis.Cmdable, client sarama.Client, l *zapx.Logger) *Module {
+func InitRankingService(artRepo *repository.ArticleRepository, interRepo *repository.InteractiveArtRepository, repo *repository.RankingRepository) service.RankingService {
+	cfg := config.GetConf().Ranking
+	return service.NewBatchRankingService(artRepo, interRepo, repo, service.RankingConfig{
+		N:         cfg.N,
+		BatchSize: cfg.BatchSize,
+	})
+}
+
+func InitRankingJob(svc service.RankingService, cmd redis.Cmdable) *job.RankingJob {
+	return job.NewRankingJob(svc, cmd, time.Duration(config.GetConf().Ranking.Interval)*time.Second)
+}
+
+func InitInteractiveJob(repo *repository.InteractiveArtRepository, cmd redis.Cmdable) *job.InteractiveJob {
+	cfg := config.GetConf().Interactive
+	return job.NewInteractiveJob(repo, cmd, job.InteractiveConfig{
+		Interval:          time.Duration(cfg.FlushInterval) * time.Second,
+		BatchSize:         cfg.BatchSize,
+		ReconcileInterval: time.Duration(cfg.ReconcileInterval) * time.Second,
+	})
+}
+
+// InitMigrator 只有存储被包成双写时才需要迁移
+func InitMigrator(artDAO dao.ArticleDAO, cmd redis.Cmdable) *migrator.Migrator {
+	dw, ok := artDAO.(*dao.DoubleWriteDAO)
+	if !ok {
+		return nil
+	}
+
+	return migrator.NewMigrator(dw, cmd, config.GetConf().Article.Migration.BatchSize)
+}
+
+// InitFeedCache 没有配置时收件箱保留 1000 篇，7 天内读过动态的用户算活跃
+func InitFeedCache(cmd redis.Cmdable) *cache.FeedCache {
+	cfg := config.GetConf().Feed
+	if cfg.InboxSize <= 0 {
+		cfg.InboxSize = 1000
+	}
+	if cfg.ActiveDays <= 0 {
+		cfg.ActiveDays = 7
+	}
+
+	return cache.NewFeedCache(cmd, cfg.InboxSize, time.Duration(cfg.ActiveDays)*24*time.Hour)
+}
+
+// bigAuthor 没有配置时粉丝数超过 5000 算大 V
+func bigAuthor() int64 {
+	if n := config.GetConf().Feed.BigAuthor; n > 0 {
+		return n
+	}
+	return 5000
+}
+
+func InitFeedService(repo *repository.FeedRepository, followRepo *repository.FollowRepository, artRepo *repository.ArticleRepository) service.FeedService {
+	return service.NewFeedService(repo, followRepo, artRepo, bigAuthor())
+}
+
+func InitFeedConsumer(client sarama.Client, followRepo *repository.FollowRepository, feedRepo *repository.FeedRepository,
+	producer sarama.SyncProducer, l *zapx.Logger) *event.FeedConsumer {
+	return event.NewFeedConsumer(client, followRepo, feedRepo, producer, l, bigAuthor())
+}
+
+func InitModule(db *gorm.DB, cmd redis.Cmdable, client sarama.Client, l *zapx.Logger, pm *problem.Module, judge *judgement.Module, artDAO dao.ArticleDAO) *Module {
 	wire.Build(
-		dao.NewArticleDao,
 		dao.NewInteractiveDao,
+		dao.NewCommentDao,
+		dao.NewSolutionDao,
+		dao.NewCollectDao,
+		dao.NewRevisionDao,
+		dao.NewTagDao,
+		dao.NewFollowDao,
+		dao.NewReviewDao,
 		cache.NewInteractiveCache,
+		cache.NewRankingCache,
+		cache.NewLocalRankingCache,
+		cache.NewAutosaveCache,
+		cache.NewRenderCache,
+		InitFeedCache,
 
+		outbox.New,
 		repository.NewArticleRepository,
 		repository.NewInteractiveArtRepository,
+		repository.NewCommentRepository,
+		repository.NewSolutionRepository,
+		repository.NewCollectRepository,
+		repository.NewRankingRepository,
+		repository.NewRevisionRepository,
+		repository.NewRenderRepository,
+		repository.NewTagRepository,
+		repository.NewFollowRepository,
+		repository.NewFeedRepository,
+		repository.NewReviewRepository,
 
 		NewSyncProducer,
-		event.NewArticleProducer,
 		event.NewArticleConsumer,
+		InitFeedConsumer,
 
 		service.NewArticleService,
 		service.NewInteractiveService,
+		service.NewCommentService,
+		service.NewSolutionService,
+		service.NewCollectService,
+		service.NewRevisionService,
+		service.NewRenderService,
+		service.NewTagService,
+		service.NewFollowService,
+		InitFeedService,
+		InitModerator,
+		InitRankingService,
+		InitRankingJob,
+		InitInteractiveJob,
+		InitMigrator,
 
 		web.NewArticleHandler,
 		web.NewAdminHandler,
+		web.NewCommentHandler,
+		web.NewSolutionHandler,
+		web.NewCollectHandler,
+		web.NewRankingHandler,
+		web.NewMigrateHandler,
+		web.NewRevisionHandler,
+		web.NewTagHandler,
+		web.NewFollowHandler,
 
+		wire.FieldsOf(new(*problem.Module), "Repo"),
+		wire.FieldsOf(new(*judgement.Module), "SubmitRepo"),
 		wire.Struct(new(Module), "*"),
 	)
 	return new(Module)
 }
+
+// InitModerator 敏感词和链接规则总是启用，配置了外部服务时最后调用
+func InitModerator() moderation.Moderator {
+	cfg := config.GetConf().Moderation
+	words := cfg.Words
+	if cfg.WordsFile != "" {
+		fileWords, err := moderation.LoadWords(cfg.WordsFile)
+		if err != nil {
+			panic(err)
+		}
+		words = append(words, fileWords...)
+	}
+	verdict, ok := moderation.ParseVerdict(cfg.WordVerdict)
+	if !ok || verdict == moderation.VerdictPass {
+		verdict = moderation.VerdictReview
+	}
+
+	chain := moderation.NewChain(
+		moderation.NewWordFilter(words, verdict),
+		moderation.NewSpamFilter(moderation.SpamConfig{
+			MaxLinks:       cfg.MaxLinks,
+			MaxRepeat:      cfg.MaxRepeat,
+			BlockedDomains: cfg.BlockedDomains,
+		}),
+	)
+	if cfg.HookURL != "" {
+		chain = append(chain, moderation.NewHookModerator(cfg.HookURL, time.Duration(cfg.HookTimeout)*time.Millisecond))
+	}
+
+	return chain
+}
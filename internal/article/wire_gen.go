@@ -8,36 +8,101 @@ package article
 
 import (
 	"github.com/IBM/sarama"
+	"github.com/crazyfrankie/onlinejudge/config"
 	"github.com/crazyfrankie/onlinejudge/internal/article/event"
+	"github.com/crazyfrankie/onlinejudge/internal/article/job"
+	"github.com/crazyfrankie/onlinejudge/internal/article/migrator"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository/cache"
 	"github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
 	"github.com/crazyfrankie/onlinejudge/internal/article/service"
+	"github.com/crazyfrankie/onlinejudge/internal/article/service/moderation"
 	"github.com/crazyfrankie/onlinejudge/internal/article/web"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement"
+	"github.com/crazyfrankie/onlinejudge/internal/problem"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 	"github.com/crazyfrankie/onlinejudge/pkg/zapx"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"time"
 )
 
 // Injectors from wire.go:
 
-func InitModule(db *gorm.DB, cmd redis.Cmdable, client sarama.Client, l *zapx.Logger) *Module {
-	gormArticleDao := dao.NewArticleDao(db)
-	articleRepository := repository.NewArticleRepository(gormArticleDao)
-	syncProducer := NewSyncProducer(client)
-	producer := event.NewArticleProducer(syncProducer)
-	articleService := service.NewArticleService(articleRepository, producer)
+func InitModule(db *gorm.DB, cmd redis.Cmdable, client sarama.Client, l *zapx.Logger, pm *problem.Module, judge *judgement.Module, artDAO dao.ArticleDAO) *Module {
+	outboxOutbox := outbox.New(db)
+	articleRepository := repository.NewArticleRepository(artDAO, outboxOutbox)
+	revisionDao := dao.NewRevisionDao(db)
+	autosaveCache := cache.NewAutosaveCache(cmd)
+	revisionRepository := repository.NewRevisionRepository(revisionDao, autosaveCache)
+	tagDao := dao.NewTagDao(db)
+	tagRepository := repository.NewTagRepository(tagDao)
+	reviewDao := dao.NewReviewDao(db)
+	reviewRepository := repository.NewReviewRepository(reviewDao)
+	renderCache := cache.NewRenderCache(cmd)
+	renderRepository := repository.NewRenderRepository(renderCache)
+	renderService := service.NewRenderService(renderRepository)
+	moderator := InitModerator()
+	articleService := service.NewArticleService(articleRepository, revisionRepository, tagRepository, reviewRepository, renderService, moderator)
 	interactiveDao := dao.NewInteractiveDao(db)
 	interactiveCache := cache.NewInteractiveCache(cmd)
 	interactiveArtRepository := repository.NewInteractiveArtRepository(interactiveDao, interactiveCache)
 	interactiveService := service.NewInteractiveService(interactiveArtRepository)
-	articleHandler := web.NewArticleHandler(articleService, interactiveService)
+	solutionDao := dao.NewSolutionDao(db, artDAO)
+	solutionRepository := repository.NewSolutionRepository(solutionDao)
+	problemRepository := pm.Repo
+	localSubmitRepo := judge.SubmitRepo
+	solutionService := service.NewSolutionService(solutionRepository, articleRepository, problemRepository, localSubmitRepo)
+	tagService := service.NewTagService(tagRepository, articleRepository, problemRepository)
+	articleHandler := web.NewArticleHandler(articleService, interactiveService, solutionService, renderService, tagService)
 	adminHandler := web.NewAdminHandler(articleService)
-	consumer := event.NewArticleConsumer(client, interactiveArtRepository, l)
+	commentDao := dao.NewCommentDao(db)
+	commentRepository := repository.NewCommentRepository(commentDao)
+	commentService := service.NewCommentService(commentRepository, articleRepository, interactiveArtRepository, problemRepository)
+	commentHandler := web.NewCommentHandler(commentService)
+	solutionHandler := web.NewSolutionHandler(solutionService)
+	collectDao := dao.NewCollectDao(db)
+	collectRepository := repository.NewCollectRepository(collectDao)
+	collectService := service.NewCollectService(collectRepository, interactiveArtRepository, articleRepository, problemRepository)
+	collectHandler := web.NewCollectHandler(collectService)
+	rankingCache := cache.NewRankingCache(cmd)
+	localRankingCache := cache.NewLocalRankingCache()
+	rankingRepository := repository.NewRankingRepository(rankingCache, localRankingCache)
+	rankingService := InitRankingService(articleRepository, interactiveArtRepository, rankingRepository)
+	rankingHandler := web.NewRankingHandler(rankingService)
+	migratorMigrator := InitMigrator(artDAO, cmd)
+	migrateHandler := web.NewMigrateHandler(migratorMigrator)
+	revisionService := service.NewRevisionService(revisionRepository, articleRepository, reviewRepository)
+	revisionHandler := web.NewRevisionHandler(revisionService)
+	tagHandler := web.NewTagHandler(tagService)
+	followDao := dao.NewFollowDao(db)
+	followRepository := repository.NewFollowRepository(followDao)
+	feedCache := InitFeedCache(cmd)
+	feedRepository := repository.NewFeedRepository(feedCache)
+	followService := service.NewFollowService(followRepository, feedRepository)
+	feedService := InitFeedService(feedRepository, followRepository, articleRepository)
+	followHandler := web.NewFollowHandler(followService, feedService)
+	syncProducer := NewSyncProducer(client)
+	consumer := event.NewArticleConsumer(client, interactiveArtRepository, syncProducer, l)
+	feedConsumer := InitFeedConsumer(client, followRepository, feedRepository, syncProducer, l)
+	rankingJob := InitRankingJob(rankingService, cmd)
+	interactiveJob := InitInteractiveJob(interactiveArtRepository, cmd)
 	module := &Module{
-		Hdl:      articleHandler,
-		AdminHdl: adminHandler,
-		Consumer: consumer,
+		Hdl:            articleHandler,
+		AdminHdl:       adminHandler,
+		CommentHdl:     commentHandler,
+		SolHdl:         solutionHandler,
+		CollectHdl:     collectHandler,
+		RankingHdl:     rankingHandler,
+		MigrateHdl:     migrateHandler,
+		RevHdl:         revisionHandler,
+		TagHdl:         tagHandler,
+		FollowHdl:      followHandler,
+		Consumer:       consumer,
+		FeedConsumer:   feedConsumer,
+		RankingJob:     rankingJob,
+		InteractiveJob: interactiveJob,
+		Migrator:       migratorMigrator,
 	}
 	return module
 }
@@ -52,3 +117,95 @@ func NewSyncProducer(client sarama.Client) sarama.SyncProducer {
 
 	return res
 }
+
+func InitRankingService(artRepo *repository.ArticleRepository, interRepo *repository.InteractiveArtRepository, repo *repository.RankingRepository) service.RankingService {
+	cfg := config.GetConf().Ranking
+	return service.NewBatchRankingService(artRepo, interRepo, repo, service.RankingConfig{
+		N:         cfg.N,
+		BatchSize: cfg.BatchSize,
+	})
+}
+
+func InitRankingJob(svc service.RankingService, cmd redis.Cmdable) *job.RankingJob {
+	return job.NewRankingJob(svc, cmd, time.Duration(config.GetConf().Ranking.Interval)*time.Second)
+}
+
+func InitInteractiveJob(repo *repository.InteractiveArtRepository, cmd redis.Cmdable) *job.InteractiveJob {
+	cfg := config.GetConf().Interactive
+	return job.NewInteractiveJob(repo, cmd, job.InteractiveConfig{
+		Interval:          time.Duration(cfg.FlushInterval) * time.Second,
+		BatchSize:         cfg.BatchSize,
+		ReconcileInterval: time.Duration(cfg.ReconcileInterval) * time.Second,
+	})
+}
+
+// InitMigrator 只有存储被包成双写时才需要迁移
+func InitMigrator(artDAO dao.ArticleDAO, cmd redis.Cmdable) *migrator.Migrator {
+	dw, ok := artDAO.(*dao.DoubleWriteDAO)
+	if !ok {
+		return nil
+	}
+
+	return migrator.NewMigrator(dw, cmd, config.GetConf().Article.Migration.BatchSize)
+}
+
+// InitFeedCache 没有配置时收件箱保留 1000 篇，7 天内读过动态的用户算活跃
+func InitFeedCache(cmd redis.Cmdable) *cache.FeedCache {
+	cfg := config.GetConf().Feed
+	if cfg.InboxSize <= 0 {
+		cfg.InboxSize = 1000
+	}
+	if cfg.ActiveDays <= 0 {
+		cfg.ActiveDays = 7
+	}
+
+	return cache.NewFeedCache(cmd, cfg.InboxSize, time.Duration(cfg.ActiveDays)*24*time.Hour)
+}
+
+// bigAuthor 没有配置时粉丝数超过 5000 算大 V
+func bigAuthor() int64 {
+	if n := config.GetConf().Feed.BigAuthor; n > 0 {
+		return n
+	}
+	return 5000
+}
+
+func InitFeedService(repo *repository.FeedRepository, followRepo *repository.FollowRepository, artRepo *repository.ArticleRepository) service.FeedService {
+	return service.NewFeedService(repo, followRepo, artRepo, bigAuthor())
+}
+
+func InitFeedConsumer(client sarama.Client, followRepo *repository.FollowRepository, feedRepo *repository.FeedRepository,
+	producer sarama.SyncProducer, l *zapx.Logger) *event.FeedConsumer {
+	return event.NewFeedConsumer(client, followRepo, feedRepo, producer, l, bigAuthor())
+}
+
+// InitModerator 敏感词和链接规则总是启用，配置了外部服务时最后调用
+func InitModerator() moderation.Moderator {
+	cfg := config.GetConf().Moderation
+	words := cfg.Words
+	if cfg.WordsFile != "" {
+		fileWords, err := moderation.LoadWords(cfg.WordsFile)
+		if err != nil {
+			panic(err)
+		}
+		words = append(words, fileWords...)
+	}
+	verdict, ok := moderation.ParseVerdict(cfg.WordVerdict)
+	if !ok || verdict == moderation.VerdictPass {
+		verdict = moderation.VerdictReview
+	}
+
+	chain := moderation.NewChain(
+		moderation.NewWordFilter(words, verdict),
+		moderation.NewSpamFilter(moderation.SpamConfig{
+			MaxLinks:       cfg.MaxLinks,
+			MaxRepeat:      cfg.MaxRepeat,
+			BlockedDomains: cfg.BlockedDomains,
+		}),
+	)
+	if cfg.HookURL != "" {
+		chain = append(chain, moderation.NewHookModerator(cfg.HookURL, time.Duration(cfg.HookTimeout)*time.Millisecond))
+	}
+
+	return chain
+}
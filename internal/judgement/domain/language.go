@@ -0,0 +1,38 @@
+package domain
+
+import "math"
+
+// Language 评测支持的语言
+// Id 同时也是提交时使用的语言标识，与 harness 生成器的 key 保持一致
+type Language struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Extension string `json:"extension"`
+	// Judge0Id 远程评测（Judge0）中的语言编号
+	Judge0Id int `json:"-"`
+	// GoJudge 本地评测机（go-judge）中的语言枚举名
+	GoJudge string `json:"-"`
+	// TimeFactor/MemFactor 时间、内存限制的倍率，例如 Java、Python 通常需要放宽
+	TimeFactor float64 `json:"timeFactor"`
+	MemFactor  float64 `json:"memFactor"`
+	Enabled    bool    `json:"enabled"`
+}
+
+// TimeLimit 按倍率换算题目的时间限制，限制为 0 表示不限制
+func (l Language) TimeLimit(base int) int {
+	return scale(base, l.TimeFactor)
+}
+
+// MemLimit 按倍率换算题目的内存限制，限制为 0 表示不限制
+func (l Language) MemLimit(base int) int {
+	return scale(base, l.MemFactor)
+}
+
+func scale(base int, factor float64) int {
+	if base <= 0 || factor <= 0 {
+		return base
+	}
+
+	return int(math.Ceil(float64(base) * factor))
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	EngineLocal  = "local"
+	EngineRemote = "remote"
+)
+
+// resultVersion 评测驱动、判题逻辑变化时改这里，旧的缓存自然失效
+const resultVersion = "v1"
+
+// ResultKey 评测结果只取决于题目数据、语言限制与代码本身，与提交者无关
+// 题目的测试数据或限制变化时 Revision 加一，语言倍率变化体现在 TimeLimit/MemLimit 上
+type ResultKey struct {
+	// Engine 本地评测与 Judge0 返回的结构不同，分开存
+	Engine    string
+	ProblemId uint64
+	Revision  int64
+	Language  string
+	TimeLimit int
+	MemLimit  int
+	CodeHash  string
+}
+
+func (k ResultKey) String() string {
+	return fmt.Sprintf("judge:result:%s:%s:%d:%d:%s:%d:%d:%s", resultVersion, k.Engine, k.ProblemId,
+		k.Revision, k.Language, k.TimeLimit, k.MemLimit, k.CodeHash)
+}
+
+// NormalizedHash 只抹掉不可能影响编译运行结果的差异：BOM、换行符与文件末尾的空白
+// 行内空白与注释在字符串字面量里同样有意义，这里不做处理，宁可少命中也不能串结果
+func NormalizedHash(code string) string {
+	code = strings.TrimPrefix(code, "\uFEFF")
+	code = strings.ReplaceAll(code, "\r\n", "\n")
+	code = strings.TrimRight(code, " \t\r\n")
+
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+const StatusAccepted = "Accepted"
+
+// Cacheable 只有确定性的结论才能缓存，超时、系统错误之类的结果和评测机负载有关
+func Cacheable(statusMsg string) bool {
+	switch statusMsg {
+	case StatusAccepted, "Wrong Answer", "Compile Error", "Compilation Error":
+		return true
+	}
+	return false
+}
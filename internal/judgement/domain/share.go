@@ -0,0 +1,21 @@
+package domain
+
+// Share 提交的分享链接，Token 不可猜测，ExpireAt 为 0 表示永不过期
+type Share struct {
+	Token        string `json:"token"`
+	SubmissionId uint64 `json:"submissionId"`
+	UserId       uint64 `json:"-"`
+	ExpireAt     int64  `json:"expireAt"`
+	Revoked      bool   `json:"revoked"`
+	Ctime        int64  `json:"ctime"`
+}
+
+// SharedSubmission 通过分享链接看到的内容，不包含提交者信息
+type SharedSubmission struct {
+	ProblemId  uint64     `json:"problemId"`
+	Language   string     `json:"language"`
+	Code       string     `json:"code"`
+	SubmitTime int64      `json:"submitTime"`
+	Evaluation Evaluation `json:"evaluation"`
+	ExpireAt   int64      `json:"expireAt"`
+}
@@ -8,6 +8,19 @@ type Submission struct {
 	CodeHash   string `json:"codeHash"`
 	Language   string `json:"language"`
 	SubmitTime int64  `json:"submitTime"`
+	// ContestId 不为 0 表示是比赛中的提交
+	ContestId uint64 `json:"contestId"`
+	// IdempotencyKey 客户端通过 Idempotency-Key 请求头传入，同一个 key 只会评测一次
+	IdempotencyKey string `json:"-"`
+}
+
+// SubmitResult 提交的结果，重复提交时直接带上已有的评测结果
+type SubmitResult struct {
+	SubmissionId uint64
+	Duplicate    bool
+	Evaluation   *Evaluation
+	// Cases 远程评测逐个用例的结果
+	Cases []RemoteEvaluation
 }
 
 type Evaluation struct {
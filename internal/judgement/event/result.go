@@ -0,0 +1,46 @@
+package event
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
+)
+
+const TopicResult = "judge_result"
+
+// ResultEvent 评测结束，和评测记录在同一个事务里写入发件箱
+type ResultEvent struct {
+	SubmissionId uint64 `json:"submission_id"`
+	ProblemId    uint64 `json:"problem_id"`
+	Uid          uint64 `json:"uid"`
+	ContestId    uint64 `json:"contest_id"`
+	Lang         string `json:"lang"`
+	State        string `json:"state"`
+	StatusMsg    string `json:"status_msg"`
+	CpuTimeUsed  int64  `json:"cpu_time_used"`
+	MemoryUsed   int64  `json:"memory_used"`
+	// Ctime 评测结束的时间（毫秒）
+	Ctime int64 `json:"ctime"`
+}
+
+// NewResultOutbox 按提交者分区，同一个用户的评测结果按顺序消费
+func NewResultOutbox(sub domain.Submission, sid uint64, eva domain.Evaluation) outbox.Event {
+	return outbox.Event{
+		Topic: TopicResult,
+		Key:   strconv.FormatUint(sub.UserId, 10),
+		Payload: ResultEvent{
+			SubmissionId: sid,
+			ProblemId:    sub.ProblemID,
+			Uid:          sub.UserId,
+			ContestId:    sub.ContestId,
+			Lang:         sub.Language,
+			State:        eva.State,
+			StatusMsg:    eva.StatusMsg,
+			CpuTimeUsed:  eva.CpuTimeUsed,
+			MemoryUsed:   eva.MemoryUsed,
+			Ctime:        time.Now().UnixMilli(),
+		},
+	}
+}
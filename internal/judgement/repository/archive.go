@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/dao"
+)
+
+type CodeArchiveRepo interface {
+	FindArchivable(ctx context.Context, before time.Time, limit int) ([]domain.Submission, error)
+	// Archive 返回压缩后的大小
+	Archive(ctx context.Context, sub domain.Submission) (int, error)
+}
+
+type S3CodeArchiveRepo struct {
+	dao     *dao.SubmitDao
+	archive dao.CodeArchive
+}
+
+func NewCodeArchiveRepo(dao *dao.SubmitDao, archive dao.CodeArchive) CodeArchiveRepo {
+	return &S3CodeArchiveRepo{
+		dao:     dao,
+		archive: archive,
+	}
+}
+
+func (r *S3CodeArchiveRepo) FindArchivable(ctx context.Context, before time.Time, limit int) ([]domain.Submission, error) {
+	subs, err := r.dao.FindArchivable(ctx, before.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Submission, 0, len(subs))
+	for _, s := range subs {
+		res = append(res, toDomainSubmission(s))
+	}
+
+	return res, nil
+}
+
+// Archive 先上传再清空数据库里的代码，中途失败时下次重新上传即可
+func (r *S3CodeArchiveRepo) Archive(ctx context.Context, sub domain.Submission) (int, error) {
+	key := codeKey(sub.Id)
+	size, err := r.archive.Put(ctx, key, sub.Code)
+	if err != nil {
+		return 0, err
+	}
+
+	return size, r.dao.MarkArchived(ctx, sub.Id, key)
+}
+
+func codeKey(sid uint64) string {
+	return fmt.Sprintf("submission/%d.zst", sid)
+}
+
+func toDomainSubmission(s dao.Submission) domain.Submission {
+	return domain.Submission{
+		Id:         s.Id,
+		ProblemID:  s.ProblemID,
+		UserId:     s.UserId,
+		Code:       s.Code,
+		CodeHash:   s.CodeHash,
+		Language:   s.Language,
+		SubmitTime: s.SubmitTime,
+	}
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/config"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+)
+
+var (
+	ErrSubmitPending = errors.New("same submission is being created")
+)
+
+//go:embed lua/dedup.lua
+var luaDedup string
+
+// SubmitDedupCache 提交去重
+// 窗口期内相同用户、题目、语言且归一化后代码相同的提交，或者携带相同幂等键的提交，都直接复用第一次的提交
+type SubmitDedupCache interface {
+	// Claim 抢占成功返回 0；重复提交返回已有的提交 id；前一次提交还没拿到 id 时返回 ErrSubmitPending
+	Claim(ctx context.Context, sub domain.Submission) (uint64, error)
+	// Bind 把提交 id 回填到 Claim 占位的 key 上
+	Bind(ctx context.Context, sub domain.Submission, sid uint64) error
+	// Release 提交创建失败时释放占位，避免窗口期内无法再次提交
+	Release(ctx context.Context, sub domain.Submission) error
+}
+
+type RedisSubmitDedupCache struct {
+	cmd redis.Cmdable
+	// window 相同代码的去重窗口
+	window time.Duration
+	// idemTTL 幂等键的有效期，一般比去重窗口长得多
+	idemTTL time.Duration
+}
+
+func NewSubmitDedupCache(cmd redis.Cmdable) SubmitDedupCache {
+	window := time.Duration(config.GetConf().Judge.DedupWindow) * time.Second
+	if window <= 0 {
+		window = time.Second * 10
+	}
+	idemTTL := time.Duration(config.GetConf().Judge.IdempotencyTTL) * time.Second
+	if idemTTL <= 0 {
+		idemTTL = time.Hour * 24
+	}
+
+	return &RedisSubmitDedupCache{
+		cmd:     cmd,
+		window:  window,
+		idemTTL: idemTTL,
+	}
+}
+
+func (c *RedisSubmitDedupCache) Claim(ctx context.Context, sub domain.Submission) (uint64, error) {
+	keys := c.keys(sub)
+	args := []any{int64(c.window.Seconds())}
+	if len(keys) > 1 {
+		args = append(args, int64(c.idemTTL.Seconds()))
+	}
+
+	res, err := c.cmd.Eval(ctx, luaDedup, keys, args...).Text()
+	if err != nil {
+		return 0, err
+	}
+
+	switch res {
+	case "":
+		return 0, nil
+	case "0":
+		return 0, ErrSubmitPending
+	}
+
+	return strconv.ParseUint(res, 10, 64)
+}
+
+func (c *RedisSubmitDedupCache) Bind(ctx context.Context, sub domain.Submission, sid uint64) error {
+	pipe := c.cmd.Pipeline()
+	for _, key := range c.keys(sub) {
+		// 只覆盖自己占的位，保留原来的过期时间
+		pipe.SetArgs(ctx, key, sid, redis.SetArgs{Mode: "XX", KeepTTL: true})
+	}
+	_, err := pipe.Exec(ctx)
+	if errors.Is(err, redis.Nil) {
+		// 占位已经过期了，不影响本次提交
+		return nil
+	}
+
+	return err
+}
+
+func (c *RedisSubmitDedupCache) Release(ctx context.Context, sub domain.Submission) error {
+	return c.cmd.Del(ctx, c.keys(sub)...).Err()
+}
+
+func (c *RedisSubmitDedupCache) keys(sub domain.Submission) []string {
+	keys := []string{fmt.Sprintf("submit:dedup:%d:%d:%s:%s", sub.UserId, sub.ProblemID, sub.Language, sub.CodeHash)}
+	if sub.IdempotencyKey != "" {
+		keys = append(keys, fmt.Sprintf("submit:idem:%d:%s", sub.UserId, sub.IdempotencyKey))
+	}
+
+	return keys
+}
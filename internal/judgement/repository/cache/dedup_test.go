@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+)
+
+func TestRedisSubmitDedupCache(t *testing.T) {
+	sub := domain.Submission{UserId: 1, ProblemID: 2, Language: "go", CodeHash: "hash"}
+	idem := sub
+	idem.IdempotencyKey = "key"
+	dedupKey := "submit:dedup:1:2:go:hash"
+	idemKey := "submit:idem:1:key"
+
+	testCases := []struct {
+		name string
+		// before 在 Claim 之前执行
+		before  func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis)
+		sub     domain.Submission
+		wantSid uint64
+		wantErr error
+		after   func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis)
+	}{
+		{
+			name: "claim on empty key",
+			sub:  idem,
+			after: func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis) {
+				// 两个 key 都占位，过期时间各自不同
+				assert.Equal(t, "0", mustGet(t, mr, dedupKey))
+				assert.Equal(t, "0", mustGet(t, mr, idemKey))
+				assert.Equal(t, time.Second*10, mr.TTL(dedupKey))
+				assert.Equal(t, time.Hour, mr.TTL(idemKey))
+			},
+		},
+		{
+			name: "claim while pending",
+			before: func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis) {
+				_, err := c.Claim(context.Background(), sub)
+				require.NoError(t, err)
+			},
+			sub:     sub,
+			wantErr: ErrSubmitPending,
+		},
+		{
+			name: "duplicate after bind",
+			before: func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis) {
+				_, err := c.Claim(context.Background(), sub)
+				require.NoError(t, err)
+				mr.FastForward(time.Second * 3)
+				require.NoError(t, c.Bind(context.Background(), sub, 42))
+			},
+			sub:     sub,
+			wantSid: 42,
+			after: func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis) {
+				// 回填不会延长去重窗口
+				assert.Equal(t, time.Second*7, mr.TTL(dedupKey))
+			},
+		},
+		{
+			name: "same idempotency key with different code",
+			before: func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis) {
+				_, err := c.Claim(context.Background(), idem)
+				require.NoError(t, err)
+				require.NoError(t, c.Bind(context.Background(), idem, 42))
+			},
+			sub: func() domain.Submission {
+				s := idem
+				s.CodeHash = "other"
+				return s
+			}(),
+			wantSid: 42,
+		},
+		{
+			name: "claim after release",
+			before: func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis) {
+				_, err := c.Claim(context.Background(), idem)
+				require.NoError(t, err)
+				require.NoError(t, c.Release(context.Background(), idem))
+			},
+			sub: idem,
+		},
+		{
+			name: "claim after window",
+			before: func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis) {
+				_, err := c.Claim(context.Background(), sub)
+				require.NoError(t, err)
+				require.NoError(t, c.Bind(context.Background(), sub, 42))
+				mr.FastForward(time.Second * 11)
+			},
+			sub: sub,
+		},
+		{
+			name: "bind after expired",
+			before: func(t *testing.T, c SubmitDedupCache, mr *miniredis.Miniredis) {
+				_, err := c.Claim(context.Background(), sub)
+				require.NoError(t, err)
+				mr.FastForward(time.Second * 11)
+				// 占位已经过期，不报错也不重新写入
+				require.NoError(t, c.Bind(context.Background(), sub, 42))
+				assert.False(t, mr.Exists(dedupKey))
+			},
+			sub: sub,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+			c := &RedisSubmitDedupCache{cmd: client, window: time.Second * 10, idemTTL: time.Hour}
+			if tc.before != nil {
+				tc.before(t, c, mr)
+			}
+
+			sid, err := c.Claim(context.Background(), tc.sub)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantSid, sid)
+			if tc.after != nil {
+				tc.after(t, c, mr)
+			}
+		})
+	}
+}
+
+func mustGet(t *testing.T, mr *miniredis.Miniredis, key string) string {
+	val, err := mr.Get(key)
+	require.NoError(t, err)
+	return val
+}
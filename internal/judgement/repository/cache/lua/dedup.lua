@@ -0,0 +1,19 @@
+-- 同一份提交的去重 key，按代码内容与幂等键各有一个
+-- submit:dedup:{uid}:{pid}:{lang}:{hash}
+-- submit:idem:{uid}:{idempotency-key}
+-- ARGV[1] 与 ARGV[2] 分别是两个 key 的过期时间（秒），顺序与 KEYS 对应
+
+-- 任意一个 key 已经存在，说明是重复提交，返回已有的提交 id
+-- 值为 "0" 表示前一次提交还在写库，id 还没回填
+for i = 1, #KEYS do
+    local val = redis.call("get", KEYS[i])
+    if val then
+        return val
+    end
+end
+
+-- 都不存在，抢占成功，先占位，拿到提交 id 后再回填
+for i = 1, #KEYS do
+    redis.call("set", KEYS[i], "0", "EX", ARGV[i])
+end
+return ""
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+)
+
+type JudgeResultCache interface {
+	Get(ctx context.Context, key domain.ResultKey, val any) error
+	Set(ctx context.Context, key domain.ResultKey, val any) error
+}
+
+type RedisJudgeResultCache struct {
+	cmd        redis.Cmdable
+	expiration time.Duration
+}
+
+func NewJudgeResultCache(cmd redis.Cmdable) JudgeResultCache {
+	return &RedisJudgeResultCache{
+		cmd:        cmd,
+		expiration: time.Hour * 24,
+	}
+}
+
+func (c *RedisJudgeResultCache) Get(ctx context.Context, key domain.ResultKey, val any) error {
+	data, err := c.cmd.Get(ctx, key.String()).Bytes()
+	if err != nil {
+		return err
+	}
+
+	return sonic.Unmarshal(data, val)
+}
+
+func (c *RedisJudgeResultCache) Set(ctx context.Context, key domain.ResultKey, val any) error {
+	data, err := sonic.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	return c.cmd.Set(ctx, key.String(), data, c.expiration).Err()
+}
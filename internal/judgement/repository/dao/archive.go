@@ -0,0 +1,75 @@
+package dao
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CodeArchive 归档后的提交代码，存取时透明地做 zstd 压缩与解压
+type CodeArchive interface {
+	// Put 返回压缩后的大小，用于统计
+	Put(ctx context.Context, key string, code string) (int, error)
+	Get(ctx context.Context, key string) (string, error)
+}
+
+type S3CodeArchive struct {
+	oss    *s3.S3
+	bucket string
+	enc    *zstd.Encoder
+	dec    *zstd.Decoder
+}
+
+func NewS3CodeArchive(oss *s3.S3, bucket string) CodeArchive {
+	// 只用 EncodeAll/DecodeAll，不需要后台 goroutine
+	enc, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	return &S3CodeArchive{
+		oss:    oss,
+		bucket: bucket,
+		enc:    enc,
+		dec:    dec,
+	}
+}
+
+func (a *S3CodeArchive) Put(ctx context.Context, key string, code string) (int, error) {
+	data := a.enc.EncodeAll([]byte(code), nil)
+	_, err := a.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:          aws.String(a.bucket),
+		Key:             aws.String(key),
+		Body:            bytes.NewReader(data),
+		ContentType:     aws.String("application/zstd"),
+		ContentEncoding: aws.String("zstd"),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
+func (a *S3CodeArchive) Get(ctx context.Context, key string) (string, error) {
+	out, err := a.oss.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return "", err
+	}
+	code, err := a.dec.DecodeAll(data, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(code), nil
+}
@@ -7,9 +7,10 @@ type Submission struct {
 	Code       string
 	CodeHash   string `gorm:"index:pid_uid_hash_lang;not null"`
 	Language   string `gorm:"index:pid_uid_hash_lang;not null"`
+	CodeKey    string `gorm:"type:varchar(128);not null;default:''"` // 代码归档后在对象存储中的 key，为空表示代码还在 Code 字段里
 	State      string
 	SubmitTime int64
-	Ctime      int64
+	Ctime      int64 `gorm:"index"`
 	Uptime     int64
 	Deltime    int64
 }
@@ -61,3 +62,14 @@ func (s State) ToString() string {
 
 	return "unknown state"
 }
+
+type SubmissionShare struct {
+	Id           uint64 `gorm:"primaryKey,autoIncrement"`
+	Token        string `gorm:"type:varchar(64);uniqueIndex;not null"`
+	SubmissionId uint64 `gorm:"index;not null"`
+	UserId       uint64 `gorm:"index;not null"`
+	ExpireAt     int64
+	Revoked      bool
+	Ctime        int64
+	Utime        int64
+}
@@ -0,0 +1,51 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrShareNotFound = errors.New("share not found")
+
+type ShareDao struct {
+	db *gorm.DB
+}
+
+func NewShareDao(db *gorm.DB) *ShareDao {
+	return &ShareDao{db: db}
+}
+
+func (d *ShareDao) Insert(ctx context.Context, share SubmissionShare) error {
+	now := time.Now().Unix()
+	share.Ctime, share.Utime = now, now
+	return d.db.WithContext(ctx).Create(&share).Error
+}
+
+func (d *ShareDao) FindByToken(ctx context.Context, token string) (SubmissionShare, error) {
+	var share SubmissionShare
+	err := d.db.WithContext(ctx).Where("token = ?", token).First(&share).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return share, ErrShareNotFound
+	}
+	return share, err
+}
+
+// Revoke 只有分享者本人可以撤销
+func (d *ShareDao) Revoke(ctx context.Context, uid uint64, token string) error {
+	res := d.db.WithContext(ctx).Model(&SubmissionShare{}).
+		Where("token = ? AND user_id = ?", token, uid).
+		Updates(map[string]any{
+			"revoked": true,
+			"utime":   time.Now().Unix(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
@@ -8,13 +8,14 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 )
 
 type SubmissionDao interface {
 	CreateSubmit(ctx context.Context, sub domain.Submission) (uint64, error)
 	CreateEvaluate(ctx context.Context, eva domain.Evaluation) error
 	UpdateEvaluate(ctx context.Context, pid, sid uint64, state string) error
-	UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any) error
+	UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any, evts ...outbox.Event) error
 	FindEvaluate(ctx context.Context, sid uint64) (domain.Evaluation, error)
 }
 
@@ -89,11 +90,18 @@ func (d *SubmitDao) UpdateEvaluate(ctx context.Context, pid, sid uint64, state s
 	return nil
 }
 
-func (d *SubmitDao) UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any) error {
-	err := d.db.WithContext(ctx).Model(&Evaluation{}).
-		Where("problem_id = ? AND submission_id = ?", pid, sid).
-		Updates(res).Error
-	return err
+// UpdateResult evts 和评测结果在同一个事务里写入发件箱
+func (d *SubmitDao) UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any, evts ...outbox.Event) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Evaluation{}).
+			Where("problem_id = ? AND submission_id = ?", pid, sid).
+			Updates(res).Error
+		if err != nil {
+			return err
+		}
+
+		return outbox.Append(tx, evts...)
+	})
 }
 
 func (d *SubmitDao) FindEvaluate(ctx context.Context, sid uint64) (domain.Evaluation, error) {
@@ -132,3 +140,41 @@ func (d *SubmitDao) FindEvaluate(ctx context.Context, sid uint64) (domain.Evalua
 		State:        State(eva.State).ToString(),
 	}, err
 }
+
+func (d *SubmitDao) FindSubmission(ctx context.Context, sid uint64) (Submission, error) {
+	var sub Submission
+	err := d.db.WithContext(ctx).Where("id = ?", sid).First(&sub).Error
+	return sub, err
+}
+
+// HasAccepted 用户在这道题上是否有过通过的提交
+func (d *SubmitDao) HasAccepted(ctx context.Context, uid, pid uint64) (bool, error) {
+	var cnt int64
+	err := d.db.WithContext(ctx).Model(&Submission{}).
+		Joins("JOIN evaluations ON evaluations.submission_id = submissions.id").
+		Where("submissions.user_id = ? AND submissions.problem_id = ? AND evaluations.status_msg = ?", uid, pid, domain.StatusAccepted).
+		Limit(1).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+// FindArchivable 找出 before 之前提交、代码还在 MySQL 中的记录
+func (d *SubmitDao) FindArchivable(ctx context.Context, before int64, limit int) ([]Submission, error) {
+	var subs []Submission
+	err := d.db.WithContext(ctx).
+		Where("ctime < ? AND code_key = ''", before).
+		Order("id").Limit(limit).
+		Find(&subs).Error
+	return subs, err
+}
+
+// MarkArchived 代码已经写到对象存储之后再清空 Code，重复执行不会丢代码
+func (d *SubmitDao) MarkArchived(ctx context.Context, sid uint64, key string) error {
+	return d.db.WithContext(ctx).Model(&Submission{}).
+		Where("id = ? AND code_key = ''", sid).
+		Updates(map[string]any{
+			"code":     "",
+			"code_key": key,
+			"uptime":   time.Now().Unix(),
+		}).Error
+}
@@ -6,25 +6,44 @@ import (
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/cache"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/dao"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 )
 
 type LocalSubmitRepo interface {
 	CreateSubmit(ctx context.Context, sub domain.Submission) (uint64, error)
 	CreateEvaluate(ctx context.Context, eva domain.Evaluation) error
 	UpdateEvaluate(ctx context.Context, pid, sid uint64, state string) error
-	UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any) error
+	UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any, evts ...outbox.Event) error
 	FindEvaluate(ctx context.Context, sid uint64) (domain.Evaluation, error)
+	// FindSubmission 代码已归档时从对象存储取回，调用方无需关心
+	FindSubmission(ctx context.Context, sid uint64) (domain.Submission, error)
+	HasAccepted(ctx context.Context, uid, pid uint64) (bool, error)
+
+	ClaimSubmit(ctx context.Context, sub domain.Submission) (uint64, error)
+	BindSubmit(ctx context.Context, sub domain.Submission, sid uint64) error
+	ReleaseSubmit(ctx context.Context, sub domain.Submission) error
+
+	FindResult(ctx context.Context, key domain.ResultKey) (domain.Evaluation, error)
+	StoreResult(ctx context.Context, key domain.ResultKey, eva domain.Evaluation) error
 }
 
+var ErrSubmitPending = cache.ErrSubmitPending
+
 type LocalSubmissionRepo struct {
-	dao   *dao.SubmitDao
-	cache cache.LocalSubmitCache
+	dao     *dao.SubmitDao
+	cache   cache.LocalSubmitCache
+	dedup   cache.SubmitDedupCache
+	result  cache.JudgeResultCache
+	archive dao.CodeArchive
 }
 
-func NewLocalSubmitRepo(cache cache.LocalSubmitCache, dao *dao.SubmitDao) LocalSubmitRepo {
+func NewLocalSubmitRepo(cache cache.LocalSubmitCache, dedup cache.SubmitDedupCache, result cache.JudgeResultCache, archive dao.CodeArchive, dao *dao.SubmitDao) LocalSubmitRepo {
 	return &LocalSubmissionRepo{
-		cache: cache,
-		dao:   dao,
+		cache:   cache,
+		dedup:   dedup,
+		result:  result,
+		archive: archive,
+		dao:     dao,
 	}
 }
 
@@ -44,6 +63,47 @@ func (r *LocalSubmissionRepo) FindEvaluate(ctx context.Context, sid uint64) (dom
 	return r.dao.FindEvaluate(ctx, sid)
 }
 
-func (r *LocalSubmissionRepo) UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any) error {
-	return r.dao.UpdateResult(ctx, pid, sid, res)
+func (r *LocalSubmissionRepo) FindSubmission(ctx context.Context, sid uint64) (domain.Submission, error) {
+	sub, err := r.dao.FindSubmission(ctx, sid)
+	if err != nil {
+		return domain.Submission{}, err
+	}
+	if sub.CodeKey != "" {
+		sub.Code, err = r.archive.Get(ctx, sub.CodeKey)
+		if err != nil {
+			return domain.Submission{}, err
+		}
+	}
+
+	return toDomainSubmission(sub), nil
+}
+
+func (r *LocalSubmissionRepo) HasAccepted(ctx context.Context, uid, pid uint64) (bool, error) {
+	return r.dao.HasAccepted(ctx, uid, pid)
+}
+
+func (r *LocalSubmissionRepo) UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any, evts ...outbox.Event) error {
+	return r.dao.UpdateResult(ctx, pid, sid, res, evts...)
+}
+
+func (r *LocalSubmissionRepo) ClaimSubmit(ctx context.Context, sub domain.Submission) (uint64, error) {
+	return r.dedup.Claim(ctx, sub)
+}
+
+func (r *LocalSubmissionRepo) BindSubmit(ctx context.Context, sub domain.Submission, sid uint64) error {
+	return r.dedup.Bind(ctx, sub, sid)
+}
+
+func (r *LocalSubmissionRepo) ReleaseSubmit(ctx context.Context, sub domain.Submission) error {
+	return r.dedup.Release(ctx, sub)
+}
+
+func (r *LocalSubmissionRepo) FindResult(ctx context.Context, key domain.ResultKey) (domain.Evaluation, error) {
+	var eva domain.Evaluation
+	err := r.result.Get(ctx, key, &eva)
+	return eva, err
+}
+
+func (r *LocalSubmissionRepo) StoreResult(ctx context.Context, key domain.ResultKey, eva domain.Evaluation) error {
+	return r.result.Set(ctx, key, eva)
 }
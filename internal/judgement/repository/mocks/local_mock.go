@@ -0,0 +1,222 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/judgement/repository/local.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/judgement/repository/local.go -destination=internal/judgement/repository/mocks/local_mock.go -package=repomocks
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	outbox "github.com/crazyfrankie/onlinejudge/pkg/outbox"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLocalSubmitRepo is a mock of LocalSubmitRepo interface.
+type MockLocalSubmitRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockLocalSubmitRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockLocalSubmitRepoMockRecorder is the mock recorder for MockLocalSubmitRepo.
+type MockLocalSubmitRepoMockRecorder struct {
+	mock *MockLocalSubmitRepo
+}
+
+// NewMockLocalSubmitRepo creates a new mock instance.
+func NewMockLocalSubmitRepo(ctrl *gomock.Controller) *MockLocalSubmitRepo {
+	mock := &MockLocalSubmitRepo{ctrl: ctrl}
+	mock.recorder = &MockLocalSubmitRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLocalSubmitRepo) EXPECT() *MockLocalSubmitRepoMockRecorder {
+	return m.recorder
+}
+
+// BindSubmit mocks base method.
+func (m *MockLocalSubmitRepo) BindSubmit(ctx context.Context, sub domain.Submission, sid uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BindSubmit", ctx, sub, sid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BindSubmit indicates an expected call of BindSubmit.
+func (mr *MockLocalSubmitRepoMockRecorder) BindSubmit(ctx, sub, sid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BindSubmit", reflect.TypeOf((*MockLocalSubmitRepo)(nil).BindSubmit), ctx, sub, sid)
+}
+
+// ClaimSubmit mocks base method.
+func (m *MockLocalSubmitRepo) ClaimSubmit(ctx context.Context, sub domain.Submission) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimSubmit", ctx, sub)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimSubmit indicates an expected call of ClaimSubmit.
+func (mr *MockLocalSubmitRepoMockRecorder) ClaimSubmit(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimSubmit", reflect.TypeOf((*MockLocalSubmitRepo)(nil).ClaimSubmit), ctx, sub)
+}
+
+// CreateEvaluate mocks base method.
+func (m *MockLocalSubmitRepo) CreateEvaluate(ctx context.Context, eva domain.Evaluation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEvaluate", ctx, eva)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEvaluate indicates an expected call of CreateEvaluate.
+func (mr *MockLocalSubmitRepoMockRecorder) CreateEvaluate(ctx, eva any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvaluate", reflect.TypeOf((*MockLocalSubmitRepo)(nil).CreateEvaluate), ctx, eva)
+}
+
+// CreateSubmit mocks base method.
+func (m *MockLocalSubmitRepo) CreateSubmit(ctx context.Context, sub domain.Submission) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubmit", ctx, sub)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubmit indicates an expected call of CreateSubmit.
+func (mr *MockLocalSubmitRepoMockRecorder) CreateSubmit(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubmit", reflect.TypeOf((*MockLocalSubmitRepo)(nil).CreateSubmit), ctx, sub)
+}
+
+// FindEvaluate mocks base method.
+func (m *MockLocalSubmitRepo) FindEvaluate(ctx context.Context, sid uint64) (domain.Evaluation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEvaluate", ctx, sid)
+	ret0, _ := ret[0].(domain.Evaluation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEvaluate indicates an expected call of FindEvaluate.
+func (mr *MockLocalSubmitRepoMockRecorder) FindEvaluate(ctx, sid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEvaluate", reflect.TypeOf((*MockLocalSubmitRepo)(nil).FindEvaluate), ctx, sid)
+}
+
+// FindResult mocks base method.
+func (m *MockLocalSubmitRepo) FindResult(ctx context.Context, key domain.ResultKey) (domain.Evaluation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindResult", ctx, key)
+	ret0, _ := ret[0].(domain.Evaluation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindResult indicates an expected call of FindResult.
+func (mr *MockLocalSubmitRepoMockRecorder) FindResult(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindResult", reflect.TypeOf((*MockLocalSubmitRepo)(nil).FindResult), ctx, key)
+}
+
+// FindSubmission mocks base method.
+func (m *MockLocalSubmitRepo) FindSubmission(ctx context.Context, sid uint64) (domain.Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubmission", ctx, sid)
+	ret0, _ := ret[0].(domain.Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubmission indicates an expected call of FindSubmission.
+func (mr *MockLocalSubmitRepoMockRecorder) FindSubmission(ctx, sid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubmission", reflect.TypeOf((*MockLocalSubmitRepo)(nil).FindSubmission), ctx, sid)
+}
+
+// HasAccepted mocks base method.
+func (m *MockLocalSubmitRepo) HasAccepted(ctx context.Context, uid, pid uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasAccepted", ctx, uid, pid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasAccepted indicates an expected call of HasAccepted.
+func (mr *MockLocalSubmitRepoMockRecorder) HasAccepted(ctx, uid, pid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAccepted", reflect.TypeOf((*MockLocalSubmitRepo)(nil).HasAccepted), ctx, uid, pid)
+}
+
+// ReleaseSubmit mocks base method.
+func (m *MockLocalSubmitRepo) ReleaseSubmit(ctx context.Context, sub domain.Submission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSubmit", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSubmit indicates an expected call of ReleaseSubmit.
+func (mr *MockLocalSubmitRepoMockRecorder) ReleaseSubmit(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSubmit", reflect.TypeOf((*MockLocalSubmitRepo)(nil).ReleaseSubmit), ctx, sub)
+}
+
+// StoreResult mocks base method.
+func (m *MockLocalSubmitRepo) StoreResult(ctx context.Context, key domain.ResultKey, eva domain.Evaluation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreResult", ctx, key, eva)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreResult indicates an expected call of StoreResult.
+func (mr *MockLocalSubmitRepoMockRecorder) StoreResult(ctx, key, eva any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreResult", reflect.TypeOf((*MockLocalSubmitRepo)(nil).StoreResult), ctx, key, eva)
+}
+
+// UpdateEvaluate mocks base method.
+func (m *MockLocalSubmitRepo) UpdateEvaluate(ctx context.Context, pid, sid uint64, state string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEvaluate", ctx, pid, sid, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEvaluate indicates an expected call of UpdateEvaluate.
+func (mr *MockLocalSubmitRepoMockRecorder) UpdateEvaluate(ctx, pid, sid, state any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEvaluate", reflect.TypeOf((*MockLocalSubmitRepo)(nil).UpdateEvaluate), ctx, pid, sid, state)
+}
+
+// UpdateResult mocks base method.
+func (m *MockLocalSubmitRepo) UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any, evts ...outbox.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pid, sid, res}
+	for _, a := range evts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateResult", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateResult indicates an expected call of UpdateResult.
+func (mr *MockLocalSubmitRepoMockRecorder) UpdateResult(ctx, pid, sid, res any, evts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pid, sid, res}, evts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResult", reflect.TypeOf((*MockLocalSubmitRepo)(nil).UpdateResult), varargs...)
+}
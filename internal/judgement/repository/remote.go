@@ -2,29 +2,58 @@ package repository
 
 import (
 	"context"
+
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/cache"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/dao"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 )
 
 type SubmitRepository interface {
-	StoreEvaluation(ctx context.Context, userId uint64, code string, evals []domain.RemoteEvaluation) error
-	AcquireEvaluation(ctx context.Context, userId uint64, hashKey string) ([]domain.RemoteEvaluation, error)
+	StoreEvaluation(ctx context.Context, key domain.ResultKey, evals []domain.RemoteEvaluation) error
+	AcquireEvaluation(ctx context.Context, key domain.ResultKey) ([]domain.RemoteEvaluation, error)
+
+	// 远程评测的提交与本地评测落在同一张表里，id 与查询方式一致
+	CreateSubmit(ctx context.Context, sub domain.Submission) (uint64, error)
+	CreateEvaluate(ctx context.Context, eva domain.Evaluation) error
+	UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any, evts ...outbox.Event) error
+	FindEvaluate(ctx context.Context, sid uint64) (domain.Evaluation, error)
 }
 
 type SubmissionRepo struct {
-	cache cache.SubmitCache
+	dao    *dao.SubmitDao
+	result cache.JudgeResultCache
 }
 
-func NewSubmitRepository(cache cache.SubmitCache) SubmitRepository {
+func NewSubmitRepository(result cache.JudgeResultCache, dao *dao.SubmitDao) SubmitRepository {
 	return &SubmissionRepo{
-		cache: cache,
+		dao:    dao,
+		result: result,
 	}
 }
 
-func (repo *SubmissionRepo) StoreEvaluation(ctx context.Context, userId uint64, hashKey string, evals []domain.RemoteEvaluation) error {
-	return repo.cache.Set(ctx, userId, hashKey, evals)
+func (repo *SubmissionRepo) StoreEvaluation(ctx context.Context, key domain.ResultKey, evals []domain.RemoteEvaluation) error {
+	return repo.result.Set(ctx, key, evals)
+}
+
+func (repo *SubmissionRepo) AcquireEvaluation(ctx context.Context, key domain.ResultKey) ([]domain.RemoteEvaluation, error) {
+	var evals []domain.RemoteEvaluation
+	err := repo.result.Get(ctx, key, &evals)
+	return evals, err
+}
+
+func (repo *SubmissionRepo) CreateSubmit(ctx context.Context, sub domain.Submission) (uint64, error) {
+	return repo.dao.CreateSubmit(ctx, sub)
+}
+
+func (repo *SubmissionRepo) CreateEvaluate(ctx context.Context, eva domain.Evaluation) error {
+	return repo.dao.CreateEvaluate(ctx, eva)
+}
+
+func (repo *SubmissionRepo) UpdateResult(ctx context.Context, pid, sid uint64, res map[string]any, evts ...outbox.Event) error {
+	return repo.dao.UpdateResult(ctx, pid, sid, res, evts...)
 }
 
-func (repo *SubmissionRepo) AcquireEvaluation(ctx context.Context, userId uint64, hashKey string) ([]domain.RemoteEvaluation, error) {
-	return repo.cache.Get(ctx, userId, hashKey)
+func (repo *SubmissionRepo) FindEvaluate(ctx context.Context, sid uint64) (domain.Evaluation, error) {
+	return repo.dao.FindEvaluate(ctx, sid)
 }
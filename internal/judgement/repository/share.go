@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/dao"
+)
+
+var ErrShareNotFound = dao.ErrShareNotFound
+
+type ShareRepository interface {
+	Create(ctx context.Context, share domain.Share) error
+	FindByToken(ctx context.Context, token string) (domain.Share, error)
+	Revoke(ctx context.Context, uid uint64, token string) error
+}
+
+type ShareRepo struct {
+	dao *dao.ShareDao
+}
+
+func NewShareRepository(dao *dao.ShareDao) ShareRepository {
+	return &ShareRepo{dao: dao}
+}
+
+func (r *ShareRepo) Create(ctx context.Context, share domain.Share) error {
+	return r.dao.Insert(ctx, dao.SubmissionShare{
+		Token:        share.Token,
+		SubmissionId: share.SubmissionId,
+		UserId:       share.UserId,
+		ExpireAt:     share.ExpireAt,
+	})
+}
+
+func (r *ShareRepo) FindByToken(ctx context.Context, token string) (domain.Share, error) {
+	s, err := r.dao.FindByToken(ctx, token)
+	if err != nil {
+		return domain.Share{}, err
+	}
+
+	return domain.Share{
+		Token:        s.Token,
+		SubmissionId: s.SubmissionId,
+		UserId:       s.UserId,
+		ExpireAt:     s.ExpireAt,
+		Revoked:      s.Revoked,
+		Ctime:        s.Ctime,
+	}, nil
+}
+
+func (r *ShareRepo) Revoke(ctx context.Context, uid uint64, token string) error {
+	return r.dao.Revoke(ctx, uid, token)
+}
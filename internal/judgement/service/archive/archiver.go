@@ -0,0 +1,106 @@
+/*
+提交代码归档
+定时把超过保留期的提交代码压缩后移到对象存储，MySQL 中只留下对象的 key
+读取时由 repository 透明地取回，对调用方无感知
+*/
+
+package archive
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository"
+)
+
+type Config struct {
+	// Retention 代码在 MySQL 中保留的时长，<= 0 表示不归档
+	Retention time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+
+type Archiver struct {
+	repo    repository.CodeArchiveRepo
+	cfg     Config
+	metrics *metrics
+}
+
+func NewArchiver(repo repository.CodeArchiveRepo, cfg Config) *Archiver {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	return &Archiver{
+		repo:    repo,
+		cfg:     cfg,
+		metrics: newMetrics(),
+	}
+}
+
+// Run 阻塞执行，直到 ctx 结束
+func (a *Archiver) Run(ctx context.Context) {
+	if a.cfg.Retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if n, err := a.RunOnce(ctx); err != nil {
+			log.Printf("archive submission code failed after %d archived: %v", n, err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce 一批一批归档，直到没有可归档的记录，返回本轮归档的条数
+func (a *Archiver) RunOnce(ctx context.Context) (int, error) {
+	start := time.Now()
+	defer func() {
+		a.metrics.duration.Observe(float64(time.Since(start).Milliseconds()))
+		a.metrics.lastRun.SetToCurrentTime()
+	}()
+
+	before := time.Now().Add(-a.cfg.Retention)
+	total := 0
+	for ctx.Err() == nil {
+		subs, err := a.repo.FindArchivable(ctx, before, a.cfg.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(subs) == 0 {
+			return total, nil
+		}
+
+		archived := 0
+		for _, sub := range subs {
+			size, err := a.repo.Archive(ctx, sub)
+			if err != nil {
+				a.metrics.subs.WithLabelValues("failed").Inc()
+				log.Printf("archive submission %d failed: %v", sub.Id, err)
+				continue
+			}
+			archived++
+			a.metrics.subs.WithLabelValues("archived").Inc()
+			a.metrics.bytes.WithLabelValues("raw").Add(float64(len(sub.Code)))
+			a.metrics.bytes.WithLabelValues("compressed").Add(float64(size))
+		}
+		total += archived
+
+		// 整批都失败时对象存储多半不可用，等下一轮再试，避免反复取同一批
+		if archived == 0 {
+			return total, nil
+		}
+	}
+
+	return total, ctx.Err()
+}
@@ -0,0 +1,47 @@
+package archive
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type metrics struct {
+	subs     *prometheus.CounterVec
+	bytes    *prometheus.CounterVec
+	duration prometheus.Summary
+	lastRun  prometheus.Gauge
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		subs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "code_archive_submissions",
+			Help:      "归档的提交数",
+		}, []string{"result"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "code_archive_bytes",
+			Help:      "归档代码压缩前后的字节数",
+		}, []string{"kind"}),
+		duration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "code_archive_run_time",
+			Help:      "每轮归档任务的耗时，单位 ms",
+			Objectives: map[float64]float64{
+				0.5:  0.01,
+				0.9:  0.01,
+				0.99: 0.001,
+			},
+		}),
+		lastRun: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "code_archive_last_run",
+			Help:      "上一轮归档任务结束的时间戳",
+		}),
+	}
+	prometheus.MustRegister(m.subs, m.bytes, m.duration, m.lastRun)
+
+	return m
+}
@@ -0,0 +1,102 @@
+package contest
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/config"
+)
+
+// Checker 判断题目当前是否处于进行中的比赛
+type Checker interface {
+	InActiveContest(ctx context.Context, problemId uint64) bool
+	// Participating 比赛正在进行、包含这道题并且用户报名了才算比赛中的提交
+	// 请求里的比赛 id 由客户端填写，使用比赛的配额和评测优先级之前都要经过这里确认
+	Participating(ctx context.Context, contestId, problemId, uid uint64) bool
+}
+
+type window struct {
+	start, end time.Time
+}
+
+func (w window) active(now time.Time) bool {
+	return !now.Before(w.start) && now.Before(w.end)
+}
+
+type contestInfo struct {
+	window
+	problems map[uint64]struct{}
+	// participants 为 nil 表示不需要报名
+	participants map[uint64]struct{}
+}
+
+// ConfigChecker 比赛信息来自配置文件
+type ConfigChecker struct {
+	problems map[uint64][]window
+	contests map[uint64]contestInfo
+	now      func() time.Time
+}
+
+func NewConfigChecker(contests []config.Contest) Checker {
+	c := &ConfigChecker{
+		problems: make(map[uint64][]window),
+		contests: make(map[uint64]contestInfo),
+		now:      time.Now,
+	}
+	for _, ct := range contests {
+		start, err := time.Parse(time.RFC3339, ct.Start)
+		if err != nil {
+			log.Printf("invalid start time of contest %d: %v", ct.Id, err)
+			continue
+		}
+		end, err := time.Parse(time.RFC3339, ct.End)
+		if err != nil {
+			log.Printf("invalid end time of contest %d: %v", ct.Id, err)
+			continue
+		}
+		w := window{start: start, end: end}
+		info := contestInfo{
+			window:   w,
+			problems: make(map[uint64]struct{}, len(ct.ProblemIds)),
+		}
+		for _, pid := range ct.ProblemIds {
+			c.problems[pid] = append(c.problems[pid], w)
+			info.problems[pid] = struct{}{}
+		}
+		if len(ct.Participants) > 0 {
+			info.participants = make(map[uint64]struct{}, len(ct.Participants))
+			for _, uid := range ct.Participants {
+				info.participants[uid] = struct{}{}
+			}
+		}
+		c.contests[ct.Id] = info
+	}
+
+	return c
+}
+
+func (c *ConfigChecker) InActiveContest(ctx context.Context, problemId uint64) bool {
+	now := c.now()
+	for _, w := range c.problems[problemId] {
+		if w.active(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ConfigChecker) Participating(ctx context.Context, contestId, problemId, uid uint64) bool {
+	info, ok := c.contests[contestId]
+	if !ok || contestId == 0 || !info.active(c.now()) {
+		return false
+	}
+	if _, ok = info.problems[problemId]; !ok {
+		return false
+	}
+	if info.participants == nil {
+		return true
+	}
+	_, ok = info.participants[uid]
+	return ok
+}
@@ -0,0 +1,58 @@
+package contest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/crazyfrankie/onlinejudge/config"
+)
+
+func TestConfigChecker_Participating(t *testing.T) {
+	c := NewConfigChecker([]config.Contest{
+		{
+			Id:         1,
+			ProblemIds: []uint64{10, 11},
+			Start:      "2024-01-01T10:00:00Z",
+			End:        "2024-01-01T12:00:00Z",
+		},
+		{
+			Id:           2,
+			ProblemIds:   []uint64{20},
+			Start:        "2024-01-01T10:00:00Z",
+			End:          "2024-01-01T12:00:00Z",
+			Participants: []uint64{100},
+		},
+	}).(*ConfigChecker)
+	c.now = func() time.Time {
+		return time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name      string
+		contestId uint64
+		problemId uint64
+		uid       uint64
+		want      bool
+	}{
+		{name: "open contest", contestId: 1, problemId: 10, uid: 1, want: true},
+		{name: "problem not in contest", contestId: 1, problemId: 20, uid: 1, want: false},
+		{name: "unknown contest", contestId: 3, problemId: 10, uid: 1, want: false},
+		{name: "no contest", contestId: 0, problemId: 10, uid: 1, want: false},
+		{name: "registered", contestId: 2, problemId: 20, uid: 100, want: true},
+		{name: "not registered", contestId: 2, problemId: 20, uid: 1, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, c.Participating(context.Background(), tc.contestId, tc.problemId, tc.uid))
+		})
+	}
+
+	c.now = func() time.Time {
+		return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	}
+	assert.False(t, c.Participating(context.Background(), 1, 10, 1))
+}
@@ -0,0 +1,353 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var cppDecl = map[Type]string{
+	Int:         "int",
+	Long:        "long long",
+	Double:      "double",
+	Bool:        "bool",
+	String:      "string",
+	IntArray:    "vector<int>",
+	StringArray: "vector<string>",
+	IntMatrix:   "vector<vector<int>>",
+	ListNode:    "ListNode*",
+	TreeNode:    "TreeNode*",
+}
+
+// cppParamDecl Snippet 中容器类型按引用传参，与 LeetCode 的写法保持一致
+var cppParamDecl = map[Type]string{
+	String:      "string&",
+	IntArray:    "vector<int>&",
+	StringArray: "vector<string>&",
+	IntMatrix:   "vector<vector<int>>&",
+}
+
+func cppNaming(op string, key string) string {
+	return "harness::" + op + key
+}
+
+type cppGenerator struct{}
+
+func (g *cppGenerator) Snippet(sig Signature) string {
+	params := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		decl, ok := cppParamDecl[p.Type]
+		if !ok {
+			decl = cppDecl[p.Type]
+		}
+		params = append(params, decl+" "+p.Name)
+	}
+
+	return fmt.Sprintf("class Solution {\npublic:\n    %s %s(%s) {\n\n    }\n};\n",
+		cppDecl[sig.Return], sig.FuncName, strings.Join(params, ", "))
+}
+
+func (g *cppGenerator) Driver(sig Signature, userCode string) (string, error) {
+	if !defines(userCode, `\b(class|struct)\s+Solution\b`) {
+		return "", fmt.Errorf("%w: class Solution not found", ErrUserCode)
+	}
+
+	data := newTemplateData(sig, cppDecl, cppNaming, strings.TrimSpace(userCode))
+	data.DefineList = data.UseList && !defines(userCode, `\bstruct\s+ListNode\s*\{`)
+	data.DefineTree = data.UseTree && !defines(userCode, `\bstruct\s+TreeNode\s*\{`)
+
+	return render(cppTemplate, data)
+}
+
+var cppTemplate = template.Must(template.New("cpp").Parse(`#include <bits/stdc++.h>
+using namespace std;
+{{if .DefineList}}
+struct ListNode {
+    int val;
+    ListNode *next;
+    ListNode() : val(0), next(nullptr) {}
+    ListNode(int x) : val(x), next(nullptr) {}
+    ListNode(int x, ListNode *next) : val(x), next(next) {}
+};
+{{end}}{{if .DefineTree}}
+struct TreeNode {
+    int val;
+    TreeNode *left;
+    TreeNode *right;
+    TreeNode() : val(0), left(nullptr), right(nullptr) {}
+    TreeNode(int x) : val(x), left(nullptr), right(nullptr) {}
+    TreeNode(int x, TreeNode *left, TreeNode *right) : val(x), left(left), right(right) {}
+};
+{{end}}
+{{.UserCode}}
+
+namespace harness {
+
+// Value JSON 风格的字面量: 数组保存在 items 中，其余保存去掉引号后的原始文本
+struct Value {
+    bool isNull = false;
+    bool isArray = false;
+    string raw;
+    vector<Value> items;
+};
+
+class Parser {
+public:
+    explicit Parser(const string &s) : s(s), pos(0) {}
+
+    Value value() {
+        skip();
+        Value v;
+        if (s[pos] == '[') {
+            v.isArray = true;
+            pos++;
+            skip();
+            if (s[pos] == ']') {
+                pos++;
+                return v;
+            }
+            while (true) {
+                v.items.push_back(value());
+                skip();
+                if (s[pos++] == ']') {
+                    return v;
+                }
+            }
+        }
+        if (s[pos] == '"') {
+            pos++;
+            while (s[pos] != '"') {
+                char c = s[pos++];
+                if (c != '\\') {
+                    v.raw.push_back(c);
+                    continue;
+                }
+                char e = s[pos++];
+                switch (e) {
+                    case 'n': v.raw.push_back('\n'); break;
+                    case 't': v.raw.push_back('\t'); break;
+                    case 'r': v.raw.push_back('\r'); break;
+                    case 'b': v.raw.push_back('\b'); break;
+                    case 'f': v.raw.push_back('\f'); break;
+                    default: v.raw.push_back(e);
+                }
+            }
+            pos++;
+            return v;
+        }
+        size_t start = pos;
+        while (pos < s.size() && s[pos] != ',' && s[pos] != ']' && !isspace((unsigned char) s[pos])) {
+            pos++;
+        }
+        v.raw = s.substr(start, pos - start);
+        v.isNull = v.raw == "null";
+        return v;
+    }
+
+private:
+    const string &s;
+    size_t pos;
+
+    void skip() {
+        while (pos < s.size() && isspace((unsigned char) s[pos])) {
+            pos++;
+        }
+    }
+};
+
+Value parse(const string &s) {
+    return Parser(s).value();
+}
+
+vector<int> toIntArray(const Value &v) {
+    vector<int> res;
+    for (const Value &item : v.items) {
+        res.push_back(stoi(item.raw));
+    }
+    return res;
+}
+
+string quote(const string &v) {
+    string res = "\"";
+    for (char c : v) {
+        switch (c) {
+            case '"': res += "\\\""; break;
+            case '\\': res += "\\\\"; break;
+            case '\n': res += "\\n"; break;
+            case '\r': res += "\\r"; break;
+            case '\t': res += "\\t"; break;
+            default: res.push_back(c);
+        }
+    }
+    return res + "\"";
+}
+
+int parseInt(const string &s) { return stoi(s); }
+
+long long parseLong(const string &s) { return stoll(s); }
+
+double parseDouble(const string &s) { return stod(s); }
+
+bool parseBool(const string &s) { return s == "true"; }
+
+string parseString(const string &s) { return parse(s).raw; }
+
+vector<int> parseIntArray(const string &s) { return toIntArray(parse(s)); }
+
+vector<string> parseStringArray(const string &s) {
+    vector<string> res;
+    for (const Value &item : parse(s).items) {
+        res.push_back(item.raw);
+    }
+    return res;
+}
+
+vector<vector<int>> parseIntMatrix(const string &s) {
+    vector<vector<int>> res;
+    for (const Value &item : parse(s).items) {
+        res.push_back(toIntArray(item));
+    }
+    return res;
+}
+
+string serializeInt(int v) { return to_string(v); }
+
+string serializeLong(long long v) { return to_string(v); }
+
+string serializeDouble(double v) {
+    ostringstream os;
+    os << fixed << setprecision(5) << v;
+    return os.str();
+}
+
+string serializeBool(bool v) { return v ? "true" : "false"; }
+
+string serializeString(const string &v) { return quote(v); }
+
+string serializeIntArray(const vector<int> &v) {
+    string res = "[";
+    for (size_t i = 0; i < v.size(); i++) {
+        if (i) res += ",";
+        res += to_string(v[i]);
+    }
+    return res + "]";
+}
+
+string serializeStringArray(const vector<string> &v) {
+    string res = "[";
+    for (size_t i = 0; i < v.size(); i++) {
+        if (i) res += ",";
+        res += quote(v[i]);
+    }
+    return res + "]";
+}
+
+string serializeIntMatrix(const vector<vector<int>> &v) {
+    string res = "[";
+    for (size_t i = 0; i < v.size(); i++) {
+        if (i) res += ",";
+        res += serializeIntArray(v[i]);
+    }
+    return res + "]";
+}
+{{- if .UseList}}
+
+ListNode *parseListNode(const string &s) {
+    ListNode dummy;
+    ListNode *cur = &dummy;
+    for (int v : parseIntArray(s)) {
+        cur->next = new ListNode(v);
+        cur = cur->next;
+    }
+    return dummy.next;
+}
+
+string serializeListNode(ListNode *head) {
+    vector<int> vals;
+    for (; head != nullptr; head = head->next) {
+        vals.push_back(head->val);
+    }
+    return serializeIntArray(vals);
+}
+{{- end}}
+{{- if .UseTree}}
+
+TreeNode *parseTreeNode(const string &s) {
+    Value vals = parse(s);
+    if (vals.items.empty() || vals.items[0].isNull) {
+        return nullptr;
+    }
+    TreeNode *root = new TreeNode(stoi(vals.items[0].raw));
+    queue<TreeNode *> q;
+    q.push(root);
+    size_t i = 1;
+    while (!q.empty() && i < vals.items.size()) {
+        TreeNode *node = q.front();
+        q.pop();
+        if (!vals.items[i].isNull) {
+            node->left = new TreeNode(stoi(vals.items[i].raw));
+            q.push(node->left);
+        }
+        i++;
+        if (i < vals.items.size() && !vals.items[i].isNull) {
+            node->right = new TreeNode(stoi(vals.items[i].raw));
+            q.push(node->right);
+        }
+        i++;
+    }
+    return root;
+}
+
+string serializeTreeNode(TreeNode *root) {
+    vector<string> vals;
+    queue<TreeNode *> q;
+    q.push(root);
+    while (!q.empty()) {
+        TreeNode *node = q.front();
+        q.pop();
+        if (node == nullptr) {
+            vals.push_back("null");
+            continue;
+        }
+        vals.push_back(to_string(node->val));
+        q.push(node->left);
+        q.push(node->right);
+    }
+    while (!vals.empty() && vals.back() == "null") {
+        vals.pop_back();
+    }
+    string res = "[";
+    for (size_t i = 0; i < vals.size(); i++) {
+        if (i) res += ",";
+        res += vals[i];
+    }
+    return res + "]";
+}
+{{- end}}
+
+}  // namespace harness
+
+int main() {
+    ios::sync_with_stdio(false);
+    vector<string> lines;
+    string line;
+    while (getline(cin, line)) {
+        size_t l = line.find_first_not_of(" \t\r\n");
+        if (l == string::npos) {
+            continue;
+        }
+        size_t r = line.find_last_not_of(" \t\r\n");
+        lines.push_back(line.substr(l, r - l + 1));
+    }
+
+    for (size_t i = 0; i + {{len .Params}} <= lines.size(); i += {{len .Params}}) {
+{{- range $i, $p := .Params}}
+        {{$p.Decl}} p{{$i}} = {{$p.Parse}}(lines[i + {{$i}}]);
+{{- end}}
+        Solution solution;
+        {{.Return.Decl}} res = solution.{{.FuncName}}({{range $i, $p := .Params}}{{if $i}}, {{end}}p{{$i}}{{end}});
+        cout << {{.Return.Serialize}}(res) << '\n';
+    }
+    return 0;
+}
+`))
@@ -0,0 +1,133 @@
+// Package harness
+/*
+根据题目作者声明的函数签名生成各语言的驱动代码
+驱动代码负责从标准输入逐行读取参数、调用用户函数并把返回值序列化后输出
+一组测试用例占 len(Params) 行，驱动会一直读到 EOF，因此一次运行可以跑多组用例
+*/
+package harness
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"text/template"
+)
+
+var (
+	ErrUserCode = errors.New("user code cannot be parsed")
+)
+
+type Generator interface {
+	// Snippet 生成展示给用户的初始代码
+	Snippet(sig Signature) string
+	// Driver 把用户代码嵌入驱动，返回可以直接编译运行的完整源文件
+	Driver(sig Signature, userCode string) (string, error)
+}
+
+var generators = map[string]Generator{
+	"go":     &goGenerator{},
+	"java":   &javaGenerator{},
+	"cpp":    &cppGenerator{},
+	"python": &pythonGenerator{},
+}
+
+func For(lang string) (Generator, error) {
+	g, ok := generators[lang]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownLanguage, lang)
+	}
+
+	return g, nil
+}
+
+// Generate 是 For(lang).Driver 的简写
+func Generate(lang string, sig Signature, userCode string) (string, error) {
+	g, err := For(lang)
+	if err != nil {
+		return "", err
+	}
+	if err = sig.Validate(); err != nil {
+		return "", err
+	}
+
+	return g.Driver(sig, userCode)
+}
+
+// TemplateData 驱动模板的渲染参数，类型名与解析/序列化函数名都已换成目标语言的写法
+type TemplateData struct {
+	FuncName string
+	Params   []TemplateParam
+	Return   TemplateParam
+	UserCode string
+	Imports  []string
+
+	// 用户代码里没有自己定义时才需要由驱动提供
+	DefineList bool
+	DefineTree bool
+	// 签名中用到时才生成对应的解析/序列化函数
+	UseList bool
+	UseTree bool
+}
+
+type TemplateParam struct {
+	Name      string
+	Decl      string
+	Parse     string
+	Serialize string
+}
+
+// typeKey 各语言的辅助函数都按 parse<Key>/serialize<Key> 命名，具体写法由 naming 决定
+var typeKey = map[Type]string{
+	Int:         "Int",
+	Long:        "Long",
+	Double:      "Double",
+	Bool:        "Bool",
+	String:      "String",
+	IntArray:    "IntArray",
+	StringArray: "StringArray",
+	IntMatrix:   "IntMatrix",
+	ListNode:    "ListNode",
+	TreeNode:    "TreeNode",
+}
+
+// naming 根据操作（parse/serialize）与类型给出目标语言中辅助函数的名字
+type naming func(op string, key string) string
+
+func newTemplateData(sig Signature, decl map[Type]string, name naming, userCode string) TemplateData {
+	data := TemplateData{
+		FuncName: sig.FuncName,
+		UserCode: userCode,
+		UseList:  sig.Uses(ListNode),
+		UseTree:  sig.Uses(TreeNode),
+	}
+	for _, p := range sig.Params {
+		data.Params = append(data.Params, TemplateParam{
+			Name:  p.Name,
+			Decl:  decl[p.Type],
+			Parse: name("parse", typeKey[p.Type]),
+		})
+	}
+	data.Return = TemplateParam{
+		Decl:      decl[sig.Return],
+		Serialize: name("serialize", typeKey[sig.Return]),
+	}
+	data.DefineList = data.UseList
+	data.DefineTree = data.UseTree
+
+	return data
+}
+
+// defines 用户代码中是否已经定义了某个类型（例如从 LeetCode 复制时带上了 ListNode 的定义）
+func defines(code string, pattern string) bool {
+	return regexp.MustCompile(pattern).MatchString(code)
+}
+
+func render(tmpl *template.Template, data TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,420 @@
+package harness
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var goDecl = map[Type]string{
+	Int:         "int",
+	Long:        "int64",
+	Double:      "float64",
+	Bool:        "bool",
+	String:      "string",
+	IntArray:    "[]int",
+	StringArray: "[]string",
+	IntMatrix:   "[][]int",
+	ListNode:    "*ListNode",
+	TreeNode:    "*TreeNode",
+}
+
+// goDriverImports 驱动本身用到的包，辅助函数每次都会生成，所以不会出现未使用的 import
+var goDriverImports = []string{"bufio", "encoding/json", "fmt", "os", "strconv", "strings"}
+
+// goStdPkgs 用户代码里直接使用但忘了 import 的常见标准库
+var goStdPkgs = map[string]string{
+	"bytes":   "bytes",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"heap":    "container/heap",
+	"list":    "container/list",
+	"math":    "math",
+	"bits":    "math/bits",
+	"rand":    "math/rand",
+	"slices":  "slices",
+	"sort":    "sort",
+	"strconv": "strconv",
+	"strings": "strings",
+	"unicode": "unicode",
+	"utf8":    "unicode/utf8",
+}
+
+// goNaming 驱动的辅助函数统一加下划线前缀，避免和用户代码里的函数重名
+func goNaming(op string, key string) string {
+	return "_" + op + key
+}
+
+type goGenerator struct{}
+
+func (g *goGenerator) Snippet(sig Signature) string {
+	params := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		params = append(params, p.Name+" "+goDecl[p.Type])
+	}
+
+	return fmt.Sprintf("func %s(%s) %s {\n\n}\n", sig.FuncName, strings.Join(params, ", "), goDecl[sig.Return])
+}
+
+func (g *goGenerator) Driver(sig Signature, userCode string) (string, error) {
+	code, err := parseGoCode(userCode)
+	if err != nil {
+		return "", err
+	}
+
+	data := newTemplateData(sig, goDecl, goNaming, code.body)
+	data.Imports = code.imports
+	data.DefineList = data.UseList && !code.declared["ListNode"]
+	data.DefineTree = data.UseTree && !code.declared["TreeNode"]
+
+	src, err := render(goTemplate, data)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := format.Source([]byte(src))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUserCode, err)
+	}
+
+	return string(res), nil
+}
+
+type goCode struct {
+	imports  []string
+	body     string
+	declared map[string]bool
+}
+
+// parseGoCode 取代原先调用 go vet 修复 import 的做法:
+// 直接解析用户代码，把用户的 import 与驱动的 import 合并，并为未解析的常见标准库补上 import
+func parseGoCode(userCode string) (goCode, error) {
+	src := userCode
+	if !strings.HasPrefix(strings.TrimSpace(src), "package ") {
+		src = "package main\n" + src
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "solution.go", src, parser.SkipObjectResolution|parser.ParseComments)
+	if err != nil {
+		return goCode{}, fmt.Errorf("%w: %v", ErrUserCode, err)
+	}
+
+	imports := make(map[string]struct{})
+	for _, p := range goDriverImports {
+		imports[strconv.Quote(p)] = struct{}{}
+	}
+	imported := make(map[string]struct{})
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		line := spec.Path.Value
+		if spec.Name != nil {
+			name = spec.Name.Name
+			line = spec.Name.Name + " " + spec.Path.Value
+		}
+		imports[line] = struct{}{}
+		imported[name] = struct{}{}
+	}
+
+	// 用户代码正文从 package 子句和最后一个 import 之后开始
+	end := f.Name.End()
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			end = gen.End()
+		}
+	}
+	body := src[fset.Position(end).Offset:]
+
+	declared := make(map[string]bool)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			declared[spec.(*ast.TypeSpec).Name.Name] = true
+		}
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		id, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if path, ok := goStdPkgs[id.Name]; ok {
+			if _, ok := imported[id.Name]; !ok && !shadowed(f, id.Name) {
+				imports[strconv.Quote(path)] = struct{}{}
+				imported[id.Name] = struct{}{}
+			}
+		}
+		return true
+	})
+
+	res := goCode{
+		body:     strings.TrimSpace(body),
+		declared: declared,
+	}
+	for line := range imports {
+		res.imports = append(res.imports, line)
+	}
+	sort.Strings(res.imports)
+
+	return res, nil
+}
+
+// shadowed 用户是否在包级别声明了与标准库同名的标识符，例如 var list []int
+func shadowed(f *ast.File, name string) bool {
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.Name == name {
+				return true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.Name == name {
+							return true
+						}
+					}
+				case *ast.TypeSpec:
+					if s.Name.Name == name {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+var goTemplate = template.Must(template.New("go").Parse(`package main
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+{{if .DefineList}}
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+{{end}}{{if .DefineTree}}
+type TreeNode struct {
+	Val   int
+	Left  *TreeNode
+	Right *TreeNode
+}
+{{end}}
+{{.UserCode}}
+
+func main() {
+	lines := _readLines()
+	for i := 0; i+{{len .Params}} <= len(lines); i += {{len .Params}} {
+{{- range $i, $p := .Params}}
+		p{{$i}} := {{$p.Parse}}(lines[i+{{$i}}])
+{{- end}}
+		res := {{.FuncName}}({{range $i, $p := .Params}}{{if $i}}, {{end}}p{{$i}}{{end}})
+		fmt.Println({{.Return.Serialize}}(res))
+	}
+}
+
+func _readLines() []string {
+	sc := bufio.NewScanner(os.Stdin)
+	sc.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func _decode(s string, v any) {
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid input %q: %v\n", s, err)
+		os.Exit(2)
+	}
+}
+
+func _encode(v any) string {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	return strings.TrimSpace(buf.String())
+}
+
+func _parseInt(s string) int {
+	var v int
+	_decode(s, &v)
+	return v
+}
+
+func _parseLong(s string) int64 {
+	var v int64
+	_decode(s, &v)
+	return v
+}
+
+func _parseDouble(s string) float64 {
+	var v float64
+	_decode(s, &v)
+	return v
+}
+
+func _parseBool(s string) bool {
+	var v bool
+	_decode(s, &v)
+	return v
+}
+
+func _parseString(s string) string {
+	var v string
+	_decode(s, &v)
+	return v
+}
+
+func _parseIntArray(s string) []int {
+	v := []int{}
+	_decode(s, &v)
+	return v
+}
+
+func _parseStringArray(s string) []string {
+	v := []string{}
+	_decode(s, &v)
+	return v
+}
+
+func _parseIntMatrix(s string) [][]int {
+	v := [][]int{}
+	_decode(s, &v)
+	return v
+}
+
+func _serializeInt(v int) string {
+	return strconv.Itoa(v)
+}
+
+func _serializeLong(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func _serializeDouble(v float64) string {
+	return strconv.FormatFloat(v, 'f', 5, 64)
+}
+
+func _serializeBool(v bool) string {
+	return strconv.FormatBool(v)
+}
+
+func _serializeString(v string) string {
+	return _encode(v)
+}
+
+func _serializeIntArray(v []int) string {
+	if v == nil {
+		v = []int{}
+	}
+	return _encode(v)
+}
+
+func _serializeStringArray(v []string) string {
+	if v == nil {
+		v = []string{}
+	}
+	return _encode(v)
+}
+
+func _serializeIntMatrix(v [][]int) string {
+	if v == nil {
+		v = [][]int{}
+	}
+	for i := range v {
+		if v[i] == nil {
+			v[i] = []int{}
+		}
+	}
+	return _encode(v)
+}
+{{if .UseList}}
+func _parseListNode(s string) *ListNode {
+	dummy := &ListNode{}
+	cur := dummy
+	for _, val := range _parseIntArray(s) {
+		cur.Next = &ListNode{Val: val}
+		cur = cur.Next
+	}
+	return dummy.Next
+}
+
+func _serializeListNode(head *ListNode) string {
+	vals := []int{}
+	for ; head != nil; head = head.Next {
+		vals = append(vals, head.Val)
+	}
+	return _encode(vals)
+}
+{{end}}{{if .UseTree}}
+func _parseTreeNode(s string) *TreeNode {
+	var vals []*int
+	_decode(s, &vals)
+	if len(vals) == 0 || vals[0] == nil {
+		return nil
+	}
+	root := &TreeNode{Val: *vals[0]}
+	queue := []*TreeNode{root}
+	for i := 1; len(queue) > 0 && i < len(vals); {
+		node := queue[0]
+		queue = queue[1:]
+		if vals[i] != nil {
+			node.Left = &TreeNode{Val: *vals[i]}
+			queue = append(queue, node.Left)
+		}
+		i++
+		if i < len(vals) && vals[i] != nil {
+			node.Right = &TreeNode{Val: *vals[i]}
+			queue = append(queue, node.Right)
+		}
+		i++
+	}
+	return root
+}
+
+func _serializeTreeNode(root *TreeNode) string {
+	vals := []*int{}
+	queue := []*TreeNode{root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if node == nil {
+			vals = append(vals, nil)
+			continue
+		}
+		val := node.Val
+		vals = append(vals, &val)
+		queue = append(queue, node.Left, node.Right)
+	}
+	for len(vals) > 0 && vals[len(vals)-1] == nil {
+		vals = vals[:len(vals)-1]
+	}
+	return _encode(vals)
+}
+{{end}}`))
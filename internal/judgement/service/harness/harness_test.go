@@ -0,0 +1,236 @@
+package harness
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var (
+	twoSum = Signature{
+		FuncName: "twoSum",
+		Params: []Param{
+			{Name: "nums", Type: IntArray},
+			{Name: "target", Type: Int},
+		},
+		Return: IntArray,
+	}
+	mergeTrees = Signature{
+		FuncName: "mergeToTree",
+		Params: []Param{
+			{Name: "head", Type: ListNode},
+			{Name: "root", Type: TreeNode},
+			{Name: "words", Type: StringArray},
+		},
+		Return: TreeNode,
+	}
+)
+
+var userCode = map[string]map[string]string{
+	"twoSum": {
+		"go": `import "sort"
+
+func twoSum(nums []int, target int) []int {
+	seen := map[int]int{}
+	for i, n := range nums {
+		if j, ok := seen[target-n]; ok {
+			res := []int{j, i}
+			sort.Ints(res)
+			return res
+		}
+		seen[n] = i
+	}
+	return nil
+}`,
+		"java": `import java.util.HashMap;
+
+public class Solution {
+    public int[] twoSum(int[] nums, int target) {
+        HashMap<Integer, Integer> seen = new HashMap<>();
+        for (int i = 0; i < nums.length; i++) {
+            Integer j = seen.get(target - nums[i]);
+            if (j != null) {
+                return new int[]{j, i};
+            }
+            seen.put(nums[i], i);
+        }
+        return new int[0];
+    }
+}`,
+		"cpp": `class Solution {
+public:
+    vector<int> twoSum(vector<int>& nums, int target) {
+        unordered_map<int, int> seen;
+        for (int i = 0; i < (int) nums.size(); i++) {
+            auto it = seen.find(target - nums[i]);
+            if (it != seen.end()) {
+                return {it->second, i};
+            }
+            seen[nums[i]] = i;
+        }
+        return {};
+    }
+};`,
+		"python": `class Solution:
+    def twoSum(self, nums: List[int], target: int) -> List[int]:
+        seen = {}
+        for i, n in enumerate(nums):
+            if target - n in seen:
+                return [seen[target - n], i]
+            seen[n] = i
+        return []`,
+	},
+	"mergeToTree": {
+		"go": `// 用户自己带上了 ListNode 的定义，驱动不应重复生成
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+
+func mergeToTree(head *ListNode, root *TreeNode, words []string) *TreeNode {
+	for ; head != nil; head = head.Next {
+		root = &TreeNode{Val: head.Val + len(strings.Join(words, "")), Left: root}
+	}
+	return root
+}`,
+		"java": `class Solution {
+    public TreeNode mergeToTree(ListNode head, TreeNode root, String[] words) {
+        for (; head != null; head = head.next) {
+            root = new TreeNode(head.val + String.join("", words).length(), root, null);
+        }
+        return root;
+    }
+}`,
+		"cpp": `class Solution {
+public:
+    TreeNode* mergeToTree(ListNode* head, TreeNode* root, vector<string>& words) {
+        int n = 0;
+        for (auto &w : words) n += w.size();
+        for (; head != nullptr; head = head->next) {
+            root = new TreeNode(head->val + n, root, nullptr);
+        }
+        return root;
+    }
+};`,
+		"python": `class Solution:
+    def mergeToTree(self, head: Optional[ListNode], root: Optional[TreeNode], words: List[str]) -> Optional[TreeNode]:
+        while head:
+            root = TreeNode(head.val + len("".join(words)), root)
+            head = head.next
+        return root`,
+	},
+}
+
+func TestGenerate(t *testing.T) {
+	for _, sig := range []Signature{twoSum, mergeTrees} {
+		for _, lang := range []string{"go", "java", "cpp", "python"} {
+			t.Run(sig.FuncName+"/"+lang, func(t *testing.T) {
+				src, err := Generate(lang, sig, userCode[sig.FuncName][lang])
+				require.NoError(t, err)
+
+				golden := filepath.Join("testdata", sig.FuncName+"."+lang+".golden")
+				if *update {
+					require.NoError(t, os.WriteFile(golden, []byte(src), 0644))
+				}
+
+				want, err := os.ReadFile(golden)
+				require.NoError(t, err)
+				assert.Equal(t, string(want), src)
+			})
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	testCases := []struct {
+		lang string
+		want string
+	}{
+		{
+			lang: "go",
+			want: "func twoSum(nums []int, target int) []int {\n\n}\n",
+		},
+		{
+			lang: "java",
+			want: "class Solution {\n    public int[] twoSum(int[] nums, int target) {\n\n    }\n}\n",
+		},
+		{
+			lang: "cpp",
+			want: "class Solution {\npublic:\n    vector<int> twoSum(vector<int>& nums, int target) {\n\n    }\n};\n",
+		},
+		{
+			lang: "python",
+			want: "class Solution:\n    def twoSum(self, nums: List[int], target: int) -> List[int]:\n        pass\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.lang, func(t *testing.T) {
+			g, err := For(tc.lang)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, g.Snippet(twoSum))
+		})
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	testCases := []struct {
+		name    string
+		raw     string
+		want    Signature
+		wantErr error
+	}{
+		{
+			name: "valid",
+			raw:  `{"name":"twoSum","params":[{"name":"nums","type":"int[]"},{"name":"target","type":"int"}],"return":"int[]"}`,
+			want: twoSum,
+		},
+		{
+			name:    "unknown type",
+			raw:     `{"name":"f","params":[{"name":"m","type":"map"}],"return":"int"}`,
+			wantErr: ErrUnknownType,
+		},
+		{
+			name:    "duplicated param",
+			raw:     `{"name":"f","params":[{"name":"a","type":"int"},{"name":"a","type":"int"}],"return":"int"}`,
+			wantErr: ErrInvalidSign,
+		},
+		{
+			name:    "bad json",
+			raw:     `{"name":`,
+			wantErr: ErrInvalidSign,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sig, err := ParseSignature(tc.raw)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, sig)
+		})
+	}
+}
+
+func TestGoImports(t *testing.T) {
+	src, err := Generate("go", twoSum, `func twoSum(nums []int, target int) []int {
+	sort.Ints(nums)
+	_ = math.MaxInt
+	return nums
+}`)
+	require.NoError(t, err)
+	assert.Contains(t, src, `"sort"`)
+	assert.Contains(t, src, `"math"`)
+
+	_, err = Generate("go", twoSum, "func twoSum(nums []int {")
+	assert.ErrorIs(t, err, ErrUserCode)
+}
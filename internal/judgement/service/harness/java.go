@@ -0,0 +1,378 @@
+package harness
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var javaDecl = map[Type]string{
+	Int:         "int",
+	Long:        "long",
+	Double:      "double",
+	Bool:        "boolean",
+	String:      "String",
+	IntArray:    "int[]",
+	StringArray: "String[]",
+	IntMatrix:   "int[][]",
+	ListNode:    "ListNode",
+	TreeNode:    "TreeNode",
+}
+
+var (
+	javaImport      = regexp.MustCompile(`(?m)^\s*import\s+(static\s+)?[\w.]+(\.\*)?\s*;\s*$`)
+	javaPackage     = regexp.MustCompile(`(?m)^\s*package\s+[\w.]+\s*;\s*$`)
+	javaPublicClass = regexp.MustCompile(`\bpublic\s+(final\s+)?class\s+Solution\b`)
+)
+
+func javaNaming(op string, key string) string {
+	return "HarnessCodec." + op + key
+}
+
+type javaGenerator struct{}
+
+func (g *javaGenerator) Snippet(sig Signature) string {
+	params := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		params = append(params, javaDecl[p.Type]+" "+p.Name)
+	}
+
+	return fmt.Sprintf("class Solution {\n    public %s %s(%s) {\n\n    }\n}\n",
+		javaDecl[sig.Return], sig.FuncName, strings.Join(params, ", "))
+}
+
+// Driver 生成的文件需要以 Main.java 保存
+// 用户的 import 会被提到文件开头，public class Solution 会被降级为包级可见，否则无法与 Main 放在同一个文件
+func (g *javaGenerator) Driver(sig Signature, userCode string) (string, error) {
+	imports := []string{"import java.io.*;", "import java.util.*;"}
+	seen := map[string]struct{}{imports[0]: {}, imports[1]: {}}
+	for _, line := range javaImport.FindAllString(userCode, -1) {
+		line = strings.TrimSpace(line)
+		if _, ok := seen[line]; !ok {
+			seen[line] = struct{}{}
+			imports = append(imports, line)
+		}
+	}
+
+	code := javaImport.ReplaceAllString(userCode, "")
+	code = javaPackage.ReplaceAllString(code, "")
+	code = javaPublicClass.ReplaceAllString(code, "class Solution")
+	if !strings.Contains(code, "class Solution") {
+		return "", fmt.Errorf("%w: class Solution not found", ErrUserCode)
+	}
+
+	data := newTemplateData(sig, javaDecl, javaNaming, strings.TrimSpace(code))
+	data.Imports = imports
+	data.DefineList = data.UseList && !defines(code, `\bclass\s+ListNode\b`)
+	data.DefineTree = data.UseTree && !defines(code, `\bclass\s+TreeNode\b`)
+
+	return render(javaTemplate, data)
+}
+
+var javaTemplate = template.Must(template.New("java").Parse(`{{range .Imports}}{{.}}
+{{end}}{{if .DefineList}}
+class ListNode {
+    int val;
+    ListNode next;
+
+    ListNode() {}
+
+    ListNode(int val) { this.val = val; }
+
+    ListNode(int val, ListNode next) { this.val = val; this.next = next; }
+}
+{{end}}{{if .DefineTree}}
+class TreeNode {
+    int val;
+    TreeNode left;
+    TreeNode right;
+
+    TreeNode() {}
+
+    TreeNode(int val) { this.val = val; }
+
+    TreeNode(int val, TreeNode left, TreeNode right) {
+        this.val = val;
+        this.left = left;
+        this.right = right;
+    }
+}
+{{end}}
+{{.UserCode}}
+
+public class Main {
+    public static void main(String[] args) throws IOException {
+        BufferedReader reader = new BufferedReader(new InputStreamReader(System.in));
+        List<String> lines = new ArrayList<>();
+        String line;
+        while ((line = reader.readLine()) != null) {
+            line = line.trim();
+            if (!line.isEmpty()) {
+                lines.add(line);
+            }
+        }
+
+        StringBuilder out = new StringBuilder();
+        for (int i = 0; i + {{len .Params}} <= lines.size(); i += {{len .Params}}) {
+{{- range $i, $p := .Params}}
+            {{$p.Decl}} p{{$i}} = {{$p.Parse}}(lines.get(i + {{$i}}));
+{{- end}}
+            {{.Return.Decl}} res = new Solution().{{.FuncName}}({{range $i, $p := .Params}}{{if $i}}, {{end}}p{{$i}}{{end}});
+            out.append({{.Return.Serialize}}(res)).append('\n');
+        }
+        System.out.print(out);
+    }
+}
+
+class HarnessCodec {
+    private final String s;
+    private int pos;
+
+    private HarnessCodec(String s) {
+        this.s = s;
+    }
+
+    // value 解析 JSON 风格的字面量: 数组返回 List，字符串返回去掉引号后的内容，null 返回 null，其余原样返回
+    private Object value() {
+        skip();
+        char ch = s.charAt(pos);
+        if (ch == '[') {
+            pos++;
+            List<Object> list = new ArrayList<>();
+            skip();
+            if (s.charAt(pos) == ']') {
+                pos++;
+                return list;
+            }
+            while (true) {
+                list.add(value());
+                skip();
+                if (s.charAt(pos++) == ']') {
+                    return list;
+                }
+            }
+        }
+        if (ch == '"') {
+            pos++;
+            StringBuilder sb = new StringBuilder();
+            while (true) {
+                char c = s.charAt(pos++);
+                if (c == '"') {
+                    return sb.toString();
+                }
+                if (c != '\\') {
+                    sb.append(c);
+                    continue;
+                }
+                char e = s.charAt(pos++);
+                switch (e) {
+                    case 'n': sb.append('\n'); break;
+                    case 't': sb.append('\t'); break;
+                    case 'r': sb.append('\r'); break;
+                    case 'b': sb.append('\b'); break;
+                    case 'f': sb.append('\f'); break;
+                    case 'u':
+                        sb.append((char) Integer.parseInt(s.substring(pos, pos + 4), 16));
+                        pos += 4;
+                        break;
+                    default: sb.append(e);
+                }
+            }
+        }
+        int start = pos;
+        while (pos < s.length() && s.charAt(pos) != ',' && s.charAt(pos) != ']' && !Character.isWhitespace(s.charAt(pos))) {
+            pos++;
+        }
+        String token = s.substring(start, pos);
+        return token.equals("null") ? null : token;
+    }
+
+    private void skip() {
+        while (pos < s.length() && Character.isWhitespace(s.charAt(pos))) {
+            pos++;
+        }
+    }
+
+    private static Object parse(String s) {
+        return new HarnessCodec(s.trim()).value();
+    }
+
+    private static int[] toIntArray(List<?> list) {
+        int[] res = new int[list.size()];
+        for (int i = 0; i < res.length; i++) {
+            res[i] = Integer.parseInt((String) list.get(i));
+        }
+        return res;
+    }
+
+    private static String quote(String v) {
+        StringBuilder sb = new StringBuilder("\"");
+        for (char c : v.toCharArray()) {
+            switch (c) {
+                case '"': sb.append("\\\""); break;
+                case '\\': sb.append("\\\\"); break;
+                case '\n': sb.append("\\n"); break;
+                case '\r': sb.append("\\r"); break;
+                case '\t': sb.append("\\t"); break;
+                default: sb.append(c);
+            }
+        }
+        return sb.append('"').toString();
+    }
+
+    static int parseInt(String s) {
+        return Integer.parseInt(s.trim());
+    }
+
+    static long parseLong(String s) {
+        return Long.parseLong(s.trim());
+    }
+
+    static double parseDouble(String s) {
+        return Double.parseDouble(s.trim());
+    }
+
+    static boolean parseBool(String s) {
+        return Boolean.parseBoolean(s.trim());
+    }
+
+    static String parseString(String s) {
+        return (String) parse(s);
+    }
+
+    static int[] parseIntArray(String s) {
+        return toIntArray((List<?>) parse(s));
+    }
+
+    static String[] parseStringArray(String s) {
+        List<?> list = (List<?>) parse(s);
+        String[] res = new String[list.size()];
+        for (int i = 0; i < res.length; i++) {
+            res[i] = (String) list.get(i);
+        }
+        return res;
+    }
+
+    static int[][] parseIntMatrix(String s) {
+        List<?> list = (List<?>) parse(s);
+        int[][] res = new int[list.size()][];
+        for (int i = 0; i < res.length; i++) {
+            res[i] = toIntArray((List<?>) list.get(i));
+        }
+        return res;
+    }
+
+    static String serializeInt(int v) {
+        return String.valueOf(v);
+    }
+
+    static String serializeLong(long v) {
+        return String.valueOf(v);
+    }
+
+    static String serializeDouble(double v) {
+        return String.format(Locale.ROOT, "%.5f", v);
+    }
+
+    static String serializeBool(boolean v) {
+        return String.valueOf(v);
+    }
+
+    static String serializeString(String v) {
+        return quote(v);
+    }
+
+    static String serializeIntArray(int[] v) {
+        StringJoiner sj = new StringJoiner(",", "[", "]");
+        for (int x : v) {
+            sj.add(String.valueOf(x));
+        }
+        return sj.toString();
+    }
+
+    static String serializeStringArray(String[] v) {
+        StringJoiner sj = new StringJoiner(",", "[", "]");
+        for (String x : v) {
+            sj.add(quote(x));
+        }
+        return sj.toString();
+    }
+
+    static String serializeIntMatrix(int[][] v) {
+        StringJoiner sj = new StringJoiner(",", "[", "]");
+        for (int[] row : v) {
+            sj.add(serializeIntArray(row));
+        }
+        return sj.toString();
+    }
+{{- if .UseList}}
+
+    static ListNode parseListNode(String s) {
+        ListNode dummy = new ListNode();
+        ListNode cur = dummy;
+        for (int v : parseIntArray(s)) {
+            cur.next = new ListNode(v);
+            cur = cur.next;
+        }
+        return dummy.next;
+    }
+
+    static String serializeListNode(ListNode head) {
+        StringJoiner sj = new StringJoiner(",", "[", "]");
+        for (; head != null; head = head.next) {
+            sj.add(String.valueOf(head.val));
+        }
+        return sj.toString();
+    }
+{{- end}}
+{{- if .UseTree}}
+
+    static TreeNode parseTreeNode(String s) {
+        List<?> vals = (List<?>) parse(s);
+        if (vals.isEmpty() || vals.get(0) == null) {
+            return null;
+        }
+        TreeNode root = new TreeNode(Integer.parseInt((String) vals.get(0)));
+        Deque<TreeNode> queue = new ArrayDeque<>();
+        queue.add(root);
+        int i = 1;
+        while (!queue.isEmpty() && i < vals.size()) {
+            TreeNode node = queue.poll();
+            if (vals.get(i) != null) {
+                node.left = new TreeNode(Integer.parseInt((String) vals.get(i)));
+                queue.add(node.left);
+            }
+            i++;
+            if (i < vals.size() && vals.get(i) != null) {
+                node.right = new TreeNode(Integer.parseInt((String) vals.get(i)));
+                queue.add(node.right);
+            }
+            i++;
+        }
+        return root;
+    }
+
+    static String serializeTreeNode(TreeNode root) {
+        List<String> vals = new ArrayList<>();
+        Deque<TreeNode> queue = new LinkedList<>();
+        queue.add(root);
+        while (!queue.isEmpty()) {
+            TreeNode node = queue.poll();
+            if (node == null) {
+                vals.add("null");
+                continue;
+            }
+            vals.add(String.valueOf(node.val));
+            queue.add(node.left);
+            queue.add(node.right);
+        }
+        int n = vals.size();
+        while (n > 0 && vals.get(n - 1).equals("null")) {
+            n--;
+        }
+        return "[" + String.join(",", vals.subList(0, n)) + "]";
+    }
+{{- end}}
+}
+`))
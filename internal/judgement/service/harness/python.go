@@ -0,0 +1,220 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+var pythonDecl = map[Type]string{
+	Int:         "int",
+	Long:        "int",
+	Double:      "float",
+	Bool:        "bool",
+	String:      "str",
+	IntArray:    "List[int]",
+	StringArray: "List[str]",
+	IntMatrix:   "List[List[int]]",
+	ListNode:    "Optional[ListNode]",
+	TreeNode:    "Optional[TreeNode]",
+}
+
+// pythonNaming 辅助函数使用下划线风格，例如 _parse_int_array
+func pythonNaming(op string, key string) string {
+	var sb strings.Builder
+	sb.WriteString("_" + op)
+	for _, r := range key {
+		if unicode.IsUpper(r) {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+type pythonGenerator struct{}
+
+func (g *pythonGenerator) Snippet(sig Signature) string {
+	params := []string{"self"}
+	for _, p := range sig.Params {
+		params = append(params, p.Name+": "+pythonDecl[p.Type])
+	}
+
+	return fmt.Sprintf("class Solution:\n    def %s(%s) -> %s:\n        pass\n",
+		sig.FuncName, strings.Join(params, ", "), pythonDecl[sig.Return])
+}
+
+func (g *pythonGenerator) Driver(sig Signature, userCode string) (string, error) {
+	if !defines(userCode, `(?m)^class\s+Solution\b`) {
+		return "", fmt.Errorf("%w: class Solution not found", ErrUserCode)
+	}
+
+	data := newTemplateData(sig, pythonDecl, pythonNaming, strings.TrimSpace(userCode))
+	data.DefineList = data.UseList && !defines(userCode, `(?m)^class\s+ListNode\b`)
+	data.DefineTree = data.UseTree && !defines(userCode, `(?m)^class\s+TreeNode\b`)
+
+	return render(pythonTemplate, data)
+}
+
+var pythonTemplate = template.Must(template.New("python").Parse(`import json
+import sys
+from collections import deque
+from typing import *
+{{if .DefineList}}
+
+class ListNode:
+    def __init__(self, val=0, next=None):
+        self.val = val
+        self.next = next
+{{end}}{{if .DefineTree}}
+
+class TreeNode:
+    def __init__(self, val=0, left=None, right=None):
+        self.val = val
+        self.left = left
+        self.right = right
+{{end}}
+
+{{.UserCode}}
+
+
+def _encode(v):
+    return json.dumps(v, separators=(",", ":"), ensure_ascii=False)
+
+
+def _parse_int(s):
+    return int(s)
+
+
+def _parse_long(s):
+    return int(s)
+
+
+def _parse_double(s):
+    return float(s)
+
+
+def _parse_bool(s):
+    return json.loads(s)
+
+
+def _parse_string(s):
+    return json.loads(s)
+
+
+def _parse_int_array(s):
+    return json.loads(s)
+
+
+def _parse_string_array(s):
+    return json.loads(s)
+
+
+def _parse_int_matrix(s):
+    return json.loads(s)
+
+
+def _serialize_int(v):
+    return str(int(v))
+
+
+def _serialize_long(v):
+    return str(int(v))
+
+
+def _serialize_double(v):
+    return "%.5f" % v
+
+
+def _serialize_bool(v):
+    return "true" if v else "false"
+
+
+def _serialize_string(v):
+    return _encode(v)
+
+
+def _serialize_int_array(v):
+    return _encode(list(v or []))
+
+
+def _serialize_string_array(v):
+    return _encode(list(v or []))
+
+
+def _serialize_int_matrix(v):
+    return _encode([list(row) for row in (v or [])])
+{{- if .UseList}}
+
+
+def _parse_list_node(s):
+    dummy = cur = ListNode()
+    for val in json.loads(s):
+        cur.next = ListNode(val)
+        cur = cur.next
+    return dummy.next
+
+
+def _serialize_list_node(head):
+    vals = []
+    while head is not None:
+        vals.append(head.val)
+        head = head.next
+    return _encode(vals)
+{{- end}}
+{{- if .UseTree}}
+
+
+def _parse_tree_node(s):
+    vals = json.loads(s)
+    if not vals or vals[0] is None:
+        return None
+    root = TreeNode(vals[0])
+    queue = deque([root])
+    i = 1
+    while queue and i < len(vals):
+        node = queue.popleft()
+        if vals[i] is not None:
+            node.left = TreeNode(vals[i])
+            queue.append(node.left)
+        i += 1
+        if i < len(vals) and vals[i] is not None:
+            node.right = TreeNode(vals[i])
+            queue.append(node.right)
+        i += 1
+    return root
+
+
+def _serialize_tree_node(root):
+    vals = []
+    queue = deque([root])
+    while queue:
+        node = queue.popleft()
+        if node is None:
+            vals.append(None)
+            continue
+        vals.append(node.val)
+        queue.append(node.left)
+        queue.append(node.right)
+    while vals and vals[-1] is None:
+        vals.pop()
+    return _encode(vals)
+{{- end}}
+
+
+def _main():
+    lines = [line.strip() for line in sys.stdin if line.strip()]
+    out = []
+    for i in range(0, len(lines) - {{len .Params}} + 1, {{len .Params}}):
+{{- range $i, $p := .Params}}
+        p{{$i}} = {{$p.Parse}}(lines[i + {{$i}}])
+{{- end}}
+        res = Solution().{{.FuncName}}({{range $i, $p := .Params}}{{if $i}}, {{end}}p{{$i}}{{end}})
+        out.append({{.Return.Serialize}}(res))
+    sys.stdout.write("\n".join(out) + ("\n" if out else ""))
+
+
+if __name__ == "__main__":
+    _main()
+`))
@@ -0,0 +1,123 @@
+package harness
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownType     = errors.New("unknown parameter type")
+	ErrInvalidSign     = errors.New("invalid function signature")
+	ErrUnknownLanguage = errors.New("no harness for language")
+)
+
+// Type 题目作者声明函数签名时可用的参数/返回值类型
+// 输入输出统一使用 LeetCode 风格的字面量，每个参数占一行:
+// int/long: 5, double: 1.5, boolean: true, string: "abc"
+// int[]: [1,2,3], string[]: ["a","b"], int[][]: [[1,2],[3]]
+// ListNode: [1,2,3], TreeNode: [1,null,2,3] (层序，null 表示空节点)
+type Type string
+
+const (
+	Int         Type = "int"
+	Long        Type = "long"
+	Double      Type = "double"
+	Bool        Type = "boolean"
+	String      Type = "string"
+	IntArray    Type = "int[]"
+	StringArray Type = "string[]"
+	IntMatrix   Type = "int[][]"
+	ListNode    Type = "ListNode"
+	TreeNode    Type = "TreeNode"
+)
+
+var types = map[Type]struct{}{
+	Int: {}, Long: {}, Double: {}, Bool: {}, String: {},
+	IntArray: {}, StringArray: {}, IntMatrix: {}, ListNode: {}, TreeNode: {},
+}
+
+func (t Type) Valid() bool {
+	_, ok := types[t]
+	return ok
+}
+
+type Param struct {
+	Name string `json:"name"`
+	Type Type   `json:"type"`
+}
+
+// Signature 题目作者只需声明一次，各语言的驱动代码由它生成
+type Signature struct {
+	FuncName string  `json:"name"`
+	Params   []Param `json:"params"`
+	Return   Type    `json:"return"`
+}
+
+// ParseSignature 解析题目中以 JSON 保存的函数签名
+// 例如 {"name":"twoSum","params":[{"name":"nums","type":"int[]"},{"name":"target","type":"int"}],"return":"int[]"}
+func ParseSignature(raw string) (Signature, error) {
+	var sig Signature
+	if err := json.Unmarshal([]byte(raw), &sig); err != nil {
+		return Signature{}, fmt.Errorf("%w: %v", ErrInvalidSign, err)
+	}
+
+	return sig, sig.Validate()
+}
+
+func (s Signature) Validate() error {
+	if !isIdent(s.FuncName) {
+		return fmt.Errorf("%w: bad function name %q", ErrInvalidSign, s.FuncName)
+	}
+	if len(s.Params) == 0 {
+		return fmt.Errorf("%w: no params", ErrInvalidSign)
+	}
+
+	seen := make(map[string]struct{}, len(s.Params))
+	for _, p := range s.Params {
+		if !isIdent(p.Name) {
+			return fmt.Errorf("%w: bad param name %q", ErrInvalidSign, p.Name)
+		}
+		if _, ok := seen[p.Name]; ok {
+			return fmt.Errorf("%w: duplicated param %q", ErrInvalidSign, p.Name)
+		}
+		seen[p.Name] = struct{}{}
+		if !p.Type.Valid() {
+			return fmt.Errorf("%w: %q", ErrUnknownType, p.Type)
+		}
+	}
+	if !s.Return.Valid() {
+		return fmt.Errorf("%w: %q", ErrUnknownType, s.Return)
+	}
+
+	return nil
+}
+
+// Uses 签名中是否用到了某个类型，用于决定是否生成链表/树的定义
+func (s Signature) Uses(t Type) bool {
+	if s.Return == t {
+		return true
+	}
+	for _, p := range s.Params {
+		if p.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return !strings.HasPrefix(s, "__")
+}
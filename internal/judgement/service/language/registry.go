@@ -0,0 +1,95 @@
+package language
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/crazyfrankie/go-judge/pkg/rpc"
+
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+)
+
+var (
+	ErrUnknownLanguage  = errors.New("unknown language")
+	ErrLanguageDisabled = errors.New("language disabled")
+)
+
+// Registry 统一管理评测语言，本地评测与远程评测都从这里查语言信息
+type Registry interface {
+	// Get 按 Id 或展示名查找语言，不区分大小写，兼容 "go" 与 "Go"、"cpp" 与 "C++" 两种写法
+	Get(lang string) (domain.Language, error)
+	// List 返回所有启用的语言，顺序与配置一致
+	List() []domain.Language
+	// GoJudge 语言在 go-judge 中对应的枚举
+	GoJudge(lang domain.Language) rpc.Language
+}
+
+type MemRegistry struct {
+	langs []domain.Language
+	index map[string]int
+}
+
+// Defaults 未配置语言时使用的默认值，与之前写死在两个评测服务里的映射一致
+func Defaults() []domain.Language {
+	return []domain.Language{
+		{Id: "go", Name: "Go", Extension: "go", Judge0Id: 95, GoJudge: "go", TimeFactor: 1, MemFactor: 1, Enabled: true},
+		{Id: "java", Name: "Java", Extension: "java", Judge0Id: 26, GoJudge: "java", TimeFactor: 2, MemFactor: 2, Enabled: true},
+		{Id: "cpp", Name: "C++", Extension: "cpp", Judge0Id: 10, GoJudge: "cpp", TimeFactor: 1, MemFactor: 1, Enabled: true},
+		{Id: "python", Name: "Python", Extension: "py", Judge0Id: 71, GoJudge: "python", TimeFactor: 3, MemFactor: 2, Enabled: true},
+	}
+}
+
+func NewRegistry(langs []domain.Language) (Registry, error) {
+	r := &MemRegistry{
+		langs: langs,
+		index: make(map[string]int, len(langs)*2),
+	}
+	for i, l := range langs {
+		if l.Id == "" {
+			return nil, fmt.Errorf("language #%d: empty id", i)
+		}
+		if _, ok := rpc.Language_value[l.GoJudge]; l.GoJudge != "" && !ok {
+			return nil, fmt.Errorf("language %s: unknown go-judge language %q", l.Id, l.GoJudge)
+		}
+		for _, key := range []string{l.Id, l.Name} {
+			if key == "" {
+				continue
+			}
+			key = strings.ToLower(key)
+			if j, ok := r.index[key]; ok && j != i {
+				return nil, fmt.Errorf("language %s: duplicated key %q", l.Id, key)
+			}
+			r.index[key] = i
+		}
+	}
+
+	return r, nil
+}
+
+func (r *MemRegistry) Get(lang string) (domain.Language, error) {
+	i, ok := r.index[strings.ToLower(strings.TrimSpace(lang))]
+	if !ok {
+		return domain.Language{}, fmt.Errorf("%w: %s", ErrUnknownLanguage, lang)
+	}
+	if !r.langs[i].Enabled {
+		return domain.Language{}, fmt.Errorf("%w: %s", ErrLanguageDisabled, lang)
+	}
+
+	return r.langs[i], nil
+}
+
+func (r *MemRegistry) List() []domain.Language {
+	res := make([]domain.Language, 0, len(r.langs))
+	for _, l := range r.langs {
+		if l.Enabled {
+			res = append(res, l)
+		}
+	}
+
+	return res
+}
+
+func (r *MemRegistry) GoJudge(lang domain.Language) rpc.Language {
+	return rpc.Language(rpc.Language_value[lang.GoJudge])
+}
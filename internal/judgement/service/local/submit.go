@@ -2,54 +2,94 @@ package local
 
 import (
 	"context"
-	"crypto/sha256"
 	"errors"
-	"fmt"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/dao"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/event"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/contest"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/harness"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/sched"
 	repository2 "github.com/crazyfrankie/onlinejudge/internal/problem/repository"
 
 	"github.com/crazyfrankie/go-judge/pkg/rpc"
 )
 
 var (
-	ErrSyntax = errors.New("your code not fit format")
+	ErrSyntax        = errors.New("your code not fit format")
+	ErrSubmitPending = errors.New("same submission is being judged, please retry later")
 )
 
 type LocSubmitService interface {
-	RunCode(ctx context.Context, submission domain.Submission) (uint64, error)
+	RunCode(ctx context.Context, submission domain.Submission) (domain.SubmitResult, error)
 	CheckResult(ctx context.Context, submitId uint64) (domain.Evaluation, error)
+	GetSubmission(ctx context.Context, submitId uint64) (domain.Submission, error)
 }
 
 type LocSubmitSvc struct {
-	repo   repository.LocalSubmitRepo
-	pmRepo repository2.ProblemRepository
-	client rpc.JudgeServiceClient
+	repo    repository.LocalSubmitRepo
+	pmRepo  repository2.ProblemRepository
+	client  rpc.JudgeServiceClient
+	langs   language.Registry
+	checker contest.Checker
 }
 
-func NewLocSubmitService(repo repository.LocalSubmitRepo, pmRepo repository2.ProblemRepository, client rpc.JudgeServiceClient) LocSubmitService {
+func NewLocSubmitService(repo repository.LocalSubmitRepo, pmRepo repository2.ProblemRepository, client rpc.JudgeServiceClient, langs language.Registry, checker contest.Checker) LocSubmitService {
 	return &LocSubmitSvc{
-		repo:   repo,
-		pmRepo: pmRepo,
-		client: client,
+		repo:    repo,
+		pmRepo:  pmRepo,
+		client:  client,
+		langs:   langs,
+		checker: checker,
 	}
 }
 
-func (l *LocSubmitSvc) RunCode(ctx context.Context, submission domain.Submission) (uint64, error) {
+func (l *LocSubmitSvc) RunCode(ctx context.Context, submission domain.Submission) (_ domain.SubmitResult, err error) {
+	lang, err := l.langs.Get(submission.Language)
+	if err != nil {
+		return domain.SubmitResult{}, err
+	}
+	submission.Language = lang.Id
+
 	pm, err := l.pmRepo.FindProblemByID(ctx, submission.ProblemID)
 	if err != nil {
-		return 0, err
+		return domain.SubmitResult{}, err
 	}
 
-	submission.CodeHash = hashCode(submission.Code)
+	// 比赛 id 由客户端填写，核实不了的按普通提交处理，不能借此抢占比赛的评测优先级
+	if submission.ContestId != 0 && !l.checker.Participating(ctx, submission.ContestId, submission.ProblemID, submission.UserId) {
+		submission.ContestId = 0
+	}
+
+	submission.CodeHash = domain.NormalizedHash(submission.Code)
+	// 窗口期内的重复提交直接返回第一次的提交与评测结果，不再重复评测
 	var submitID uint64
+	submitID, err = l.claim(ctx, submission)
+	if err != nil {
+		return domain.SubmitResult{}, err
+	}
+	if submitID != 0 {
+		var eva domain.Evaluation
+		eva, err = l.repo.FindEvaluate(ctx, submitID)
+		if err != nil {
+			return domain.SubmitResult{}, err
+		}
+		return domain.SubmitResult{SubmissionId: submitID, Duplicate: true, Evaluation: &eva}, nil
+	}
+	// 占位之后任何一步失败都要释放，否则窗口期内的重试会一直拿到这次没评完的提交
+	defer func() {
+		if err != nil {
+			_ = l.repo.ReleaseSubmit(context.WithoutCancel(ctx), submission)
+		}
+	}()
+
 	submitID, err = l.repo.CreateSubmit(ctx, submission)
 	if err != nil {
-		return 0, err
+		return domain.SubmitResult{}, err
 	}
 
 	err = l.repo.CreateEvaluate(ctx, domain.Evaluation{
@@ -59,42 +99,102 @@ func (l *LocSubmitSvc) RunCode(ctx context.Context, submission domain.Submission
 		State:        "PENDING",
 	})
 	if err != nil {
-		return 0, err
-	}
-
-	var res *rpc.JudgeResponse
-	res, err = l.client.Judge(ctx, &rpc.JudgeRequest{
-		Language:       getLanguage(submission.Language),
-		ProblemId:      int64(submission.ProblemID),
-		Uid:            int64(submission.UserId),
-		Code:           submission.Code,
-		FullTemplate:   pm.FullTemplate,
-		TypeDefinition: pm.TypeDefinition,
-		Input:          pm.Input,
-		Output:         pm.Output,
-		MaxMem:         strconv.Itoa(pm.MaxMem),
-		MaxTime:        strconv.Itoa(pm.MaxRuntime),
-	})
+		return domain.SubmitResult{}, err
+	}
+	err = l.repo.BindSubmit(ctx, submission, submitID)
 	if err != nil {
-		return 0, err
+		return domain.SubmitResult{}, err
 	}
 
-	fmt.Println(res)
+	code, fullTemplate, typeDefinition := submission.Code, pm.FullTemplate, pm.TypeDefinition
+	// 题目声明了函数签名时由 harness 生成完整的驱动程序，评测机直接编译运行即可，不再需要模板拼接
+	if pm.Signature != "" {
+		code, err = driver(pm.Signature, submission.Language, submission.Code)
+		if err != nil {
+			return domain.SubmitResult{}, err
+		}
+		fullTemplate, typeDefinition = "", ""
+	}
+
+	// 相同题目版本、语言与代码的结论是确定的，任何人提交过都可以直接复用
+	key := domain.ResultKey{
+		Engine:    domain.EngineLocal,
+		ProblemId: pm.Id,
+		Revision:  pm.Revision,
+		Language:  lang.Id,
+		TimeLimit: lang.TimeLimit(pm.MaxRuntime),
+		MemLimit:  lang.MemLimit(pm.MaxMem),
+		CodeHash:  domain.NormalizedHash(submission.Code),
+	}
+	result, err := l.repo.FindResult(ctx, key)
+	if err != nil {
+		class := sched.ClassSubmit
+		// 走到这里的比赛 id 都已经核实过
+		if submission.ContestId != 0 {
+			class = sched.ClassContest
+		}
+
+		var res *rpc.JudgeResponse
+		res, err = l.client.Judge(sched.WithClass(ctx, class), &rpc.JudgeRequest{
+			Language:       l.langs.GoJudge(lang),
+			ProblemId:      int64(submission.ProblemID),
+			Uid:            int64(submission.UserId),
+			Code:           code,
+			FullTemplate:   fullTemplate,
+			TypeDefinition: typeDefinition,
+			Input:          pm.Input,
+			Output:         pm.Output,
+			MaxMem:         strconv.Itoa(key.MemLimit),
+			MaxTime:        strconv.Itoa(key.TimeLimit),
+		})
+		if err != nil {
+			return domain.SubmitResult{}, err
+		}
+
+		result = domain.Evaluation{
+			CpuTimeUsed:  res.GetResult().GetTimeUsed(),
+			RealTimeUsed: res.GetResult().GetTimeUsed(),
+			MemoryUsed:   res.GetResult().GetMemoryUsed(),
+			StatusMsg:    res.GetResult().GetStatusMsg(),
+		}
+		if domain.Cacheable(result.StatusMsg) {
+			_ = l.repo.StoreResult(ctx, key, result)
+		}
+	}
 
-	//评测结果存入数据库
+	//评测结果存入数据库，评测结束事件随结果一起写入发件箱
 	var state dao.State
+	result.State = dao.SUCCESS
 	err = l.repo.UpdateResult(ctx, submission.ProblemID, submitID, map[string]any{
-		"cpu_time_used":  res.GetResult().TimeUsed,
-		"real_time_used": res.GetResult().TimeUsed,
-		"memory_used":    res.GetResult().MemoryUsed,
-		"status_msg":     res.GetResult().StatusMsg,
-		"state":          state.ToUint8(dao.PENGIND),
-	})
+		"cpu_time_used":  result.CpuTimeUsed,
+		"real_time_used": result.RealTimeUsed,
+		"memory_used":    result.MemoryUsed,
+		"status_msg":     result.StatusMsg,
+		"state":          state.ToUint8(result.State),
+	}, event.NewResultOutbox(submission, submitID, result))
 	if err != nil {
-		return 0, err
+		return domain.SubmitResult{}, err
+	}
+
+	return domain.SubmitResult{SubmissionId: submitID}, nil
+}
+
+// claim 抢占去重窗口，前一次相同的提交还没写完库时稍等片刻拿它的 id
+func (l *LocSubmitSvc) claim(ctx context.Context, submission domain.Submission) (uint64, error) {
+	for i := 0; i < 10; i++ {
+		sid, err := l.repo.ClaimSubmit(ctx, submission)
+		if !errors.Is(err, repository.ErrSubmitPending) {
+			return sid, err
+		}
+
+		select {
+		case <-time.After(time.Millisecond * 50):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
 	}
 
-	return submitID, nil
+	return 0, ErrSubmitPending
 }
 
 func (l *LocSubmitSvc) CheckResult(ctx context.Context, submitId uint64) (domain.Evaluation, error) {
@@ -106,66 +206,15 @@ func (l *LocSubmitSvc) CheckResult(ctx context.Context, submitId uint64) (domain
 	return res, nil
 }
 
-func getLanguage(s string) rpc.Language {
-	var lang rpc.Language
-	switch s {
-	case "go":
-		lang = rpc.Language_go
-	case "java":
-		lang = rpc.Language_java
-	case "cpp":
-		lang = rpc.Language_cpp
-	case "python":
-		lang = rpc.Language_python
-	}
-	return lang
-}
-
-func hashCode(code string) string {
-	preprocessed := preprocessCode(code)
-	sum := sha256.Sum256([]byte(preprocessed))
-	return fmt.Sprintf("%x", sum)
+func (l *LocSubmitSvc) GetSubmission(ctx context.Context, submitId uint64) (domain.Submission, error) {
+	return l.repo.FindSubmission(ctx, submitId)
 }
 
-func preprocessCode(code string) string {
-	var buf strings.Builder
-	inBlockComment := false
-
-	for _, line := range strings.Split(code, "\n") {
-		trimmed := strings.TrimSpace(line)
-
-		// 空行直接跳过
-		if trimmed == "" {
-			continue
-		}
-
-		// 处理多行注释（/* ... */）
-		if strings.Contains(trimmed, "/*") {
-			inBlockComment = true
-		}
-		if inBlockComment {
-			if strings.Contains(trimmed, "*/") {
-				inBlockComment = false
-			}
-			continue
-		}
-
-		// 单行注释过滤（C/Java）
-		if idx := strings.Index(trimmed, "//"); idx != -1 {
-			trimmed = trimmed[:idx]
-			trimmed = strings.TrimSpace(trimmed)
-		}
-
-		// Python 注释（#）
-		if idx := strings.Index(trimmed, "#"); idx != -1 {
-			trimmed = trimmed[:idx]
-			trimmed = strings.TrimSpace(trimmed)
-		}
-
-		if trimmed != "" {
-			buf.WriteString(trimmed)
-			buf.WriteString("\n")
-		}
+func driver(signature string, lang string, userCode string) (string, error) {
+	sig, err := harness.ParseSignature(signature)
+	if err != nil {
+		return "", err
 	}
-	return buf.String()
+
+	return harness.Generate(lang, sig, userCode)
 }
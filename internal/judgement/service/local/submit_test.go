@@ -0,0 +1,157 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository"
+	repomocks "github.com/crazyfrankie/onlinejudge/internal/judgement/repository/mocks"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
+	domain2 "github.com/crazyfrankie/onlinejudge/internal/problem/domain"
+	pmmocks "github.com/crazyfrankie/onlinejudge/internal/problem/repository/mocks"
+)
+
+func TestLocSubmitSvc_Claim(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(repo *repomocks.MockLocalSubmitRepo)
+		// cancel 重试期间请求被取消
+		cancel  bool
+		wantSid uint64
+		wantErr error
+	}{
+		{
+			name: "claimed",
+			mock: func(repo *repomocks.MockLocalSubmitRepo) {
+				repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(0), nil)
+			},
+		},
+		{
+			name: "duplicate",
+			mock: func(repo *repomocks.MockLocalSubmitRepo) {
+				repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(42), nil)
+			},
+			wantSid: 42,
+		},
+		{
+			name: "pending then bound",
+			mock: func(repo *repomocks.MockLocalSubmitRepo) {
+				gomock.InOrder(
+					repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(0), repository.ErrSubmitPending).Times(2),
+					repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(42), nil),
+				)
+			},
+			wantSid: 42,
+		},
+		{
+			name: "still pending",
+			mock: func(repo *repomocks.MockLocalSubmitRepo) {
+				repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(0), repository.ErrSubmitPending).Times(10)
+			},
+			wantErr: ErrSubmitPending,
+		},
+		{
+			name: "canceled while pending",
+			mock: func(repo *repomocks.MockLocalSubmitRepo) {
+				repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(0), repository.ErrSubmitPending)
+			},
+			cancel:  true,
+			wantErr: context.Canceled,
+		},
+		{
+			name: "redis error",
+			mock: func(repo *repomocks.MockLocalSubmitRepo) {
+				repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(0), errors.New("redis down"))
+			},
+			wantErr: errors.New("redis down"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := repomocks.NewMockLocalSubmitRepo(ctrl)
+			tc.mock(repo)
+			svc := &LocSubmitSvc{repo: repo}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel {
+				cancel()
+			}
+			sid, err := svc.claim(ctx, domain.Submission{})
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSid, sid)
+		})
+	}
+}
+
+func TestLocSubmitSvc_RunCode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(repo *repomocks.MockLocalSubmitRepo)
+		want    domain.SubmitResult
+		wantErr bool
+	}{
+		{
+			name: "duplicate returns first submission",
+			mock: func(repo *repomocks.MockLocalSubmitRepo) {
+				repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(42), nil)
+				repo.EXPECT().FindEvaluate(gomock.Any(), uint64(42)).Return(domain.Evaluation{SubmissionId: 42}, nil)
+			},
+			want: domain.SubmitResult{SubmissionId: 42, Duplicate: true, Evaluation: &domain.Evaluation{SubmissionId: 42}},
+		},
+		{
+			name: "release when create failed",
+			mock: func(repo *repomocks.MockLocalSubmitRepo) {
+				repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(0), nil)
+				repo.EXPECT().CreateSubmit(gomock.Any(), gomock.Any()).Return(uint64(0), errors.New("db down"))
+				repo.EXPECT().ReleaseSubmit(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "release when bind failed",
+			mock: func(repo *repomocks.MockLocalSubmitRepo) {
+				repo.EXPECT().ClaimSubmit(gomock.Any(), gomock.Any()).Return(uint64(0), nil)
+				repo.EXPECT().CreateSubmit(gomock.Any(), gomock.Any()).Return(uint64(1), nil)
+				repo.EXPECT().CreateEvaluate(gomock.Any(), gomock.Any()).Return(nil)
+				repo.EXPECT().BindSubmit(gomock.Any(), gomock.Any(), uint64(1)).Return(errors.New("redis down"))
+				repo.EXPECT().ReleaseSubmit(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	langs, err := language.NewRegistry([]domain.Language{{Id: "go", Enabled: true}})
+	require.NoError(t, err)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := repomocks.NewMockLocalSubmitRepo(ctrl)
+			pmRepo := pmmocks.NewMockProblemRepository(ctrl)
+			pmRepo.EXPECT().FindProblemByID(gomock.Any(), uint64(1)).Return(domain2.Problem{Id: 1}, nil)
+			tc.mock(repo)
+			svc := NewLocSubmitService(repo, pmRepo, nil, langs, nil)
+
+			res, err := svc.RunCode(context.Background(), domain.Submission{
+				UserId:    1,
+				ProblemID: 1,
+				Language:  "go",
+				Code:      "package main",
+			})
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package pool
+
+import (
+	"sync"
+	"time"
+)
+
+type State int32
+
+const (
+	// Closed 正常放行
+	Closed State = iota
+	// Open 熔断中，直接拒绝
+	Open
+	// HalfOpen 熔断时间到了，放一个请求过去试探
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half_open"
+	}
+	return "unknown"
+}
+
+// Breaker 按连续失败次数熔断的断路器
+// 连续失败 threshold 次后打开，经过 timeout 进入半开，半开时只放一个请求，成功则关闭，失败则重新打开
+type Breaker struct {
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	threshold int
+	timeout   time.Duration
+}
+
+func NewBreaker(threshold int, timeout time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		timeout:   timeout,
+	}
+}
+
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < b.timeout {
+			return false
+		}
+		b.state = HalfOpen
+		b.probing = true
+		return true
+	case HalfOpen:
+		// 已经有一个试探请求在路上了
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+
+	return true
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = Closed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.state = Open
+		b.openedAt = time.Now()
+	}
+}
+
+// Release 请求的结果不能说明节点好坏时调用，只归还半开状态下的试探名额
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
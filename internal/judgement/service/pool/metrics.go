@@ -0,0 +1,54 @@
+package pool
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type metrics struct {
+	reqs     *prometheus.CounterVec
+	duration *prometheus.SummaryVec
+	inflight *prometheus.GaugeVec
+	up       *prometheus.GaugeVec
+	breaker  *prometheus.GaugeVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		reqs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "judge_node_requests",
+			Help:      "统计评测节点的请求数",
+		}, []string{"node", "result"}),
+		duration: prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "judge_node_resp_time",
+			Help:      "统计评测节点的响应时间",
+			Objectives: map[float64]float64{
+				0.5:  0.01,
+				0.9:  0.01,
+				0.99: 0.001,
+			},
+		}, []string{"node"}),
+		inflight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "judge_node_inflight",
+			Help:      "评测节点正在处理的请求数",
+		}, []string{"node"}),
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "judge_node_up",
+			Help:      "评测节点健康检查结果，1 为健康",
+		}, []string{"node"}),
+		breaker: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "judge_node_breaker_state",
+			Help:      "评测节点断路器状态，0 关闭 1 打开 2 半开",
+		}, []string{"node"}),
+	}
+	prometheus.MustRegister(m.reqs, m.duration, m.inflight, m.up, m.breaker)
+
+	return m
+}
@@ -0,0 +1,51 @@
+package pool
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+const (
+	RoundRobin  = "round_robin"
+	LeastLoaded = "least_loaded"
+)
+
+// Picker 给出本次请求尝试节点的顺序，第一个失败了按顺序往后切换
+type Picker interface {
+	Pick(nodes []*Node) []*Node
+}
+
+func NewPicker(strategy string) Picker {
+	if strategy == LeastLoaded {
+		return &leastLoaded{}
+	}
+	return &roundRobin{}
+}
+
+type roundRobin struct {
+	idx uint64
+}
+
+func (r *roundRobin) Pick(nodes []*Node) []*Node {
+	// 原子操作，并发安全
+	idx := atomic.AddUint64(&r.idx, 1)
+	length := uint64(len(nodes))
+	res := make([]*Node, 0, length)
+	for i := idx; i < length+idx; i++ {
+		res = append(res, nodes[int(i%length)])
+	}
+	return res
+}
+
+// leastLoaded 按正在处理的请求数从小到大排序，负载相同时轮询打散
+type leastLoaded struct {
+	rr roundRobin
+}
+
+func (l *leastLoaded) Pick(nodes []*Node) []*Node {
+	res := l.rr.Pick(nodes)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Inflight() < res[j].Inflight()
+	})
+	return res
+}
@@ -0,0 +1,250 @@
+/*
+评测节点池
+多个 go-judge 节点之间做负载均衡、健康检查与熔断，某个节点失败时自动切换到下一个
+Pool 本身实现了 rpc.JudgeServiceClient，对调用方透明
+*/
+
+package pool
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/crazyfrankie/go-judge/pkg/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrNoAvailableNode = errors.New("no available judge node")
+)
+
+type Node struct {
+	Addr    string
+	client  rpc.JudgeServiceClient
+	health  grpc_health_v1.HealthClient
+	breaker *Breaker
+
+	inflight int64
+	healthy  atomic.Bool
+	// lastErr 最近一次失败的原因，给管理接口展示用
+	lastErr atomic.Value
+}
+
+func NewNode(addr string, cc grpc.ClientConnInterface, breaker *Breaker) *Node {
+	n := &Node{
+		Addr:    addr,
+		client:  rpc.NewJudgeServiceClient(cc),
+		health:  grpc_health_v1.NewHealthClient(cc),
+		breaker: breaker,
+	}
+	// 第一次健康检查之前先认为节点可用
+	n.healthy.Store(true)
+	return n
+}
+
+func (n *Node) Inflight() int64 {
+	return atomic.LoadInt64(&n.inflight)
+}
+
+// available 健康检查通过且断路器放行
+func (n *Node) available() bool {
+	return n.healthy.Load() && n.breaker.Allow()
+}
+
+type NodeStatus struct {
+	Addr     string `json:"addr"`
+	Healthy  bool   `json:"healthy"`
+	Breaker  string `json:"breaker"`
+	Inflight int64  `json:"inflight"`
+	LastErr  string `json:"lastErr,omitempty"`
+}
+
+type Config struct {
+	Strategy string
+	// HealthInterval 健康检查间隔
+	HealthInterval time.Duration
+	// FailureThreshold 连续失败多少次触发熔断
+	FailureThreshold int
+	// OpenTimeout 熔断后多久进入半开状态
+	OpenTimeout time.Duration
+}
+
+type Pool struct {
+	nodes   []*Node
+	picker  Picker
+	cfg     Config
+	metrics *metrics
+}
+
+func NewPool(nodes []*Node, cfg Config) *Pool {
+	p := &Pool{
+		nodes:   nodes,
+		picker:  NewPicker(cfg.Strategy),
+		cfg:     cfg,
+		metrics: newMetrics(),
+	}
+	for _, n := range nodes {
+		p.metrics.up.WithLabelValues(n.Addr).Set(1)
+	}
+
+	return p
+}
+
+func (p *Pool) Judge(ctx context.Context, in *rpc.JudgeRequest, opts ...grpc.CallOption) (*rpc.JudgeResponse, error) {
+	if len(p.nodes) == 0 {
+		return nil, ErrNoAvailableNode
+	}
+
+	for _, n := range p.picker.Pick(p.nodes) {
+		if !n.available() {
+			continue
+		}
+
+		res, failover, err := p.call(ctx, n, in, opts...)
+		switch {
+		case err == nil:
+			return res, nil
+		case ctx.Err() != nil:
+			// 调用者的超时时间到了
+			// 调用者主动取消了
+			return nil, err
+		case !failover:
+			// 请求本身有问题，或者节点可能已经执行过了，换节点可能会重复评测
+			return nil, err
+		}
+	}
+
+	return nil, ErrNoAvailableNode
+}
+
+// call 返回的 failover 表示可以换一个节点重试
+func (p *Pool) call(ctx context.Context, n *Node, in *rpc.JudgeRequest, opts ...grpc.CallOption) (*rpc.JudgeResponse, bool, error) {
+	p.metrics.inflight.WithLabelValues(n.Addr).Set(float64(atomic.AddInt64(&n.inflight, 1)))
+	start := time.Now()
+	defer func() {
+		p.metrics.duration.WithLabelValues(n.Addr).Observe(float64(time.Since(start).Milliseconds()))
+		p.metrics.inflight.WithLabelValues(n.Addr).Set(float64(atomic.AddInt64(&n.inflight, -1)))
+	}()
+
+	// 请求发到了连接上才会记录对端地址，用来区分超时发生在发送之前还是之后
+	var pr peer.Peer
+	res, err := n.client.Judge(ctx, in, append(opts[:len(opts):len(opts)], grpc.Peer(&pr))...)
+	failover := retryable(err, pr.Addr != nil)
+	switch {
+	case err == nil:
+		n.breaker.Success()
+		p.metrics.reqs.WithLabelValues(n.Addr, "success").Inc()
+	case ctx.Err() != nil:
+		// 调用方自己取消了，不计入熔断，但试探名额要还回去
+		n.breaker.Release()
+		p.metrics.reqs.WithLabelValues(n.Addr, "canceled").Inc()
+	case !unhealthy(err):
+		// 节点正常响应了，只是请求本身有问题
+		n.breaker.Success()
+		p.metrics.reqs.WithLabelValues(n.Addr, "error").Inc()
+	default:
+		n.breaker.Failure()
+		n.lastErr.Store(err.Error())
+		if failover {
+			p.metrics.reqs.WithLabelValues(n.Addr, "failover").Inc()
+		} else {
+			p.metrics.reqs.WithLabelValues(n.Addr, "error").Inc()
+		}
+	}
+	p.metrics.breaker.WithLabelValues(n.Addr).Set(float64(n.breaker.State()))
+
+	return res, failover, err
+}
+
+// unhealthy 节点不可用、超时、过载、内部错误，说明节点本身有问题，计入熔断
+func unhealthy(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// retryable 只有能确定节点没有执行评测的错误才换一个节点重试，否则同一份代码可能被评测两次
+// Unavailable 和 ResourceExhausted 是节点拒绝了请求，DeadlineExceeded 只有请求还没发出去时才算；
+// Internal、Unknown 这类错误可能是评测完之后才出的，不重试
+func retryable(err error, sent bool) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	case codes.DeadlineExceeded:
+		return !sent
+	}
+	return false
+}
+
+// StartHealthCheck 定时检查所有节点，直到 ctx 结束
+func (p *Pool) StartHealthCheck(ctx context.Context) {
+	if p.cfg.HealthInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(p.cfg.HealthInterval)
+		defer ticker.Stop()
+		for {
+			p.checkAll(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (p *Pool) checkAll(ctx context.Context) {
+	for _, n := range p.nodes {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		res, err := n.health.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		cancel()
+
+		healthy := err == nil && res.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING
+		// 节点没有实现健康检查服务时只能靠熔断来兜底
+		if status.Code(err) == codes.Unimplemented {
+			healthy = true
+		}
+		if err != nil && !healthy {
+			n.lastErr.Store(err.Error())
+		}
+		if n.healthy.Swap(healthy) != healthy {
+			log.Printf("judge node %s healthy: %v", n.Addr, healthy)
+		}
+
+		up := 0
+		if healthy {
+			up = 1
+		}
+		p.metrics.up.WithLabelValues(n.Addr).Set(float64(up))
+	}
+}
+
+func (p *Pool) Status() []NodeStatus {
+	res := make([]NodeStatus, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		st := NodeStatus{
+			Addr:     n.Addr,
+			Healthy:  n.healthy.Load(),
+			Breaker:  n.breaker.State().String(),
+			Inflight: n.Inflight(),
+		}
+		if e, ok := n.lastErr.Load().(string); ok {
+			st.LastErr = e
+		}
+		res = append(res, st)
+	}
+
+	return res
+}
@@ -0,0 +1,117 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crazyfrankie/go-judge/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeClient struct {
+	err   error
+	calls int
+}
+
+func (f *fakeClient) Judge(ctx context.Context, in *rpc.JudgeRequest, opts ...grpc.CallOption) (*rpc.JudgeResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &rpc.JudgeResponse{}, nil
+}
+
+func newTestNode(addr string, client rpc.JudgeServiceClient) *Node {
+	n := &Node{
+		Addr:    addr,
+		client:  client,
+		breaker: NewBreaker(2, time.Hour),
+	}
+	n.healthy.Store(true)
+	return n
+}
+
+func TestBreaker(t *testing.T) {
+	b := NewBreaker(2, 10*time.Millisecond)
+	assert.True(t, b.Allow())
+
+	b.Failure()
+	assert.Equal(t, Closed, b.State())
+	b.Failure()
+	assert.Equal(t, Open, b.State())
+	assert.False(t, b.Allow())
+
+	time.Sleep(20 * time.Millisecond)
+	// 半开状态只放一个请求
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+
+	b.Failure()
+	assert.Equal(t, Open, b.State())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, b.Allow())
+	b.Success()
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestPool_Failover(t *testing.T) {
+	down := &fakeClient{err: status.Error(codes.Unavailable, "down")}
+	up := &fakeClient{}
+	p := NewPool([]*Node{newTestNode("down", down), newTestNode("up", up)}, Config{})
+
+	for i := 0; i < 4; i++ {
+		_, err := p.Judge(context.Background(), &rpc.JudgeRequest{})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 4, up.calls)
+	// 连续失败两次后熔断，之后不再打到故障节点
+	assert.Equal(t, 2, down.calls)
+	assert.Equal(t, "open", p.Status()[0].Breaker)
+
+	// 请求本身的错误不切换节点
+	up.err = status.Error(codes.InvalidArgument, "bad request")
+	_, err := p.Judge(context.Background(), &rpc.JudgeRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	up.err = status.Error(codes.Unavailable, "down")
+	_, err = p.Judge(context.Background(), &rpc.JudgeRequest{})
+	assert.ErrorIs(t, err, ErrNoAvailableNode)
+}
+
+func TestRetryable(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		sent bool
+		want bool
+	}{
+		{name: "unavailable", err: status.Error(codes.Unavailable, "down"), sent: true, want: true},
+		{name: "overloaded", err: status.Error(codes.ResourceExhausted, "busy"), sent: true, want: true},
+		{name: "timeout before send", err: status.Error(codes.DeadlineExceeded, "timeout"), sent: false, want: true},
+		{name: "timeout after send", err: status.Error(codes.DeadlineExceeded, "timeout"), sent: true, want: false},
+		{name: "internal", err: status.Error(codes.Internal, "panic"), sent: true, want: false},
+		{name: "unknown", err: status.Error(codes.Unknown, "eof"), sent: true, want: false},
+		{name: "bad request", err: status.Error(codes.InvalidArgument, "bad request"), sent: true, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, retryable(tc.err, tc.sent))
+		})
+	}
+}
+
+func TestLeastLoaded(t *testing.T) {
+	a, b := newTestNode("a", nil), newTestNode("b", nil)
+	a.inflight = 3
+	picker := NewPicker(LeastLoaded)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "b", picker.Pick([]*Node{a, b})[0].Addr)
+	}
+}
@@ -0,0 +1,180 @@
+/*
+提交配额
+按用户、按用户在单道题目上分别限制提交频率，比如每分钟 N 次、每天 M 次
+管理员与比赛期间各自使用独立的档位，每条规则背后都是一个 ratelimit.Limiter
+*/
+
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/infra/contract/ratelimit"
+)
+
+type Tier string
+
+const (
+	TierNormal  Tier = "normal"
+	TierAdmin   Tier = "admin"
+	TierContest Tier = "contest"
+)
+
+type Scope string
+
+const (
+	// ScopeUser 用户的全部提交
+	ScopeUser Scope = "user"
+	// ScopeProblem 用户在单道题目上的提交
+	ScopeProblem Scope = "problem"
+)
+
+// adminObject 拥有这个权限的用户使用管理员档位
+const adminObject = "judge:quota:admin"
+
+type Rule struct {
+	Scope    Scope
+	Interval time.Duration
+	Rate     int
+}
+
+type Request struct {
+	Uid       uint64
+	ProblemId uint64
+	// ContestId 由客户端填写，经过 ContestChecker 核实后才使用比赛档位
+	ContestId uint64
+}
+
+// QuotaError 超出配额，RetryAfter 为 0 表示无法给出准确的等待时间
+type QuotaError struct {
+	*er.BizError
+	Tier       Tier
+	Rule       Rule
+	RetryAfter time.Duration
+}
+
+// Detail 返回给前端的限流详情
+func (e *QuotaError) Detail() map[string]any {
+	return map[string]any{
+		"tier":       e.Tier,
+		"scope":      e.Rule.Scope,
+		"interval":   int(e.Rule.Interval.Seconds()),
+		"rate":       e.Rule.Rate,
+		"retryAfter": int((e.RetryAfter + time.Second - 1) / time.Second),
+	}
+}
+
+type Quota interface {
+	Check(ctx context.Context, req Request) error
+}
+
+// Authorizer 与 mws.Authorizer 一致，用来判断是否是管理员
+type Authorizer interface {
+	Authorize(subject, object, action string) (bool, error)
+}
+
+// ContestChecker 与 contest.Checker 一致，用来核实用户是否在比赛中
+type ContestChecker interface {
+	Participating(ctx context.Context, contestId, problemId, uid uint64) bool
+}
+
+// LimiterFactory 每条规则的窗口与阈值不同，需要各自的限流器
+type LimiterFactory func(interval time.Duration, rate int) ratelimit.Limiter
+
+type limitedRule struct {
+	Rule
+	limiter ratelimit.Limiter
+}
+
+type LimiterQuota struct {
+	tiers   map[Tier][]limitedRule
+	authz   Authorizer
+	checker ContestChecker
+}
+
+func NewQuota(tiers map[Tier][]Rule, factory LimiterFactory, authz Authorizer, checker ContestChecker) Quota {
+	q := &LimiterQuota{
+		tiers:   make(map[Tier][]limitedRule, len(tiers)),
+		authz:   authz,
+		checker: checker,
+	}
+	for tier, rules := range tiers {
+		for _, r := range rules {
+			if r.Interval <= 0 || r.Rate <= 0 {
+				continue
+			}
+			q.tiers[tier] = append(q.tiers[tier], limitedRule{
+				Rule:    r,
+				limiter: factory(r.Interval, r.Rate),
+			})
+		}
+	}
+
+	return q
+}
+
+func (q *LimiterQuota) Check(ctx context.Context, req Request) error {
+	tier := q.tier(ctx, req)
+	for _, r := range q.tiers[tier] {
+		key := q.key(tier, r.Rule, req)
+		limited, err := r.limiter.Limit(ctx, key)
+		if err != nil {
+			// redis 出问题时不能让所有人都没法提交
+			continue
+		}
+		if !limited {
+			continue
+		}
+
+		qe := &QuotaError{
+			BizError: er.NewBizError(constant.ErrJudgeQuotaExceeded),
+			Tier:     tier,
+			Rule:     r.Rule,
+		}
+		if w, ok := r.limiter.(ratelimit.Waiter); ok {
+			qe.RetryAfter, _ = w.RetryAfter(ctx, key)
+		}
+		if qe.RetryAfter <= 0 {
+			// 给不出准确时间时保守地等一整个窗口
+			qe.RetryAfter = r.Interval
+		}
+
+		return qe
+	}
+
+	return nil
+}
+
+// tier 管理员不受比赛档位的限制
+// 核实不了的比赛 id 按普通提交处理，否则随便填一个比赛 id 就能换一套独立的配额
+func (q *LimiterQuota) tier(ctx context.Context, req Request) Tier {
+	if q.authz != nil {
+		ok, err := q.authz.Authorize(strconv.FormatUint(req.Uid, 10), adminObject, "CALL")
+		if err == nil && ok {
+			return TierAdmin
+		}
+	}
+	if req.ContestId != 0 && q.checker != nil && q.checker.Participating(ctx, req.ContestId, req.ProblemId, req.Uid) {
+		return TierContest
+	}
+
+	return TierNormal
+}
+
+func (q *LimiterQuota) key(tier Tier, r Rule, req Request) string {
+	window := int64(r.Interval.Seconds())
+	prefix := fmt.Sprintf("submit-quota:%s", tier)
+	if tier == TierContest {
+		prefix = fmt.Sprintf("%s:%d", prefix, req.ContestId)
+	}
+	if r.Scope == ScopeProblem {
+		return fmt.Sprintf("%s:problem:%d:%d:%d", prefix, req.Uid, req.ProblemId, window)
+	}
+
+	return fmt.Sprintf("%s:user:%d:%d", prefix, req.Uid, window)
+}
@@ -3,9 +3,7 @@ package remote
 import (
 	"bytes"
 	"context"
-	"crypto/sha256"
 	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,12 +12,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sync/atomic"
+	"strconv"
 
 	"github.com/bytedance/sonic"
 
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/event"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/dao"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
 	domain2 "github.com/crazyfrankie/onlinejudge/internal/problem/domain"
 	repository2 "github.com/crazyfrankie/onlinejudge/internal/problem/repository"
 )
@@ -30,12 +31,13 @@ var (
 
 type SubmitService interface {
 	RunCode(ctx context.Context, submission domain.Submission, language string) ([]domain.RemoteEvaluation, error)
-	SubmitCode(ctx context.Context, submission domain.Submission, language string) ([]domain.RemoteEvaluation, error)
+	SubmitCode(ctx context.Context, submission domain.Submission, language string) (domain.SubmitResult, error)
+	CheckResult(ctx context.Context, submitId uint64) (domain.Evaluation, error)
 
 	GetEvaluation(eval map[string]interface{}) (domain.RemoteEvaluation, error)
 
-	GetResult(ctx context.Context, testCases []domain2.TestCase, langId int8, encodedCode string, result []domain.RemoteEvaluation) ([]domain.RemoteEvaluation, error)
-	SetSubmission(id int8, code, stdin, stdout string) Submission
+	GetResult(ctx context.Context, testCases []domain2.TestCase, lang domain.Language, pm domain2.Problem, encodedCode string, result []domain.RemoteEvaluation) ([]domain.RemoteEvaluation, error)
+	SetSubmission(lang domain.Language, pm domain2.Problem, code, stdin, stdout string) Submission
 
 	CodeFormat(language, way string, submission domain.Submission) (error, bool)
 	GoFormat(code, way string, userId, problemId uint64) bool
@@ -49,94 +51,137 @@ type SubmitService interface {
 type SubmissionSvc struct {
 	repo        repository.SubmitRepository
 	pmRepo      repository2.ProblemRepository
-	language    map[string]int8
+	langs       language.Registry
 	rapidapiKey string
 }
 
-func NewSubmitService(repo repository.SubmitRepository, pmRepo repository2.ProblemRepository, key string) SubmitService {
-	lang := map[string]int8{
-		"Go":     95,
-		"Java":   26,
-		"Python": 71,
-		"C++":    10,
-	}
+func NewSubmitService(repo repository.SubmitRepository, pmRepo repository2.ProblemRepository, langs language.Registry, key string) SubmitService {
 	return &SubmissionSvc{
 		repo:        repo,
 		pmRepo:      pmRepo,
-		language:    lang,
+		langs:       langs,
 		rapidapiKey: key,
 	}
 }
 
 type Submission struct {
-	LanguageId int8   `json:"language_id"`
-	Code       string `json:"source_code"`
-	Stdin      string `json:"stdin"`  // 接收多个标准输入
-	StdOut     string `json:"stdout"` // 接收多个期望输出
+	LanguageId   int     `json:"language_id"`
+	Code         string  `json:"source_code"`
+	Stdin        string  `json:"stdin"`                    // 接收多个标准输入
+	StdOut       string  `json:"stdout"`                   // 接收多个期望输出
+	CpuTimeLimit float64 `json:"cpu_time_limit,omitempty"` // 单位 s
+	MemoryLimit  int     `json:"memory_limit,omitempty"`   // 单位 KB
 }
 
 func (svc *SubmissionSvc) RunCode(ctx context.Context, submission domain.Submission, language string) ([]domain.RemoteEvaluation, error) {
-	//先查缓存 如果有则直接返回
-	hash := sha256.Sum256([]byte(submission.Code))
-	hashString := hex.EncodeToString(hash[:])
+	return svc.judge(ctx, submission, language, "run")
+}
 
-	evals, err := svc.repo.AcquireEvaluation(ctx, submission.UserId, hashString)
-	if err == nil {
-		return evals, err
+// SubmitCode 正式提交会和本地评测一样留下提交与评测记录，之后可以通过提交 id 查询
+func (svc *SubmissionSvc) SubmitCode(ctx context.Context, submission domain.Submission, language string) (domain.SubmitResult, error) {
+	lang, err := svc.langs.Get(language)
+	if err != nil {
+		return domain.SubmitResult{}, err
 	}
+	submission.Language = lang.Id
+	submission.CodeHash = domain.NormalizedHash(submission.Code)
 
-	//判断语言类型
-	id, ok := svc.language[language]
-	if !ok {
-		return evals, errors.New("no language to fit")
+	sid, err := svc.repo.CreateSubmit(ctx, submission)
+	if err != nil {
+		return domain.SubmitResult{}, err
+	}
+	err = svc.repo.CreateEvaluate(ctx, domain.Evaluation{
+		SubmissionId: sid,
+		ProblemId:    submission.ProblemID,
+		Lang:         lang.Id,
+		State:        dao.PENGIND,
+	})
+	if err != nil {
+		return domain.SubmitResult{}, err
 	}
 
-	// 代码格式检查
-	err, done := svc.CodeFormat(language, "run", submission)
-
-	if !done {
-		return evals, err
+	var state dao.State
+	evals, err := svc.judge(ctx, submission, lang.Id, "submit")
+	if err != nil {
+		// 评测失败也要把状态落库，否则这条提交会一直处于 PENDING
+		failed := domain.Evaluation{StatusMsg: err.Error(), State: dao.FAILED}
+		_ = svc.repo.UpdateResult(ctx, submission.ProblemID, sid, map[string]any{
+			"status_msg": failed.StatusMsg,
+			"state":      state.ToUint8(failed.State),
+		}, event.NewResultOutbox(submission, sid, failed))
+		return domain.SubmitResult{SubmissionId: sid}, err
+	}
+
+	eva := summarize(evals)
+	eva.SubmissionId, eva.ProblemId, eva.Lang, eva.State = sid, submission.ProblemID, lang.Id, dao.SUCCESS
+	err = svc.repo.UpdateResult(ctx, submission.ProblemID, sid, map[string]any{
+		"cpu_time_used":  eva.CpuTimeUsed,
+		"real_time_used": eva.RealTimeUsed,
+		"memory_used":    eva.MemoryUsed,
+		"status_msg":     eva.StatusMsg,
+		"state":          state.ToUint8(dao.SUCCESS),
+	}, event.NewResultOutbox(submission, sid, eva))
+	if err != nil {
+		return domain.SubmitResult{}, err
 	}
 
-	// base64 编码
-	encodedCode := base64.StdEncoding.EncodeToString([]byte(submission.Code))
+	return domain.SubmitResult{SubmissionId: sid, Evaluation: &eva, Cases: evals}, nil
+}
 
-	// 获取测试用例
-	testCases, err := svc.pmRepo.FindTestById(ctx, submission.ProblemID)
-	if err != nil {
-		return evals, fmt.Errorf("failed to get test cases: %w", err)
-	}
+func (svc *SubmissionSvc) CheckResult(ctx context.Context, submitId uint64) (domain.Evaluation, error) {
+	return svc.repo.FindEvaluate(ctx, submitId)
+}
 
-	// 获取返回结果
-	evals, err = svc.GetResult(ctx, testCases, id, encodedCode, evals)
-
-	// 更新缓存
-	var updating int32
-
-	if atomic.LoadInt32(&updating) == 0 {
-		if atomic.CompareAndSwapInt32(&updating, 0, 1) {
-			go func() {
-				// 更新逻辑
-				svc.repo.StoreEvaluation(ctx, submission.UserId, hashString, evals)
-				atomic.StoreInt32(&updating, 0) // 更新完成，重置状态
-			}()
+// summarize 把逐个用例的结果汇总成一条评测记录
+// 取第一个没通过的用例作为结论，时间与内存取所有用例中的最大值，Judge0 的时间以 s 计，这里换算成 ms
+func summarize(evals []domain.RemoteEvaluation) domain.Evaluation {
+	eva := domain.Evaluation{StatusMsg: "Accepted"}
+	for _, e := range evals {
+		if eva.StatusMsg == "Accepted" && e.Msg != "Accepted" {
+			eva.StatusMsg = e.Msg
+		}
+		if sec, err := strconv.ParseFloat(e.RunTime, 64); err == nil {
+			eva.CpuTimeUsed = max(eva.CpuTimeUsed, int64(sec*1000))
 		}
+		eva.MemoryUsed = max(eva.MemoryUsed, e.RunMem)
 	}
+	eva.RealTimeUsed = eva.CpuTimeUsed
 
-	return evals, err
+	return eva
 }
 
-func (svc *SubmissionSvc) SubmitCode(ctx context.Context, submission domain.Submission, language string) ([]domain.RemoteEvaluation, error) {
+func (svc *SubmissionSvc) judge(ctx context.Context, submission domain.Submission, language, way string) ([]domain.RemoteEvaluation, error) {
 	var evals []domain.RemoteEvaluation
 
 	//判断语言类型
-	id, ok := svc.language[language]
-	if !ok {
-		return evals, errors.New("no language to fit")
+	lang, err := svc.langs.Get(language)
+	if err != nil {
+		return evals, err
+	}
+
+	pm, err := svc.pmRepo.FindProblemByID(ctx, submission.ProblemID)
+	if err != nil {
+		return evals, fmt.Errorf("failed to get problem: %w", err)
 	}
 
+	//先查缓存 如果有则直接返回，结果与提交者无关，按题目版本、语言与代码共享
+	key := domain.ResultKey{
+		Engine:    domain.EngineRemote,
+		ProblemId: pm.Id,
+		Revision:  pm.Revision,
+		Language:  lang.Id,
+		TimeLimit: lang.TimeLimit(pm.MaxRuntime),
+		MemLimit:  lang.MemLimit(pm.MaxMem),
+		CodeHash:  domain.NormalizedHash(submission.Code),
+	}
+	evals, err = svc.repo.AcquireEvaluation(ctx, key)
+	if err == nil {
+		return evals, nil
+	}
+	evals = nil
+
 	// 代码格式检查
-	err, done := svc.CodeFormat(language, "submit", submission)
+	err, done := svc.CodeFormat(lang.Id, way, submission)
 	if !done {
 		return evals, err
 	}
@@ -151,16 +196,28 @@ func (svc *SubmissionSvc) SubmitCode(ctx context.Context, submission domain.Subm
 	}
 
 	// 获取返回结果
-	evals, err = svc.GetResult(ctx, testCases, id, encodedCode, evals)
+	evals, err = svc.GetResult(ctx, testCases, lang, pm, encodedCode, evals)
+	if err != nil {
+		return evals, err
+	}
+
+	// 更新缓存，所有用例都是确定性的结论才缓存
+	cacheable := len(evals) > 0
+	for _, e := range evals {
+		cacheable = cacheable && domain.Cacheable(e.Msg)
+	}
+	if cacheable {
+		_ = svc.repo.StoreEvaluation(ctx, key, evals)
+	}
 
-	return evals, err
+	return evals, nil
 }
 
-func (svc *SubmissionSvc) GetResult(ctx context.Context, testCases []domain2.TestCase, langId int8, encodedCode string, result []domain.RemoteEvaluation) ([]domain.RemoteEvaluation, error) {
+func (svc *SubmissionSvc) GetResult(ctx context.Context, testCases []domain2.TestCase, lang domain.Language, pm domain2.Problem, encodedCode string, result []domain.RemoteEvaluation) ([]domain.RemoteEvaluation, error) {
 	// 逐个提交测试用例
 	for i := 0; i < len(testCases); i++ {
 		// 设置提交代码以及单个测试用例
-		submit := svc.SetSubmission(langId, encodedCode, base64.StdEncoding.EncodeToString([]byte(testCases[i].Input)), base64.StdEncoding.EncodeToString([]byte(testCases[i].Output)))
+		submit := svc.SetSubmission(lang, pm, encodedCode, base64.StdEncoding.EncodeToString([]byte(testCases[i].Input)), base64.StdEncoding.EncodeToString([]byte(testCases[i].Output)))
 
 		jsonData, err := sonic.Marshal(submit)
 		if err != nil {
@@ -201,12 +258,15 @@ func (svc *SubmissionSvc) GetResult(ctx context.Context, testCases []domain2.Tes
 	return result, nil
 }
 
-func (svc *SubmissionSvc) SetSubmission(id int8, code, stdin, stdout string) Submission {
+// SetSubmission 题目的 MaxRuntime 以 ms、MaxMem 以 MB 计，按语言倍率放宽后换算成 Judge0 的单位
+func (svc *SubmissionSvc) SetSubmission(lang domain.Language, pm domain2.Problem, code, stdin, stdout string) Submission {
 	submit := Submission{
-		LanguageId: id,
-		Code:       code,
-		Stdin:      stdin,  // 标准输入
-		StdOut:     stdout, // 期望输出
+		LanguageId:   lang.Judge0Id,
+		Code:         code,
+		Stdin:        stdin,  // 标准输入
+		StdOut:       stdout, // 期望输出
+		CpuTimeLimit: float64(lang.TimeLimit(pm.MaxRuntime)) / 1000,
+		MemoryLimit:  lang.MemLimit(pm.MaxMem) * 1024,
 	}
 	return submit
 }
@@ -215,13 +275,13 @@ func (svc *SubmissionSvc) CodeFormat(language, way string, submission domain.Sub
 	// 代码格式检查
 	var formatOk bool
 	switch language {
-	case "Go":
+	case "go":
 		formatOk = svc.GoFormat(submission.Code, way, submission.ProblemID, submission.UserId)
-	case "Java":
+	case "java":
 		formatOk = svc.JavaFormat(submission.Code, way, submission.ProblemID, submission.UserId)
-	case "Python":
+	case "python":
 		formatOk = svc.PythonFormat(submission.Code, way, submission.ProblemID, submission.UserId)
-	case "C++":
+	case "cpp":
 		formatOk = svc.CppFormat(submission.Code, way, submission.ProblemID, submission.UserId)
 	}
 	if !formatOk {
@@ -0,0 +1,49 @@
+package sched
+
+import "context"
+
+// Class 评测任务的优先级，数值越小优先级越高
+// 只调度发往自己评测机的任务，remote 走第三方评测服务，由对方限流
+type Class int
+
+const (
+	// ClassContest 比赛中的提交
+	ClassContest Class = iota
+	// ClassSubmit 普通提交
+	ClassSubmit
+	// ClassRun 自测运行，目前还没有自测接口，先占好位置和监控标签
+	ClassRun
+	// ClassRejudge 重测、出题校验这类系统发起的评测
+	ClassRejudge
+
+	numClass
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassContest:
+		return "contest"
+	case ClassSubmit:
+		return "submit"
+	case ClassRun:
+		return "run"
+	case ClassRejudge:
+		return "rejudge"
+	}
+	return "unknown"
+}
+
+type classKey struct{}
+
+// WithClass 在 ctx 上标记评测任务的优先级
+func WithClass(ctx context.Context, c Class) context.Context {
+	return context.WithValue(ctx, classKey{}, c)
+}
+
+// ClassFrom 没有标记时按普通提交处理
+func ClassFrom(ctx context.Context) Class {
+	if c, ok := ctx.Value(classKey{}).(Class); ok && c >= 0 && c < numClass {
+		return c
+	}
+	return ClassSubmit
+}
@@ -0,0 +1,31 @@
+package sched
+
+import (
+	"context"
+
+	"github.com/crazyfrankie/go-judge/pkg/rpc"
+	"google.golang.org/grpc"
+)
+
+// Client 所有评测请求先经过调度器排队，优先级由 WithClass 标记在 ctx 上
+type Client struct {
+	next  rpc.JudgeServiceClient
+	sched *Scheduler
+}
+
+func NewClient(next rpc.JudgeServiceClient, sched *Scheduler) rpc.JudgeServiceClient {
+	return &Client{
+		next:  next,
+		sched: sched,
+	}
+}
+
+func (c *Client) Judge(ctx context.Context, in *rpc.JudgeRequest, opts ...grpc.CallOption) (*rpc.JudgeResponse, error) {
+	release, err := c.sched.Acquire(ctx, ClassFrom(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return c.next.Judge(ctx, in, opts...)
+}
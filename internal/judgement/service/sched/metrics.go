@@ -0,0 +1,42 @@
+package sched
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	depth *prometheus.GaugeVec
+	wait  *prometheus.HistogramVec
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		depth: register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "judge_queue_depth",
+			Help:      "各优先级排队中的评测任务数",
+		}, []string{"class"})),
+		wait: register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "cfc_studio_frank",
+			Subsystem: "onlinejudge",
+			Name:      "judge_queue_wait_time",
+			Help:      "评测任务排队等待的时间，单位 ms",
+			Buckets:   []float64{0, 10, 50, 100, 500, 1000, 5000, 10000, 30000},
+		}, []string{"class"})),
+	}
+}
+
+// register 多个调度器共用同一组指标
+func register[T prometheus.Collector](c T) T {
+	if err := prometheus.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return are.ExistingCollector.(T)
+		}
+		panic(err)
+	}
+	return c
+}
@@ -0,0 +1,140 @@
+/*
+评测任务调度
+评测机能同时处理的任务有限，任务超出并发数时按优先级排队
+高优先级先被调度，但每一轮每个等级都至少能分到自己权重那么多的名额，低优先级不会被饿死
+*/
+
+package sched
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultWeights 每一轮各等级最多调度的任务数，依次为 contest、submit、run、rejudge
+var DefaultWeights = [numClass]int{8, 4, 2, 1}
+
+type waiter struct {
+	class Class
+	enq   time.Time
+	ready chan struct{}
+}
+
+type Scheduler struct {
+	mu sync.Mutex
+	// free 空闲的并发名额
+	free    int
+	queues  [numClass]*list.List
+	weights [numClass]int
+	// credits 本轮各等级剩余可调度的次数
+	credits [numClass]int
+
+	metrics *metrics
+}
+
+func NewScheduler(concurrency int, weights [numClass]int) *Scheduler {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	for i := range weights {
+		if weights[i] <= 0 {
+			weights[i] = 1
+		}
+	}
+
+	s := &Scheduler{
+		free:    concurrency,
+		weights: weights,
+		credits: weights,
+		metrics: newMetrics(),
+	}
+	for i := range s.queues {
+		s.queues[i] = list.New()
+	}
+
+	return s
+}
+
+// Acquire 拿到一个并发名额，用完之后必须调用返回的 release
+func (s *Scheduler) Acquire(ctx context.Context, c Class) (func(), error) {
+	s.mu.Lock()
+	if s.free > 0 && s.waiting() == 0 {
+		s.free--
+		s.mu.Unlock()
+		s.metrics.wait.WithLabelValues(c.String()).Observe(0)
+		return s.releaser(), nil
+	}
+
+	w := &waiter{class: c, enq: time.Now(), ready: make(chan struct{})}
+	elem := s.queues[c].PushBack(w)
+	s.metrics.depth.WithLabelValues(c.String()).Set(float64(s.queues[c].Len()))
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		s.metrics.wait.WithLabelValues(c.String()).Observe(float64(time.Since(w.enq).Milliseconds()))
+		return s.releaser(), nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// 取消的同时恰好被调度到了，把名额交给下一个任务
+			s.mu.Unlock()
+			s.release()
+		default:
+			s.queues[c].Remove(elem)
+			s.metrics.depth.WithLabelValues(c.String()).Set(float64(s.queues[c].Len()))
+			s.mu.Unlock()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Scheduler) releaser() func() {
+	var once sync.Once
+	return func() {
+		once.Do(s.release)
+	}
+}
+
+// release 名额直接交给下一个排队的任务，没有任务排队时才归还
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.next()
+	if w == nil {
+		s.free++
+		return
+	}
+	close(w.ready)
+}
+
+// next 按优先级挑选下一个任务，某个等级本轮的名额用完后让给低优先级
+// 所有排队的等级都用完名额之后开始新的一轮
+func (s *Scheduler) next() *waiter {
+	for round := 0; round < 2; round++ {
+		for c, q := range s.queues {
+			if q.Len() == 0 || s.credits[c] == 0 {
+				continue
+			}
+			s.credits[c]--
+			w := q.Remove(q.Front()).(*waiter)
+			s.metrics.depth.WithLabelValues(w.class.String()).Set(float64(q.Len()))
+			return w
+		}
+		s.credits = s.weights
+	}
+
+	return nil
+}
+
+func (s *Scheduler) waiting() int {
+	n := 0
+	for _, q := range s.queues {
+		n += q.Len()
+	}
+	return n
+}
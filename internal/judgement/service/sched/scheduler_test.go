@@ -0,0 +1,91 @@
+package sched
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enqueue 依次让任务进入队列，保证入队顺序是确定的
+func enqueue(t *testing.T, s *Scheduler, classes []Class, order *[]Class, mu *sync.Mutex, wg *sync.WaitGroup) {
+	for i, c := range classes {
+		wg.Add(1)
+		go func(c Class) {
+			defer wg.Done()
+			release, err := s.Acquire(context.Background(), c)
+			require.NoError(t, err)
+			mu.Lock()
+			*order = append(*order, c)
+			mu.Unlock()
+			release()
+		}(c)
+
+		require.Eventually(t, func() bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.waiting() == i+1
+		}, time.Second, time.Millisecond)
+	}
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	testCases := []struct {
+		name    string
+		weights [numClass]int
+		classes []Class
+		want    []Class
+	}{
+		{
+			name:    "higher class first",
+			weights: [numClass]int{8, 4, 2, 1},
+			classes: []Class{ClassRejudge, ClassRun, ClassSubmit, ClassContest},
+			want:    []Class{ClassContest, ClassSubmit, ClassRun, ClassRejudge},
+		},
+		{
+			name:    "lower class not starved",
+			weights: [numClass]int{2, 1, 1, 1},
+			classes: []Class{ClassRejudge, ClassRejudge, ClassContest, ClassContest, ClassContest, ClassContest},
+			want:    []Class{ClassContest, ClassContest, ClassRejudge, ClassContest, ClassContest, ClassRejudge},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewScheduler(1, tc.weights)
+			hold, err := s.Acquire(context.Background(), ClassSubmit)
+			require.NoError(t, err)
+
+			var (
+				mu    sync.Mutex
+				wg    sync.WaitGroup
+				order []Class
+			)
+			enqueue(t, s, tc.classes, &order, &mu, &wg)
+			hold()
+			wg.Wait()
+
+			assert.Equal(t, tc.want, order)
+			assert.Equal(t, 1, s.free)
+		})
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := NewScheduler(1, DefaultWeights)
+	hold, err := s.Acquire(context.Background(), ClassSubmit)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = s.Acquire(ctx, ClassContest)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, s.waiting())
+
+	hold()
+	hold()
+	assert.Equal(t, 1, s.free)
+}
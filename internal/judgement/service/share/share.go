@@ -0,0 +1,117 @@
+package share
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/contest"
+)
+
+type Service interface {
+	// Create ttl 为 0 表示永不过期
+	Create(ctx context.Context, uid, sid uint64, ttl time.Duration) (domain.Share, error)
+	Revoke(ctx context.Context, uid uint64, token string) error
+	View(ctx context.Context, token string) (domain.SharedSubmission, error)
+}
+
+type ShareSvc struct {
+	repo    repository.ShareRepository
+	subRepo repository.LocalSubmitRepo
+	contest contest.Checker
+}
+
+func NewShareService(repo repository.ShareRepository, subRepo repository.LocalSubmitRepo, contest contest.Checker) Service {
+	return &ShareSvc{
+		repo:    repo,
+		subRepo: subRepo,
+		contest: contest,
+	}
+}
+
+func (s *ShareSvc) Create(ctx context.Context, uid, sid uint64, ttl time.Duration) (domain.Share, error) {
+	sub, err := s.subRepo.FindSubmission(ctx, sid)
+	if err != nil {
+		return domain.Share{}, err
+	}
+	if sub.UserId != uid {
+		return domain.Share{}, er.NewBizError(constant.ErrUserForbidden)
+	}
+	if s.contest.InActiveContest(ctx, sub.ProblemID) {
+		return domain.Share{}, er.NewBizError(constant.ErrShareInContest)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return domain.Share{}, err
+	}
+	share := domain.Share{
+		Token:        token,
+		SubmissionId: sid,
+		UserId:       uid,
+		Ctime:        time.Now().Unix(),
+	}
+	if ttl > 0 {
+		share.ExpireAt = time.Now().Add(ttl).Unix()
+	}
+
+	return share, s.repo.Create(ctx, share)
+}
+
+func (s *ShareSvc) Revoke(ctx context.Context, uid uint64, token string) error {
+	err := s.repo.Revoke(ctx, uid, token)
+	if errors.Is(err, repository.ErrShareNotFound) {
+		return er.NewBizError(constant.ErrShareNotFound)
+	}
+	return err
+}
+
+// View 比赛可能在链接创建之后才开始，所以查看时还要再检查一次
+func (s *ShareSvc) View(ctx context.Context, token string) (domain.SharedSubmission, error) {
+	share, err := s.repo.FindByToken(ctx, token)
+	if errors.Is(err, repository.ErrShareNotFound) {
+		return domain.SharedSubmission{}, er.NewBizError(constant.ErrShareNotFound)
+	}
+	if err != nil {
+		return domain.SharedSubmission{}, err
+	}
+	if share.Revoked || (share.ExpireAt > 0 && share.ExpireAt <= time.Now().Unix()) {
+		return domain.SharedSubmission{}, er.NewBizError(constant.ErrShareNotFound)
+	}
+
+	sub, err := s.subRepo.FindSubmission(ctx, share.SubmissionId)
+	if err != nil {
+		return domain.SharedSubmission{}, err
+	}
+	if s.contest.InActiveContest(ctx, sub.ProblemID) {
+		return domain.SharedSubmission{}, er.NewBizError(constant.ErrShareInContest)
+	}
+	eva, err := s.subRepo.FindEvaluate(ctx, share.SubmissionId)
+	if err != nil {
+		return domain.SharedSubmission{}, err
+	}
+
+	return domain.SharedSubmission{
+		ProblemId:  sub.ProblemID,
+		Language:   sub.Language,
+		Code:       sub.Code,
+		SubmitTime: sub.SubmitTime,
+		Evaluation: eva,
+		ExpireAt:   share.ExpireAt,
+	}, nil
+}
+
+// newToken 192 位随机数，无法被猜出或遍历
+func newToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
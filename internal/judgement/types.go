@@ -1,11 +1,26 @@
 package judgement
 
-import "github.com/crazyfrankie/onlinejudge/internal/judgement/web"
+import (
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/archive"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/web"
+)
 
 type LocHandler = web.LocalSubmitHandler
 type RemHandler = web.SubmissionHandler
+type LangHandler = web.LanguageHandler
+type NodeHandler = web.NodeHandler
+type ShareHandler = web.ShareHandler
+type SubmitRepo = repository.LocalSubmitRepo
 
 type Module struct {
-	LocHdl *LocHandler
-	RemHdl *RemHandler
+	LocHdl   *LocHandler
+	RemHdl   *RemHandler
+	LangHdl  *LangHandler
+	NodeHdl  *NodeHandler
+	ShareHdl *ShareHandler
+	// Archiver 提交代码归档任务，由 main 启动
+	Archiver *archive.Archiver
+	// SubmitRepo 供题解等模块查询用户的评测记录
+	SubmitRepo SubmitRepo
 }
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
+)
+
+type LanguageHandler struct {
+	langs language.Registry
+}
+
+func NewLanguageHandler(langs language.Registry) *LanguageHandler {
+	return &LanguageHandler{
+		langs: langs,
+	}
+}
+
+func (ctl *LanguageHandler) RegisterRoute(r *gin.Engine) {
+	r.GET("api/languages", ctl.List())
+}
+
+func (ctl *LanguageHandler) List() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.Success(c, ctl.langs.List())
+	}
+}
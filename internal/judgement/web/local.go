@@ -6,10 +6,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/crazyfrankie/onlinejudge/common/constant"
+	er "github.com/crazyfrankie/onlinejudge/common/errors"
 	"github.com/crazyfrankie/onlinejudge/common/response"
 	"github.com/crazyfrankie/onlinejudge/infra/contract/token"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/local"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/quota"
 )
 
 const (
@@ -19,15 +22,22 @@ const (
 
 type SubmitResp struct {
 	SubmissionId uint64 `json:"submission_id"`
+	// Duplicate 为 true 表示命中了去重窗口或幂等键，Evaluation 是已有的评测结果
+	Duplicate  bool               `json:"duplicate,omitempty"`
+	Evaluation *domain.Evaluation `json:"evaluation,omitempty"`
+	// Cases 远程评测逐个用例的结果
+	Cases []domain.RemoteEvaluation `json:"cases,omitempty"`
 }
 
 type LocalSubmitHandler struct {
-	svc local.LocSubmitService
+	svc   local.LocSubmitService
+	quota quota.Quota
 }
 
-func NewLocalSubmitHandler(svc local.LocSubmitService) *LocalSubmitHandler {
+func NewLocalSubmitHandler(svc local.LocSubmitService, quota quota.Quota) *LocalSubmitHandler {
 	return &LocalSubmitHandler{
-		svc: svc,
+		svc:   svc,
+		quota: quota,
 	}
 }
 
@@ -36,6 +46,7 @@ func (ctl *LocalSubmitHandler) RegisterRoute(r *gin.Engine) {
 	{
 		submitGroup.POST("submit", ctl.RunCode())
 		submitGroup.GET("check/:submissionId", ctl.Check())
+		submitGroup.GET("submission/:submissionId", ctl.GetSubmission())
 	}
 }
 
@@ -46,6 +57,8 @@ func (ctl *LocalSubmitHandler) RunCode() gin.HandlerFunc {
 			ProblemId uint64 `json:"problem_id"`
 			TypedCode string `json:"typed_code"`
 			Language  string `json:"language"`
+			// ContestId 比赛中的提交使用比赛的配额
+			ContestId uint64 `json:"contest_id"`
 		}
 		var req Req
 		if err := c.Bind(&req); err != nil {
@@ -56,12 +69,22 @@ func (ctl *LocalSubmitHandler) RunCode() gin.HandlerFunc {
 		claims := c.MustGet("claims")
 		claim, _ := claims.(*token.Claims)
 
-		submitId, err := ctl.svc.RunCode(c.Request.Context(), domain.Submission{
-			ProblemID:  req.ProblemId,
-			UserId:     claim.Id,
-			Code:       req.TypedCode,
-			Language:   req.Language,
-			SubmitTime: time.Now().Unix(),
+		if !checkQuota(c, ctl.quota, name, quota.Request{
+			Uid:       claim.Id,
+			ProblemId: req.ProblemId,
+			ContestId: req.ContestId,
+		}) {
+			return
+		}
+
+		res, err := ctl.svc.RunCode(c.Request.Context(), domain.Submission{
+			ProblemID:      req.ProblemId,
+			UserId:         claim.Id,
+			Code:           req.TypedCode,
+			Language:       req.Language,
+			SubmitTime:     time.Now().Unix(),
+			ContestId:      req.ContestId,
+			IdempotencyKey: c.GetHeader("Idempotency-Key"),
 		})
 		if err != nil {
 			response.ErrorWithLog(c, name, bizError, err)
@@ -69,7 +92,9 @@ func (ctl *LocalSubmitHandler) RunCode() gin.HandlerFunc {
 		}
 
 		response.SuccessWithLog(c, SubmitResp{
-			SubmissionId: submitId,
+			SubmissionId: res.SubmissionId,
+			Duplicate:    res.Duplicate,
+			Evaluation:   res.Evaluation,
 		}, name, success)
 	}
 }
@@ -90,3 +115,27 @@ func (ctl *LocalSubmitHandler) Check() gin.HandlerFunc {
 		response.SuccessWithLog(c, res, name, success)
 	}
 }
+
+// GetSubmission 提交详情，只有提交者本人可以查看代码
+func (ctl *LocalSubmitHandler) GetSubmission() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Judge/Local/GetSubmission"
+
+		sid := c.Param("submissionId")
+		id, _ := strconv.ParseUint(sid, 10, 64)
+
+		res, err := ctl.svc.GetSubmission(c.Request.Context(), id)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		claim := c.MustGet("claims").(*token.Claims)
+		if res.UserId != claim.Id {
+			response.ErrorWithLog(c, name, bizError, er.NewBizError(constant.ErrUserForbidden))
+			return
+		}
+
+		response.SuccessWithLog(c, res, name, success)
+	}
+}
@@ -0,0 +1,29 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/pool"
+)
+
+type NodeHandler struct {
+	pool *pool.Pool
+}
+
+func NewNodeHandler(pool *pool.Pool) *NodeHandler {
+	return &NodeHandler{
+		pool: pool,
+	}
+}
+
+func (ctl *NodeHandler) RegisterRoute(r *gin.Engine) {
+	r.GET("api/admin/judge/nodes", ctl.Status())
+}
+
+// Status 列出所有评测节点的健康状态、断路器状态与当前负载
+func (ctl *NodeHandler) Status() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.Success(c, ctl.pool.Status())
+	}
+}
@@ -0,0 +1,30 @@
+package web
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/quota"
+)
+
+// checkQuota 超出配额时写好响应并返回 false，同时通过 Retry-After 告诉客户端多久之后再试
+func checkQuota(c *gin.Context, q quota.Quota, name string, req quota.Request) bool {
+	err := q.Check(c.Request.Context(), req)
+	if err == nil {
+		return true
+	}
+
+	var qe *quota.QuotaError
+	if errors.As(err, &qe) {
+		detail := qe.Detail()
+		c.Header("Retry-After", strconv.Itoa(detail["retryAfter"].(int)))
+		response.ErrorWithData(c, name, bizError, qe, detail)
+		return false
+	}
+
+	response.ErrorWithLog(c, name, bizError, err)
+	return false
+}
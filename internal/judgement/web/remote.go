@@ -1,20 +1,27 @@
 package web
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/crazyfrankie/onlinejudge/common/response"
 	"github.com/gin-gonic/gin"
 
+	"github.com/crazyfrankie/onlinejudge/infra/contract/token"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/quota"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/remote"
 )
 
 type SubmissionHandler struct {
-	svc remote.SubmitService
+	svc   remote.SubmitService
+	quota quota.Quota
 }
 
-func NewSubmissionHandler(svc remote.SubmitService) *SubmissionHandler {
+func NewSubmissionHandler(svc remote.SubmitService, quota quota.Quota) *SubmissionHandler {
 	return &SubmissionHandler{
-		svc: svc,
+		svc:   svc,
+		quota: quota,
 	}
 }
 
@@ -23,6 +30,7 @@ func (ctl *SubmissionHandler) RegisterRoute(r *gin.Engine) {
 	{
 		submitGroup.POST("run", ctl.RunCode())
 		submitGroup.POST("submit", ctl.SubmitCode())
+		submitGroup.GET("check/:submissionId", ctl.Check())
 	}
 }
 
@@ -34,6 +42,7 @@ func (ctl *SubmissionHandler) RunCode() gin.HandlerFunc {
 			ProblemId uint64 `json:"problemId"`
 			Code      string `json:"code"`
 			Language  string `json:"language"`
+			ContestId uint64 `json:"contestId"`
 		}
 
 		var req Req
@@ -42,8 +51,18 @@ func (ctl *SubmissionHandler) RunCode() gin.HandlerFunc {
 			return
 		}
 
+		// 配额按登录用户计算，不信任请求体里的 userId
+		claim := c.MustGet("claims").(*token.Claims)
+		if !checkQuota(c, ctl.quota, name, quota.Request{
+			Uid:       claim.Id,
+			ProblemId: req.ProblemId,
+			ContestId: req.ContestId,
+		}) {
+			return
+		}
+
 		result, err := ctl.svc.RunCode(c.Request.Context(), domain.Submission{
-			UserId:    req.UserId,
+			UserId:    claim.Id,
 			ProblemID: req.ProblemId,
 			Code:      req.Code,
 		}, req.Language)
@@ -65,6 +84,7 @@ func (ctl *SubmissionHandler) SubmitCode() gin.HandlerFunc {
 			ProblemId uint64 `json:"problemId"`
 			Code      string `json:"code"`
 			Language  string `json:"language"`
+			ContestId uint64 `json:"contestId"`
 		}
 
 		var req Req
@@ -73,16 +93,49 @@ func (ctl *SubmissionHandler) SubmitCode() gin.HandlerFunc {
 			return
 		}
 
-		result, err := ctl.svc.RunCode(c.Request.Context(), domain.Submission{
-			UserId:    req.UserId,
-			ProblemID: req.ProblemId,
-			Code:      req.Code,
+		// 配额按登录用户计算，不信任请求体里的 userId
+		claim := c.MustGet("claims").(*token.Claims)
+		if !checkQuota(c, ctl.quota, name, quota.Request{
+			Uid:       claim.Id,
+			ProblemId: req.ProblemId,
+			ContestId: req.ContestId,
+		}) {
+			return
+		}
+
+		res, err := ctl.svc.SubmitCode(c.Request.Context(), domain.Submission{
+			UserId:     claim.Id,
+			ProblemID:  req.ProblemId,
+			Code:       req.Code,
+			SubmitTime: time.Now().Unix(),
+			ContestId:  req.ContestId,
 		}, req.Language)
 		if err != nil {
 			response.ErrorWithLog(c, name, bizError, err)
 			return
 		}
 
-		response.SuccessWithLog(c, result, name, success)
+		response.SuccessWithLog(c, SubmitResp{
+			SubmissionId: res.SubmissionId,
+			Evaluation:   res.Evaluation,
+			Cases:        res.Cases,
+		}, name, success)
+	}
+}
+
+func (ctl *SubmissionHandler) Check() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Judge/Remote/Check"
+
+		sid := c.Param("submissionId")
+		id, _ := strconv.ParseUint(sid, 10, 64)
+
+		res, err := ctl.svc.CheckResult(c.Request.Context(), id)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, res, name, success)
 	}
 }
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/crazyfrankie/onlinejudge/common/response"
+	"github.com/crazyfrankie/onlinejudge/infra/contract/token"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/share"
+)
+
+type ShareHandler struct {
+	svc share.Service
+}
+
+func NewShareHandler(svc share.Service) *ShareHandler {
+	return &ShareHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *ShareHandler) RegisterRoute(r *gin.Engine) {
+	r.POST("api/local/submission/:submissionId/share", ctl.Create())
+	r.DELETE("api/local/share/:token", ctl.Revoke())
+	// 不需要登录，见 ioc.GinMiddlewares
+	r.GET("api/share/:token", ctl.View())
+}
+
+func (ctl *ShareHandler) Create() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Judge/Share/Create"
+		type Req struct {
+			// ExpireIn 有效期，单位 s，为 0 表示永不过期
+			ExpireIn int64 `json:"expire_in"`
+		}
+		var req Req
+		if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+			response.ErrorWithLog(c, name, "bind req error", err)
+			return
+		}
+
+		sid, _ := strconv.ParseUint(c.Param("submissionId"), 10, 64)
+		claim := c.MustGet("claims").(*token.Claims)
+
+		res, err := ctl.svc.Create(c.Request.Context(), claim.Id, sid, time.Duration(req.ExpireIn)*time.Second)
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, res, name, success)
+	}
+}
+
+func (ctl *ShareHandler) Revoke() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Judge/Share/Revoke"
+
+		claim := c.MustGet("claims").(*token.Claims)
+		err := ctl.svc.Revoke(c.Request.Context(), claim.Id, c.Param("token"))
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, nil, name, success)
+	}
+}
+
+func (ctl *ShareHandler) View() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := "onlinejudge/Judge/Share/View"
+
+		res, err := ctl.svc.View(c.Request.Context(), c.Param("token"))
+		if err != nil {
+			response.ErrorWithLog(c, name, bizError, err)
+			return
+		}
+
+		response.SuccessWithLog(c, res, name, success)
+	}
+}
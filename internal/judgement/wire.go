@@ -3,23 +3,35 @@
 package judgement
 
 import (
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/crazyfrankie/go-judge/pkg/rpc"
+	"github.com/crazyfrankie/onlinejudge/config"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/cache"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/dao"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/archive"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/contest"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/local"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/pool"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/quota"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/remote"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/share"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/web"
 	"github.com/crazyfrankie/onlinejudge/internal/problem"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"os"
+	"time"
 )
 
 var LocalSet = wire.NewSet(
 	dao.NewSubmitDao,
 	cache.NewLocalSubmitCache,
+	cache.NewSubmitDedupCache,
+	cache.NewJudgeResultCache,
+	InitCodeArchive,
 	repository.NewLocalSubmitRepo,
 
 	local.NewLocSubmitService,
@@ -28,8 +40,6 @@ var LocalSet = wire.NewSet(
 )
 
 var RemoteSet = wire.NewSet(
-	cache.NewSubmitCache,
-
 	repository.NewSubmitRepository,
 
 	remote.NewSubmitService,
@@ -37,6 +47,15 @@ var RemoteSet = wire.NewSet(
 	web.NewSubmissionHandler,
 )
 
+var ShareSet = wire.NewSet(
+	dao.NewShareDao,
+	repository.NewShareRepository,
+
+	share.NewShareService,
+
+	web.NewShareHandler,
+)
+
 func InitJudgeKey() string {
 	key, ok := os.LookupEnv("RAPIDAPI_KEY")
 	if !ok {
@@ -46,11 +65,34 @@ func InitJudgeKey() string {
 	return key
 }
 
-func InitModule(cmd redis.Cmdable, db *gorm.DB, module *problem.Module, judge rpc.JudgeServiceClient) *Module {
+func InitCodeArchive(oss *s3.S3) dao.CodeArchive {
+	return dao.NewS3CodeArchive(oss, config.GetConf().Archive.Bucket)
+}
+
+func InitContestChecker() contest.Checker {
+	return contest.NewConfigChecker(config.GetConf().Contests)
+}
+
+func InitArchiver(repo repository.CodeArchiveRepo) *archive.Archiver {
+	cfg := config.GetConf().Archive
+	return archive.NewArchiver(repo, archive.Config{
+		Retention: time.Duration(cfg.RetentionDays) * time.Hour * 24,
+		Interval:  time.Duration(cfg.Interval) * time.Second,
+		BatchSize: cfg.BatchSize,
+	})
+}
+
+func InitModule(cmd redis.Cmdable, db *gorm.DB, module *problem.Module, judge rpc.JudgeServiceClient, nodes *pool.Pool, langs language.Registry, quota quota.Quota, oss *s3.S3) *Module {
 	wire.Build(
 		LocalSet,
 		RemoteSet,
+		ShareSet,
 		InitJudgeKey,
+		InitContestChecker,
+		repository.NewCodeArchiveRepo,
+		InitArchiver,
+		web.NewLanguageHandler,
+		web.NewNodeHandler,
 
 		wire.FieldsOf(new(*problem.Module), "Repo"),
 		wire.Struct(new(Module), "*"),
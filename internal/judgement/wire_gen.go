@@ -7,46 +7,73 @@
 package judgement
 
 import (
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/crazyfrankie/go-judge/pkg/rpc"
+	"github.com/crazyfrankie/onlinejudge/config"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/cache"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/repository/dao"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/archive"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/contest"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/local"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/pool"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/quota"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/remote"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/share"
 	"github.com/crazyfrankie/onlinejudge/internal/judgement/web"
 	"github.com/crazyfrankie/onlinejudge/internal/problem"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"os"
+	"time"
 )
 
 // Injectors from wire.go:
 
-func InitModule(cmd redis.Cmdable, db *gorm.DB, module *problem.Module, judge rpc.JudgeServiceClient) *Module {
+func InitModule(cmd redis.Cmdable, db *gorm.DB, module *problem.Module, judge rpc.JudgeServiceClient, nodes *pool.Pool, langs language.Registry, quota quota.Quota, oss *s3.S3) *Module {
 	localSubmitCache := cache.NewLocalSubmitCache(cmd)
 	submitDao := dao.NewSubmitDao(db)
-	localSubmitRepo := repository.NewLocalSubmitRepo(localSubmitCache, submitDao)
+	submitDedupCache := cache.NewSubmitDedupCache(cmd)
+	judgeResultCache := cache.NewJudgeResultCache(cmd)
+	codeArchive := InitCodeArchive(oss)
+	localSubmitRepo := repository.NewLocalSubmitRepo(localSubmitCache, submitDedupCache, judgeResultCache, codeArchive, submitDao)
 	problemRepository := module.Repo
-	locSubmitService := local.NewLocSubmitService(localSubmitRepo, problemRepository, judge)
-	localSubmitHandler := web.NewLocalSubmitHandler(locSubmitService)
-	submitCache := cache.NewSubmitCache(cmd)
-	submitRepository := repository.NewSubmitRepository(submitCache)
+	checker := InitContestChecker()
+	locSubmitService := local.NewLocSubmitService(localSubmitRepo, problemRepository, judge, langs, checker)
+	localSubmitHandler := web.NewLocalSubmitHandler(locSubmitService, quota)
+	submitRepository := repository.NewSubmitRepository(judgeResultCache, submitDao)
 	string2 := InitJudgeKey()
-	submitService := remote.NewSubmitService(submitRepository, problemRepository, string2)
-	submissionHandler := web.NewSubmissionHandler(submitService)
+	submitService := remote.NewSubmitService(submitRepository, problemRepository, langs, string2)
+	submissionHandler := web.NewSubmissionHandler(submitService, quota)
+	languageHandler := web.NewLanguageHandler(langs)
+	nodeHandler := web.NewNodeHandler(nodes)
+	shareDao := dao.NewShareDao(db)
+	shareRepository := repository.NewShareRepository(shareDao)
+	shareService := share.NewShareService(shareRepository, localSubmitRepo, checker)
+	shareHandler := web.NewShareHandler(shareService)
+	codeArchiveRepo := repository.NewCodeArchiveRepo(submitDao, codeArchive)
+	archiver := InitArchiver(codeArchiveRepo)
 	judgementModule := &Module{
-		LocHdl: localSubmitHandler,
-		RemHdl: submissionHandler,
+		LocHdl:     localSubmitHandler,
+		RemHdl:     submissionHandler,
+		LangHdl:    languageHandler,
+		NodeHdl:    nodeHandler,
+		ShareHdl:   shareHandler,
+		Archiver:   archiver,
+		SubmitRepo: localSubmitRepo,
 	}
 	return judgementModule
 }
 
 // wire.go:
 
-var LocalSet = wire.NewSet(dao.NewSubmitDao, cache.NewLocalSubmitCache, repository.NewLocalSubmitRepo, local.NewLocSubmitService, web.NewLocalSubmitHandler)
+var LocalSet = wire.NewSet(dao.NewSubmitDao, cache.NewLocalSubmitCache, cache.NewSubmitDedupCache, cache.NewJudgeResultCache, InitCodeArchive, repository.NewLocalSubmitRepo, local.NewLocSubmitService, web.NewLocalSubmitHandler)
 
-var RemoteSet = wire.NewSet(cache.NewSubmitCache, repository.NewSubmitRepository, remote.NewSubmitService, web.NewSubmissionHandler)
+var RemoteSet = wire.NewSet(repository.NewSubmitRepository, remote.NewSubmitService, web.NewSubmissionHandler)
+
+var ShareSet = wire.NewSet(dao.NewShareDao, repository.NewShareRepository, share.NewShareService, web.NewShareHandler)
 
 func InitJudgeKey() string {
 	key, ok := os.LookupEnv("RAPIDAPI_KEY")
@@ -56,3 +83,20 @@ func InitJudgeKey() string {
 
 	return key
 }
+
+func InitCodeArchive(oss *s3.S3) dao.CodeArchive {
+	return dao.NewS3CodeArchive(oss, config.GetConf().Archive.Bucket)
+}
+
+func InitContestChecker() contest.Checker {
+	return contest.NewConfigChecker(config.GetConf().Contests)
+}
+
+func InitArchiver(repo repository.CodeArchiveRepo) *archive.Archiver {
+	cfg := config.GetConf().Archive
+	return archive.NewArchiver(repo, archive.Config{
+		Retention: time.Duration(cfg.RetentionDays) * time.Hour * 24,
+		Interval:  time.Duration(cfg.Interval) * time.Second,
+		BatchSize: cfg.BatchSize,
+	})
+}
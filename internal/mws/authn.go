@@ -35,6 +35,11 @@ func (a *AuthnHandler) Authn() gin.HandlerFunc {
 			c.Next()
 			return
 		}
+		// 带路径参数的路由按注册时的模式匹配，例如 /api/share/:token
+		if _, ok := a.ignorePaths[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
 
 		tk := extractToken(c)
 		claims, err := a.jwt.ParseToken(tk)
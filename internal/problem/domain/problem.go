@@ -13,8 +13,18 @@ type Problem struct {
 	FullTemplate   string
 	TypeDefinition string
 	Func           string
-	MaxMem         int `json:"maxMem"`
-	MaxRuntime     int `json:"maxRuntime"`
+	Signature      string `json:"signature"`
+	MaxMem         int    `json:"maxMem"`
+	MaxRuntime     int    `json:"maxRuntime"`
+	Revision       int64  `json:"revision"`
+	// 参考解与已知错误解只在出题/改题时使用，不对外暴露
+	Reference      Solution   `json:"-"`
+	WrongSolutions []Solution `json:"-"`
+}
+
+type Solution struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
 }
 
 type RoughProblem struct {
@@ -0,0 +1,26 @@
+package domain
+
+// ValidationReport 出题/改题时参考解与错误解的评测报告
+type ValidationReport struct {
+	Passed    bool             `json:"passed"`
+	Message   string           `json:"message,omitempty"`
+	Reference SolutionReport   `json:"reference"`
+	Wrong     []SolutionReport `json:"wrong,omitempty"`
+}
+
+// SolutionReport 单份解答的评测结果
+// 参考解需要通过全部用例，错误解至少要在一个用例上失败，Passed 表示是否符合预期
+type SolutionReport struct {
+	Language string       `json:"language"`
+	Passed   bool         `json:"passed"`
+	Message  string       `json:"message,omitempty"`
+	Cases    []CaseReport `json:"cases,omitempty"`
+}
+
+type CaseReport struct {
+	Index      int    `json:"index"`
+	Accepted   bool   `json:"accepted"`
+	StatusMsg  string `json:"statusMsg"`
+	TimeUsed   int64  `json:"timeUsed"`
+	MemoryUsed int64  `json:"memoryUsed"`
+}
@@ -7,6 +7,7 @@ type Problem struct {
 	FullTemplate   string
 	TypeDefinition string
 	Func           string
+	Signature      string `gorm:"type:text"`
 	Inputs         string
 	Outputs        string
 	Difficulty     string `gorm:"type:varchar(20)"`
@@ -14,6 +15,7 @@ type Problem struct {
 	TotalPass      int64  `gorm:"not null,default:0"`
 	MaxMem         int    `gorm:"not null,default:0"`
 	MaxRuntime     int    `gorm:"not null,default:0"`
+	Revision       int64  `gorm:"not null;default:1"` // 测试数据或限制变化时加一，评测结果缓存以此失效
 	Ctime          int64
 	Utime          int64
 }
@@ -27,3 +29,13 @@ type ProblemTag struct {
 	ProblemID uint64 `gorm:"primaryKey,autoIncrement:false;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	TagID     uint64 `gorm:"primaryKey,autoIncrement:false;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
+
+// ProblemSolution 出题人提供的参考解与已知错误解，改题时用于重新校验
+type ProblemSolution struct {
+	ID        uint64 `gorm:"primaryKey,autoIncrement"`
+	ProblemID uint64 `gorm:"index"`
+	Language  string `gorm:"type:varchar(20)"`
+	Code      string `gorm:"type:text"`
+	Wrong     bool
+	Ctime     int64
+}
@@ -32,7 +32,10 @@ type ProblemDao interface {
 	FindProblemsByName(ctx context.Context, name string) ([]domain.RoughProblem, error)
 	FindByTitle(ctx context.Context, tag, title string) (domain.Problem, error)
 	FindProblemByID(ctx context.Context, id uint64) (domain.Problem, error)
+	// FindProblemsByIDs 只返回 id 和标题，不存在的 id 直接忽略
+	FindProblemsByIDs(ctx context.Context, ids []uint64) ([]domain.Problem, error)
 	FindTestById(ctx context.Context, id uint64) ([]domain.TestCase, error)
+	FindSolutions(ctx context.Context, id uint64) (domain.Solution, []domain.Solution, error)
 }
 
 type GormProblemDao struct {
@@ -53,7 +56,7 @@ func (dao *GormProblemDao) CreateProblem(ctx context.Context, problem domain.Pro
 		return err
 	}
 
-	outputs, err := sonic.MarshalString(problem.Input)
+	outputs, err := sonic.MarshalString(problem.Output)
 	if err != nil {
 		return err
 	}
@@ -65,13 +68,23 @@ func (dao *GormProblemDao) CreateProblem(ctx context.Context, problem domain.Pro
 		FullTemplate:   problem.FullTemplate,
 		TypeDefinition: problem.TypeDefinition,
 		Func:           problem.Func,
+		Signature:      problem.Signature,
 		Inputs:         inputs,
 		Outputs:        outputs,
+		MaxMem:         problem.MaxMem,
+		MaxRuntime:     problem.MaxRuntime,
+		Revision:       1,
 		Ctime:          now,
 		Utime:          now,
 	}
 
-	err = dao.db.WithContext(ctx).Create(&pm).Error
+	err = dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&pm).Error; err != nil {
+			return err
+		}
+
+		return createSolutions(tx, pm.ID, problem, now)
+	})
 
 	if errors.Is(err, gorm.ErrDuplicatedKey) {
 		return ErrProblemExists
@@ -85,6 +98,31 @@ func (dao *GormProblemDao) UpdateProblem(ctx context.Context, id uint64, problem
 	updateData := make(map[string]interface{})
 
 	// 检查需要更新的字段并添加到 updateData
+	if len(problem.Input) > 0 || len(problem.Output) > 0 {
+		inputs, err := sonic.MarshalString(problem.Input)
+		if err != nil {
+			return domain.Problem{}, err
+		}
+		outputs, err := sonic.MarshalString(problem.Output)
+		if err != nil {
+			return domain.Problem{}, err
+		}
+		updateData["inputs"] = inputs
+		updateData["outputs"] = outputs
+	}
+	if problem.Signature != "" {
+		updateData["signature"] = problem.Signature
+	}
+	if problem.MaxMem > 0 {
+		updateData["max_mem"] = problem.MaxMem
+	}
+	if problem.MaxRuntime > 0 {
+		updateData["max_runtime"] = problem.MaxRuntime
+	}
+	// 以上几项都会影响评测结果
+	if len(updateData) > 0 {
+		updateData["revision"] = gorm.Expr("revision + 1")
+	}
 	if problem.Title != "" {
 		updateData["title"] = problem.Title
 	}
@@ -95,7 +133,9 @@ func (dao *GormProblemDao) UpdateProblem(ctx context.Context, id uint64, problem
 		updateData["difficulty"] = problem.Difficulty
 	}
 
-	if len(updateData) == 0 {
+	// 参考解和错误解总是一起传入，其中一项有变化就整体替换
+	replaceSolutions := problem.Reference.Code != "" || len(problem.WrongSolutions) > 0
+	if len(updateData) == 0 && !replaceSolutions {
 		return domain.Problem{}, errors.New("no fields to update")
 	}
 
@@ -105,8 +145,23 @@ func (dao *GormProblemDao) UpdateProblem(ctx context.Context, id uint64, problem
 		return domain.Problem{}, ErrProblemNotFound
 	}
 
-	// 更新数据
-	if err := dao.db.WithContext(ctx).Model(&pm).Updates(updateData).Error; err != nil {
+	// 更新数据，解答有变化时整体替换旧的解答
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(updateData) > 0 {
+			if err := tx.Model(&pm).Updates(updateData).Error; err != nil {
+				return err
+			}
+		}
+		if !replaceSolutions {
+			return nil
+		}
+		if err := tx.Where("problem_id = ?", pm.ID).Delete(&ProblemSolution{}).Error; err != nil {
+			return err
+		}
+
+		return createSolutions(tx, pm.ID, problem, time.Now().Unix())
+	})
+	if err != nil {
 		return domain.Problem{}, err
 	}
 
@@ -114,7 +169,7 @@ func (dao *GormProblemDao) UpdateProblem(ctx context.Context, id uint64, problem
 		Id:         pm.ID,
 		Title:      pm.Title,
 		Content:    pm.Content,
-		PassRate:   fmt.Sprintf("%.2f%", float64(pm.TotalPass/pm.TotalSubmit)),
+		PassRate:   passRate(pm.TotalPass, pm.TotalSubmit),
 		MaxRuntime: pm.MaxRuntime,
 		MaxMem:     pm.MaxMem,
 		Difficulty: pm.Difficulty,
@@ -209,7 +264,8 @@ func (dao *GormProblemDao) FindProblemsByName(ctx context.Context, name string)
 	var problems []domain.RoughProblem
 
 	query := `
-        SELECT p.id, p.tag, p.title, p.pass_rate
+        SELECT p.id, t.name AS tag, p.title,
+            CONCAT(ROUND(IF(p.total_submit = 0, 0, p.total_pass * 100 / p.total_submit), 2), '%') AS pass_rate
         FROM problem p
         JOIN problem_tag pt ON p.id = pt.problem_id
         JOIN tag t ON pt.tag_id = t.id
@@ -241,7 +297,7 @@ func (dao *GormProblemDao) FindByTitle(ctx context.Context, tag, title string) (
 		Title:      problem.Title,
 		Content:    problem.Content,
 		Tag:        tag,
-		PassRate:   fmt.Sprintf("%.2f%", float64(problem.TotalPass/problem.TotalSubmit)),
+		PassRate:   passRate(problem.TotalPass, problem.TotalSubmit),
 		MaxMem:     problem.MaxMem,
 		MaxRuntime: problem.MaxRuntime,
 		Difficulty: problem.Difficulty,
@@ -250,10 +306,29 @@ func (dao *GormProblemDao) FindByTitle(ctx context.Context, tag, title string) (
 	return pm, nil
 }
 
+func (dao *GormProblemDao) FindProblemsByIDs(ctx context.Context, ids []uint64) ([]domain.Problem, error) {
+	var pms []Problem
+	err := dao.db.WithContext(ctx).Model(&Problem{}).Select("id", "title").Where("id IN ?", ids).Find(&pms).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Problem, 0, len(pms))
+	for _, pm := range pms {
+		res = append(res, domain.Problem{Id: pm.ID, Title: pm.Title})
+	}
+
+	return res, nil
+}
+
 func (dao *GormProblemDao) FindProblemByID(ctx context.Context, id uint64) (domain.Problem, error) {
 	var pm Problem
-	err := dao.db.WithContext(ctx).Model(&Problem{}).Where("id = ?", id).Find(&pm).Error
+	err := dao.db.WithContext(ctx).Model(&Problem{}).Where("id = ?", id).First(&pm).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Problem{}, ErrProblemNotFound
+		}
+
 		return domain.Problem{}, err
 	}
 
@@ -271,8 +346,10 @@ func (dao *GormProblemDao) FindProblemByID(ctx context.Context, id uint64) (doma
 		FullTemplate:   pm.FullTemplate,
 		TypeDefinition: pm.TypeDefinition,
 		Func:           pm.Func,
+		Signature:      pm.Signature,
 		MaxMem:         pm.MaxMem,
 		MaxRuntime:     pm.MaxRuntime,
+		Revision:       pm.Revision,
 	}, nil
 }
 
@@ -302,3 +379,60 @@ func (dao *GormProblemDao) FindTestById(ctx context.Context, id uint64) ([]domai
 
 	return res, nil
 }
+
+func (dao *GormProblemDao) FindSolutions(ctx context.Context, id uint64) (domain.Solution, []domain.Solution, error) {
+	var sols []ProblemSolution
+	err := dao.db.WithContext(ctx).Where("problem_id = ?", id).Order("id").Find(&sols).Error
+	if err != nil {
+		return domain.Solution{}, nil, err
+	}
+
+	var ref domain.Solution
+	var wrong []domain.Solution
+	for _, s := range sols {
+		sol := domain.Solution{
+			Language: s.Language,
+			Code:     s.Code,
+		}
+		if s.Wrong {
+			wrong = append(wrong, sol)
+		} else {
+			ref = sol
+		}
+	}
+
+	return ref, wrong, nil
+}
+
+func createSolutions(tx *gorm.DB, pid uint64, problem domain.Problem, now int64) error {
+	if problem.Reference.Code == "" {
+		return nil
+	}
+
+	sols := []ProblemSolution{{
+		ProblemID: pid,
+		Language:  problem.Reference.Language,
+		Code:      problem.Reference.Code,
+		Ctime:     now,
+	}}
+	for _, w := range problem.WrongSolutions {
+		sols = append(sols, ProblemSolution{
+			ProblemID: pid,
+			Language:  w.Language,
+			Code:      w.Code,
+			Wrong:     true,
+			Ctime:     now,
+		})
+	}
+
+	return tx.Create(&sols).Error
+}
+
+// passRate 与 FindProblemsByName 中的 SQL 保持一致，没有提交时通过率为 0
+func passRate(pass, submit int64) string {
+	if submit == 0 {
+		return "0.00%"
+	}
+
+	return fmt.Sprintf("%.2f%%", float64(pass)*100/float64(submit))
+}
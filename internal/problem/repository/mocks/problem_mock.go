@@ -0,0 +1,235 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/problem/repository/problem.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/problem/repository/problem.go -destination=internal/problem/repository/mocks/problem_mock.go -package=repomocks
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/crazyfrankie/onlinejudge/internal/problem/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProblemRepository is a mock of ProblemRepository interface.
+type MockProblemRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProblemRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockProblemRepositoryMockRecorder is the mock recorder for MockProblemRepository.
+type MockProblemRepositoryMockRecorder struct {
+	mock *MockProblemRepository
+}
+
+// NewMockProblemRepository creates a new mock instance.
+func NewMockProblemRepository(ctrl *gomock.Controller) *MockProblemRepository {
+	mock := &MockProblemRepository{ctrl: ctrl}
+	mock.recorder = &MockProblemRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProblemRepository) EXPECT() *MockProblemRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateTag mocks base method.
+func (m *MockProblemRepository) CreateTag(ctx context.Context, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", ctx, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockProblemRepositoryMockRecorder) CreateTag(ctx, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockProblemRepository)(nil).CreateTag), ctx, tag)
+}
+
+// FindAllProblems mocks base method.
+func (m *MockProblemRepository) FindAllProblems(ctx context.Context) ([]domain.Problem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllProblems", ctx)
+	ret0, _ := ret[0].([]domain.Problem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllProblems indicates an expected call of FindAllProblems.
+func (mr *MockProblemRepositoryMockRecorder) FindAllProblems(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllProblems", reflect.TypeOf((*MockProblemRepository)(nil).FindAllProblems), ctx)
+}
+
+// FindAllTags mocks base method.
+func (m *MockProblemRepository) FindAllTags(ctx context.Context) ([]domain.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllTags", ctx)
+	ret0, _ := ret[0].([]domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllTags indicates an expected call of FindAllTags.
+func (mr *MockProblemRepositoryMockRecorder) FindAllTags(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllTags", reflect.TypeOf((*MockProblemRepository)(nil).FindAllTags), ctx)
+}
+
+// FindByTitle mocks base method.
+func (m *MockProblemRepository) FindByTitle(ctx context.Context, id uint64, tag, title string) (domain.Problem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTitle", ctx, id, tag, title)
+	ret0, _ := ret[0].(domain.Problem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTitle indicates an expected call of FindByTitle.
+func (mr *MockProblemRepositoryMockRecorder) FindByTitle(ctx, id, tag, title any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTitle", reflect.TypeOf((*MockProblemRepository)(nil).FindByTitle), ctx, id, tag, title)
+}
+
+// FindCountInTag mocks base method.
+func (m *MockProblemRepository) FindCountInTag(ctx context.Context) ([]domain.TagWithCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCountInTag", ctx)
+	ret0, _ := ret[0].([]domain.TagWithCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCountInTag indicates an expected call of FindCountInTag.
+func (mr *MockProblemRepositoryMockRecorder) FindCountInTag(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCountInTag", reflect.TypeOf((*MockProblemRepository)(nil).FindCountInTag), ctx)
+}
+
+// FindProblemByID mocks base method.
+func (m *MockProblemRepository) FindProblemByID(ctx context.Context, id uint64) (domain.Problem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProblemByID", ctx, id)
+	ret0, _ := ret[0].(domain.Problem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProblemByID indicates an expected call of FindProblemByID.
+func (mr *MockProblemRepositoryMockRecorder) FindProblemByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProblemByID", reflect.TypeOf((*MockProblemRepository)(nil).FindProblemByID), ctx, id)
+}
+
+// FindProblemsByIDs mocks base method.
+func (m *MockProblemRepository) FindProblemsByIDs(ctx context.Context, ids []uint64) ([]domain.Problem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProblemsByIDs", ctx, ids)
+	ret0, _ := ret[0].([]domain.Problem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProblemsByIDs indicates an expected call of FindProblemsByIDs.
+func (mr *MockProblemRepositoryMockRecorder) FindProblemsByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProblemsByIDs", reflect.TypeOf((*MockProblemRepository)(nil).FindProblemsByIDs), ctx, ids)
+}
+
+// FindProblemsByName mocks base method.
+func (m *MockProblemRepository) FindProblemsByName(ctx context.Context, name string) ([]domain.RoughProblem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProblemsByName", ctx, name)
+	ret0, _ := ret[0].([]domain.RoughProblem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProblemsByName indicates an expected call of FindProblemsByName.
+func (mr *MockProblemRepositoryMockRecorder) FindProblemsByName(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProblemsByName", reflect.TypeOf((*MockProblemRepository)(nil).FindProblemsByName), ctx, name)
+}
+
+// FindSolutions mocks base method.
+func (m *MockProblemRepository) FindSolutions(ctx context.Context, id uint64) (domain.Solution, []domain.Solution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSolutions", ctx, id)
+	ret0, _ := ret[0].(domain.Solution)
+	ret1, _ := ret[1].([]domain.Solution)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindSolutions indicates an expected call of FindSolutions.
+func (mr *MockProblemRepositoryMockRecorder) FindSolutions(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSolutions", reflect.TypeOf((*MockProblemRepository)(nil).FindSolutions), ctx, id)
+}
+
+// FindTestById mocks base method.
+func (m *MockProblemRepository) FindTestById(ctx context.Context, id uint64) ([]domain.TestCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTestById", ctx, id)
+	ret0, _ := ret[0].([]domain.TestCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTestById indicates an expected call of FindTestById.
+func (mr *MockProblemRepositoryMockRecorder) FindTestById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTestById", reflect.TypeOf((*MockProblemRepository)(nil).FindTestById), ctx, id)
+}
+
+// InsertProblem mocks base method.
+func (m *MockProblemRepository) InsertProblem(ctx context.Context, pm domain.Problem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertProblem", ctx, pm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertProblem indicates an expected call of InsertProblem.
+func (mr *MockProblemRepositoryMockRecorder) InsertProblem(ctx, pm any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertProblem", reflect.TypeOf((*MockProblemRepository)(nil).InsertProblem), ctx, pm)
+}
+
+// UpdateProblem mocks base method.
+func (m *MockProblemRepository) UpdateProblem(ctx context.Context, id uint64, problem domain.Problem) (domain.Problem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProblem", ctx, id, problem)
+	ret0, _ := ret[0].(domain.Problem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProblem indicates an expected call of UpdateProblem.
+func (mr *MockProblemRepositoryMockRecorder) UpdateProblem(ctx, id, problem any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProblem", reflect.TypeOf((*MockProblemRepository)(nil).UpdateProblem), ctx, id, problem)
+}
+
+// UpdateTag mocks base method.
+func (m *MockProblemRepository) UpdateTag(ctx context.Context, id uint64, newTag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTag", ctx, id, newTag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTag indicates an expected call of UpdateTag.
+func (mr *MockProblemRepositoryMockRecorder) UpdateTag(ctx, id, newTag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTag", reflect.TypeOf((*MockProblemRepository)(nil).UpdateTag), ctx, id, newTag)
+}
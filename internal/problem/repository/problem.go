@@ -28,7 +28,10 @@ type ProblemRepository interface {
 	FindProblemsByName(ctx context.Context, name string) ([]domain.RoughProblem, error)
 	FindByTitle(ctx context.Context, id uint64, tag, title string) (domain.Problem, error)
 	FindProblemByID(ctx context.Context, id uint64) (domain.Problem, error)
+	// FindProblemsByIDs 只带 id 和标题
+	FindProblemsByIDs(ctx context.Context, ids []uint64) ([]domain.Problem, error)
 	FindTestById(ctx context.Context, id uint64) ([]domain.TestCase, error)
+	FindSolutions(ctx context.Context, id uint64) (domain.Solution, []domain.Solution, error)
 }
 
 type CacheProblemRepo struct {
@@ -118,3 +121,11 @@ func (repo *CacheProblemRepo) FindProblemByID(ctx context.Context, pid uint64) (
 func (repo *CacheProblemRepo) FindTestById(ctx context.Context, id uint64) ([]domain.TestCase, error) {
 	return repo.dao.FindTestById(ctx, id)
 }
+
+func (repo *CacheProblemRepo) FindProblemsByIDs(ctx context.Context, ids []uint64) ([]domain.Problem, error) {
+	return repo.dao.FindProblemsByIDs(ctx, ids)
+}
+
+func (repo *CacheProblemRepo) FindSolutions(ctx context.Context, id uint64) (domain.Solution, []domain.Solution, error) {
+	return repo.dao.FindSolutions(ctx, id)
+}
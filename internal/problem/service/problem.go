@@ -30,18 +30,31 @@ type ProblemService interface {
 	GetProblem(ctx context.Context, id uint64, tag, title string) (domain.Problem, error)
 }
 
+// ValidationError 参考解校验未通过，携带完整的评测报告返回给出题人
+type ValidationError struct {
+	*er.BizError
+	Report domain.ValidationReport
+}
+
 type ProblemSvc struct {
-	repo repository.ProblemRepository
+	repo      repository.ProblemRepository
+	validator Validator
 }
 
-func NewProblemService(repo repository.ProblemRepository) ProblemService {
+func NewProblemService(repo repository.ProblemRepository, validator Validator) ProblemService {
 	return &ProblemSvc{
-		repo: repo,
+		repo:      repo,
+		validator: validator,
 	}
 }
 
 func (svc *ProblemSvc) AddProblem(ctx context.Context, problem domain.Problem) error {
-	err := svc.repo.InsertProblem(ctx, problem)
+	err := svc.validate(ctx, problem)
+	if err != nil {
+		return err
+	}
+
+	err = svc.repo.InsertProblem(ctx, problem)
 	if err != nil {
 		if errors.Is(err, ErrProblemExists) {
 			return er.NewBizError(constant.ErrProblemExists)
@@ -59,6 +72,37 @@ func (svc *ProblemSvc) ModifyProblem(ctx context.Context, id string, problem dom
 		return domain.Problem{}, err
 	}
 
+	// 修改了评测相关的字段时，需要用新数据（或已保存的参考解）重新校验
+	if judgeChanged(problem) {
+		var old domain.Problem
+		old, err = svc.repo.FindProblemByID(ctx, uint64(Id))
+		if err != nil {
+			if errors.Is(err, repository.ErrProblemNotFound) {
+				return domain.Problem{}, er.NewBizError(constant.ErrProblemNotFound)
+			}
+
+			return domain.Problem{}, er.NewBizError(constant.ErrProblemInternalServer)
+		}
+		old.Reference, old.WrongSolutions, err = svc.repo.FindSolutions(ctx, uint64(Id))
+		if err != nil {
+			return domain.Problem{}, er.NewBizError(constant.ErrProblemInternalServer)
+		}
+
+		// 早期题目没有保存参考解，此时没有可对照的基准，只能跳过校验；
+		// 但不能只加错误解，校验时会因为缺少参考解而失败
+		merged := mergeJudge(old, problem)
+		if merged.Reference.Code != "" || len(problem.WrongSolutions) > 0 {
+			err = svc.validate(ctx, merged)
+			if err != nil {
+				return domain.Problem{}, err
+			}
+		}
+		// 解答在库里整体替换，只改了参考解或错误解时另一项沿用原来的，保存的与校验的一致
+		if solutionsChanged(problem) {
+			problem.Reference, problem.WrongSolutions = merged.Reference, merged.WrongSolutions
+		}
+	}
+
 	var pm domain.Problem
 	pm, err = svc.repo.UpdateProblem(ctx, uint64(Id), problem)
 	if err != nil {
@@ -154,3 +198,51 @@ func (svc *ProblemSvc) GetProblem(ctx context.Context, id uint64, tag, title str
 
 	return pm, nil
 }
+
+func (svc *ProblemSvc) validate(ctx context.Context, problem domain.Problem) error {
+	report, err := svc.validator.Validate(ctx, problem)
+	if err != nil {
+		return er.NewBizError(constant.ErrProblemJudgeUnavailable)
+	}
+	if !report.Passed {
+		return &ValidationError{
+			BizError: er.NewBizError(constant.ErrProblemValidation),
+			Report:   report,
+		}
+	}
+
+	return nil
+}
+
+func judgeChanged(pm domain.Problem) bool {
+	return len(pm.Input) > 0 || len(pm.Output) > 0 || pm.MaxMem > 0 || pm.MaxRuntime > 0 ||
+		pm.Signature != "" || pm.Reference.Code != "" || len(pm.WrongSolutions) > 0
+}
+
+func solutionsChanged(pm domain.Problem) bool {
+	return pm.Reference.Code != "" || len(pm.WrongSolutions) > 0
+}
+
+// mergeJudge 用本次修改的字段覆盖原题目中评测相关的部分
+func mergeJudge(old domain.Problem, pm domain.Problem) domain.Problem {
+	if len(pm.Input) > 0 || len(pm.Output) > 0 {
+		old.Input, old.Output = pm.Input, pm.Output
+	}
+	if pm.MaxMem > 0 {
+		old.MaxMem = pm.MaxMem
+	}
+	if pm.MaxRuntime > 0 {
+		old.MaxRuntime = pm.MaxRuntime
+	}
+	if pm.Signature != "" {
+		old.Signature = pm.Signature
+	}
+	if pm.Reference.Code != "" {
+		old.Reference = pm.Reference
+	}
+	if len(pm.WrongSolutions) > 0 {
+		old.WrongSolutions = pm.WrongSolutions
+	}
+
+	return old
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/crazyfrankie/onlinejudge/internal/problem/domain"
+	repomocks "github.com/crazyfrankie/onlinejudge/internal/problem/repository/mocks"
+)
+
+// fakeValidator 记录收到的题目，按 passed 返回结果
+type fakeValidator struct {
+	passed bool
+	got    []domain.Problem
+}
+
+func (v *fakeValidator) Validate(ctx context.Context, pm domain.Problem) (domain.ValidationReport, error) {
+	v.got = append(v.got, pm)
+	if pm.Reference.Code == "" {
+		return domain.ValidationReport{Message: ErrNoReference.Error()}, nil
+	}
+	return domain.ValidationReport{Passed: v.passed}, nil
+}
+
+func TestMergeJudge(t *testing.T) {
+	old := domain.Problem{
+		Input:          []string{"1"},
+		Output:         []string{"1"},
+		MaxMem:         64,
+		MaxRuntime:     1000,
+		Reference:      domain.Solution{Language: "go", Code: "ref"},
+		WrongSolutions: []domain.Solution{{Language: "go", Code: "wrong"}},
+	}
+	newRef := domain.Solution{Language: "cpp", Code: "ref2"}
+	newWrong := []domain.Solution{{Language: "cpp", Code: "wrong2"}}
+
+	testCases := []struct {
+		name string
+		pm   domain.Problem
+		want domain.Problem
+	}{
+		{
+			name: "only reference",
+			pm:   domain.Problem{Reference: newRef},
+			want: domain.Problem{Input: old.Input, Output: old.Output, MaxMem: 64, MaxRuntime: 1000,
+				Reference: newRef, WrongSolutions: old.WrongSolutions},
+		},
+		{
+			name: "only wrong solutions",
+			pm:   domain.Problem{WrongSolutions: newWrong},
+			want: domain.Problem{Input: old.Input, Output: old.Output, MaxMem: 64, MaxRuntime: 1000,
+				Reference: old.Reference, WrongSolutions: newWrong},
+		},
+		{
+			name: "limits",
+			pm:   domain.Problem{MaxMem: 128},
+			want: domain.Problem{Input: old.Input, Output: old.Output, MaxMem: 128, MaxRuntime: 1000,
+				Reference: old.Reference, WrongSolutions: old.WrongSolutions},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, mergeJudge(old, tc.pm))
+		})
+	}
+}
+
+func TestProblemSvc_ModifyProblem(t *testing.T) {
+	ref := domain.Solution{Language: "go", Code: "ref"}
+	wrong := []domain.Solution{{Language: "go", Code: "wrong"}}
+	newRef := domain.Solution{Language: "go", Code: "ref2"}
+	newWrong := []domain.Solution{{Language: "go", Code: "wrong2"}}
+	stored := domain.Problem{Id: 1, Input: []string{"1"}, Output: []string{"1"}}
+
+	testCases := []struct {
+		name string
+		// oldRef 为空表示早期没有保存参考解的题目
+		oldRef     domain.Solution
+		passed     bool
+		req        domain.Problem
+		wantSaved  domain.Problem
+		wantChecks int
+		wantErr    bool
+	}{
+		{
+			name:       "only reference keeps stored wrong solutions",
+			oldRef:     ref,
+			passed:     true,
+			req:        domain.Problem{Reference: newRef},
+			wantSaved:  domain.Problem{Reference: newRef, WrongSolutions: wrong},
+			wantChecks: 1,
+		},
+		{
+			name:       "only wrong solutions keeps stored reference",
+			oldRef:     ref,
+			passed:     true,
+			req:        domain.Problem{WrongSolutions: newWrong},
+			wantSaved:  domain.Problem{Reference: ref, WrongSolutions: newWrong},
+			wantChecks: 1,
+		},
+		{
+			name:       "validation failed",
+			oldRef:     ref,
+			req:        domain.Problem{Reference: newRef},
+			wantChecks: 1,
+			wantErr:    true,
+		},
+		{
+			name:      "legacy problem skips validation",
+			req:       domain.Problem{MaxMem: 128},
+			wantSaved: domain.Problem{MaxMem: 128},
+		},
+		{
+			name:       "legacy problem cannot add only wrong solutions",
+			req:        domain.Problem{WrongSolutions: newWrong},
+			wantChecks: 1,
+			wantErr:    true,
+		},
+		{
+			name:      "not judge related",
+			req:       domain.Problem{Title: "title"},
+			wantSaved: domain.Problem{Title: "title"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := repomocks.NewMockProblemRepository(ctrl)
+			if judgeChanged(tc.req) {
+				repo.EXPECT().FindProblemByID(gomock.Any(), uint64(1)).Return(stored, nil)
+				var oldWrong []domain.Solution
+				if tc.oldRef.Code != "" {
+					oldWrong = wrong
+				}
+				repo.EXPECT().FindSolutions(gomock.Any(), uint64(1)).Return(tc.oldRef, oldWrong, nil)
+			}
+			if !tc.wantErr {
+				repo.EXPECT().UpdateProblem(gomock.Any(), uint64(1), tc.wantSaved).Return(domain.Problem{Id: 1}, nil)
+			}
+			v := &fakeValidator{passed: tc.passed}
+			svc := NewProblemService(repo, v)
+
+			_, err := svc.ModifyProblem(context.Background(), "1", tc.req)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, v.got, tc.wantChecks)
+			if tc.wantChecks > 0 && !tc.wantErr {
+				// 校验的就是要保存的解答
+				assert.Equal(t, tc.wantSaved.Reference, v.got[0].Reference)
+				assert.Equal(t, tc.wantSaved.WrongSolutions, v.got[0].WrongSolutions)
+			}
+		})
+	}
+}
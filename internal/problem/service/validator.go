@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/crazyfrankie/go-judge/pkg/rpc"
+
+	domain2 "github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/harness"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/sched"
+	"github.com/crazyfrankie/onlinejudge/internal/problem/domain"
+)
+
+const accepted = "Accepted"
+
+var (
+	ErrNoReference  = errors.New("reference solution is required")
+	ErrCaseMismatch = errors.New("inputs and outputs mismatch")
+)
+
+// Validator 出题/改题时把参考解和已知错误解送去评测
+// 参考解必须在限制内通过全部用例，错误解必须至少在一个用例上失败，否则说明测试数据有问题
+type Validator interface {
+	Validate(ctx context.Context, pm domain.Problem) (domain.ValidationReport, error)
+}
+
+type JudgeValidator struct {
+	client rpc.JudgeServiceClient
+	langs  language.Registry
+}
+
+func NewValidator(client rpc.JudgeServiceClient, langs language.Registry) Validator {
+	return &JudgeValidator{
+		client: client,
+		langs:  langs,
+	}
+}
+
+// Validate 只有评测机本身不可用时才返回 error，解答不符合预期体现在 report.Passed 上
+func (v *JudgeValidator) Validate(ctx context.Context, pm domain.Problem) (domain.ValidationReport, error) {
+	var report domain.ValidationReport
+	if strings.TrimSpace(pm.Reference.Code) == "" {
+		report.Message = ErrNoReference.Error()
+		return report, nil
+	}
+	if len(pm.Input) == 0 || len(pm.Input) != len(pm.Output) {
+		report.Message = ErrCaseMismatch.Error()
+		return report, nil
+	}
+
+	var err error
+	report.Reference, err = v.run(ctx, pm, pm.Reference, true)
+	if err != nil {
+		return domain.ValidationReport{}, err
+	}
+	report.Passed = report.Reference.Passed
+
+	for _, wrong := range pm.WrongSolutions {
+		var res domain.SolutionReport
+		res, err = v.run(ctx, pm, wrong, false)
+		if err != nil {
+			return domain.ValidationReport{}, err
+		}
+		report.Wrong = append(report.Wrong, res)
+		report.Passed = report.Passed && res.Passed
+	}
+
+	return report, nil
+}
+
+// run 逐个用例评测，这样报告可以精确到是哪一组数据出了问题
+func (v *JudgeValidator) run(ctx context.Context, pm domain.Problem, sol domain.Solution, expectPass bool) (domain.SolutionReport, error) {
+	report := domain.SolutionReport{Language: sol.Language}
+
+	lang, err := v.langs.Get(sol.Language)
+	if err != nil {
+		report.Message = err.Error()
+		return report, nil
+	}
+
+	code, fullTemplate, typeDefinition := sol.Code, pm.FullTemplate, pm.TypeDefinition
+	if pm.Signature != "" {
+		sig, err := harness.ParseSignature(pm.Signature)
+		if err != nil {
+			report.Message = err.Error()
+			return report, nil
+		}
+		code, err = harness.Generate(lang.Id, sig, sol.Code)
+		if err != nil {
+			report.Message = err.Error()
+			return report, nil
+		}
+		fullTemplate, typeDefinition = "", ""
+	}
+
+	// 出题校验不能挤占用户提交的评测资源
+	ctx = sched.WithClass(ctx, sched.ClassRejudge)
+	allPass := true
+	for i := range pm.Input {
+		res, err := v.client.Judge(ctx, &rpc.JudgeRequest{
+			Language:       v.langs.GoJudge(lang),
+			ProblemId:      int64(pm.Id),
+			Uid:            int64(pm.UserId),
+			Code:           code,
+			FullTemplate:   fullTemplate,
+			TypeDefinition: typeDefinition,
+			Input:          pm.Input[i : i+1],
+			Output:         pm.Output[i : i+1],
+			MaxMem:         strconv.Itoa(lang.MemLimit(pm.MaxMem)),
+			MaxTime:        strconv.Itoa(lang.TimeLimit(pm.MaxRuntime)),
+		})
+		if err != nil {
+			return domain.SolutionReport{}, err
+		}
+
+		result := res.GetResult()
+		cr := domain.CaseReport{
+			Index:      i,
+			StatusMsg:  result.GetStatusMsg(),
+			TimeUsed:   result.GetTimeUsed(),
+			MemoryUsed: result.GetMemoryUsed(),
+		}
+		cr.Accepted = cr.StatusMsg == accepted && withinLimit(lang, pm, cr)
+		report.Cases = append(report.Cases, cr)
+		allPass = allPass && cr.Accepted
+	}
+
+	report.Passed = allPass == expectPass
+	if !report.Passed {
+		if expectPass {
+			report.Message = "reference solution failed on some cases"
+		} else {
+			report.Message = "wrong solution passed all cases"
+		}
+	}
+
+	return report, nil
+}
+
+// withinLimit 评测机会按限制判 TLE/MLE，这里再兜底检查一次，限制为 0 表示不限制
+func withinLimit(lang domain2.Language, pm domain.Problem, cr domain.CaseReport) bool {
+	if pm.MaxRuntime > 0 && cr.TimeUsed > int64(lang.TimeLimit(pm.MaxRuntime)) {
+		return false
+	}
+	if pm.MaxMem > 0 && cr.MemoryUsed > int64(lang.MemLimit(pm.MaxMem)) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crazyfrankie/go-judge/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	domain2 "github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
+	"github.com/crazyfrankie/onlinejudge/internal/problem/domain"
+)
+
+// fakeJudge 没有评测结果，每个用例都不算通过
+type fakeJudge struct {
+	err   error
+	calls int
+}
+
+func (f *fakeJudge) Judge(ctx context.Context, in *rpc.JudgeRequest, opts ...grpc.CallOption) (*rpc.JudgeResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &rpc.JudgeResponse{}, nil
+}
+
+func TestJudgeValidator_Validate(t *testing.T) {
+	langs, err := language.NewRegistry([]domain2.Language{{Id: "go", Enabled: true}})
+	require.NoError(t, err)
+	pm := domain.Problem{
+		Input:     []string{"1", "2"},
+		Output:    []string{"1", "2"},
+		Reference: domain.Solution{Language: "go", Code: "ref"},
+	}
+
+	testCases := []struct {
+		name      string
+		pm        func() domain.Problem
+		judgeErr  error
+		wantCalls int
+		wantErr   bool
+		check     func(t *testing.T, report domain.ValidationReport)
+	}{
+		{
+			name: "no reference",
+			pm: func() domain.Problem {
+				p := pm
+				p.Reference = domain.Solution{}
+				return p
+			},
+			check: func(t *testing.T, report domain.ValidationReport) {
+				assert.False(t, report.Passed)
+				assert.Equal(t, ErrNoReference.Error(), report.Message)
+			},
+		},
+		{
+			name: "case mismatch",
+			pm: func() domain.Problem {
+				p := pm
+				p.Output = []string{"1"}
+				return p
+			},
+			check: func(t *testing.T, report domain.ValidationReport) {
+				assert.False(t, report.Passed)
+				assert.Equal(t, ErrCaseMismatch.Error(), report.Message)
+			},
+		},
+		{
+			name: "unknown language",
+			pm: func() domain.Problem {
+				p := pm
+				p.Reference.Language = "rust"
+				return p
+			},
+			check: func(t *testing.T, report domain.ValidationReport) {
+				assert.False(t, report.Passed)
+				assert.NotEmpty(t, report.Reference.Message)
+			},
+		},
+		{
+			name:      "reference failed",
+			pm:        func() domain.Problem { return pm },
+			wantCalls: 2,
+			check: func(t *testing.T, report domain.ValidationReport) {
+				assert.False(t, report.Passed)
+				assert.False(t, report.Reference.Passed)
+				assert.Len(t, report.Reference.Cases, 2)
+			},
+		},
+		{
+			name: "wrong solution rejected",
+			pm: func() domain.Problem {
+				p := pm
+				p.WrongSolutions = []domain.Solution{{Language: "go", Code: "wrong"}}
+				return p
+			},
+			wantCalls: 4,
+			check: func(t *testing.T, report domain.ValidationReport) {
+				// 参考解没通过，错误解没通过全部用例符合预期
+				assert.False(t, report.Passed)
+				require.Len(t, report.Wrong, 1)
+				assert.True(t, report.Wrong[0].Passed)
+			},
+		},
+		{
+			name:      "judge unavailable",
+			pm:        func() domain.Problem { return pm },
+			judgeErr:  errors.New("unavailable"),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeJudge{err: tc.judgeErr}
+			v := NewValidator(client, langs)
+
+			report, err := v.Validate(context.Background(), tc.pm())
+			assert.Equal(t, tc.wantCalls, client.calls)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, report)
+		})
+	}
+}
+
+func TestWithinLimit(t *testing.T) {
+	lang := domain2.Language{TimeFactor: 2, MemFactor: 1}
+	pm := domain.Problem{MaxRuntime: 1000, MaxMem: 64}
+
+	assert.True(t, withinLimit(lang, pm, domain.CaseReport{TimeUsed: 2000, MemoryUsed: 64}))
+	assert.False(t, withinLimit(lang, pm, domain.CaseReport{TimeUsed: 2001, MemoryUsed: 64}))
+	assert.False(t, withinLimit(lang, pm, domain.CaseReport{TimeUsed: 10, MemoryUsed: 65}))
+	// 限制为 0 表示不限制
+	assert.True(t, withinLimit(lang, domain.Problem{}, domain.CaseReport{TimeUsed: 1 << 30, MemoryUsed: 1 << 30}))
+}
@@ -1,6 +1,8 @@
 package web
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/crazyfrankie/onlinejudge/common/response"
@@ -53,18 +55,21 @@ func (ctl *ProblemHandler) AddProblem() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		name := "onlinejudge/Problem/AddProblem"
 		type Req struct {
-			UserId         uint64   `json:"user_id"`
-			Title          string   `json:"title"`
-			Tag            string   `json:"tag"`
-			Content        string   `json:"content"`
-			FullTemplate   string   `json:"fullTemplate"`
-			TypeDefinition string   `json:"typeDefinition"`
-			Func           string   `json:"func"`
-			Inputs         []string `json:"inputs"`
-			Outputs        []string `json:"outputs"`
-			MaxMem         int      `json:"max_mem"`
-			MaxRunTime     int      `json:"max_run_time"`
-			Difficulty     string   `json:"difficulty"`
+			UserId         uint64            `json:"user_id"`
+			Title          string            `json:"title"`
+			Tag            string            `json:"tag"`
+			Content        string            `json:"content"`
+			FullTemplate   string            `json:"fullTemplate"`
+			TypeDefinition string            `json:"typeDefinition"`
+			Func           string            `json:"func"`
+			Signature      string            `json:"signature"`
+			Inputs         []string          `json:"inputs"`
+			Outputs        []string          `json:"outputs"`
+			MaxMem         int               `json:"max_mem"`
+			MaxRunTime     int               `json:"max_run_time"`
+			Difficulty     string            `json:"difficulty"`
+			Reference      domain.Solution   `json:"reference"`
+			WrongSolutions []domain.Solution `json:"wrong_solutions"`
 		}
 
 		var req Req
@@ -81,16 +86,19 @@ func (ctl *ProblemHandler) AddProblem() gin.HandlerFunc {
 			FullTemplate:   req.FullTemplate,
 			TypeDefinition: req.TypeDefinition,
 			Func:           req.Func,
+			Signature:      req.Signature,
 			Input:          req.Inputs,
 			Output:         req.Outputs,
 			MaxMem:         req.MaxMem,
 			MaxRuntime:     req.MaxRunTime,
 			Difficulty:     req.Difficulty,
+			Reference:      req.Reference,
+			WrongSolutions: req.WrongSolutions,
 		}
 
 		err := ctl.svc.AddProblem(c.Request.Context(), pm)
 		if err != nil {
-			response.ErrorWithLog(c, name, bizError, err)
+			ctl.validationError(c, name, err)
 			return
 		}
 
@@ -102,9 +110,16 @@ func (ctl *ProblemHandler) ModifyProblem() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		name := "onlinejudge/Problem/ModifyProblem"
 		type Req struct {
-			Title      string `json:"title"`
-			Content    string `json:"content"`
-			Difficulty string `json:"difficulty"`
+			Title          string            `json:"title"`
+			Content        string            `json:"content"`
+			Difficulty     string            `json:"difficulty"`
+			Signature      string            `json:"signature"`
+			Inputs         []string          `json:"inputs"`
+			Outputs        []string          `json:"outputs"`
+			MaxMem         int               `json:"max_mem"`
+			MaxRunTime     int               `json:"max_run_time"`
+			Reference      domain.Solution   `json:"reference"`
+			WrongSolutions []domain.Solution `json:"wrong_solutions"`
 		}
 
 		var req Req
@@ -116,12 +131,19 @@ func (ctl *ProblemHandler) ModifyProblem() gin.HandlerFunc {
 		id := c.Param("id")
 
 		pm, err := ctl.svc.ModifyProblem(c.Request.Context(), id, domain.Problem{
-			Title:      req.Title,
-			Content:    req.Content,
-			Difficulty: req.Difficulty,
+			Title:          req.Title,
+			Content:        req.Content,
+			Difficulty:     req.Difficulty,
+			Signature:      req.Signature,
+			Input:          req.Inputs,
+			Output:         req.Outputs,
+			MaxMem:         req.MaxMem,
+			MaxRuntime:     req.MaxRunTime,
+			Reference:      req.Reference,
+			WrongSolutions: req.WrongSolutions,
 		})
 		if err != nil {
-			response.ErrorWithLog(c, name, bizError, err)
+			ctl.validationError(c, name, err)
 			return
 		}
 
@@ -129,6 +151,17 @@ func (ctl *ProblemHandler) ModifyProblem() gin.HandlerFunc {
 	}
 }
 
+// validationError 参考解校验失败时把评测报告一并返回，方便出题人定位是哪组数据有问题
+func (ctl *ProblemHandler) validationError(c *gin.Context, name string, err error) {
+	var verr *service.ValidationError
+	if errors.As(err, &verr) {
+		response.ErrorWithData(c, name, bizError, verr, verr.Report)
+		return
+	}
+
+	response.ErrorWithLog(c, name, bizError, err)
+}
+
 func (ctl *ProblemHandler) GetAllProblems() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		name := "onlinejudge/Problem/GetAllProblems"
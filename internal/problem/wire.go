@@ -3,6 +3,8 @@
 package problem
 
 import (
+	"github.com/crazyfrankie/go-judge/pkg/rpc"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
 	"github.com/crazyfrankie/onlinejudge/internal/problem/repository"
 	"github.com/crazyfrankie/onlinejudge/internal/problem/repository/cache"
 	"github.com/crazyfrankie/onlinejudge/internal/problem/repository/dao"
@@ -13,12 +15,13 @@ import (
 	"gorm.io/gorm"
 )
 
-func InitModule(cmd redis.Cmdable, db *gorm.DB) *Module {
+func InitModule(cmd redis.Cmdable, db *gorm.DB, judge rpc.JudgeServiceClient, langs language.Registry) *Module {
 	wire.Build(
 		cache.NewProblemCache,
 		dao.NewProblemDao,
 
 		repository.NewProblemRepository,
+		service.NewValidator,
 		service.NewProblemService,
 
 		web.NewProblemHandler,
@@ -7,22 +7,25 @@
 package problem
 
 import (
-	"github.com/redis/go-redis/v9"
-	"gorm.io/gorm"
+	"github.com/crazyfrankie/go-judge/pkg/rpc"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
 	"github.com/crazyfrankie/onlinejudge/internal/problem/repository"
 	"github.com/crazyfrankie/onlinejudge/internal/problem/repository/cache"
 	"github.com/crazyfrankie/onlinejudge/internal/problem/repository/dao"
 	"github.com/crazyfrankie/onlinejudge/internal/problem/service"
 	"github.com/crazyfrankie/onlinejudge/internal/problem/web"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 // Injectors from wire.go:
 
-func InitModule(cmd redis.Cmdable, db *gorm.DB) *Module {
+func InitModule(cmd redis.Cmdable, db *gorm.DB, judge rpc.JudgeServiceClient, langs language.Registry) *Module {
 	problemDao := dao.NewProblemDao(db)
 	problemCache := cache.NewProblemCache(cmd)
 	problemRepository := repository.NewProblemRepository(problemDao, problemCache)
-	problemService := service.NewProblemService(problemRepository)
+	validator := service.NewValidator(judge, langs)
+	problemService := service.NewProblemService(problemRepository, validator)
 	problemHandler := web.NewProblemHandler(problemService)
 	module := &Module{
 		Hdl:  problemHandler,
@@ -4,9 +4,20 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/crazyfrankie/onlinejudge/internal/article/event"
+	"github.com/crazyfrankie/onlinejudge/internal/article/job"
+	"github.com/crazyfrankie/onlinejudge/internal/article/migrator"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/archive"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 )
 
 type App struct {
 	Server    *gin.Engine
 	Consumers []event.Consumer
+	Archiver  *archive.Archiver
+	Ranking   *job.RankingJob
+	// Interactive 计数落库和对账任务
+	Interactive *job.InteractiveJob
+	// Migrator 没有配置文章存储迁移时为 nil
+	Migrator *migrator.Migrator
+	Outbox   *outbox.Relay
 }
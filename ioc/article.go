@@ -0,0 +1,48 @@
+package ioc
+
+import (
+	"github.com/aws/aws-sdk-go/service/s3"
+	"gorm.io/gorm"
+
+	"github.com/crazyfrankie/onlinejudge/config"
+	articledao "github.com/crazyfrankie/onlinejudge/internal/article/repository/dao"
+)
+
+// InitArticleDAO 按配置选择文章的存储后端，只有用到 mongo 时才会连接 MongoDB
+// 配置了迁移目标时返回双写的 DAO
+func InitArticleDAO(db *gorm.DB, oss *s3.S3) articledao.ArticleDAO {
+	cfg := config.GetConf().Article
+	src := newArticleDAO(cfg.Storage, cfg.Bucket, db, oss)
+	if cfg.Migration.Target == "" {
+		return src
+	}
+
+	if sharesTables(cfg.Storage, cfg.Migration.Target) {
+		panic("article storage " + cfg.Storage + " and " + cfg.Migration.Target + " share the same tables")
+	}
+	dst := newArticleDAO(cfg.Migration.Target, cfg.Bucket, db, oss)
+
+	return articledao.NewDoubleWriteDAO(src, dst, cfg.Migration.Pattern)
+}
+
+func newArticleDAO(storage, bucket string, db *gorm.DB, oss *s3.S3) articledao.MigrateDAO {
+	switch storage {
+	case "", "mysql":
+		return articledao.NewArticleDao(db)
+	case "mongo":
+		mdb, node := InitMongoDB()
+		return articledao.NewMongoArticleDao(mdb, node)
+	case "oss":
+		return articledao.NewOSSArticleDao(oss, db, bucket)
+	}
+
+	panic("unknown article storage: " + storage)
+}
+
+// sharesTables mysql 和 oss 的元数据在同一组表里，不能互相迁移
+func sharesTables(a, b string) bool {
+	isMySQL := func(s string) bool {
+		return s == "" || s == "mysql" || s == "oss"
+	}
+	return isMySQL(a) && isMySQL(b)
+}
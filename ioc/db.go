@@ -18,6 +18,7 @@ import (
 	judgedao "github.com/crazyfrankie/onlinejudge/internal/judgement/repository/dao"
 	problemdao "github.com/crazyfrankie/onlinejudge/internal/problem/repository/dao"
 	userdao "github.com/crazyfrankie/onlinejudge/internal/user/repository/dao"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 )
 
 func InitDB() *gorm.DB {
@@ -31,6 +32,8 @@ func InitDB() *gorm.DB {
 		},
 		// 可设置外键约束
 		DisableForeignKeyConstraintWhenMigrating: true,
+		// 唯一索引冲突转换成 gorm.ErrDuplicatedKey，各个 dao 依赖它判断重复
+		TranslateError: true,
 	})
 	if err != nil {
 		panic("failed to connect database")
@@ -46,7 +49,10 @@ func InitDB() *gorm.DB {
 	sqlDB.SetConnMaxLifetime(time.Duration(config.GetConf().MySQL.ConnMaxLifeTime) * time.Minute) // 连接的最大生命周期
 
 	db.AutoMigrate(&userdao.User{}, problemdao.Problem{}, problemdao.ProblemTag{},
-		problemdao.Tag{}, judgedao.Submission{}, judgedao.Evaluation{}, articledao.Article{}, articledao.Interactive{}, articledao.OnlineArticle{})
+		problemdao.Tag{}, problemdao.ProblemSolution{}, judgedao.Submission{}, judgedao.Evaluation{}, judgedao.SubmissionShare{}, articledao.Article{}, articledao.Interactive{}, articledao.OnlineArticle{}, articledao.Comment{},
+		articledao.Solution{}, articledao.SolutionReveal{}, articledao.CollectFolder{}, articledao.UserCollect{},
+		articledao.ArticleRevision{}, articledao.ArticleTag{},
+		articledao.UserFollow{}, articledao.FollowStats{}, articledao.ArticleReview{}, outbox.OutboxMessage{})
 
 	// prometheus 埋点
 	err = db.Use(prometheus.New(prometheus.Config{
@@ -1,18 +1,77 @@
 package ioc
 
 import (
-	"github.com/crazyfrankie/onlinejudge/config"
+	"context"
+	"log"
+	"time"
 
 	"github.com/crazyfrankie/go-judge/pkg/rpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/crazyfrankie/onlinejudge/config"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/pool"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/sched"
 )
 
-func InitJudgeClient() rpc.JudgeServiceClient {
-	cc, err := grpc.NewClient(config.GetConf().Judge.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return nil
+func InitJudgePool() *pool.Pool {
+	cfg := config.GetConf().Judge
+	addrs := cfg.Addrs
+	if len(addrs) == 0 && cfg.Addr != "" {
+		addrs = []string{cfg.Addr}
+	}
+
+	threshold := cfg.FailureThreshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+	openTimeout := time.Duration(cfg.OpenTimeout) * time.Second
+	if openTimeout <= 0 {
+		openTimeout = 30 * time.Second
+	}
+	interval := time.Duration(cfg.HealthInterval) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	nodes := make([]*pool.Node, 0, len(addrs))
+	for _, addr := range addrs {
+		cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			// 地址本身不合法，跳过这个节点，其余节点照常工作
+			log.Printf("failed to create judge client for %s: %v", addr, err)
+			continue
+		}
+		nodes = append(nodes, pool.NewNode(addr, cc, pool.NewBreaker(threshold, openTimeout)))
+	}
+	if len(nodes) == 0 {
+		panic("no judge node available, check judge.addr/judge.addrs in config")
+	}
+
+	p := pool.NewPool(nodes, pool.Config{
+		Strategy:         cfg.Strategy,
+		HealthInterval:   interval,
+		FailureThreshold: threshold,
+		OpenTimeout:      openTimeout,
+	})
+	p.StartHealthCheck(context.Background())
+
+	return p
+}
+
+func InitJudgeClient(p *pool.Pool) rpc.JudgeServiceClient {
+	cfg := config.GetConf().Judge
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8 * len(p.Status())
+	}
+
+	weights := sched.DefaultWeights
+	for i := range weights {
+		if w, ok := cfg.Weights[sched.Class(i).String()]; ok && w > 0 {
+			weights[i] = w
+		}
 	}
 
-	return rpc.NewJudgeServiceClient(cc)
+	return sched.NewClient(p, sched.NewScheduler(concurrency, weights))
 }
@@ -0,0 +1,34 @@
+package ioc
+
+import (
+	"github.com/crazyfrankie/onlinejudge/config"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/domain"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/language"
+)
+
+func InitLanguages() language.Registry {
+	langs := language.Defaults()
+	if cfg := config.GetConf().Languages; len(cfg) > 0 {
+		langs = make([]domain.Language, 0, len(cfg))
+		for _, l := range cfg {
+			langs = append(langs, domain.Language{
+				Id:         l.Id,
+				Name:       l.Name,
+				Version:    l.Version,
+				Extension:  l.Extension,
+				Judge0Id:   l.Judge0Id,
+				GoJudge:    l.GoJudge,
+				TimeFactor: l.TimeFactor,
+				MemFactor:  l.MemFactor,
+				Enabled:    l.Enabled,
+			})
+		}
+	}
+
+	registry, err := language.NewRegistry(langs)
+	if err != nil {
+		panic(err)
+	}
+
+	return registry
+}
@@ -2,6 +2,7 @@ package ioc
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	snowflake "github.com/crazyfrankie/snow-flake"
@@ -9,19 +10,29 @@ import (
 	"go.mongodb.org/mongo-driver/v2/event"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/crazyfrankie/onlinejudge/config"
 )
 
 func InitMongoDB() (*mongo.Database, *snowflake.Node) {
 	monitor := &event.CommandMonitor{}
 
-	opt := options.Client().ApplyURI("mongodb://localhost:27017").
+	cfg := config.GetConf().Mongo
+	if cfg.URI == "" {
+		cfg.URI = "mongodb://localhost:27017"
+	}
+	if cfg.Database == "" {
+		cfg.Database = "onlinejudge"
+	}
+
+	opt := options.Client().ApplyURI(cfg.URI).
 		SetMonitor(monitor)
 	client, err := mongo.Connect(opt)
 	if err != nil {
 		panic(err)
 	}
 
-	db := client.Database("onlinejudge")
+	db := client.Database(cfg.Database)
 	err = InitCollections(db)
 	if err != nil {
 		panic(err)
@@ -39,23 +50,17 @@ func InitCollections(db *mongo.Database) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
-	err := db.CreateCollection(ctx, "articles")
-	if err != nil {
-		return err
-	}
-
-	err = db.CreateCollection(ctx, "published_articles")
-	if err != nil {
-		return err
-	}
-
-	err = db.CreateCollection(ctx, "article_chunks")
-	if err != nil {
-		return err
+	// 重启时集合已经存在，忽略这个错误
+	for _, name := range []string{"articles", "published_articles", "article_chunks"} {
+		err := db.CreateCollection(ctx, name)
+		var ce mongo.CommandError
+		if err != nil && !(errors.As(err, &ce) && ce.Name == "NamespaceExists") {
+			return err
+		}
 	}
 
 	// 创建分片相关索引
-	_, err = db.Collection("article_chunks").Indexes().CreateMany(ctx, []mongo.IndexModel{
+	_, err := db.Collection("article_chunks").Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{
 			Keys: bson.D{{Key: "article_id", Value: 1}, {Key: "order", Value: 1}},
 		},
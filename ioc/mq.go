@@ -1,10 +1,14 @@
 package ioc
 
 import (
+	"time"
+
 	"github.com/IBM/sarama"
+	"gorm.io/gorm"
 
 	"github.com/crazyfrankie/onlinejudge/config"
 	"github.com/crazyfrankie/onlinejudge/internal/article/event"
+	"github.com/crazyfrankie/onlinejudge/pkg/outbox"
 )
 
 func InitKafka() sarama.Client {
@@ -18,6 +22,22 @@ func InitKafka() sarama.Client {
 	return client
 }
 
-func NewConsumers(csm event.Consumer) []event.Consumer {
-	return []event.Consumer{csm}
+func NewConsumers(csm event.Consumer, feed *event.FeedConsumer) []event.Consumer {
+	return []event.Consumer{csm, feed}
+}
+
+// InitOutboxRelay 投递各个模块写入发件箱的事件
+func InitOutboxRelay(db *gorm.DB, client sarama.Client) *outbox.Relay {
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		panic(err)
+	}
+
+	cfg := config.GetConf().Outbox
+	return outbox.NewRelay(db, producer, outbox.RelayConfig{
+		Interval:    time.Duration(cfg.Interval) * time.Millisecond,
+		BatchSize:   cfg.BatchSize,
+		Retention:   time.Duration(cfg.RetentionDays) * time.Hour * 24,
+		MaxAttempts: cfg.MaxAttempts,
+	})
 }
@@ -0,0 +1,25 @@
+package ioc
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/crazyfrankie/onlinejudge/config"
+)
+
+func InitOSS() *s3.S3 {
+	cfg := config.GetConf().OSS
+	sess, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, ""),
+		Endpoint:         aws.String(cfg.Endpoint),
+		Region:           aws.String(cfg.Region),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return s3.New(sess)
+}
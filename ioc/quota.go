@@ -0,0 +1,63 @@
+package ioc
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/crazyfrankie/onlinejudge/config"
+	"github.com/crazyfrankie/onlinejudge/infra/contract/ratelimit"
+	ratelimitimpl "github.com/crazyfrankie/onlinejudge/infra/impl/ratelimit"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/contest"
+	"github.com/crazyfrankie/onlinejudge/internal/judgement/service/quota"
+	"github.com/crazyfrankie/onlinejudge/internal/mws"
+)
+
+// defaultQuota 没有配置时使用的档位
+var defaultQuota = map[quota.Tier]config.QuotaTier{
+	quota.TierNormal: {
+		User:    []config.QuotaRule{{Interval: 60, Rate: 10}, {Interval: 86400, Rate: 500}},
+		Problem: []config.QuotaRule{{Interval: 60, Rate: 5}},
+	},
+	quota.TierAdmin: {
+		User: []config.QuotaRule{{Interval: 60, Rate: 120}},
+	},
+	quota.TierContest: {
+		User:    []config.QuotaRule{{Interval: 60, Rate: 6}},
+		Problem: []config.QuotaRule{{Interval: 60, Rate: 2}},
+	},
+}
+
+func InitQuota(cmd redis.Cmdable, authz mws.Authorizer) quota.Quota {
+	cfg := config.GetConf()
+	tiers := map[quota.Tier]config.QuotaTier{
+		quota.TierNormal:  cfg.Quota.Normal,
+		quota.TierAdmin:   cfg.Quota.Admin,
+		quota.TierContest: cfg.Quota.Contest,
+	}
+
+	rules := make(map[quota.Tier][]quota.Rule, len(tiers))
+	for tier, t := range tiers {
+		if len(t.User) == 0 && len(t.Problem) == 0 {
+			t = defaultQuota[tier]
+		}
+		rules[tier] = append(quotaRules(quota.ScopeUser, t.User), quotaRules(quota.ScopeProblem, t.Problem)...)
+	}
+
+	return quota.NewQuota(rules, func(interval time.Duration, rate int) ratelimit.Limiter {
+		return ratelimitimpl.NewRedisSlideWindowLimiter(cmd, interval, rate)
+	}, authz, contest.NewConfigChecker(cfg.Contests))
+}
+
+func quotaRules(scope quota.Scope, cfg []config.QuotaRule) []quota.Rule {
+	res := make([]quota.Rule, 0, len(cfg))
+	for _, r := range cfg {
+		res = append(res, quota.Rule{
+			Scope:    scope,
+			Interval: time.Duration(r.Interval) * time.Second,
+			Rate:     r.Rate,
+		})
+	}
+
+	return res
+}
@@ -20,7 +20,7 @@ import (
 	"github.com/crazyfrankie/onlinejudge/internal/user/web/third"
 )
 
-func InitWebServer(mdl []gin.HandlerFunc, userHdl *user.Handler, proHdl *problem.Handler, oauthHdl *third.OAuthWeChatHandler, localHdl *judgement.LocHandler, remoteHdl *judgement.RemHandler, gitHdl *third.OAuthGithubHandler, artHdl *article.Handler, adminHdl *article.AdminHandler) *gin.Engine {
+func InitWebServer(mdl []gin.HandlerFunc, userHdl *user.Handler, proHdl *problem.Handler, oauthHdl *third.OAuthWeChatHandler, localHdl *judgement.LocHandler, remoteHdl *judgement.RemHandler, langHdl *judgement.LangHandler, nodeHdl *judgement.NodeHandler, shareHdl *judgement.ShareHandler, gitHdl *third.OAuthGithubHandler, artHdl *article.Handler, adminHdl *article.AdminHandler, commentHdl *article.CommentHandler, solHdl *article.SolutionHandler, collectHdl *article.CollectHandler, rankHdl *article.RankingHandler, migrateHdl *article.MigrateHandler, revHdl *article.RevisionHandler, tagHdl *article.TagHandler, followHdl *article.FollowHandler) *gin.Engine {
 	server := gin.Default()
 	server.Use(mdl...)
 	// 注册路由
@@ -29,9 +29,20 @@ func InitWebServer(mdl []gin.HandlerFunc, userHdl *user.Handler, proHdl *problem
 	oauthHdl.RegisterRoute(server)
 	localHdl.RegisterRoute(server)
 	remoteHdl.RegisterRoute(server)
+	langHdl.RegisterRoute(server)
+	nodeHdl.RegisterRoute(server)
+	shareHdl.RegisterRoute(server)
 	gitHdl.RegisterRoute(server)
 	artHdl.RegisterRoute(server)
 	adminHdl.RegisterRoute(server)
+	commentHdl.RegisterRoute(server)
+	solHdl.RegisterRoute(server)
+	collectHdl.RegisterRoute(server)
+	rankHdl.RegisterRoute(server)
+	migrateHdl.RegisterRoute(server)
+	revHdl.RegisterRoute(server)
+	tagHdl.RegisterRoute(server)
+	followHdl.RegisterRoute(server)
 
 	return server
 }
@@ -47,7 +58,7 @@ func GinMiddlewares(cmd redis.Cmdable, limiter ratelimit.Limiter, jwt token.Toke
 		cors.New(cors.Config{
 			AllowOrigins:     []string{"http://localhost:8081"},
 			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+			AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Idempotency-Key"},
 			ExposeHeaders:    []string{"Content-Length", "x-token-token", "x-refresh-token"},
 			AllowCredentials: true,
 			MaxAge:           12 * time.Hour,
@@ -74,6 +85,8 @@ func GinMiddlewares(cmd redis.Cmdable, limiter ratelimit.Limiter, jwt token.Toke
 			IgnorePaths("/api/oauth/github/callback").
 			IgnorePaths("/api/oauth/wechat/callback").
 			IgnorePaths("/api/user/test").
+			IgnorePaths("/api/languages").
+			IgnorePaths("/api/share/:token").
 			Authn(),
 
 		mws.NewAuthzHandler(authz).Authz(),
@@ -16,7 +16,12 @@ var BaseSet = wire.NewSet(InitDB, InitRedis, InitKafka, InitLog, InitToken)
 func InitApp() *App {
 	wire.Build(
 		BaseSet,
+		InitJudgePool,
 		InitJudgeClient,
+		InitLanguages,
+		InitQuota,
+		InitOSS,
+		InitArticleDAO,
 		sm.InitModule,
 		user.InitModule,
 		problem.InitModule,
@@ -28,6 +33,7 @@ func InitApp() *App {
 		InitWebServer,
 
 		NewConsumers,
+		InitOutboxRelay,
 
 		wire.FieldsOf(new(*user.Module), "Hdl"),
 		wire.FieldsOf(new(*user.Module), "GithubHdl"),
@@ -35,9 +41,25 @@ func InitApp() *App {
 		wire.FieldsOf(new(*problem.Module), "Hdl"),
 		wire.FieldsOf(new(*judgement.Module), "LocHdl"),
 		wire.FieldsOf(new(*judgement.Module), "RemHdl"),
+		wire.FieldsOf(new(*judgement.Module), "LangHdl"),
+		wire.FieldsOf(new(*judgement.Module), "NodeHdl"),
+		wire.FieldsOf(new(*judgement.Module), "ShareHdl"),
+		wire.FieldsOf(new(*judgement.Module), "Archiver"),
 		wire.FieldsOf(new(*article.Module), "Hdl"),
 		wire.FieldsOf(new(*article.Module), "AdminHdl"),
+		wire.FieldsOf(new(*article.Module), "CommentHdl"),
+		wire.FieldsOf(new(*article.Module), "SolHdl"),
+		wire.FieldsOf(new(*article.Module), "CollectHdl"),
+		wire.FieldsOf(new(*article.Module), "RankingHdl"),
 		wire.FieldsOf(new(*article.Module), "Consumer"),
+		wire.FieldsOf(new(*article.Module), "RankingJob"),
+		wire.FieldsOf(new(*article.Module), "InteractiveJob"),
+		wire.FieldsOf(new(*article.Module), "MigrateHdl"),
+		wire.FieldsOf(new(*article.Module), "RevHdl"),
+		wire.FieldsOf(new(*article.Module), "TagHdl"),
+		wire.FieldsOf(new(*article.Module), "FollowHdl"),
+		wire.FieldsOf(new(*article.Module), "FeedConsumer"),
+		wire.FieldsOf(new(*article.Module), "Migrator"),
 		wire.Struct(new(App), "*"),
 	)
 
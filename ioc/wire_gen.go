@@ -27,25 +27,52 @@ func InitApp() *App {
 	module := sm.InitModule(cmdable, limiter)
 	userModule := user.InitModule(cmdable, db, limiter, module, token)
 	userHandler := userModule.Hdl
-	problemModule := problem.InitModule(cmdable, db)
+	poolPool := InitJudgePool()
+	judgeServiceClient := InitJudgeClient(poolPool)
+	registry := InitLanguages()
+	problemModule := problem.InitModule(cmdable, db, judgeServiceClient, registry)
 	problemHandler := problemModule.Hdl
 	oAuthWeChatHandler := userModule.WeChatHdl
-	judgeServiceClient := InitJudgeClient()
-	judgementModule := judgement.InitModule(cmdable, db, problemModule, judgeServiceClient)
+	quotaQuota := InitQuota(cmdable, authorizer)
+	s3 := InitOSS()
+	judgementModule := judgement.InitModule(cmdable, db, problemModule, judgeServiceClient, poolPool, registry, quotaQuota, s3)
 	localSubmitHandler := judgementModule.LocHdl
 	submissionHandler := judgementModule.RemHdl
+	languageHandler := judgementModule.LangHdl
+	nodeHandler := judgementModule.NodeHdl
+	shareHandler := judgementModule.ShareHdl
 	oAuthGithubHandler := userModule.GithubHdl
 	client := InitKafka()
 	logger := InitLog()
-	articleModule := article.InitModule(db, cmdable, client, logger)
+	articleDAO := InitArticleDAO(db, s3)
+	articleModule := article.InitModule(db, cmdable, client, logger, problemModule, judgementModule, articleDAO)
 	articleHandler := articleModule.Hdl
 	adminHandler := articleModule.AdminHdl
-	engine := InitWebServer(v, userHandler, problemHandler, oAuthWeChatHandler, localSubmitHandler, submissionHandler, oAuthGithubHandler, articleHandler, adminHandler)
+	commentHandler := articleModule.CommentHdl
+	solutionHandler := articleModule.SolHdl
+	collectHandler := articleModule.CollectHdl
+	rankingHandler := articleModule.RankingHdl
+	migrateHandler := articleModule.MigrateHdl
+	revisionHandler := articleModule.RevHdl
+	tagHandler := articleModule.TagHdl
+	followHandler := articleModule.FollowHdl
+	engine := InitWebServer(v, userHandler, problemHandler, oAuthWeChatHandler, localSubmitHandler, submissionHandler, languageHandler, nodeHandler, shareHandler, oAuthGithubHandler, articleHandler, adminHandler, commentHandler, solutionHandler, collectHandler, rankingHandler, migrateHandler, revisionHandler, tagHandler, followHandler)
 	consumer := articleModule.Consumer
-	v2 := NewConsumers(consumer)
+	feedConsumer := articleModule.FeedConsumer
+	v2 := NewConsumers(consumer, feedConsumer)
+	archiver := judgementModule.Archiver
+	rankingJob := articleModule.RankingJob
+	interactiveJob := articleModule.InteractiveJob
+	migrator := articleModule.Migrator
+	relay := InitOutboxRelay(db, client)
 	app := &App{
-		Server:    engine,
-		Consumers: v2,
+		Server:      engine,
+		Consumers:   v2,
+		Archiver:    archiver,
+		Ranking:     rankingJob,
+		Interactive: interactiveJob,
+		Migrator:    migrator,
+		Outbox:      relay,
 	}
 	return app
 }
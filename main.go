@@ -42,6 +42,54 @@ func main() {
 		}
 	})
 
+	// 提交代码归档任务
+	archiveCtx, archiveCancel := context.WithCancel(context.Background())
+	g.Add(func() error {
+		app.Archiver.Run(archiveCtx)
+		<-archiveCtx.Done()
+		return nil
+	}, func(err error) {
+		archiveCancel()
+	})
+
+	// 热榜计算任务
+	rankingCtx, rankingCancel := context.WithCancel(context.Background())
+	g.Add(func() error {
+		app.Ranking.Run(rankingCtx)
+		return nil
+	}, func(err error) {
+		rankingCancel()
+	})
+
+	// 计数落库和对账任务
+	interactiveCtx, interactiveCancel := context.WithCancel(context.Background())
+	g.Add(func() error {
+		app.Interactive.Run(interactiveCtx)
+		return nil
+	}, func(err error) {
+		interactiveCancel()
+	})
+
+	// 文章存储迁移，同步管理员切换的读写模式
+	if app.Migrator != nil {
+		migrateCtx, migrateCancel := context.WithCancel(context.Background())
+		g.Add(func() error {
+			app.Migrator.Run(migrateCtx)
+			return nil
+		}, func(err error) {
+			migrateCancel()
+		})
+	}
+
+	// 事务发件箱投递任务
+	outboxCtx, outboxCancel := context.WithCancel(context.Background())
+	g.Add(func() error {
+		app.Outbox.Run(outboxCtx)
+		return nil
+	}, func(err error) {
+		outboxCancel()
+	})
+
 	// start consumers
 	for _, consumer := range app.Consumers {
 		err := consumer.Start()
@@ -0,0 +1,473 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 块级元素，解析成树之后再统一渲染
+type block interface{}
+
+type paragraph struct {
+	lines []string
+}
+
+type heading struct {
+	level int
+	raw   string
+}
+
+type codeBlock struct {
+	lang string
+	code string
+}
+
+type mathBlock struct {
+	tex string
+}
+
+type thematicBreak struct{}
+
+type blockquote struct {
+	children []block
+}
+
+type list struct {
+	ordered bool
+	start   int
+	tight   bool
+	items   [][]block
+}
+
+type table struct {
+	align  []string
+	header []string
+	rows   [][]string
+}
+
+var (
+	atxRe       = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+|$)(.*)$`)
+	atxCloseRe  = regexp.MustCompile(`(?:^|[ \t]+)#+[ \t]*$`)
+	hrRe        = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	setextH1Re  = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	setextH2Re  = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	fenceRe     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})(.*)$")
+	markerRe    = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])( +|$)`)
+	delimCellRe = regexp.MustCompile(`^:?-+:?$`)
+	langRe      = regexp.MustCompile(`[^a-z0-9+#._-]`)
+)
+
+// blockParsers 按优先级尝试，都不匹配时作为段落
+var blockParsers []func(lines []string) (block, int)
+
+// interrupters 可以打断段落的块
+var interrupters []func(lines []string) (block, int)
+
+func init() {
+	interrupters = []func(lines []string) (block, int){
+		parseFence, parseMathBlock, parseHeading, parseHR, parseQuote, parseListInterrupt, parseTable,
+	}
+	blockParsers = []func(lines []string) (block, int){
+		parseIndentedCode, parseFence, parseMathBlock, parseHeading, parseHR, parseQuote, parseList, parseTable,
+	}
+}
+
+func parseBlocks(lines []string) []block {
+	var blocks []block
+	for i := 0; i < len(lines); {
+		if isBlank(lines[i]) {
+			i++
+			continue
+		}
+
+		var (
+			b block
+			n int
+		)
+		for _, parse := range blockParsers {
+			if b, n = parse(lines[i:]); n > 0 {
+				break
+			}
+		}
+		if n == 0 {
+			b, n = parseParagraph(lines[i:])
+		}
+		blocks = append(blocks, b)
+		i += n
+	}
+
+	return blocks
+}
+
+func startsBlock(lines []string) bool {
+	for _, parse := range interrupters {
+		if _, n := parse(lines); n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// parseParagraph 连续的非空行，遇到能打断段落的块时结束，下划线形式的标题也在这里处理
+func parseParagraph(lines []string) (block, int) {
+	i := 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			break
+		}
+		if setextH1Re.MatchString(line) {
+			return heading{level: 1, raw: joinInline(lines[:i])}, i + 1
+		}
+		if setextH2Re.MatchString(line) {
+			return heading{level: 2, raw: joinInline(lines[:i])}, i + 1
+		}
+		if startsBlock(lines[i:]) {
+			break
+		}
+	}
+
+	return paragraph{lines: lines[:i]}, i
+}
+
+func parseIndentedCode(lines []string) (block, int) {
+	i := 0
+	for ; i < len(lines); i++ {
+		if !isBlank(lines[i]) && indent(lines[i]) < 4 {
+			break
+		}
+	}
+	// 结尾的空行不属于代码块
+	for i > 0 && isBlank(lines[i-1]) {
+		i--
+	}
+	if i == 0 {
+		return nil, 0
+	}
+
+	code := make([]string, 0, i)
+	for _, line := range lines[:i] {
+		if len(line) >= 4 {
+			line = line[4:]
+		} else {
+			line = ""
+		}
+		code = append(code, line)
+	}
+
+	return codeBlock{code: strings.Join(code, "\n") + "\n"}, i
+}
+
+func parseFence(lines []string) (block, int) {
+	m := fenceRe.FindStringSubmatch(lines[0])
+	if m == nil {
+		return nil, 0
+	}
+	pad, fence, info := len(m[1]), m[2], strings.TrimSpace(m[3])
+	if fence[0] == '`' && strings.Contains(info, "`") {
+		return nil, 0
+	}
+
+	var code []string
+	i := 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if indent(lines[i]) < 4 && len(trimmed) >= len(fence) &&
+			strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		line := lines[i]
+		// 去掉和开头的围栏一样多的缩进
+		line = line[min(pad, indent(line)):]
+		code = append(code, line)
+	}
+
+	var lang string
+	if fields := strings.Fields(info); len(fields) > 0 {
+		lang = langRe.ReplaceAllString(strings.ToLower(fields[0]), "")
+	}
+	text := strings.Join(code, "\n")
+	if len(code) > 0 {
+		text += "\n"
+	}
+
+	return codeBlock{lang: lang, code: text}, i
+}
+
+// parseMathBlock $$ 开头，到以 $$ 结尾的行为止，没有结尾时不是公式
+func parseMathBlock(lines []string) (block, int) {
+	first := strings.TrimSpace(lines[0])
+	if indent(lines[0]) >= 4 || !strings.HasPrefix(first, "$$") {
+		return nil, 0
+	}
+	rest := first[2:]
+	if len(rest) >= 2 && strings.HasSuffix(rest, "$$") {
+		return mathBlock{tex: strings.TrimSpace(rest[:len(rest)-2])}, 1
+	}
+
+	tex := []string{rest}
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasSuffix(line, "$$") {
+			tex = append(tex, line[:len(line)-2])
+			return mathBlock{tex: strings.TrimSpace(strings.Join(tex, "\n"))}, i + 1
+		}
+		tex = append(tex, lines[i])
+	}
+
+	return nil, 0
+}
+
+func parseHeading(lines []string) (block, int) {
+	m := atxRe.FindStringSubmatch(lines[0])
+	if m == nil {
+		return nil, 0
+	}
+	raw := atxCloseRe.ReplaceAllString(m[2], "")
+
+	return heading{level: len(m[1]), raw: strings.TrimSpace(raw)}, 1
+}
+
+func parseHR(lines []string) (block, int) {
+	if !hrRe.MatchString(lines[0]) {
+		return nil, 0
+	}
+	return thematicBreak{}, 1
+}
+
+// parseQuote 连续以 > 开头的行，段落可以不带 > 懒惰续行
+func parseQuote(lines []string) (block, int) {
+	if !isQuoteLine(lines[0]) {
+		return nil, 0
+	}
+
+	var inner []string
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isQuoteLine(line) {
+			line = strings.TrimLeft(line, " ")[1:]
+			line = strings.TrimPrefix(line, " ")
+			inner = append(inner, line)
+			continue
+		}
+		if isBlank(line) || isBlank(inner[len(inner)-1]) || startsBlock(lines[i:]) {
+			break
+		}
+		inner = append(inner, line)
+	}
+
+	return blockquote{children: parseBlocks(inner)}, i
+}
+
+type marker struct {
+	ordered bool
+	// delim 无序列表是符号本身，有序列表是 . 或 )
+	delim byte
+	start int
+	// width 内容相对行首的缩进，后续行至少缩进这么多才属于这一项
+	width int
+	rest  string
+}
+
+func parseMarker(line string) (marker, bool) {
+	m := markerRe.FindStringSubmatch(line)
+	if m == nil {
+		return marker{}, false
+	}
+	res := marker{
+		width: len(m[0]),
+		rest:  line[len(m[0]):],
+	}
+	sym := m[2]
+	if n := len(sym); sym[n-1] == '.' || sym[n-1] == ')' {
+		res.ordered = true
+		res.delim = sym[n-1]
+		res.start, _ = strconv.Atoi(sym[:n-1])
+	} else {
+		res.delim = sym[0]
+	}
+	// 标记后面超过 4 个空格时，内容是缩进代码块，只算一个空格
+	if spaces := len(m[3]); spaces > 4 {
+		res.width = len(m[1]) + len(sym) + 1
+		res.rest = line[res.width:]
+	}
+	if m[3] == "" {
+		res.width = len(m[1]) + len(sym) + 1
+	}
+
+	return res, true
+}
+
+// parseListInterrupt 打断段落的列表项必须有内容，有序列表还要从 1 开始
+func parseListInterrupt(lines []string) (block, int) {
+	m, ok := parseMarker(lines[0])
+	if !ok || isBlank(m.rest) || (m.ordered && m.start != 1) {
+		return nil, 0
+	}
+	return parseList(lines)
+}
+
+func parseList(lines []string) (block, int) {
+	first, ok := parseMarker(lines[0])
+	if !ok {
+		return nil, 0
+	}
+
+	l := list{ordered: first.ordered, start: first.start, tight: true}
+	i := 0
+	for i < len(lines) {
+		m, ok := parseMarker(lines[i])
+		if !ok || m.ordered != first.ordered || m.delim != first.delim || hrRe.MatchString(lines[i]) {
+			break
+		}
+
+		item := []string{m.rest}
+		i++
+		for i < len(lines) {
+			line := lines[i]
+			switch {
+			case isBlank(line):
+				item = append(item, "")
+			case indent(line) >= m.width:
+				item = append(item, line[m.width:])
+			case !isBlank(item[len(item)-1]) && !isMarker(line) && !startsBlock(lines[i:]):
+				// 懒惰续行，属于上一行的段落
+				item = append(item, strings.TrimLeft(line, " "))
+			default:
+				goto done
+			}
+			i++
+		}
+	done:
+		trailing := 0
+		for len(item) > 1 && isBlank(item[len(item)-1]) {
+			item = item[:len(item)-1]
+			trailing++
+		}
+		for _, line := range item[1:] {
+			if isBlank(line) {
+				l.tight = false
+			}
+		}
+		if trailing > 0 && i < len(lines) {
+			if next, ok := parseMarker(lines[i]); ok && next.ordered == first.ordered && next.delim == first.delim {
+				l.tight = false
+			}
+		}
+
+		l.items = append(l.items, parseBlocks(item))
+	}
+
+	return l, i
+}
+
+// parseTable GFM 表格，第二行是分隔行，列数要和表头一致
+func parseTable(lines []string) (block, int) {
+	if len(lines) < 2 || !strings.Contains(lines[0], "|") || indent(lines[0]) >= 4 {
+		return nil, 0
+	}
+	header := splitRow(lines[0])
+	delims := splitRow(lines[1])
+	if len(header) != len(delims) {
+		return nil, 0
+	}
+
+	align := make([]string, len(delims))
+	for i, d := range delims {
+		if !delimCellRe.MatchString(d) {
+			return nil, 0
+		}
+		left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":")
+		switch {
+		case left && right:
+			align[i] = "center"
+		case left:
+			align[i] = "left"
+		case right:
+			align[i] = "right"
+		}
+	}
+
+	t := table{align: align, header: header}
+	i := 2
+	for ; i < len(lines); i++ {
+		if isBlank(lines[i]) || !strings.Contains(lines[i], "|") {
+			break
+		}
+		row := splitRow(lines[i])
+		// 多的列丢掉，少的补空
+		cells := make([]string, len(header))
+		copy(cells, row)
+		t.rows = append(t.rows, cells)
+	}
+
+	return t, i
+}
+
+// splitRow 按没有转义的 | 切分，首尾的 | 可以省略
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var (
+		cells []string
+		cell  strings.Builder
+	)
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func isMarker(line string) bool {
+	_, ok := parseMarker(line)
+	return ok
+}
+
+func isQuoteLine(line string) bool {
+	return indent(line) < 4 && strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// joinInline 把段落的多行拼起来交给行内解析，行尾两个以上空格转成 \ 表示的硬换行
+func joinInline(lines []string) string {
+	var sb strings.Builder
+	for i, line := range lines {
+		line = strings.TrimLeft(line, " ")
+		if i == len(lines)-1 {
+			sb.WriteString(strings.TrimRight(line, " "))
+			break
+		}
+		trimmed := strings.TrimRight(line, " ")
+		sb.WriteString(trimmed)
+		if len(line)-len(trimmed) >= 2 {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
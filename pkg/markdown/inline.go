@@ -0,0 +1,434 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	schemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*$`)
+	emailRe  = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+)
+
+// inline 行内解析，同时输出 HTML 和纯文本
+type inline struct {
+	html strings.Builder
+	text strings.Builder
+}
+
+func renderInline(s string) (string, string) {
+	w := &inline{}
+	w.parse(s)
+	return w.html.String(), w.text.String()
+}
+
+func (w *inline) parse(s string) {
+	for i := 0; i < len(s); {
+		if n := w.special(s, i); n > 0 {
+			i += n
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		w.literal(s[i : i+size])
+		i += size
+	}
+}
+
+// special 处理 s[i] 开始的行内语法，返回消耗的字节数，0 表示按字面输出
+func (w *inline) special(s string, i int) int {
+	switch s[i] {
+	case '\\':
+		if i+1 < len(s) && s[i+1] == '\n' {
+			w.html.WriteString("<br>\n")
+			w.text.WriteByte('\n')
+			return 2
+		}
+		if i+1 < len(s) && isPunct(s[i+1]) {
+			w.literal(s[i+1 : i+2])
+			return 2
+		}
+	case '\n':
+		w.html.WriteByte('\n')
+		w.text.WriteByte(' ')
+		return 1
+	case '`':
+		return w.codeSpan(s, i)
+	case '$':
+		return w.math(s, i)
+	case '!':
+		if i+1 < len(s) && s[i+1] == '[' {
+			if n := w.link(s, i+1, true); n > 0 {
+				return n + 1
+			}
+		}
+	case '[':
+		return w.link(s, i, false)
+	case '<':
+		return w.autolink(s, i)
+	case '*', '_', '~':
+		return w.emphasis(s, i)
+	}
+
+	return 0
+}
+
+func (w *inline) literal(s string) {
+	w.html.WriteString(html.EscapeString(s))
+	w.text.WriteString(s)
+}
+
+func (w *inline) codeSpan(s string, i int) int {
+	n := runLen(s, i)
+	end := closingCode(s, i+n, n)
+	if end < 0 {
+		// 没有配对的反引号，整段按字面输出
+		w.literal(s[i : i+n])
+		return n
+	}
+
+	code := strings.ReplaceAll(s[i+n:end], "\n", " ")
+	if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+		code = code[1 : len(code)-1]
+	}
+	w.html.WriteString("<code>")
+	w.html.WriteString(html.EscapeString(code))
+	w.html.WriteString("</code>")
+	w.text.WriteString(code)
+
+	return end + n - i
+}
+
+// math $...$ 行内公式，$$...$$ 在行内时按独立公式输出
+// 开头的 $ 后面和结尾的 $ 前面不能是空白，结尾的 $ 后面不能是数字，避免把金额当成公式
+func (w *inline) math(s string, i int) int {
+	delim, class := "$", "math math-inline"
+	if strings.HasPrefix(s[i:], "$$") {
+		delim, class = "$$", "math math-display"
+	}
+	start := i + len(delim)
+	if start >= len(s) || isSpace(s[start]) {
+		return 0
+	}
+
+	for j := start; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case s[j] == '$':
+			end := j + len(delim)
+			// 公式中间不能再出现 $，否则整段按字面输出
+			if !strings.HasPrefix(s[j:], delim) || isSpace(s[j-1]) ||
+				(delim == "$" && end < len(s) && s[end] >= '0' && s[end] <= '9') {
+				return 0
+			}
+			tex := s[start:j]
+			w.html.WriteString(`<span class="` + class + `">`)
+			w.html.WriteString(html.EscapeString(tex))
+			w.html.WriteString("</span>")
+			w.text.WriteString(tex)
+			return end - i
+		}
+	}
+
+	return 0
+}
+
+// link [text](url "title") 和 ![alt](url)，地址不安全时只保留文字
+func (w *inline) link(s string, i int, image bool) int {
+	j := closingBracket(s, i)
+	if j < 0 || j+1 >= len(s) || s[j+1] != '(' {
+		return 0
+	}
+	dest, title, end, ok := parseDest(s, j+2)
+	if !ok {
+		return 0
+	}
+	labelHTML, labelText := renderInline(s[i+1 : j])
+	safe := safeURL(dest)
+
+	switch {
+	case image && safe:
+		w.html.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(labelText) + `"`)
+		if title != "" {
+			w.html.WriteString(` title="` + html.EscapeString(title) + `"`)
+		}
+		w.html.WriteString(` loading="lazy">`)
+	case image:
+		w.html.WriteString(html.EscapeString(labelText))
+	case safe:
+		w.html.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+		if title != "" {
+			w.html.WriteString(` title="` + html.EscapeString(title) + `"`)
+		}
+		w.html.WriteString(` rel="nofollow noopener noreferrer">`)
+		w.html.WriteString(labelHTML)
+		w.html.WriteString("</a>")
+	default:
+		w.html.WriteString(labelHTML)
+	}
+	w.text.WriteString(labelText)
+
+	return end - i
+}
+
+// autolink <https://...> 和 <name@example.com>，其他的 < 按字面转义
+func (w *inline) autolink(s string, i int) int {
+	j := strings.IndexByte(s[i+1:], '>')
+	if j < 0 {
+		return 0
+	}
+	inner := s[i+1 : i+1+j]
+	if strings.ContainsAny(inner, " \t\n<") {
+		return 0
+	}
+
+	var href string
+	switch {
+	case schemeRe.MatchString(inner) && safeURL(inner):
+		href = inner
+	case emailRe.MatchString(inner):
+		href = "mailto:" + inner
+	default:
+		return 0
+	}
+	w.html.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer">`)
+	w.html.WriteString(html.EscapeString(inner))
+	w.html.WriteString("</a>")
+	w.text.WriteString(inner)
+
+	return j + 2
+}
+
+// emphasis *em* **strong** ***both*** ~~del~~，下划线不能出现在单词中间
+func (w *inline) emphasis(s string, i int) int {
+	c := s[i]
+	n := runLen(s, i)
+	if (c == '~' && n != 2) || n > 3 || !opens(s, i, n) {
+		w.literal(s[i : i+n])
+		return n
+	}
+
+	end := -1
+	for j := i + n; j < len(s); {
+		switch {
+		case s[j] == '\\':
+			j += 2
+		case s[j] == '`':
+			m := runLen(s, j)
+			if k := closingCode(s, j+m, m); k >= 0 {
+				j = k + m
+			} else {
+				j += m
+			}
+		case s[j] == c:
+			m := runLen(s, j)
+			if m == n && closes(s, j, m) {
+				end = j
+				j = len(s)
+			} else {
+				j += m
+			}
+		default:
+			j++
+		}
+	}
+	if end < 0 {
+		w.literal(s[i : i+n])
+		return n
+	}
+
+	open, closing := "<em>", "</em>"
+	switch {
+	case c == '~':
+		open, closing = "<del>", "</del>"
+	case n == 2:
+		open, closing = "<strong>", "</strong>"
+	case n == 3:
+		open, closing = "<em><strong>", "</strong></em>"
+	}
+	w.html.WriteString(open)
+	w.parse(s[i+n : end])
+	w.html.WriteString(closing)
+
+	return end + n - i
+}
+
+func opens(s string, i, n int) bool {
+	if i+n >= len(s) || isSpace(s[i+n]) {
+		return false
+	}
+	if s[i] == '_' && i > 0 && isWord(s[:i], true) {
+		return false
+	}
+	return true
+}
+
+func closes(s string, j, m int) bool {
+	if isSpace(s[j-1]) {
+		return false
+	}
+	if s[j] == '_' && j+m < len(s) && isWord(s[j+m:], false) {
+		return false
+	}
+	return true
+}
+
+// isWord 判断紧挨着的字符是不是字母或数字，last 为 true 时看 s 的最后一个字符
+func isWord(s string, last bool) bool {
+	var r rune
+	if last {
+		r, _ = utf8.DecodeLastRuneInString(s)
+	} else {
+		r, _ = utf8.DecodeRuneInString(s)
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// closingCode 从 from 开始找长度正好为 n 的反引号串
+func closingCode(s string, from, n int) int {
+	for j := from; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := runLen(s, j)
+		if m == n {
+			return j
+		}
+		j += m
+	}
+	return -1
+}
+
+// closingBracket 找和 s[i] 的 [ 配对的 ]
+func closingBracket(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			m := runLen(s, j)
+			if k := closingCode(s, j+m, m); k >= 0 {
+				j = k + m - 1
+			} else {
+				j += m - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// parseDest 解析 ( 之后的地址和可选标题，返回 ) 之后的位置
+func parseDest(s string, i int) (string, string, int, bool) {
+	i = skipSpaces(s, i)
+	var dest string
+	if i < len(s) && s[i] == '<' {
+		j := strings.IndexAny(s[i+1:], ">\n")
+		if j < 0 || s[i+1+j] != '>' {
+			return "", "", 0, false
+		}
+		dest = s[i+1 : i+1+j]
+		i += j + 2
+	} else {
+		start, depth := i, 0
+	loop:
+		for ; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case ' ', '\t', '\n':
+				break loop
+			}
+		}
+		if i > len(s) {
+			i = len(s)
+		}
+		dest = s[start:i]
+	}
+
+	i = skipSpaces(s, i)
+	var title string
+	if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+		q := s[i]
+		j := strings.IndexByte(s[i+1:], q)
+		if j < 0 {
+			return "", "", 0, false
+		}
+		title = s[i+1 : i+1+j]
+		i = skipSpaces(s, i+j+2)
+	}
+	if i >= len(s) || s[i] != ')' {
+		return "", "", 0, false
+	}
+
+	return unescape(dest), unescape(title), i + 1, true
+}
+
+// safeURL 只允许 http、https、mailto 和相对地址，挡掉 javascript: data: 之类
+func safeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+func runLen(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
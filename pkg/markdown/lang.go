@@ -0,0 +1,43 @@
+package markdown
+
+import "strings"
+
+// langHints 没有标注语言的代码块按特征猜，命中最多且唯一的语言胜出
+var langHints = []struct {
+	lang     string
+	patterns []string
+}{
+	{"go", []string{"package main", "func main()", ":= ", "fmt.", "func ("}},
+	{"cpp", []string{"#include <iostream>", "#include <bits/stdc++.h>", "std::", "cout <<", "cin >>", "using namespace std"}},
+	{"c", []string{"#include <stdio.h>", "#include <stdlib.h>", "printf(", "scanf(", "malloc("}},
+	{"java", []string{"public class ", "public static void main", "System.out.", "import java."}},
+	{"python", []string{"def ", "elif ", "print(", "self.", "import sys", "__name__"}},
+	{"rust", []string{"fn main()", "let mut ", "println!", "impl ", "use std::"}},
+	{"javascript", []string{"console.log", "function ", "=> {", "const ", "require("}},
+	{"sql", []string{"SELECT ", "INSERT INTO", "CREATE TABLE", " FROM ", " WHERE "}},
+	{"bash", []string{"#!/bin/bash", "#!/bin/sh", "echo ", "sudo ", "apt-get "}},
+}
+
+// DetectLanguage 猜不出来时返回空串
+func DetectLanguage(code string) string {
+	best, bestScore, tie := "", 0, false
+	for _, h := range langHints {
+		score := 0
+		for _, p := range h.patterns {
+			if strings.Contains(code, p) {
+				score++
+			}
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = h.lang, score, false
+		case score == bestScore && score > 0:
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+
+	return best
+}
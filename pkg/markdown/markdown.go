@@ -0,0 +1,210 @@
+/*
+服务端 Markdown 渲染
+支持 CommonMark 常用的子集、GFM 表格和删除线，以及 $...$ / $$...$$ 公式
+原始 HTML 一律转义，链接和图片只允许 http、https、mailto 和相对地址，输出可以直接嵌到页面里
+公式和代码高亮交给前端，这里只输出 math-inline / math-display 和 language-xxx 的 class
+*/
+
+package markdown
+
+import (
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
+
+type Result struct {
+	HTML string
+	// TOC 按出现顺序的所有标题，ID 和 HTML 里标题的 id 一致
+	TOC []Heading
+	// Text 去掉标记后的纯文本，不含代码块和公式块
+	Text string
+}
+
+func Render(src string) Result {
+	src = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\t", "    ").Replace(src)
+
+	r := &renderer{ids: make(map[string]int)}
+	for _, b := range parseBlocks(strings.Split(src, "\n")) {
+		r.block(b)
+	}
+
+	return Result{
+		HTML: r.html.String(),
+		TOC:  r.toc,
+		Text: strings.TrimSpace(r.text.String()),
+	}
+}
+
+// Abstract 从渲染后的纯文本生成摘要，空白压缩成一个空格，最多 n 个字符
+func Abstract(src string, n int) string {
+	text := strings.Join(strings.Fields(Render(src).Text), " ")
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n])
+}
+
+type renderer struct {
+	html strings.Builder
+	text strings.Builder
+	toc  []Heading
+	// ids 已经用过的标题 id，重复时加后缀
+	ids map[string]int
+}
+
+func (r *renderer) block(b block) {
+	switch b := b.(type) {
+	case paragraph:
+		r.html.WriteString("<p>")
+		r.inline(joinInline(b.lines))
+		r.html.WriteString("</p>\n")
+		r.text.WriteByte('\n')
+	case heading:
+		h, text := renderInline(b.raw)
+		id := r.headingID(text)
+		r.toc = append(r.toc, Heading{Level: b.level, Text: text, ID: id})
+		tag := "h" + strconv.Itoa(b.level)
+		r.html.WriteString("<" + tag + ` id="` + html.EscapeString(id) + `">` + h + "</" + tag + ">\n")
+		r.text.WriteString(text + "\n")
+	case codeBlock:
+		lang := b.lang
+		if lang == "" {
+			lang = DetectLanguage(b.code)
+		}
+		if lang != "" {
+			r.html.WriteString(`<pre><code class="language-` + lang + `">`)
+		} else {
+			r.html.WriteString("<pre><code>")
+		}
+		r.html.WriteString(html.EscapeString(b.code))
+		r.html.WriteString("</code></pre>\n")
+	case mathBlock:
+		r.html.WriteString(`<div class="math math-display">` + html.EscapeString(b.tex) + "</div>\n")
+	case thematicBreak:
+		r.html.WriteString("<hr>\n")
+	case blockquote:
+		r.html.WriteString("<blockquote>\n")
+		for _, child := range b.children {
+			r.block(child)
+		}
+		r.html.WriteString("</blockquote>\n")
+	case list:
+		r.list(b)
+	case table:
+		r.table(b)
+	}
+}
+
+func (r *renderer) inline(s string) {
+	h, text := renderInline(s)
+	r.html.WriteString(h)
+	r.text.WriteString(text)
+}
+
+// list 紧凑列表的段落不包 <p>
+func (r *renderer) list(l list) {
+	tag := "ul"
+	if l.ordered {
+		tag = "ol"
+	}
+	r.html.WriteString("<" + tag)
+	if l.ordered && l.start != 1 {
+		r.html.WriteString(` start="` + strconv.Itoa(l.start) + `"`)
+	}
+	r.html.WriteString(">\n")
+
+	for _, item := range l.items {
+		r.html.WriteString("<li>")
+		for i, child := range item {
+			p, ok := child.(paragraph)
+			if !ok || !l.tight {
+				if i == 0 {
+					r.html.WriteByte('\n')
+				}
+				r.block(child)
+				continue
+			}
+			r.inline(joinInline(p.lines))
+			r.text.WriteByte('\n')
+			if i < len(item)-1 {
+				r.html.WriteByte('\n')
+			}
+		}
+		r.html.WriteString("</li>\n")
+	}
+
+	r.html.WriteString("</" + tag + ">\n")
+}
+
+func (r *renderer) table(t table) {
+	r.html.WriteString("<table>\n<thead>\n")
+	r.row("th", t.header, t.align)
+	r.html.WriteString("</thead>\n")
+	if len(t.rows) > 0 {
+		r.html.WriteString("<tbody>\n")
+		for _, row := range t.rows {
+			r.row("td", row, t.align)
+		}
+		r.html.WriteString("</tbody>\n")
+	}
+	r.html.WriteString("</table>\n")
+}
+
+func (r *renderer) row(tag string, cells []string, align []string) {
+	r.html.WriteString("<tr>\n")
+	for i, cell := range cells {
+		r.html.WriteString("<" + tag)
+		if align[i] != "" {
+			r.html.WriteString(` align="` + align[i] + `"`)
+		}
+		r.html.WriteString(">")
+		r.inline(cell)
+		r.html.WriteString("</" + tag + ">\n")
+		r.text.WriteByte(' ')
+	}
+	r.html.WriteString("</tr>\n")
+	r.text.WriteByte('\n')
+}
+
+func (r *renderer) headingID(text string) string {
+	id := slug(text)
+	n := r.ids[id]
+	r.ids[id] = n + 1
+	if n == 0 {
+		return id
+	}
+
+	return id + "-" + strconv.Itoa(n)
+}
+
+// slug 保留字母（包括中文）和数字，空白和连字符合并成一个 -
+func slug(text string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			dash = true
+		}
+	}
+	if sb.Len() == 0 {
+		return "section"
+	}
+
+	return sb.String()
+}
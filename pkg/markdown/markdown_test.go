@@ -0,0 +1,110 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		html string
+	}{
+		{
+			name: "paragraph and emphasis",
+			src:  "*a* **b** ***c*** ~~d~~ `e*f*`\nnext",
+			html: "<p><em>a</em> <strong>b</strong> <em><strong>c</strong></em> <del>d</del> <code>e*f*</code>\nnext</p>\n",
+		},
+		{
+			name: "intraword underscore",
+			src:  "snake_case_name and _em_",
+			html: "<p>snake_case_name and <em>em</em></p>\n",
+		},
+		{
+			name: "raw html escaped",
+			src:  "<script>alert(1)</script>\n\n<img src=x onerror=alert(1)>",
+			html: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n<p>&lt;img src=x onerror=alert(1)&gt;</p>\n",
+		},
+		{
+			name: "links",
+			src:  `[ok](https://a.com "t") [bad](javascript:alert(1)) ![i](/a.png) <https://b.com>`,
+			html: `<p><a href="https://a.com" title="t" rel="nofollow noopener noreferrer">ok</a> bad <img src="/a.png" alt="i" loading="lazy"> <a href="https://b.com" rel="nofollow noopener noreferrer">https://b.com</a></p>` + "\n",
+		},
+		{
+			name: "hard break",
+			src:  "a  \nb\\\nc",
+			html: "<p>a<br>\nb<br>\nc</p>\n",
+		},
+		{
+			name: "fenced code",
+			src:  "```Go\nif a < b {\n}\n```\n\n~~~\n#include <iostream>\nusing namespace std;\n~~~",
+			html: "<pre><code class=\"language-go\">if a &lt; b {\n}\n</code></pre>\n" +
+				"<pre><code class=\"language-cpp\">#include &lt;iostream&gt;\nusing namespace std;\n</code></pre>\n",
+		},
+		{
+			name: "indented code",
+			src:  "    x := 1\n\n    y := 2\n\ntext",
+			html: "<pre><code class=\"language-go\">x := 1\n\ny := 2\n</code></pre>\n<p>text</p>\n",
+		},
+		{
+			name: "math",
+			src:  "price $5 and $10, $a^2<b$\n\n$$\n\\sum_{i=1}^n i\n$$",
+			html: "<p>price $5 and $10, <span class=\"math math-inline\">a^2&lt;b</span></p>\n" +
+				"<div class=\"math math-display\">\\sum_{i=1}^n i</div>\n",
+		},
+		{
+			name: "tight nested list",
+			src:  "- a\n- b\n  1. c\n  2. d\n- e",
+			html: "<ul>\n<li>a</li>\n<li>b\n<ol>\n<li>c</li>\n<li>d</li>\n</ol>\n</li>\n<li>e</li>\n</ul>\n",
+		},
+		{
+			name: "loose list",
+			src:  "3. a\n\n4. b",
+			html: "<ol start=\"3\">\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ol>\n",
+		},
+		{
+			name: "quote and hr",
+			src:  "> a\nlazy\n> # h\n\n---",
+			html: "<blockquote>\n<p>a\nlazy</p>\n<h1 id=\"h\">h</h1>\n</blockquote>\n<hr>\n",
+		},
+		{
+			name: "table",
+			src:  "| a | b |\n|:--|--:|\n| 1 | `x\\|y` |\n| 2 |",
+			html: "<table>\n<thead>\n<tr>\n<th align=\"left\">a</th>\n<th align=\"right\">b</th>\n</tr>\n</thead>\n" +
+				"<tbody>\n<tr>\n<td align=\"left\">1</td>\n<td align=\"right\"><code>x|y</code></td>\n</tr>\n" +
+				"<tr>\n<td align=\"left\">2</td>\n<td align=\"right\"></td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name: "setext heading",
+			src:  "Title\n===\nSub\n---",
+			html: "<h1 id=\"title\">Title</h1>\n<h2 id=\"sub\">Sub</h2>\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.html, Render(tc.src).HTML)
+		})
+	}
+}
+
+func TestRenderTOC(t *testing.T) {
+	res := Render("# 快速 排序\n\n## Step 1: *Partition*\n\n## Step 1: Partition\n\n### !!!")
+
+	assert.Equal(t, []Heading{
+		{Level: 1, Text: "快速 排序", ID: "快速-排序"},
+		{Level: 2, Text: "Step 1: Partition", ID: "step-1-partition"},
+		{Level: 2, Text: "Step 1: Partition", ID: "step-1-partition-1"},
+		{Level: 3, Text: "!!!", ID: "section"},
+	}, res.TOC)
+	assert.Contains(t, res.HTML, `<h2 id="step-1-partition">Step 1: <em>Partition</em></h2>`)
+}
+
+func TestAbstract(t *testing.T) {
+	src := "# 标题\n\n正文**加粗**，[链接](https://a.com)。\n\n```go\nfunc main() {}\n```\n\n- 列表"
+
+	assert.Equal(t, "标题 正文加粗，链接。 列表", Abstract(src, 100))
+	assert.Equal(t, "标题 正文", Abstract(src, 5))
+}
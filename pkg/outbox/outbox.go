@@ -0,0 +1,82 @@
+/*
+事务发件箱
+业务在修改数据的同一个事务里写入待发送的事件，由 Relay 异步投递到 Kafka
+事务提交了事件就一定会被投递，至少一次，消费方需要自己保证幂等
+*/
+
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"gorm.io/gorm"
+)
+
+const (
+	StatusPending uint8 = iota
+	StatusDelivered
+	// StatusFailed 重试次数用完仍然没有投递成功，需要人工处理
+	StatusFailed
+)
+
+// OutboxMessage 发件箱里的一条事件，Payload 是 JSON
+type OutboxMessage struct {
+	ID       uint64 `gorm:"primaryKey,autoIncrement"`
+	Topic    string `gorm:"type:varchar(128)"`
+	Key      string `gorm:"type:varchar(128)"`
+	Payload  []byte `gorm:"type:blob"`
+	Status   uint8  `gorm:"index:status_id,priority:1"`
+	Attempts int
+	Ctime    int64
+	Utime    int64 `gorm:"index"`
+}
+
+// Event 待写入的事件，Payload 会被序列化成 JSON，Key 为空时由 Kafka 随机选择分区
+type Event struct {
+	Topic   string
+	Key     string
+	Payload any
+}
+
+// Append 写入事件，tx 是业务所在的事务时事件和业务数据一起提交或回滚
+func Append(tx *gorm.DB, evts ...Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	msgs := make([]OutboxMessage, 0, len(evts))
+	for _, evt := range evts {
+		data, err := sonic.Marshal(evt.Payload)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, OutboxMessage{
+			Topic:   evt.Topic,
+			Key:     evt.Key,
+			Payload: data,
+			Status:  StatusPending,
+			Ctime:   now,
+			Utime:   now,
+		})
+	}
+
+	return tx.Create(&msgs).Error
+}
+
+// Outbox 业务数据不在 MySQL 里、没法共用事务时使用，只能在业务成功之后再写入
+type Outbox struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Outbox {
+	return &Outbox{
+		db: db,
+	}
+}
+
+func (o *Outbox) Append(ctx context.Context, evts ...Event) error {
+	return Append(o.db.WithContext(ctx), evts...)
+}
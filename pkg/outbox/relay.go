@@ -0,0 +1,224 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RelayConfig struct {
+	// Interval 发件箱为空时多久再查一次
+	Interval  time.Duration
+	BatchSize int
+	// Retention 已投递的事件保留多久，<= 0 表示不清理
+	Retention time.Duration
+	// MaxAttempts 一条事件最多投递几次，用完之后标记为 StatusFailed 不再重试
+	MaxAttempts int
+}
+
+// Relay 把发件箱里的事件投递到 Kafka
+// 每批在一个事务里用 FOR UPDATE SKIP LOCKED 锁住，多个实例同时运行时不会重复取到同一批
+// 发送成功但标记失败时下一轮会重新发送，所以是至少一次
+// 投递失败的事件不会挡住后面的事件，所以重试时同一个 key 的事件可能乱序
+type Relay struct {
+	db       *gorm.DB
+	producer sarama.SyncProducer
+	cfg      RelayConfig
+}
+
+func NewRelay(db *gorm.DB, producer sarama.SyncProducer, cfg RelayConfig) *Relay {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+
+	return &Relay{
+		db:       db,
+		producer: producer,
+		cfg:      cfg,
+	}
+}
+
+// Run 阻塞执行，直到 ctx 结束
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if n, err := r.RunOnce(ctx); err != nil {
+			log.Printf("relay outbox failed after %d delivered: %v", n, err)
+		}
+		if err := r.purge(ctx); err != nil {
+			log.Printf("purge outbox failed: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce 按 id 从小到大把发件箱扫一遍，返回本轮投递的条数
+// 发送失败的事件跳过，继续投递后面的批次，最后返回最近一次的发送错误
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	var (
+		total   int
+		after   uint64
+		sendErr error
+	)
+	for ctx.Err() == nil {
+		n, last, err := r.deliver(ctx, after)
+		total += n
+		var se *sendError
+		if errors.As(err, &se) {
+			sendErr = se.err
+		} else if err != nil {
+			return total, err
+		}
+		// 一批都没取到，这一轮扫完了
+		if last == 0 {
+			return total, sendErr
+		}
+		after = last
+	}
+
+	return total, ctx.Err()
+}
+
+// sendError 只有发送失败，数据库里的状态已经更新
+type sendError struct {
+	err error
+}
+
+func (e *sendError) Error() string {
+	return e.err.Error()
+}
+
+// deliver 投递 id 在 after 之后的一批，返回投递成功的条数和这一批最大的 id
+// 部分失败时成功的照常标记，失败的留到下一轮，次数用完的标记为 StatusFailed
+func (r *Relay) deliver(ctx context.Context, after uint64) (int, uint64, error) {
+	var (
+		delivered int
+		last      uint64
+		sendErr   error
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var msgs []OutboxMessage
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND id > ?", StatusPending, after).
+			Order("id").
+			Limit(r.cfg.BatchSize).
+			Find(&msgs).Error
+		if err != nil || len(msgs) == 0 {
+			return err
+		}
+		last = msgs[len(msgs)-1].ID
+
+		pms := make([]*sarama.ProducerMessage, 0, len(msgs))
+		for _, msg := range msgs {
+			pm := &sarama.ProducerMessage{
+				Topic: msg.Topic,
+				Value: sarama.ByteEncoder(msg.Payload),
+				Headers: []sarama.RecordHeader{
+					{Key: []byte("outbox_id"), Value: []byte(strconv.FormatUint(msg.ID, 10))},
+				},
+			}
+			if msg.Key != "" {
+				pm.Key = sarama.StringEncoder(msg.Key)
+			}
+			pms = append(pms, pm)
+		}
+
+		sendErr = r.producer.SendMessages(pms)
+		failed := make(map[*sarama.ProducerMessage]struct{})
+		var perrs sarama.ProducerErrors
+		if errors.As(sendErr, &perrs) {
+			for _, pe := range perrs {
+				failed[pe.Msg] = struct{}{}
+			}
+		} else if sendErr != nil {
+			for _, pm := range pms {
+				failed[pm] = struct{}{}
+			}
+		}
+
+		var ok, retry, dead []uint64
+		for i, pm := range pms {
+			_, has := failed[pm]
+			switch {
+			case !has:
+				ok = append(ok, msgs[i].ID)
+			case msgs[i].Attempts+1 >= r.cfg.MaxAttempts:
+				dead = append(dead, msgs[i].ID)
+			default:
+				retry = append(retry, msgs[i].ID)
+			}
+		}
+
+		now := time.Now().UnixMilli()
+		if len(ok) > 0 {
+			err = tx.Model(&OutboxMessage{}).Where("id IN ?", ok).Updates(map[string]any{
+				"status":   StatusDelivered,
+				"attempts": gorm.Expr("attempts + 1"),
+				"utime":    now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(retry) > 0 {
+			err = tx.Model(&OutboxMessage{}).Where("id IN ?", retry).Updates(map[string]any{
+				"attempts": gorm.Expr("attempts + 1"),
+				"utime":    now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(dead) > 0 {
+			log.Printf("outbox messages %v failed after %d attempts", dead, r.cfg.MaxAttempts)
+			err = tx.Model(&OutboxMessage{}).Where("id IN ?", dead).Updates(map[string]any{
+				"status":   StatusFailed,
+				"attempts": gorm.Expr("attempts + 1"),
+				"utime":    now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		delivered = len(ok)
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if sendErr != nil {
+		return delivered, last, &sendError{err: sendErr}
+	}
+
+	return delivered, last, nil
+}
+
+// purge 清理保留期之前已经投递的事件
+func (r *Relay) purge(ctx context.Context) error {
+	if r.cfg.Retention <= 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-r.cfg.Retention).UnixMilli()
+	return r.db.WithContext(ctx).
+		Where("status = ? AND utime < ?", StatusDelivered, before).
+		Delete(&OutboxMessage{}).Error
+}
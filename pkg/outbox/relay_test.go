@@ -0,0 +1,109 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// 依赖本地的 MySQL，没有配置时跳过
+// OUTBOX_TEST_MYSQL_DSN=root:root@tcp(localhost:3306)/oj_test
+
+type fakeProducer struct {
+	sarama.SyncProducer
+	// fail 这些 topic 的消息发送失败
+	fail map[string]bool
+	sent []*sarama.ProducerMessage
+}
+
+func (p *fakeProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	var errs sarama.ProducerErrors
+	for _, msg := range msgs {
+		if p.fail[msg.Topic] {
+			errs = append(errs, &sarama.ProducerError{Msg: msg, Err: errors.New("broker down")})
+			continue
+		}
+		p.sent = append(p.sent, msg)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func openMySQL(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("OUTBOX_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("OUTBOX_TEST_MYSQL_DSN not set")
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&OutboxMessage{}))
+	require.NoError(t, db.Exec("TRUNCATE TABLE outbox_messages").Error)
+
+	return db
+}
+
+func TestRelay(t *testing.T) {
+	db := openMySQL(t)
+	ctx := context.Background()
+
+	// 回滚的事务里写入的事件不会投递
+	_ = db.Transaction(func(tx *gorm.DB) error {
+		require.NoError(t, Append(tx, Event{Topic: "a", Payload: 0}))
+		return errors.New("rollback")
+	})
+	require.NoError(t, Append(db, Event{Topic: "a", Key: "1", Payload: 1}, Event{Topic: "b", Payload: 2}, Event{Topic: "a", Payload: 3}))
+
+	producer := &fakeProducer{fail: map[string]bool{"b": true}}
+	relay := NewRelay(db, producer, RelayConfig{BatchSize: 2})
+
+	// 失败的不挡住后面的批次
+	n, err := relay.RunOnce(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, producer.sent, 2)
+	assert.Equal(t, sarama.StringEncoder("1"), producer.sent[0].Key)
+	assert.Equal(t, "a", producer.sent[1].Topic)
+
+	// 失败的留在发件箱，恢复之后补发
+	producer.fail = nil
+	n, err = relay.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, producer.sent, 3)
+	assert.Equal(t, "b", producer.sent[2].Topic)
+
+	var pending int64
+	require.NoError(t, db.Model(&OutboxMessage{}).Where("status = ?", StatusPending).Count(&pending).Error)
+	assert.Zero(t, pending)
+}
+
+func TestRelay_MaxAttempts(t *testing.T) {
+	db := openMySQL(t)
+	ctx := context.Background()
+
+	require.NoError(t, Append(db, Event{Topic: "poison", Payload: 1}, Event{Topic: "a", Payload: 2}))
+	producer := &fakeProducer{fail: map[string]bool{"poison": true}}
+	relay := NewRelay(db, producer, RelayConfig{BatchSize: 1, MaxAttempts: 2})
+
+	for i := 0; i < 3; i++ {
+		_, _ = relay.RunOnce(ctx)
+	}
+
+	// 用完两次之后不再重试，后面的事件照常投递
+	var msgs []OutboxMessage
+	require.NoError(t, db.Order("id").Find(&msgs).Error)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, StatusFailed, msgs[0].Status)
+	assert.Equal(t, 2, msgs[0].Attempts)
+	assert.Equal(t, StatusDelivered, msgs[1].Status)
+	require.Len(t, producer.sent, 1)
+}
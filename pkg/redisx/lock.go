@@ -0,0 +1,83 @@
+/*
+基于 SETNX 的分布式锁
+value 是每次加锁时生成的随机串，续约和释放都要先比较 value，保证只操作自己的锁
+*/
+
+package redisx
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	//go:embed lua/unlock.lua
+	luaUnlock string
+	//go:embed lua/refresh.lua
+	luaRefresh string
+
+	// ErrLockHeld 锁被别人持有
+	ErrLockHeld = errors.New("lock is held by others")
+	// ErrLockLost 锁已经过期或者被别人拿走
+	ErrLockLost = errors.New("lock is lost")
+)
+
+type Lock struct {
+	cmd   redis.Cmdable
+	key   string
+	value string
+	ttl   time.Duration
+}
+
+// TryLock 只尝试一次，拿不到时返回 ErrLockHeld
+func TryLock(ctx context.Context, cmd redis.Cmdable, key string, ttl time.Duration) (*Lock, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	value := hex.EncodeToString(buf)
+
+	ok, err := cmd.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLockHeld
+	}
+
+	return &Lock{
+		cmd:   cmd,
+		key:   key,
+		value: value,
+		ttl:   ttl,
+	}, nil
+}
+
+// Refresh 续约，把过期时间重新设为加锁时的 ttl
+func (l *Lock) Refresh(ctx context.Context) error {
+	res, err := l.cmd.Eval(ctx, luaRefresh, []string{l.key}, l.value, l.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (l *Lock) Unlock(ctx context.Context) error {
+	res, err := l.cmd.Eval(ctx, luaUnlock, []string{l.key}, l.value).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockLost
+	}
+	return nil
+}
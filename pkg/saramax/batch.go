@@ -0,0 +1,198 @@
+package saramax
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+// BatchConfig 零值字段使用默认值
+type BatchConfig struct {
+	// Size 攒够多少条消息处理一次，默认 100
+	Size int
+	// Interval 最多等多久处理一次，不足 Size 条也处理，默认 1s
+	Interval time.Duration
+	// MaxRetries 处理失败后的重试次数，默认 3，小于 0 表示不重试
+	MaxRetries int
+	// Backoff 第一次重试前的等待时间，之后每次翻倍，默认 100ms
+	Backoff time.Duration
+	// DeadLetter 重试之后仍然失败、或者反序列化失败的消息发到这个 topic
+	// 为空时只记录日志然后丢弃
+	DeadLetter string
+	Producer   sarama.SyncProducer
+}
+
+// BatchHandler 按数量和时间窗口批量处理消息
+// 一批消息要么全部处理成功，要么全部进入死信 topic，之后统一提交
+// 死信也发送失败时结束这次会话，从上次提交的位置重新消费
+type BatchHandler[T any] struct {
+	l   *zap.Logger
+	fn  func(msgs []*sarama.ConsumerMessage, ts []T) error
+	cfg BatchConfig
+}
+
+func NewBatchHandler[T any](l *zap.Logger, fn func(msgs []*sarama.ConsumerMessage, ts []T) error, cfg BatchConfig) *BatchHandler[T] {
+	if cfg.Size <= 0 {
+		cfg.Size = 100
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 100 * time.Millisecond
+	}
+
+	return &BatchHandler[T]{
+		l:   l,
+		fn:  fn,
+		cfg: cfg,
+	}
+}
+
+func (h *BatchHandler[T]) Setup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *BatchHandler[T]) Cleanup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *BatchHandler[T]) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	msgs := claim.Messages()
+	for {
+		batch := make([]*sarama.ConsumerMessage, 0, h.cfg.Size)
+		ts := make([]T, 0, h.cfg.Size)
+		done := false
+		// last 这一轮读到的最后一条消息，包括转发到死信的，批处理完成后才提交
+		var last *sarama.ConsumerMessage
+
+		timer := time.NewTimer(h.cfg.Interval)
+	collect:
+		for len(batch) < h.cfg.Size {
+			select {
+			case msg, ok := <-msgs:
+				if !ok {
+					done = true
+					break collect
+				}
+				last = msg
+				var t T
+				if err := json.Unmarshal(msg.Value, &t); err != nil {
+					h.l.Error("反序列化消息失败",
+						zap.Error(err),
+						zap.String("topic", msg.Topic),
+						zap.Int32("partition", msg.Partition),
+						zap.Int64("offset", msg.Offset))
+					if err = h.deadLetter(err, msg); err != nil {
+						timer.Stop()
+						return err
+					}
+					// 前面的消息还没处理，这里不能提交
+					continue
+				}
+				batch = append(batch, msg)
+				ts = append(ts, t)
+			case <-timer.C:
+				break collect
+			case <-session.Context().Done():
+				// 没处理的这一批不提交，重新分配后会再消费
+				timer.Stop()
+				return nil
+			}
+		}
+		timer.Stop()
+
+		if len(batch) > 0 {
+			ok, err := h.handle(session, batch, ts)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+		}
+		if last != nil {
+			// 同一个 claim 里的消息属于同一个分区，提交最后一条即可
+			session.MarkMessage(last, "")
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// handle 处理一批消息，重试用完后转发到死信；会话结束时返回 false，这一批不提交
+func (h *BatchHandler[T]) handle(session sarama.ConsumerGroupSession, batch []*sarama.ConsumerMessage, ts []T) (bool, error) {
+	err := h.fn(batch, ts)
+	backoff := h.cfg.Backoff
+	for i := 0; err != nil && i < h.cfg.MaxRetries; i++ {
+		h.l.Warn("批量处理消息失败，准备重试",
+			zap.Error(err),
+			zap.Int("retry", i+1),
+			zap.Int("size", len(batch)))
+
+		select {
+		case <-time.After(backoff):
+		case <-session.Context().Done():
+			return false, nil
+		}
+		backoff *= 2
+		err = h.fn(batch, ts)
+	}
+
+	if err != nil {
+		last := batch[len(batch)-1]
+		h.l.Error("批量处理消息失败",
+			zap.Error(err),
+			zap.String("topic", last.Topic),
+			zap.Int32("partition", last.Partition),
+			zap.Int64("offset", last.Offset),
+			zap.Int("size", len(batch)))
+		if err = h.deadLetter(err, batch...); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// deadLetter 原样转发消息，在 header 里带上来源和失败原因
+func (h *BatchHandler[T]) deadLetter(cause error, msgs ...*sarama.ConsumerMessage) error {
+	if h.cfg.DeadLetter == "" || h.cfg.Producer == nil {
+		return nil
+	}
+
+	pms := make([]*sarama.ProducerMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		pm := &sarama.ProducerMessage{
+			Topic: h.cfg.DeadLetter,
+			Value: sarama.ByteEncoder(msg.Value),
+			Headers: []sarama.RecordHeader{
+				{Key: []byte("origin_topic"), Value: []byte(msg.Topic)},
+				{Key: []byte("origin_partition"), Value: []byte(strconv.FormatInt(int64(msg.Partition), 10))},
+				{Key: []byte("origin_offset"), Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+				{Key: []byte("error"), Value: []byte(cause.Error())},
+			},
+		}
+		if msg.Key != nil {
+			pm.Key = sarama.ByteEncoder(msg.Key)
+		}
+		pms = append(pms, pm)
+	}
+
+	err := h.cfg.Producer.SendMessages(pms)
+	if err != nil {
+		h.l.Error("发送死信消息失败",
+			zap.Error(err),
+			zap.String("topic", h.cfg.DeadLetter),
+			zap.Int("size", len(msgs)))
+	}
+
+	return err
+}
@@ -0,0 +1,158 @@
+package saramax
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type event struct {
+	ID int `json:"id"`
+}
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	msgs chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.msgs }
+
+type fakeProducer struct {
+	sarama.SyncProducer
+	err  error
+	sent []*sarama.ProducerMessage
+}
+
+func (p *fakeProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, msgs...)
+	return nil
+}
+
+func newClaim(values ...string) *fakeClaim {
+	ch := make(chan *sarama.ConsumerMessage, len(values))
+	for i, v := range values {
+		ch <- &sarama.ConsumerMessage{Topic: "t", Offset: int64(i), Value: []byte(v)}
+	}
+	close(ch)
+	return &fakeClaim{msgs: ch}
+}
+
+func TestBatchHandler(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   []string
+		fails    int
+		sendErr  error
+		wantErr  error
+		wantSize []int
+		wantDLQ  int
+		marked   []int64
+	}{
+		{
+			name:     "split by size",
+			values:   []string{`{"id":1}`, `{"id":2}`, `{"id":3}`},
+			wantSize: []int{2, 1},
+			marked:   []int64{1, 2},
+		},
+		{
+			name:     "retry then succeed",
+			values:   []string{`{"id":1}`},
+			fails:    2,
+			wantSize: []int{1, 1, 1},
+			marked:   []int64{0},
+		},
+		{
+			name:     "dead letter after retries",
+			values:   []string{`bad`, `{"id":1}`, `{"id":2}`},
+			fails:    10,
+			wantSize: []int{2, 2, 2},
+			wantDLQ:  3,
+			marked:   []int64{2},
+		},
+		{
+			name:    "only bad messages",
+			values:  []string{`bad`, `bad`},
+			wantDLQ: 2,
+			marked:  []int64{1},
+		},
+		{
+			name:     "dead letter failed",
+			values:   []string{`{"id":1}`},
+			fails:    10,
+			sendErr:  errors.New("kafka down"),
+			wantErr:  errors.New("kafka down"),
+			wantSize: []int{1, 1, 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sizes []int
+			calls := 0
+			producer := &fakeProducer{err: tc.sendErr}
+			h := NewBatchHandler[event](zap.NewNop(), func(msgs []*sarama.ConsumerMessage, ts []event) error {
+				calls++
+				sizes = append(sizes, len(ts))
+				if calls <= tc.fails {
+					return errors.New("db down")
+				}
+				return nil
+			}, BatchConfig{
+				Size:       2,
+				Interval:   time.Second,
+				MaxRetries: 2,
+				Backoff:    time.Millisecond,
+				DeadLetter: "t_dlq",
+				Producer:   producer,
+			})
+			session := &fakeSession{ctx: context.Background()}
+
+			err := h.ConsumeClaim(session, newClaim(tc.values...))
+
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSize, sizes)
+			assert.Equal(t, tc.marked, session.marked)
+			require.Len(t, producer.sent, tc.wantDLQ)
+			for _, msg := range producer.sent {
+				assert.Equal(t, "t_dlq", msg.Topic)
+			}
+		})
+	}
+}
+
+func TestBatchHandlerInterval(t *testing.T) {
+	ch := make(chan *sarama.ConsumerMessage, 1)
+	ch <- &sarama.ConsumerMessage{Value: []byte(`{"id":1}`)}
+	got := make(chan int, 1)
+	h := NewBatchHandler[event](zap.NewNop(), func(msgs []*sarama.ConsumerMessage, ts []event) error {
+		got <- ts[0].ID
+		close(ch)
+		return nil
+	}, BatchConfig{Size: 10, Interval: 10 * time.Millisecond})
+
+	err := h.ConsumeClaim(&fakeSession{ctx: context.Background()}, &fakeClaim{msgs: ch})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, <-got)
+}